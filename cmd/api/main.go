@@ -11,11 +11,16 @@ import (
 
 	"question-service/internal/app"
 	"question-service/internal/config"
-	"question-service/internal/db"
+	"question-service/internal/gqlapi"
+	"question-service/internal/grpcapi"
 	httptransport "question-service/internal/http"
 	"question-service/internal/logger"
-	"question-service/internal/repository"
+	"question-service/internal/outbox"
+	"question-service/internal/realtime"
 	"question-service/internal/service"
+	"question-service/internal/transfer"
+	"question-service/internal/webhook"
+	"question-service/internal/worker"
 )
 
 func main() {
@@ -31,25 +36,97 @@ func run() error {
 
 	cfg := config.Load()
 
-	conn, err := db.New(cfg, log)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	broker := realtime.NewBroker(cfg.SSEReplayBufferSize)
+
+	store, err := newStorage(ctx, cfg, broker, log)
 	if err != nil {
 		return err
 	}
+	defer store.close()
 
-	qRepo := repository.NewQuestionRepository(conn)
-	aRepo := repository.NewAnswerRepository(conn)
+	publisher, err := newPublisher(cfg, log)
+	if err != nil {
+		return err
+	}
 
-	qSvc := service.NewQuestionService(qRepo)
-	aSvc := service.NewAnswerService(aRepo, qRepo)
+	qSvc := service.NewQuestionService(store.questions, store.outbox, store.tx)
+	aSvc := service.NewAnswerService(store.answers, store.questions, store.outbox, store.tx, store.notifier)
+	aSvc.SetMaxReplyDepth(cfg.AnswerMaxReplyDepth)
+	qSvc.SetDuplicateThreshold(cfg.DuplicateThreshold)
 
-	router := httptransport.NewRouter(qSvc, aSvc, log)
+	ws := httptransport.NewWSHandler(qSvc, aSvc, broker, log, httptransport.WSConfig{
+		OriginPatterns: cfg.WSAllowedOrigins,
+		SendQueueSize:  cfg.WSSendQueueSize,
+		WriteTimeout:   cfg.WSWriteTimeout,
+		PingInterval:   cfg.WSPingInterval,
+	})
 
-	application := app.NewApp(log, app.Config{
-		Address: cfg.HTTPPort,
-	}, router, conn)
+	gql, err := gqlapi.NewHandler(qSvc, aSvc, log)
+	if err != nil {
+		return err
+	}
 
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
-	defer stop()
+	trash := service.NewTrashService(store.questions, store.answers)
+	opts := httptransport.Options{
+		RequireIfMatch:   cfg.HTTPRequireIfMatch,
+		BatchMaxItems:    cfg.HTTPBatchMaxItems,
+		Idempotency:      store.idempotency,
+		IdempotencyTTL:   cfg.IdempotencyTTL,
+		IdempotencyLease: cfg.IdempotencyLease,
+		Comments:         service.NewCommentService(store.comments, store.questions, store.answers, store.tx),
+
+		ReadYourWritesWindow: cfg.DBReadYourWritesWindow,
+
+		Events:                  broker,
+		EventsHeartbeat:         cfg.SSEHeartbeatInterval,
+		EventsMaxConnsPerClient: cfg.SSEMaxConnectionsPerClient,
+		WebSocket:               ws,
+		GraphQL:                 gql,
+	}
+	if cfg.AdminToken != "" {
+		opts.Trash = trash
+		webhooks := service.NewWebhookService(store.webhooks)
+		webhooks.SetAllowPrivateTargets(cfg.WebhookAllowPrivateTargets)
+		opts.Webhooks = webhooks
+		opts.Transfer = transfer.NewService(store.questions, store.answers, store.tx)
+		opts.Moderation = service.NewModerationService(store.moderation, store.questions, store.answers, qSvc, store.tx)
+		opts.AdminToken = cfg.AdminToken
+	}
+	router := httptransport.NewRouter(qSvc, aSvc, log, opts)
+
+	application := app.NewApp(log, app.Config{
+		Address:     cfg.HTTPPort,
+		GRPCAddress: cfg.GRPCPort,
+	}, router, store.db)
+	if cfg.GRPCEnabled {
+		grpcServer, grpcHealth := grpcapi.New(qSvc, aSvc, log)
+		application.GRPCServer = grpcServer
+		application.OnShutdown(grpcHealth.Shutdown)
+	}
+	application.OnShutdown(broker.Close)
+	application.OnShutdown(ws.Close)
+	for name, w := range store.workers {
+		application.AddWorker(name, w)
+	}
+	application.AddWorker("idempotency-janitor", worker.IdempotencyJanitor(store.idempotency, cfg.IdempotencyJanitorInterval, log))
+	application.AddWorker("trash-purger", worker.TrashPurger(trash, cfg.TrashRetention, cfg.TrashPurgeInterval, log))
+	relay := outbox.NewRelay(store.outbox, publisher, cfg.OutboxPollInterval, cfg.OutboxBatchSize, log)
+	relay.SetMaxAttempts(cfg.OutboxMaxAttempts)
+	application.AddWorker("outbox-relay", relay.Run)
+	application.AddWorker("webhook-fanout", webhook.NewFanout(store.outbox, store.webhooks, cfg.OutboxPollInterval, cfg.OutboxBatchSize, log).Run)
+	application.AddWorker("webhook-dispatcher", webhook.NewDispatcher(store.webhooks, webhook.DispatcherConfig{
+		Interval:            cfg.WebhookPollInterval,
+		BatchSize:           cfg.WebhookBatchSize,
+		Timeout:             cfg.WebhookTimeout,
+		MaxAttempts:         cfg.WebhookMaxAttempts,
+		DisableAfter:        cfg.WebhookDisableAfterFailures,
+		RetryInitialDelay:   cfg.WebhookRetryInitialDelay,
+		RetryMaxDelay:       cfg.WebhookRetryMaxDelay,
+		AllowPrivateTargets: cfg.WebhookAllowPrivateTargets,
+	}, log).Run)
 
 	if err := application.Run(ctx); err != nil {
 		log.Error("application stopped with error", zap.Error(err))
@@ -58,3 +135,18 @@ func run() error {
 
 	return nil
 }
+
+// newPublisher создаёт публикатор доменных событий, выбранный в конфигурации.
+func newPublisher(cfg *config.Config, log *logger.Logger) (outbox.Publisher, error) {
+	switch cfg.OutboxPublisher {
+	case "log":
+		return outbox.NewLogPublisher(log), nil
+	case "webhook":
+		if cfg.OutboxWebhookURL == "" {
+			return nil, fmt.Errorf("OUTBOX_WEBHOOK_URL is required for the webhook publisher")
+		}
+		return outbox.NewWebhookPublisher(cfg.OutboxWebhookURL, cfg.OutboxWebhookTimeout), nil
+	default:
+		return nil, fmt.Errorf("unknown outbox publisher %q", cfg.OutboxPublisher)
+	}
+}
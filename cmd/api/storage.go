@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"question-service/internal/app"
+	"question-service/internal/config"
+	"question-service/internal/db"
+	"question-service/internal/logger"
+	"question-service/internal/realtime"
+	"question-service/internal/repository"
+	"question-service/internal/service"
+)
+
+// storage объединяет репозитории выбранного бэкенда хранения.
+type storage struct {
+	questions   repository.QuestionRepository
+	answers     repository.AnswerRepository
+	comments    repository.CommentRepository
+	moderation  repository.ModerationRepository
+	idempotency repository.IdempotencyRepository
+	outbox      repository.OutboxRepository
+	webhooks    repository.WebhookRepository
+	tx          service.TxManager
+	// notifier оповещает брокер событий (всех экземпляров, если их несколько).
+	notifier service.Notifier
+
+	// db — подключение к primary; nil для хранилища в памяти.
+	db      *gorm.DB
+	workers map[string]app.Worker
+	close   func() error
+}
+
+func newStorage(ctx context.Context, cfg *config.Config, broker *realtime.Broker, log *logger.Logger) (*storage, error) {
+	switch cfg.StorageBackend {
+	case "postgres":
+		return newPostgresStorage(ctx, cfg, broker, log)
+	case "memory":
+		log.Warn("using in-memory storage, data will be lost on restart")
+		return newMemoryStorage(broker), nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.StorageBackend)
+	}
+}
+
+func newPostgresStorage(ctx context.Context, cfg *config.Config, broker *realtime.Broker, log *logger.Logger) (*storage, error) {
+	conn, err := db.New(ctx, cfg, log)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.AutoMigrate {
+		err = db.Migrate(ctx, conn, log)
+	} else {
+		err = db.CheckSchema(ctx, conn, log)
+	}
+	if err != nil {
+		if sqlDB, dbErr := conn.DB(); dbErr == nil {
+			_ = sqlDB.Close()
+		}
+		return nil, err
+	}
+
+	cluster, err := db.NewCluster(conn, cfg, log)
+	if err != nil {
+		return nil, err
+	}
+
+	outbox := repository.NewOutboxRepository(cluster)
+
+	return &storage{
+		questions:   repository.NewQuestionRepository(cluster),
+		answers:     repository.NewAnswerRepository(cluster),
+		comments:    repository.NewCommentRepository(cluster),
+		moderation:  repository.NewModerationRepository(cluster),
+		idempotency: repository.NewIdempotencyRepository(cluster),
+		outbox:      outbox,
+		webhooks:    repository.NewWebhookRepository(cluster),
+		tx:          db.NewTxManager(cluster),
+		notifier:    realtime.NewPGNotifier(cluster, log),
+		db:          conn,
+		workers: map[string]app.Worker{
+			"db-replica-health": cluster.RunHealthChecks,
+			"realtime-listener": realtime.NewListener(cfg.DSN(), outbox, broker, log).Run,
+		},
+		close: cluster.Close,
+	}, nil
+}
+
+func newMemoryStorage(broker *realtime.Broker) *storage {
+	store := repository.NewMemoryStore()
+
+	return &storage{
+		questions:   repository.NewMemoryQuestionRepository(store),
+		answers:     repository.NewMemoryAnswerRepository(store),
+		comments:    repository.NewMemoryCommentRepository(store),
+		moderation:  repository.NewMemoryModerationRepository(store),
+		idempotency: repository.NewMemoryIdempotencyRepository(store),
+		outbox:      repository.NewMemoryOutboxRepository(store),
+		webhooks:    repository.NewMemoryWebhookRepository(store),
+		tx:          store,
+		notifier:    broker,
+		close:       func() error { return nil },
+	}
+}
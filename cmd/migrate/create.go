@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/pressly/goose/v3"
+)
+
+var nonWord = regexp.MustCompile(`[^a-z0-9]+`)
+
+var sqlTemplate = template.Must(template.New("sql").Parse(`-- +goose Up
+
+-- +goose Down
+`))
+
+// Go-миграции регистрируются в пакете migrations и попадают в бинарник вместе с ним.
+var goTemplate = template.Must(template.New("go").Parse(`package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(up{{.Func}}, down{{.Func}})
+}
+
+func up{{.Func}}(ctx context.Context, tx *sql.Tx) error {
+	return nil
+}
+
+func down{{.Func}}(ctx context.Context, tx *sql.Tx) error {
+	return nil
+}
+`))
+
+// create создаёт файл миграции с номером на единицу больше последнего:
+// 0007_add_comments.sql — в том же формате, что и существующие миграции.
+func create(dir, name, kind string) (string, error) {
+	var tmpl *template.Template
+	switch kind {
+	case "sql":
+		tmpl = sqlTemplate
+	case "go":
+		tmpl = goTemplate
+	default:
+		return "", usageError(fmt.Sprintf("create: unknown migration type %q, want sql or go", kind))
+	}
+
+	snake := strings.Trim(nonWord.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if snake == "" {
+		return "", usageError(fmt.Sprintf("create: invalid migration name %q", name))
+	}
+
+	var next int64 = 1
+	migrations, err := collect(nil, dir)
+	switch {
+	case err == nil:
+		if last, err := migrations.Last(); err == nil {
+			next = last.Version + 1
+		}
+	case !errors.Is(err, goose.ErrNoMigrationFiles):
+		return "", err
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("%04d_%s.%s", next, snake, kind))
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var camel strings.Builder
+	for _, part := range strings.Split(snake, "_") {
+		camel.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	if err := tmpl.Execute(f, struct{ Func string }{camel.String()}); err != nil {
+		return "", err
+	}
+	return path, f.Close()
+}
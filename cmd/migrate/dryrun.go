@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"slices"
+
+	"github.com/pressly/goose/v3"
+)
+
+// step — миграция и направление, в котором её выполнит команда.
+type step struct {
+	m  *goose.Migration
+	up bool
+}
+
+// plan возвращает миграции, которые выполнит command при текущей версии current.
+func plan(migrations goose.Migrations, current int64, command string, target int64) ([]step, error) {
+	var steps []step
+
+	switch command {
+	case "up", "up-to":
+		if command == "up" {
+			target = goose.MaxVersion
+		}
+		for _, m := range migrations {
+			if m.Version > current && m.Version <= target {
+				steps = append(steps, step{m: m, up: true})
+			}
+		}
+
+	case "down-to":
+		for _, m := range slices.Backward(migrations) {
+			if m.Version > target && m.Version <= current {
+				steps = append(steps, step{m: m})
+			}
+		}
+
+	case "down", "redo":
+		m, err := migrations.Current(current)
+		if err != nil {
+			return nil, fmt.Errorf("no applied migration to %s: %w", command, err)
+		}
+		steps = append(steps, step{m: m})
+		if command == "redo" {
+			steps = append(steps, step{m: m, up: true})
+		}
+	}
+	return steps, nil
+}
+
+// dryRunCommand печатает SQL, который выполнила бы command, ничего не меняя в базе.
+func dryRunCommand(ctx context.Context, db *sql.DB, fsys fs.FS, dir, command string, target int64, w io.Writer) error {
+	current, err := appliedVersion(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	migrations, err := collect(fsys, dir)
+	if err != nil {
+		return err
+	}
+
+	steps, err := plan(migrations, current, command, target)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "-- current version: %d\n", current)
+	if len(steps) == 0 {
+		fmt.Fprintln(w, "-- nothing to do")
+		return nil
+	}
+
+	for _, s := range steps {
+		direction := "down"
+		if s.up {
+			direction = "up"
+		}
+		name := filepath.Base(s.m.Source)
+		if s.m.Type != goose.TypeSQL {
+			fmt.Fprintf(w, "\n-- %s (%s): Go migration, SQL is not available\n", name, direction)
+			continue
+		}
+
+		data, err := readSource(fsys, s.m.Source)
+		if err != nil {
+			return err
+		}
+		parsed, err := parseSQLMigration(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", s.m.Source, err)
+		}
+
+		query := parsed.down
+		if s.up {
+			query = parsed.up
+		}
+		fmt.Fprintf(w, "\n-- %s (%s)\n%s\n", name, direction, query)
+	}
+	return nil
+}
+
+// appliedVersion читает текущую версию схемы, не создавая таблицу версий goose,
+// как это делает goose.GetDBVersion.
+func appliedVersion(ctx context.Context, db *sql.DB) (int64, error) {
+	var exists bool
+	if err := db.QueryRowContext(ctx, `SELECT to_regclass('goose_db_version') IS NOT NULL`).Scan(&exists); err != nil {
+		return 0, fmt.Errorf("check goose version table: %w", err)
+	}
+	if !exists {
+		return 0, nil
+	}
+
+	var version int64
+	if err := db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version_id), 0) FROM goose_db_version`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("get schema version: %w", err)
+	}
+	return version, nil
+}
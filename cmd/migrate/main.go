@@ -1,62 +1,248 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"io/fs"
 	"log"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
 
 	_ "github.com/lib/pq"
 	"github.com/pressly/goose/v3"
 
 	"question-service/internal/config"
+	"question-service/migrations"
 )
 
-const migrationsDir = "migrations"
+const defaultMigrationsDir = "migrations"
+
+// Коды выхода.
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+const usage = `Usage: migrate [flags] <command> [args]
+
+Commands:
+  up               apply all pending migrations
+  up-to VERSION    apply pending migrations up to VERSION
+  down             roll back the last applied migration
+  down-to VERSION  roll back migrations down to VERSION
+  redo             roll back the last applied migration and apply it again
+  status           show applied and pending migrations
+  version          print the current schema version
+  create NAME sql|go
+                   create a new migration file
+  validate         check migration files without connecting to the database
+
+Flags:
+  --dir DIR        read migrations from DIR instead of the ones embedded in the
+                   binary; create writes to DIR (default "migrations")
+  --dry-run        print the SQL that up, up-to, down, down-to or redo would run
+                   without running it
+
+The database is configured with DATABASE_URL or DB_* variables, as for the API.
+`
+
+// usageError — ошибка в аргументах командной строки.
+type usageError string
+
+func (e usageError) Error() string { return string(e) }
+
+// redactWriter убирает пароли из строк подключения во всём, что пишется в лог.
+type redactWriter struct {
+	w io.Writer
+}
+
+func (r redactWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(r.w, config.RedactDSN(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
 
 func main() {
-	cfg := config.Load()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
 
-	dsn := fmt.Sprintf(
-		"postgres://%s:%s@%s:%s/%s?sslmode=%s",
-		cfg.DBUser,
-		cfg.DBPass,
-		cfg.DBHost,
-		cfg.DBPort,
-		cfg.DBName,
-		cfg.DBSSL,
-	)
+// run выполняет команду и возвращает код выхода.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	logger := log.New(redactWriter{stderr}, "", log.LstdFlags)
+	goose.SetLogger(log.New(redactWriter{stdout}, "[goose] ", log.LstdFlags))
 
-	log.Println("connecting to database for migrations:", dsn)
+	err := execute(ctx, args, stdout, logger)
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, flag.ErrHelp):
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	}
 
-	db, err := sql.Open("postgres", dsn)
-	if err != nil {
-		log.Fatalf("failed to open db: %v", err)
+	var uerr usageError
+	if errors.As(err, &uerr) {
+		fmt.Fprintf(stderr, "migrate: %v\n\n%s", err, usage)
+		return exitUsage
+	}
+	logger.Printf("migrate: %v", err)
+	return exitError
+}
+
+func execute(ctx context.Context, args []string, stdout io.Writer, logger *log.Logger) error {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	dir := flags.String("dir", "", "")
+	dryRun := flags.Bool("dry-run", false, "")
+
+	// флаги можно указывать и до команды, и после неё
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return err
+			}
+			return usageError(err.Error())
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+	if len(positional) == 0 {
+		return usageError("command is required")
+	}
+
+	command, cmdArgs := positional[0], positional[1:]
+
+	// без --dir используются миграции, встроенные в бинарник
+	var fsys fs.FS
+	migrationsDir := *dir
+	if migrationsDir == "" {
+		fsys, migrationsDir = migrations.FS, "."
+	}
+	goose.SetBaseFS(fsys)
+	defer goose.SetBaseFS(nil)
+
+	// команды, которым не нужна база
+	switch command {
+	case "help":
+		return flag.ErrHelp
+	case "create":
+		if len(cmdArgs) != 2 {
+			return usageError("create: expected NAME and type (sql or go)")
+		}
+		createDir := *dir
+		if createDir == "" {
+			createDir = defaultMigrationsDir
+		}
+		path, err := create(createDir, cmdArgs[0], cmdArgs[1])
+		if err != nil {
+			return err
+		}
+		logger.Printf("created %s", path)
+		return nil
+	case "validate":
+		if len(cmdArgs) != 0 {
+			return usageError("validate: unexpected arguments")
+		}
+		n, err := validate(fsys, migrationsDir)
+		if err != nil {
+			return err
+		}
+		logger.Printf("%d migrations are valid", n)
+		return nil
 	}
-	defer db.Close()
 
-	if err := db.Ping(); err != nil {
-		log.Fatalf("failed to ping db: %v", err)
+	var target int64
+	switch command {
+	case "up-to", "down-to":
+		if len(cmdArgs) != 1 {
+			return usageError(command + ": expected VERSION")
+		}
+		v, err := strconv.ParseInt(cmdArgs[0], 10, 64)
+		if err != nil || v < 0 {
+			return usageError(fmt.Sprintf("%s: invalid version %q", command, cmdArgs[0]))
+		}
+		target = v
+	case "up", "down", "redo", "status", "version":
+		if len(cmdArgs) != 0 {
+			return usageError(command + ": unexpected arguments")
+		}
+	default:
+		return usageError(fmt.Sprintf("unknown command %q", command))
 	}
 
-	goose.SetLogger(log.New(os.Stdout, "[goose] ", log.LstdFlags))
+	if *dryRun && (command == "status" || command == "version") {
+		return usageError("--dry-run is supported only for up, up-to, down, down-to and redo")
+	}
+
+	cfg := config.Load()
+	logger.Printf("connecting to database: %s", cfg.DSN())
+
+	db, err := sql.Open("postgres", cfg.DSN())
+	if err != nil {
+		return fmt.Errorf("open db: %w", err)
+	}
+	defer db.Close()
 
+	if err := db.PingContext(ctx); err != nil {
+		return fmt.Errorf("ping db: %w", err)
+	}
 	if err := goose.SetDialect("postgres"); err != nil {
-		log.Fatalf("failed to set goose dialect: %v", err)
+		return fmt.Errorf("set goose dialect: %w", err)
 	}
 
-	log.Println("running migrations from", migrationsDir)
+	if *dryRun {
+		return dryRunCommand(ctx, db, fsys, migrationsDir, command, target, stdout)
+	}
 
-	if err := goose.Up(db, migrationsDir); err != nil {
-		log.Fatalf("failed to run migrations: %v", err)
+	before, err := goose.GetDBVersionContext(ctx, db)
+	if err != nil {
+		return fmt.Errorf("get schema version: %w", err)
 	}
 
-	current, err := goose.EnsureDBVersion(db)
+	switch command {
+	case "up":
+		err = goose.UpContext(ctx, db, migrationsDir)
+	case "up-to":
+		err = goose.UpToContext(ctx, db, migrationsDir, target)
+	case "down":
+		err = goose.DownContext(ctx, db, migrationsDir)
+	case "down-to":
+		err = goose.DownToContext(ctx, db, migrationsDir, target)
+	case "redo":
+		err = goose.RedoContext(ctx, db, migrationsDir)
+	case "status":
+		return goose.StatusContext(ctx, db, migrationsDir)
+	case "version":
+		fmt.Fprintln(stdout, before)
+		return nil
+	}
 	if err != nil {
-		log.Printf("migrations applied, current version: unknown")
-	} else {
-		log.Printf("migrations already up to date, current version: %d", current)
+		return fmt.Errorf("%s: %w", command, err)
 	}
 
-	log.Println("migrations applied successfully")
+	after, err := goose.GetDBVersionContext(ctx, db)
+	if err != nil {
+		return fmt.Errorf("get schema version: %w", err)
+	}
+	if after == before && command != "redo" {
+		logger.Printf("nothing to do, schema version %d", after)
+	} else {
+		logger.Printf("schema version %d -> %d", before, after)
+	}
+	return nil
 }
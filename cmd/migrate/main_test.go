@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/require"
+
+	"question-service/migrations"
+)
+
+const repoMigrations = "../../migrations"
+
+func TestValidate_RepoMigrations(t *testing.T) {
+	n, err := validate(nil, repoMigrations)
+	require.NoError(t, err)
+	require.Positive(t, n)
+
+	embedded, err := validate(migrations.FS, ".")
+	require.NoError(t, err)
+	require.Equal(t, n, embedded)
+}
+
+func TestParseSQLMigration(t *testing.T) {
+	m, err := parseSQLMigration([]byte("-- +goose Up\nCREATE TABLE t (id INT);\n\n-- +goose Down\nDROP TABLE t;\n"))
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE t (id INT);", m.up)
+	require.Equal(t, "DROP TABLE t;", m.down)
+
+	for name, body := range map[string]string{
+		"no up":            "CREATE TABLE t (id INT);\n",
+		"down before up":   "-- +goose Down\nDROP TABLE t;\n-- +goose Up\nCREATE TABLE t (id INT);\n",
+		"empty up":         "-- +goose Up\n-- +goose Down\nDROP TABLE t;\n",
+		"unclosed block":   "-- +goose Up\n-- +goose StatementBegin\nSELECT 1;\n",
+		"unknown":          "-- +goose Up\n-- +goose Sideways\nSELECT 1;\n",
+		"end without open": "-- +goose Up\nSELECT 1;\n-- +goose StatementEnd\n",
+	} {
+		_, err := parseSQLMigration([]byte(body))
+		require.Errorf(t, err, name)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"0001_a.sql", "0002_b.sql", "0003_c.sql", "0004_d.sql"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("-- +goose Up\nSELECT 1;\n"), 0o644))
+	}
+	migrations, err := goose.CollectMigrations(dir, 0, goose.MaxVersion)
+	require.NoError(t, err)
+
+	versions := func(steps []step) []int64 {
+		var out []int64
+		for _, s := range steps {
+			v := s.m.Version
+			if !s.up {
+				v = -v
+			}
+			out = append(out, v)
+		}
+		return out
+	}
+
+	for _, tc := range []struct {
+		command string
+		target  int64
+		want    []int64
+	}{
+		{"up", 0, []int64{3, 4}},
+		{"up-to", 3, []int64{3}},
+		{"down", 0, []int64{-2}},
+		{"down-to", 0, []int64{-2, -1}},
+		{"redo", 0, []int64{-2, 2}},
+	} {
+		steps, err := plan(migrations, 2, tc.command, tc.target)
+		require.NoError(t, err)
+		require.Equal(t, tc.want, versions(steps), tc.command)
+	}
+
+	_, err = plan(migrations, 0, "down", 0)
+	require.Error(t, err)
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "0006_create_webhooks.sql"), []byte("-- +goose Up\nSELECT 1;\n"), 0o644))
+
+	path, err := create(dir, "Add comments", "sql")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "0007_add_comments.sql"), path)
+
+	path, err = create(dir, "backfill-counts", "go")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "0008_backfill_counts.go"), path)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "func upBackfillCounts(")
+
+	_, err = create(dir, "x", "yaml")
+	require.Error(t, err)
+}
+
+func TestRun_ExitCodes(t *testing.T) {
+	for _, tc := range []struct {
+		args []string
+		code int
+	}{
+		{nil, exitUsage},
+		{[]string{"sideways"}, exitUsage},
+		{[]string{"up-to"}, exitUsage},
+		{[]string{"up-to", "abc"}, exitUsage},
+		{[]string{"status", "--dry-run"}, exitUsage},
+		{[]string{"--help"}, exitOK},
+		{[]string{"validate"}, exitOK},
+		{[]string{"validate", "--dir", repoMigrations}, exitOK},
+		{[]string{"validate", "--dir", t.TempDir()}, exitError},
+	} {
+		var stdout, stderr bytes.Buffer
+		require.Equalf(t, tc.code, run(context.Background(), tc.args, &stdout, &stderr), "args %v: %s", tc.args, stderr.String())
+	}
+}
+
+func TestRedactWriter(t *testing.T) {
+	var buf bytes.Buffer
+	_, err := redactWriter{&buf}.Write([]byte("connecting to postgres://app:s3cret@db/qna\n"))
+	require.NoError(t, err)
+	require.Equal(t, "connecting to postgres://app:xxxxx@db/qna\n", buf.String())
+}
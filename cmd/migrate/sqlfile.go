@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/pressly/goose/v3"
+)
+
+// sqlMigration — разобранный файл SQL-миграции goose.
+type sqlMigration struct {
+	up   string
+	down string
+}
+
+// parseSQLMigration делит файл миграции на секции Up и Down и проверяет разметку:
+// ровно одна секция Up, Down — после неё, StatementBegin и StatementEnd парные.
+func parseSQLMigration(data []byte) (*sqlMigration, error) {
+	var (
+		m         sqlMigration
+		section   string // "", "up" или "down"
+		statement bool
+		up, down  strings.Builder
+	)
+
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+
+		if ann, ok := strings.CutPrefix(strings.TrimSpace(line), "-- +goose "); ok {
+			switch strings.ToLower(strings.TrimSpace(ann)) {
+			case "up":
+				if section != "" {
+					return nil, fmt.Errorf("line %d: unexpected +goose Up", n)
+				}
+				section = "up"
+			case "down":
+				if section != "up" {
+					return nil, fmt.Errorf("line %d: +goose Down must follow +goose Up", n)
+				}
+				if statement {
+					return nil, fmt.Errorf("line %d: +goose Down inside a statement block", n)
+				}
+				section = "down"
+			case "statementbegin":
+				if section == "" || statement {
+					return nil, fmt.Errorf("line %d: unexpected +goose StatementBegin", n)
+				}
+				statement = true
+			case "statementend":
+				if !statement {
+					return nil, fmt.Errorf("line %d: +goose StatementEnd without StatementBegin", n)
+				}
+				statement = false
+			case "no transaction":
+			default:
+				if !strings.HasPrefix(strings.ToLower(ann), "envsub") {
+					return nil, fmt.Errorf("line %d: unknown annotation %q", n, line)
+				}
+			}
+			continue
+		}
+
+		switch section {
+		case "up":
+			up.WriteString(line + "\n")
+		case "down":
+			down.WriteString(line + "\n")
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	if section == "" {
+		return nil, fmt.Errorf("missing +goose Up annotation")
+	}
+	if statement {
+		return nil, fmt.Errorf("+goose StatementBegin without StatementEnd")
+	}
+
+	m.up = strings.TrimSpace(up.String())
+	m.down = strings.TrimSpace(down.String())
+	if m.up == "" {
+		return nil, fmt.Errorf("empty +goose Up section")
+	}
+	return &m, nil
+}
+
+// readSource читает файл миграции из fsys или, если fsys == nil, с диска.
+func readSource(fsys fs.FS, path string) ([]byte, error) {
+	if fsys == nil {
+		return os.ReadFile(path)
+	}
+	return fs.ReadFile(fsys, path)
+}
+
+// collect собирает миграции из каталога dir в fsys или, если fsys == nil, на диске.
+func collect(fsys fs.FS, dir string) (goose.Migrations, error) {
+	goose.SetBaseFS(fsys)
+	defer goose.SetBaseFS(nil)
+	return goose.CollectMigrations(dir, 0, goose.MaxVersion)
+}
+
+// validate проверяет файлы миграций в dir, не подключаясь к базе.
+func validate(fsys fs.FS, dir string) (int, error) {
+	migrations, err := collect(fsys, dir)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, m := range migrations {
+		if m.Type != goose.TypeSQL {
+			continue
+		}
+		data, err := readSource(fsys, m.Source)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := parseSQLMigration(data); err != nil {
+			return 0, fmt.Errorf("%s: %w", m.Source, err)
+		}
+	}
+	return len(migrations), nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// settings — настройки qsctl. Источники по возрастанию приоритета:
+// значения по умолчанию, файл конфигурации, переменные окружения QSCTL_*, флаги.
+type settings struct {
+	URL    string `yaml:"url"`
+	Token  string `yaml:"token"`
+	Output string `yaml:"output"`
+}
+
+// globalFlags — флаги, общие для всех команд.
+type globalFlags struct {
+	config string
+	url    string
+	token  string
+	output string
+}
+
+// defaultConfigPath возвращает ~/.config/qsctl/config.yaml или пустую строку.
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "qsctl", "config.yaml")
+}
+
+// loadSettings собирает настройки из всех источников.
+func loadSettings(flags globalFlags) (settings, error) {
+	s := settings{URL: "http://localhost:8080", Output: outputTable}
+
+	path, explicit := flags.config, flags.config != ""
+	if !explicit {
+		path, explicit = os.Getenv("QSCTL_CONFIG"), os.Getenv("QSCTL_CONFIG") != ""
+	}
+	if !explicit {
+		path = defaultConfigPath()
+	}
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			if err := yaml.Unmarshal(data, &s); err != nil {
+				return s, fmt.Errorf("parse config %s: %w", path, err)
+			}
+		case errors.Is(err, fs.ErrNotExist) && !explicit:
+			// файла по умолчанию может не быть
+		default:
+			return s, fmt.Errorf("read config: %w", err)
+		}
+	}
+
+	override(&s.URL, os.Getenv("QSCTL_URL"), flags.url)
+	override(&s.Token, os.Getenv("QSCTL_TOKEN"), flags.token)
+	override(&s.Output, os.Getenv("QSCTL_OUTPUT"), flags.output)
+
+	switch s.Output {
+	case outputTable, outputJSON, outputYAML:
+	default:
+		return s, usageError(fmt.Sprintf("unknown output format %q: want table, json or yaml", s.Output))
+	}
+	return s, nil
+}
+
+// override заменяет dst последним непустым значением из values.
+func override(dst *string, values ...string) {
+	for _, v := range values {
+		if v != "" {
+			*dst = v
+		}
+	}
+}
@@ -0,0 +1,332 @@
+// qsctl — консольный клиент API сервиса вопросов и ответов.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"question-service/pkg/client"
+)
+
+// Коды выхода. Ошибки API отображаются на код по HTTP-статусу ответа.
+const (
+	exitOK           = 0
+	exitError        = 1 // прочие ошибки
+	exitUsage        = 2 // неверные аргументы командной строки
+	exitBadRequest   = 3 // 400, 422
+	exitUnauthorized = 4 // 401, 403
+	exitNotFound     = 5 // 404
+	exitConflict     = 6 // 409, 412, 428
+	exitRateLimited  = 7 // 429
+	exitServer       = 8 // 5xx
+	exitUnavailable  = 9 // сервис недоступен или не ответил вовремя
+)
+
+const usage = `Usage: qsctl [flags] <command> [args]
+
+Commands:
+  health                                   check that the service is up
+  questions list [--limit N]               list questions
+  questions get ID                         show a question with its answers
+  questions create TEXT                    create a question
+  questions delete ID [--version N]        delete a question with its answers
+  answers get ID                           show an answer
+  answers create QUESTION_ID --user USER TEXT
+                                           add an answer to a question
+  answers delete ID [--version N]          delete an answer
+
+Flags:
+  --config PATH    config file (default ~/.config/qsctl/config.yaml, env QSCTL_CONFIG)
+  --url URL        service base url (env QSCTL_URL, default http://localhost:8080)
+  --token TOKEN    bearer token (env QSCTL_TOKEN)
+  -o, --output F   output format: table, json or yaml (env QSCTL_OUTPUT)
+  --timeout D      request timeout (default 30s)
+`
+
+// usageError — ошибка в аргументах командной строки.
+type usageError string
+
+func (e usageError) Error() string { return string(e) }
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// run выполняет команду и возвращает код выхода.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	err := execute(ctx, args, stdout)
+	if err == nil {
+		return exitOK
+	}
+
+	var uerr usageError
+	if errors.As(err, &uerr) {
+		fmt.Fprintf(stderr, "qsctl: %v\n\n%s", err, usage)
+		return exitUsage
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	}
+
+	fmt.Fprintf(stderr, "qsctl: %v\n", err)
+	return exitCode(err)
+}
+
+// exitCode отображает ошибку на код выхода.
+func exitCode(err error) int {
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) {
+		switch s := apiErr.StatusCode; {
+		case s == http.StatusBadRequest || s == http.StatusUnprocessableEntity:
+			return exitBadRequest
+		case s == http.StatusUnauthorized || s == http.StatusForbidden:
+			return exitUnauthorized
+		case s == http.StatusNotFound:
+			return exitNotFound
+		case s == http.StatusConflict || s == http.StatusPreconditionFailed || s == http.StatusPreconditionRequired:
+			return exitConflict
+		case s == http.StatusTooManyRequests:
+			return exitRateLimited
+		case s >= http.StatusInternalServerError:
+			return exitServer
+		}
+		return exitError
+	}
+
+	var netErr interface{ Timeout() bool }
+	if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) {
+		return exitUnavailable
+	}
+	return exitError
+}
+
+func execute(ctx context.Context, args []string, stdout io.Writer) error {
+	var g globalFlags
+	var timeout time.Duration
+
+	// флаги до команды
+	fs := newFlagSet(&g, &timeout)
+	if err := fs.Parse(args); err != nil {
+		return parseError(err)
+	}
+	args = fs.Args()
+	if len(args) == 0 {
+		return usageError("command is required")
+	}
+
+	cmd := args[0]
+	args = args[1:]
+	if cmd == "help" {
+		return flag.ErrHelp
+	}
+	if cmd == "questions" || cmd == "answers" {
+		if len(args) == 0 {
+			return usageError(cmd + ": subcommand is required")
+		}
+		cmd += " " + args[0]
+		args = args[1:]
+	}
+
+	// флаги команды; общие флаги допустимы и после неё
+	fs = newFlagSet(&g, &timeout)
+	limit := fs.Int("limit", 0, "")
+	version := fs.Int("version", 0, "")
+	user := fs.String("user", "", "")
+	positional, err := parseInterleaved(fs, args)
+	if err != nil {
+		return parseError(err)
+	}
+
+	s, err := loadSettings(g)
+	if err != nil {
+		return err
+	}
+
+	c, err := client.New(s.URL, client.Config{Token: s.Token})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	p := &printer{w: stdout, format: s.Output}
+
+	switch cmd {
+	case "health":
+		if err := expectArgs(cmd, positional, 0); err != nil {
+			return err
+		}
+		if err := c.Health(ctx); err != nil {
+			return err
+		}
+		return p.health()
+
+	case "questions list":
+		if err := expectArgs(cmd, positional, 0); err != nil {
+			return err
+		}
+		return listQuestions(ctx, c, p, *limit)
+
+	case "questions get":
+		if err := expectArgs(cmd, positional, 1); err != nil {
+			return err
+		}
+		id, err := parseID("question", positional[0])
+		if err != nil {
+			return err
+		}
+		q, err := c.GetQuestion(ctx, id)
+		if err != nil {
+			return err
+		}
+		return p.question(q)
+
+	case "questions create":
+		if err := expectArgs(cmd, positional, 1); err != nil {
+			return err
+		}
+		q, err := c.CreateQuestion(ctx, positional[0])
+		if err != nil {
+			return err
+		}
+		return p.question(q)
+
+	case "questions delete":
+		if err := expectArgs(cmd, positional, 1); err != nil {
+			return err
+		}
+		id, err := parseID("question", positional[0])
+		if err != nil {
+			return err
+		}
+		if err := c.DeleteQuestion(ctx, id, *version); err != nil {
+			return err
+		}
+		return p.deleted("question", id)
+
+	case "answers get":
+		if err := expectArgs(cmd, positional, 1); err != nil {
+			return err
+		}
+		id, err := parseID("answer", positional[0])
+		if err != nil {
+			return err
+		}
+		a, err := c.GetAnswer(ctx, id)
+		if err != nil {
+			return err
+		}
+		return p.answer(a)
+
+	case "answers create":
+		if err := expectArgs(cmd, positional, 2); err != nil {
+			return err
+		}
+		if *user == "" {
+			return usageError(cmd + ": --user is required")
+		}
+		questionID, err := parseID("question", positional[0])
+		if err != nil {
+			return err
+		}
+		a, err := c.CreateAnswer(ctx, questionID, *user, positional[1])
+		if err != nil {
+			return err
+		}
+		return p.answer(a)
+
+	case "answers delete":
+		if err := expectArgs(cmd, positional, 1); err != nil {
+			return err
+		}
+		id, err := parseID("answer", positional[0])
+		if err != nil {
+			return err
+		}
+		if err := c.DeleteAnswer(ctx, id, *version); err != nil {
+			return err
+		}
+		return p.deleted("answer", id)
+	}
+
+	return usageError(fmt.Sprintf("unknown command %q", cmd))
+}
+
+// listQuestions выводит вопросы постранично; limit > 0 ограничивает их число.
+func listQuestions(ctx context.Context, c *client.Client, p *printer, limit int) error {
+	pageSize := 100
+	if limit > 0 && limit < pageSize {
+		pageSize = limit
+	}
+
+	var questions []client.Question
+	for q, err := range c.Questions(ctx, pageSize) {
+		if err != nil {
+			return err
+		}
+		questions = append(questions, q)
+		if limit > 0 && len(questions) == limit {
+			break
+		}
+	}
+	return p.questions(questions)
+}
+
+func newFlagSet(g *globalFlags, timeout *time.Duration) *flag.FlagSet {
+	fs := flag.NewFlagSet("qsctl", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&g.config, "config", g.config, "")
+	fs.StringVar(&g.url, "url", g.url, "")
+	fs.StringVar(&g.token, "token", g.token, "")
+	fs.StringVar(&g.output, "output", g.output, "")
+	fs.StringVar(&g.output, "o", g.output, "")
+	if *timeout == 0 {
+		*timeout = 30 * time.Second
+	}
+	fs.DurationVar(timeout, "timeout", *timeout, "")
+	return fs
+}
+
+// parseInterleaved разбирает флаги, перемешанные с позиционными аргументами.
+func parseInterleaved(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func parseError(err error) error {
+	if errors.Is(err, flag.ErrHelp) {
+		return err
+	}
+	return usageError(err.Error())
+}
+
+func expectArgs(cmd string, args []string, n int) error {
+	if len(args) != n {
+		return usageError(fmt.Sprintf("%s: expected %d argument(s), got %d: %s", cmd, n, len(args), strings.Join(args, " ")))
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	httptransport "question-service/internal/http"
+	"question-service/internal/logger"
+	"question-service/internal/repository"
+	"question-service/internal/service"
+)
+
+func newServer(t *testing.T) string {
+	t.Helper()
+
+	// настройки только из флагов теста
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	for _, key := range []string{"QSCTL_CONFIG", "QSCTL_URL", "QSCTL_TOKEN", "QSCTL_OUTPUT"} {
+		t.Setenv(key, "")
+	}
+
+	store := repository.NewMemoryStore()
+	qRepo := repository.NewMemoryQuestionRepository(store)
+	events := repository.NewMemoryOutboxRepository(store)
+	qSvc := service.NewQuestionService(qRepo, events, store)
+	aSvc := service.NewAnswerService(repository.NewMemoryAnswerRepository(store), qRepo, events, store, nil)
+
+	srv := httptest.NewServer(httptransport.NewRouter(qSvc, aSvc, &logger.Logger{Logger: zap.NewNop()}, httptransport.Options{}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func runCmd(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun_Commands(t *testing.T) {
+	url := newServer(t)
+
+	code, out, _ := runCmd(t, "--url", url, "-o", "json", "questions", "create", "What is GORM?")
+	require.Equal(t, exitOK, code)
+	require.Contains(t, out, `"text": "What is GORM?"`)
+
+	code, out, _ = runCmd(t, "answers", "create", "1", "--user", "u1", "An ORM", "--url", url, "-o", "yaml")
+	require.Equal(t, exitOK, code)
+	require.Contains(t, out, "question_id: 1\nuser_id: u1\n")
+
+	code, out, _ = runCmd(t, "--url", url, "questions", "get", "1")
+	require.Equal(t, exitOK, code)
+	require.Contains(t, out, "Text:     What is GORM?")
+	require.Contains(t, out, "An ORM")
+
+	code, out, _ = runCmd(t, "--url", url, "questions", "list", "--limit", "1")
+	require.Equal(t, exitOK, code)
+	require.Contains(t, out, "ID  VERSION")
+
+	code, _, stderr := runCmd(t, "--url", url, "questions", "delete", "1", "--version", "1")
+	require.Equal(t, exitConflict, code)
+	require.Contains(t, stderr, "version mismatch")
+
+	code, out, _ = runCmd(t, "--url", url, "questions", "delete", "1")
+	require.Equal(t, exitOK, code)
+	require.Equal(t, "question 1 deleted\n", out)
+
+	code, _, _ = runCmd(t, "--url", url, "answers", "get", "1")
+	require.Equal(t, exitNotFound, code)
+
+	code, _, _ = runCmd(t, "--url", url, "questions", "create", " ")
+	require.Equal(t, exitBadRequest, code)
+}
+
+func TestRun_ConfigFile(t *testing.T) {
+	url := newServer(t)
+
+	path := filepath.Join(t.TempDir(), "qsctl.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("url: "+url+"\noutput: json\n"), 0o600))
+
+	code, out, _ := runCmd(t, "--config", path, "health")
+	require.Equal(t, exitOK, code)
+	require.JSONEq(t, `{"status":"ok"}`, out)
+
+	// переменные окружения важнее файла
+	t.Setenv("QSCTL_OUTPUT", "table")
+	code, out, _ = runCmd(t, "--config", path, "health")
+	require.Equal(t, exitOK, code)
+	require.Equal(t, "ok\n", out)
+}
+
+func TestRun_UsageErrors(t *testing.T) {
+	newServer(t)
+
+	for _, args := range [][]string{
+		{},
+		{"questions"},
+		{"questions", "frobnicate"},
+		{"questions", "get"},
+		{"questions", "get", "abc"},
+		{"answers", "create", "1", "text"},
+		{"--output", "xml", "health"},
+		{"--unknown", "health"},
+	} {
+		code, _, _ := runCmd(t, args...)
+		require.Equalf(t, exitUsage, code, "args %v", args)
+	}
+}
+
+func TestRun_Unavailable(t *testing.T) {
+	url := newServer(t)
+	code, _, _ := runCmd(t, "--url", url+"0", "--timeout", "1s", "health")
+	require.Equal(t, exitUnavailable, code)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"question-service/pkg/client"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// maxCellWidth — длина текста вопроса или ответа в таблице, остальное обрезается.
+const maxCellWidth = 60
+
+// printer выводит результаты команд в выбранном формате.
+type printer struct {
+	w      io.Writer
+	format string
+}
+
+// print выводит v в JSON или YAML; в табличном формате вызывает table.
+func (p *printer) print(v any, table func(w *tabwriter.Writer)) error {
+	switch p.format {
+	case outputJSON:
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+
+	case outputYAML:
+		// через JSON, чтобы ключи совпадали с API и сохраняли порядок полей
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		var node yaml.Node
+		if err := yaml.Unmarshal(data, &node); err != nil {
+			return err
+		}
+		blockStyle(&node)
+		enc := yaml.NewEncoder(p.w)
+		enc.SetIndent(2)
+		if err := enc.Encode(&node); err != nil {
+			return err
+		}
+		return enc.Close()
+
+	default:
+		tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+		table(tw)
+		return tw.Flush()
+	}
+}
+
+// blockStyle убирает flow-стиль и кавычки, которые YAML-парсер унаследовал от JSON.
+func blockStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
+}
+
+func (p *printer) questions(questions []client.Question) error {
+	if questions == nil {
+		questions = []client.Question{}
+	}
+	return p.print(questions, func(w *tabwriter.Writer) {
+		fmt.Fprintln(w, "ID\tVERSION\tCREATED\tTEXT")
+		for _, q := range questions {
+			fmt.Fprintf(w, "%d\t%d\t%s\t%s\n", q.ID, q.Version, formatTime(q.CreatedAt), cell(q.Text))
+		}
+	})
+}
+
+func (p *printer) question(q *client.Question) error {
+	return p.print(q, func(w *tabwriter.Writer) {
+		fmt.Fprintf(w, "ID:\t%d\n", q.ID)
+		fmt.Fprintf(w, "Version:\t%d\n", q.Version)
+		fmt.Fprintf(w, "Created:\t%s\n", formatTime(q.CreatedAt))
+		fmt.Fprintf(w, "Text:\t%s\n", q.Text)
+		fmt.Fprintf(w, "Answers:\t%d\n", len(q.Answers))
+		if len(q.Answers) == 0 {
+			return
+		}
+
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "ID\tUSER\tVERSION\tCREATED\tTEXT")
+		for _, a := range q.Answers {
+			fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%s\n", a.ID, a.UserID, a.Version, formatTime(a.CreatedAt), cell(a.Text))
+		}
+	})
+}
+
+func (p *printer) answer(a *client.Answer) error {
+	return p.print(a, func(w *tabwriter.Writer) {
+		fmt.Fprintf(w, "ID:\t%d\n", a.ID)
+		fmt.Fprintf(w, "Question:\t%d\n", a.QuestionID)
+		fmt.Fprintf(w, "User:\t%s\n", a.UserID)
+		fmt.Fprintf(w, "Version:\t%d\n", a.Version)
+		fmt.Fprintf(w, "Created:\t%s\n", formatTime(a.CreatedAt))
+		fmt.Fprintf(w, "Text:\t%s\n", a.Text)
+	})
+}
+
+// deleted сообщает об удалении ресурса kind с идентификатором id.
+func (p *printer) deleted(kind string, id int) error {
+	return p.print(map[string]any{"id": id, "deleted": true}, func(w *tabwriter.Writer) {
+		fmt.Fprintf(w, "%s %d deleted\n", kind, id)
+	})
+}
+
+func (p *printer) health() error {
+	return p.print(map[string]string{"status": "ok"}, func(w *tabwriter.Writer) {
+		fmt.Fprintln(w, "ok")
+	})
+}
+
+func formatTime(t time.Time) string {
+	return t.Local().Format(time.DateTime)
+}
+
+// cell готовит текст для ячейки таблицы: одна строка не длиннее maxCellWidth символов.
+func cell(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > maxCellWidth {
+		return string(r[:maxCellWidth-1]) + "…"
+	}
+	return s
+}
+
+// parseID разбирает положительный идентификатор из аргумента команды.
+func parseID(kind, v string) (int, error) {
+	id, err := strconv.Atoi(v)
+	if err != nil || id <= 0 {
+		return 0, usageError(fmt.Sprintf("invalid %s id %q", kind, v))
+	}
+	return id, nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"question-service/internal/domain"
+)
+
+// maxUserIDLength — размер колонки answers.user_id.
+const maxUserIDLength = 64
+
+// fixtureFile — файл фикстур в YAML или JSON:
+//
+//	questions:
+//	  - text: How do I paginate in GORM?
+//	    created_at: 2025-01-02T10:00:00Z
+//	    answers:
+//	      - user_id: alice
+//	        text: Use keyset pagination.
+type fixtureFile struct {
+	Questions []fixtureQuestion `json:"questions" yaml:"questions"`
+}
+
+type fixtureQuestion struct {
+	Text      string          `json:"text"       yaml:"text"`
+	CreatedAt time.Time       `json:"created_at" yaml:"created_at"`
+	Answers   []fixtureAnswer `json:"answers"    yaml:"answers"`
+}
+
+type fixtureAnswer struct {
+	UserID    string    `json:"user_id"    yaml:"user_id"`
+	Text      string    `json:"text"       yaml:"text"`
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+}
+
+// loadFixtures читает файл фикстур; формат определяется по расширению.
+// Без created_at запись получает время вставки.
+func loadFixtures(path string) ([]domain.Question, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f fixtureFile
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&f)
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(&f)
+	default:
+		return nil, fmt.Errorf("%s: unsupported fixture format %q, want .json, .yaml or .yml", path, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	questions := make([]domain.Question, 0, len(f.Questions))
+	for i, fq := range f.Questions {
+		if strings.TrimSpace(fq.Text) == "" {
+			return nil, fmt.Errorf("%s: question %d: text is required", path, i+1)
+		}
+		q := domain.Question{Text: fq.Text, CreatedAt: fq.CreatedAt.UTC(), Version: 1}
+
+		for j, fa := range fq.Answers {
+			switch {
+			case fa.UserID == "" || len(fa.UserID) > maxUserIDLength:
+				return nil, fmt.Errorf("%s: question %d, answer %d: user_id must be 1 to %d characters", path, i+1, j+1, maxUserIDLength)
+			case strings.TrimSpace(fa.Text) == "":
+				return nil, fmt.Errorf("%s: question %d, answer %d: text is required", path, i+1, j+1)
+			}
+			q.Answers = append(q.Answers, domain.Answer{
+				UserID:    fa.UserID,
+				Text:      fa.Text,
+				CreatedAt: fa.CreatedAt.UTC(),
+				Version:   1,
+			})
+		}
+		questions = append(questions, q)
+	}
+	return questions, nil
+}
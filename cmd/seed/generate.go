@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"time"
+
+	"question-service/internal/domain"
+)
+
+// Распределения числа ответов на вопрос.
+const (
+	distUniform = "uniform" // равновероятно от min до max
+	distPoisson = "poisson" // около середины диапазона
+	distZipf    = "zipf"    // у большинства вопросов мало ответов, у немногих — много
+)
+
+// genOptions — параметры генерации данных.
+type genOptions struct {
+	Questions    int
+	MinAnswers   int
+	MaxAnswers   int
+	Distribution string
+	Users        int
+	From, To     time.Time
+}
+
+func (o genOptions) validate() error {
+	switch {
+	case o.Questions < 0:
+		return fmt.Errorf("number of questions must not be negative")
+	case o.MinAnswers < 0 || o.MaxAnswers < o.MinAnswers:
+		return fmt.Errorf("invalid answers range %d-%d", o.MinAnswers, o.MaxAnswers)
+	case o.Users <= 0:
+		return fmt.Errorf("number of users must be positive")
+	case !o.From.Before(o.To):
+		return fmt.Errorf("--from must be before --to")
+	}
+	switch o.Distribution {
+	case distUniform, distPoisson, distZipf:
+		return nil
+	default:
+		return fmt.Errorf("unknown distribution %q: want uniform, poisson or zipf", o.Distribution)
+	}
+}
+
+// generate создаёт вопросы с ответами. Результат зависит только от opts и rng:
+// с одинаковым seed получаются одинаковые данные. Вопросы и ответы каждого
+// вопроса упорядочены по времени создания.
+func generate(opts genOptions, rng *rand.Rand) []domain.Question {
+	g := &generator{
+		rng:     rng,
+		opts:    opts,
+		answers: answerCounter(opts, rng),
+		// активность пользователей неравномерна: одни отвечают часто, другие — изредка
+		users: rand.NewZipf(rng, 1.2, 1, uint64(opts.Users-1)),
+	}
+
+	span := opts.To.Sub(opts.From)
+	questions := make([]domain.Question, opts.Questions)
+	for i := range questions {
+		created := opts.From.Add(time.Duration(rng.Int64N(int64(span))))
+		questions[i] = domain.Question{
+			Text:      g.questionText(),
+			CreatedAt: created.UTC(),
+			Version:   1,
+			Answers:   g.answersFor(created),
+		}
+	}
+
+	slices.SortStableFunc(questions, func(a, b domain.Question) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return questions
+}
+
+type generator struct {
+	rng     *rand.Rand
+	opts    genOptions
+	answers func() int
+	users   *rand.Zipf
+}
+
+func (g *generator) answersFor(questionCreated time.Time) []domain.Answer {
+	n := g.answers()
+	if n == 0 {
+		return nil
+	}
+
+	// большая часть ответов приходит вскоре после вопроса
+	left := g.opts.To.Sub(questionCreated)
+	answers := make([]domain.Answer, n)
+	for i := range answers {
+		delay := time.Duration(g.rng.ExpFloat64() * float64(6*time.Hour))
+		if delay >= left {
+			delay = time.Duration(g.rng.Int64N(int64(left)))
+		}
+		answers[i] = domain.Answer{
+			UserID:    userID(int(g.users.Uint64()) + 1),
+			Text:      g.answerText(),
+			CreatedAt: questionCreated.Add(delay).UTC(),
+			Version:   1,
+		}
+	}
+
+	slices.SortStableFunc(answers, func(a, b domain.Answer) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return answers
+}
+
+// answerCounter возвращает функцию, выбирающую число ответов на вопрос.
+func answerCounter(opts genOptions, rng *rand.Rand) func() int {
+	lo, hi := opts.MinAnswers, opts.MaxAnswers
+	if lo == hi {
+		return func() int { return lo }
+	}
+
+	switch opts.Distribution {
+	case distPoisson:
+		mean := float64(lo+hi) / 2
+		return func() int {
+			for range 100 {
+				if n := poisson(rng, mean); n >= lo && n <= hi {
+					return n
+				}
+			}
+			return int(math.Round(mean))
+		}
+	case distZipf:
+		z := rand.NewZipf(rng, 1.5, 1, uint64(hi-lo))
+		return func() int { return lo + int(z.Uint64()) }
+	default:
+		return func() int { return lo + rng.IntN(hi-lo+1) }
+	}
+}
+
+// poisson — алгоритм Кнута; подходит для небольших mean.
+func poisson(rng *rand.Rand, mean float64) int {
+	limit := math.Exp(-mean)
+	n, p := 0, rng.Float64()
+	for p > limit {
+		n++
+		p *= rng.Float64()
+	}
+	return n
+}
+
+func userID(n int) string {
+	return fmt.Sprintf("user-%04d", n)
+}
+
+var (
+	questionTemplates = []string{
+		"How do I %s %s in %s?",
+		"What is the best way to %s %s with %s?",
+		"Why does %[3]s fail when I try to %[1]s %[2]s?",
+		"Is it possible to %s %s using only %s?",
+		"Can someone explain how to %s %s in %s without downtime?",
+		"What are the trade-offs when you %s %s with %s?",
+	}
+	verbs = []string{
+		"cache", "paginate", "validate", "migrate", "index", "serialize",
+		"retry", "monitor", "shard", "test", "profile", "deploy",
+	}
+	objects = []string{
+		"database queries", "HTTP requests", "background jobs", "user sessions",
+		"large JSON payloads", "file uploads", "webhooks", "search results",
+		"configuration", "feature flags", "audit logs", "rate limits",
+	}
+	technologies = []string{
+		"Go", "PostgreSQL", "Redis", "Kafka", "Kubernetes", "gRPC",
+		"GraphQL", "Docker", "nginx", "GORM", "Prometheus", "Terraform",
+	}
+	answerSentences = []string{
+		"I ran into the same problem last year.",
+		"The simplest option is to start with the standard library.",
+		"Make sure you measure before optimizing anything.",
+		"Check the documentation, there is a section about exactly this.",
+		"We solved it with a small wrapper and a couple of integration tests.",
+		"This depends a lot on how much traffic you expect.",
+		"Wrapping the whole thing in a transaction fixed it for us.",
+		"Keep the timeout short and retry with exponential backoff.",
+		"An index on the foreign key made a huge difference.",
+		"I would avoid doing this in the request path.",
+		"Move that work to a background worker and return early.",
+		"Try enabling debug logs, the error message is usually clear.",
+		"It works, but be careful with memory usage on large inputs.",
+		"Upgrading to the latest version solved it.",
+		"There is a well-known library for this, no need to write your own.",
+	}
+)
+
+func (g *generator) questionText() string {
+	tmpl := pick(g.rng, questionTemplates)
+	return fmt.Sprintf(tmpl, pick(g.rng, verbs), pick(g.rng, objects), pick(g.rng, technologies))
+}
+
+func (g *generator) answerText() string {
+	n := 1 + g.rng.IntN(3)
+	sentences := make([]string, n)
+	for i := range sentences {
+		sentences[i] = pick(g.rng, answerSentences)
+	}
+	return strings.Join(sentences, " ")
+}
+
+func pick[T any](rng *rand.Rand, items []T) T {
+	return items[rng.IntN(len(items))]
+}
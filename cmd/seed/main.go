@@ -0,0 +1,280 @@
+// seed заполняет базу правдоподобными вопросами и ответами для демо и нагрузочных
+// тестов, а также загружает фикстуры из YAML- и JSON-файлов.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"question-service/internal/config"
+	"question-service/internal/db"
+	"question-service/internal/domain"
+	"question-service/internal/logger"
+	"question-service/internal/repository"
+	"question-service/internal/service"
+)
+
+// Коды выхода.
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+const usage = `Usage: seed [flags] [FIXTURE...]
+
+Generates questions with answers and inserts them into the database. FIXTURE
+files (.yaml, .yml or .json) are loaded too; when fixtures are given, data is
+generated only if --questions is set explicitly.
+
+Flags:
+  --questions N      number of questions to generate (default 100)
+  --answers MIN-MAX  answers per question, a range or a single number (default 0-10)
+  --distribution D   distribution of answers per question: uniform, poisson or zipf
+                     (default poisson)
+  --users N          number of distinct user ids (default 50)
+  --from TIME        earliest creation time, RFC 3339 or YYYY-MM-DD
+                     (default 90 days before --to)
+  --to TIME          latest creation time (default now)
+  --seed N           random seed; the same seed, flags and --to give the same data
+                     (default random)
+  --batch N          rows per INSERT (default 500)
+
+The database is configured with DATABASE_URL or DB_* variables, as for the API.
+Everything is inserted in one transaction. Seeded rows produce no events or
+webhook deliveries.
+`
+
+// usageError — ошибка в аргументах командной строки.
+type usageError string
+
+func (e usageError) Error() string { return string(e) }
+
+// target — репозитории, в которые загружаются данные.
+type target struct {
+	questions repository.QuestionRepository
+	answers   repository.AnswerRepository
+	tx        service.TxManager
+	close     func() error
+}
+
+// openFunc подключается к хранилищу; в тестах подменяется хранилищем в памяти.
+type openFunc func(ctx context.Context, log *logger.Logger) (*target, error)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr, openPostgres)
+	stop()
+	os.Exit(code)
+}
+
+// run выполняет команду и возвращает код выхода.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer, open openFunc) int {
+	encoder := zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig())
+	log := &logger.Logger{Logger: zap.New(zapcore.NewCore(encoder, zapcore.AddSync(stderr), zap.InfoLevel))}
+
+	err := execute(ctx, args, stdout, log, open)
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, flag.ErrHelp):
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	}
+
+	var uerr usageError
+	if errors.As(err, &uerr) {
+		fmt.Fprintf(stderr, "seed: %v\n\n%s", err, usage)
+		return exitUsage
+	}
+	log.Error("seed failed", zap.Error(err))
+	return exitError
+}
+
+func execute(ctx context.Context, args []string, stdout io.Writer, log *logger.Logger, open openFunc) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	questions := flags.Int("questions", 100, "")
+	answers := flags.String("answers", "0-10", "")
+	distribution := flags.String("distribution", distPoisson, "")
+	users := flags.Int("users", 50, "")
+	from := flags.String("from", "", "")
+	to := flags.String("to", "", "")
+	seed := flags.Uint64("seed", 0, "")
+	batch := flags.Int("batch", 500, "")
+
+	// флаги можно указывать и до файлов фикстур, и после них
+	var fixtures []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return err
+			}
+			return usageError(err.Error())
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		fixtures = append(fixtures, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+
+	set := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	opts := genOptions{Questions: *questions, Distribution: *distribution, Users: *users}
+	if len(fixtures) > 0 && !set["questions"] {
+		opts.Questions = 0
+	}
+
+	var err error
+	if opts.MinAnswers, opts.MaxAnswers, err = parseRange(*answers); err != nil {
+		return usageError(fmt.Sprintf("--answers: %v", err))
+	}
+	opts.To = time.Now().UTC()
+	if *to != "" {
+		if opts.To, err = parseTime(*to); err != nil {
+			return usageError(fmt.Sprintf("--to: %v", err))
+		}
+	}
+	opts.From = opts.To.AddDate(0, 0, -90)
+	if *from != "" {
+		if opts.From, err = parseTime(*from); err != nil {
+			return usageError(fmt.Sprintf("--from: %v", err))
+		}
+	}
+	if err := opts.validate(); err != nil {
+		return usageError(err.Error())
+	}
+	if *batch <= 0 {
+		return usageError("--batch must be positive")
+	}
+	if !set["seed"] {
+		*seed = rand.Uint64()
+	}
+
+	// фикстуры читаются до подключения к базе, чтобы ошибки в них находились сразу
+	var data []domain.Question
+	for _, path := range fixtures {
+		loaded, err := loadFixtures(path)
+		if err != nil {
+			return err
+		}
+		data = append(data, loaded...)
+	}
+	data = append(data, generate(opts, rand.New(rand.NewPCG(*seed, *seed)))...)
+
+	t, err := open(ctx, log)
+	if err != nil {
+		return err
+	}
+	defer t.close()
+
+	start := time.Now()
+	nq, na, err := insert(ctx, t, data, *batch)
+	if err != nil {
+		return err
+	}
+
+	log.Info("seed finished",
+		zap.Int("questions", nq),
+		zap.Int("answers", na),
+		zap.Int("fixture_files", len(fixtures)),
+		zap.Uint64("seed", *seed),
+		zap.Duration("duration", time.Since(start)),
+	)
+	fmt.Fprintf(stdout, "inserted %d questions and %d answers (seed %d)\n", nq, na, *seed)
+	return nil
+}
+
+// insert вставляет вопросы, затем их ответы пачками по batchSize в одной транзакции.
+func insert(ctx context.Context, t *target, questions []domain.Question, batchSize int) (int, int, error) {
+	var answers []domain.Answer
+	err := t.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := t.questions.CreateBatch(ctx, questions, batchSize); err != nil {
+			return fmt.Errorf("insert questions: %w", err)
+		}
+
+		for _, q := range questions {
+			for _, a := range q.Answers {
+				a.QuestionID = q.ID
+				answers = append(answers, a)
+			}
+		}
+		if err := t.answers.CreateBatch(ctx, answers, batchSize); err != nil {
+			return fmt.Errorf("insert answers: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return len(questions), len(answers), nil
+}
+
+// openPostgres подключается к базе из конфигурации API.
+func openPostgres(ctx context.Context, log *logger.Logger) (*target, error) {
+	cfg := config.Load()
+
+	conn, err := db.New(ctx, cfg, log)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.CheckSchema(ctx, conn, log); err != nil {
+		if sqlDB, dbErr := conn.DB(); dbErr == nil {
+			_ = sqlDB.Close()
+		}
+		return nil, err
+	}
+
+	cluster, err := db.NewCluster(conn, cfg, log)
+	if err != nil {
+		return nil, err
+	}
+
+	return &target{
+		questions: repository.NewQuestionRepository(cluster),
+		answers:   repository.NewAnswerRepository(cluster),
+		tx:        db.NewTxManager(cluster),
+		close:     cluster.Close,
+	}, nil
+}
+
+// parseRange разбирает "MIN-MAX" или одно число.
+func parseRange(s string) (int, int, error) {
+	minStr, maxStr, ok := strings.Cut(s, "-")
+	if !ok {
+		maxStr = minStr
+	}
+	lo, err1 := strconv.Atoi(strings.TrimSpace(minStr))
+	hi, err2 := strconv.Atoi(strings.TrimSpace(maxStr))
+	if err1 != nil || err2 != nil {
+		return 0, 0, fmt.Errorf("invalid range %q, want MIN-MAX", s)
+	}
+	return lo, hi, nil
+}
+
+// parseTime принимает время в RFC 3339 или дату YYYY-MM-DD (UTC).
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.UTC(), nil
+	}
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, want RFC 3339 or YYYY-MM-DD", s)
+	}
+	return t, nil
+}
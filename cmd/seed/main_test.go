@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"question-service/internal/logger"
+	"question-service/internal/repository"
+)
+
+func testOptions() genOptions {
+	return genOptions{
+		Questions:    200,
+		MinAnswers:   1,
+		MaxAnswers:   8,
+		Distribution: distPoisson,
+		Users:        10,
+		From:         time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:           time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestGenerate_DeterministicWithSeed(t *testing.T) {
+	opts := testOptions()
+
+	a := generate(opts, rand.New(rand.NewPCG(7, 7)))
+	b := generate(opts, rand.New(rand.NewPCG(7, 7)))
+	c := generate(opts, rand.New(rand.NewPCG(8, 8)))
+	require.Equal(t, a, b)
+	require.NotEqual(t, a, c)
+}
+
+func TestGenerate_RespectsOptions(t *testing.T) {
+	opts := testOptions()
+	questions := generate(opts, rand.New(rand.NewPCG(1, 1)))
+	require.Len(t, questions, opts.Questions)
+
+	users := make(map[string]bool)
+	for i, q := range questions {
+		require.NotEmpty(t, q.Text)
+		require.False(t, q.CreatedAt.Before(opts.From))
+		require.True(t, q.CreatedAt.Before(opts.To))
+		if i > 0 {
+			require.False(t, q.CreatedAt.Before(questions[i-1].CreatedAt), "questions are ordered by time")
+		}
+
+		require.GreaterOrEqual(t, len(q.Answers), opts.MinAnswers)
+		require.LessOrEqual(t, len(q.Answers), opts.MaxAnswers)
+		for _, a := range q.Answers {
+			require.NotEmpty(t, a.Text)
+			require.False(t, a.CreatedAt.Before(q.CreatedAt))
+			require.True(t, a.CreatedAt.Before(opts.To))
+			users[a.UserID] = true
+		}
+	}
+	require.LessOrEqual(t, len(users), opts.Users)
+	require.Greater(t, len(users), 1)
+}
+
+func TestAnswerCounter_StaysInRange(t *testing.T) {
+	for _, dist := range []string{distUniform, distPoisson, distZipf} {
+		opts := testOptions()
+		opts.Distribution = dist
+		opts.MinAnswers, opts.MaxAnswers = 2, 30
+
+		next := answerCounter(opts, rand.New(rand.NewPCG(3, 3)))
+		seen := make(map[int]bool)
+		for range 2000 {
+			n := next()
+			require.GreaterOrEqual(t, n, 2, dist)
+			require.LessOrEqual(t, n, 30, dist)
+			seen[n] = true
+		}
+		require.Greater(t, len(seen), 5, dist)
+	}
+}
+
+func TestLoadFixtures(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, body string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(body), 0o644))
+		return path
+	}
+
+	yamlPath := write("demo.yaml", `
+questions:
+  - text: How do I paginate in GORM?
+    created_at: 2025-01-02T10:00:00Z
+    answers:
+      - user_id: alice
+        text: Use keyset pagination.
+      - user_id: bob
+        text: Order by id and filter with id > last.
+  - text: What is an advisory lock?
+`)
+	questions, err := loadFixtures(yamlPath)
+	require.NoError(t, err)
+	require.Len(t, questions, 2)
+	require.Equal(t, time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC), questions[0].CreatedAt)
+	require.Len(t, questions[0].Answers, 2)
+	require.Equal(t, "bob", questions[0].Answers[1].UserID)
+	require.True(t, questions[1].CreatedAt.IsZero())
+
+	jsonPath := write("demo.json", `{"questions": [{"text": "Q", "answers": [{"user_id": "u1", "text": "A"}]}]}`)
+	questions, err = loadFixtures(jsonPath)
+	require.NoError(t, err)
+	require.Len(t, questions, 1)
+	require.Equal(t, "u1", questions[0].Answers[0].UserID)
+
+	for name, body := range map[string]string{
+		"unknown.yaml":   "questions:\n  - text: Q\n    title: T\n",
+		"unknown.json":   `{"questions": [{"text": "Q", "title": "T"}]}`,
+		"no-text.yaml":   "questions:\n  - answers: []\n",
+		"no-user.json":   `{"questions": [{"text": "Q", "answers": [{"text": "A"}]}]}`,
+		"fixtures.toml":  "questions = []\n",
+		"broken.json":    `{"questions": [`,
+		"empty-ans.yaml": "questions:\n  - text: Q\n    answers:\n      - user_id: u1\n        text: ' '\n",
+	} {
+		_, err := loadFixtures(write(name, body))
+		require.Error(t, err, name)
+	}
+}
+
+func memoryOpen(store *repository.MemoryStore) openFunc {
+	return func(context.Context, *logger.Logger) (*target, error) {
+		return &target{
+			questions: repository.NewMemoryQuestionRepository(store),
+			answers:   repository.NewMemoryAnswerRepository(store),
+			tx:        store,
+			close:     func() error { return nil },
+		}, nil
+	}
+}
+
+func TestRun_SeedsStore(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewMemoryStore()
+	qRepo := repository.NewMemoryQuestionRepository(store)
+
+	fixture := filepath.Join(t.TempDir(), "demo.yml")
+	require.NoError(t, os.WriteFile(fixture, []byte(`
+questions:
+  - text: Fixture question
+    answers:
+      - user_id: alice
+        text: Fixture answer
+`), 0o644))
+
+	var stdout, stderr bytes.Buffer
+	code := run(ctx, []string{fixture, "--questions", "20", "--answers", "3", "--seed", "42", "--batch", "7"}, &stdout, &stderr, memoryOpen(store))
+	require.Equal(t, exitOK, code, stderr.String())
+	require.Contains(t, stdout.String(), "inserted 21 questions and 61 answers (seed 42)")
+
+	all, err := qRepo.List(ctx, "", 0, 0)
+	require.NoError(t, err)
+	require.Len(t, all, 21)
+	require.Equal(t, "Fixture question", all[0].Text)
+
+	first, err := qRepo.GetByID(ctx, all[0].ID)
+	require.NoError(t, err)
+	require.Len(t, first.Answers, 1)
+	require.Equal(t, "alice", first.Answers[0].UserID)
+
+	last, err := qRepo.GetByID(ctx, all[20].ID)
+	require.NoError(t, err)
+	require.Len(t, last.Answers, 3)
+}
+
+func TestRun_FixturesOnlyByDefault(t *testing.T) {
+	store := repository.NewMemoryStore()
+	fixture := filepath.Join(t.TempDir(), "demo.json")
+	require.NoError(t, os.WriteFile(fixture, []byte(`{"questions": [{"text": "Q"}]}`), 0o644))
+
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), []string{fixture}, &stdout, &stderr, memoryOpen(store))
+	require.Equal(t, exitOK, code, stderr.String())
+	require.Contains(t, stdout.String(), "inserted 1 questions and 0 answers")
+}
+
+func TestRun_Errors(t *testing.T) {
+	cases := []struct {
+		args []string
+		code int
+	}{
+		{[]string{"--help"}, exitOK},
+		{[]string{"--questions", "-1"}, exitUsage},
+		{[]string{"--answers", "5-2"}, exitUsage},
+		{[]string{"--answers", "a-b"}, exitUsage},
+		{[]string{"--distribution", "normal"}, exitUsage},
+		{[]string{"--users", "0"}, exitUsage},
+		{[]string{"--from", "2025-02-01", "--to", "2025-01-01"}, exitUsage},
+		{[]string{"--to", "yesterday"}, exitUsage},
+		{[]string{"--batch", "0"}, exitUsage},
+		{[]string{"--bogus"}, exitUsage},
+		{[]string{"missing.yaml"}, exitError},
+	}
+	for _, tc := range cases {
+		var stdout, stderr bytes.Buffer
+		code := run(context.Background(), tc.args, &stdout, &stderr, memoryOpen(repository.NewMemoryStore()))
+		require.Equal(t, tc.code, code, "%v: %s", tc.args, stderr.String())
+	}
+}
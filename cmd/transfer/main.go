@@ -0,0 +1,264 @@
+// transfer выгружает вопросы с ответами из базы в файл и загружает их обратно
+// без запущенного API: для переноса данных и резервных копий.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"question-service/internal/config"
+	"question-service/internal/db"
+	"question-service/internal/logger"
+	"question-service/internal/repository"
+	"question-service/internal/transfer"
+)
+
+// Коды выхода.
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+const usage = `Usage: transfer <command> [flags]
+
+Commands:
+  export [--format F] [--output FILE]
+        write all questions with answers to FILE (default stdout)
+  import [--format F] [--dry-run] FILE
+        load questions with answers from FILE ("-" for stdin); questions with a
+        known external_id are updated
+
+Flags:
+  --format F   ndjson (jsonl), csv or json; by default taken from the file
+               extension, ndjson otherwise
+  --output F   export destination
+  --dry-run    validate the input and count changes without saving them
+
+The database is configured with DATABASE_URL or DB_* variables, as for the API.
+Import runs in one transaction and prints a JSON report; records with errors
+are skipped and listed in the report. Imported rows produce no events or
+webhook deliveries.
+`
+
+// usageError — ошибка в аргументах командной строки.
+type usageError string
+
+func (e usageError) Error() string { return string(e) }
+
+// openFunc подключается к хранилищу; в тестах подменяется хранилищем в памяти.
+type openFunc func(ctx context.Context, log *logger.Logger) (svc *transfer.Service, closeFn func() error, err error)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr, openPostgres)
+	stop()
+	os.Exit(code)
+}
+
+// run выполняет команду и возвращает код выхода.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer, open openFunc) int {
+	encoder := zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig())
+	log := &logger.Logger{Logger: zap.New(zapcore.NewCore(encoder, zapcore.AddSync(stderr), zap.InfoLevel))}
+
+	err := execute(ctx, args, stdin, stdout, log, open)
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, flag.ErrHelp):
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	}
+
+	var uerr usageError
+	if errors.As(err, &uerr) {
+		fmt.Fprintf(stderr, "transfer: %v\n\n%s", err, usage)
+		return exitUsage
+	}
+	log.Error("transfer failed", zap.Error(err))
+	return exitError
+}
+
+func execute(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, log *logger.Logger, open openFunc) error {
+	if len(args) == 0 {
+		return usageError("command is required")
+	}
+	command, args := args[0], args[1:]
+
+	flags := flag.NewFlagSet("transfer "+command, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	formatName := flags.String("format", "", "")
+	var output *string
+	var dryRun *bool
+	switch command {
+	case "export":
+		output = flags.String("output", "", "")
+	case "import":
+		dryRun = flags.Bool("dry-run", false, "")
+	case "help", "-h", "--help":
+		return flag.ErrHelp
+	default:
+		return usageError(fmt.Sprintf("unknown command %q", command))
+	}
+
+	// флаги можно указывать и до файла, и после него
+	var files []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return err
+			}
+			return usageError(err.Error())
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		files = append(files, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+
+	switch command {
+	case "export":
+		if len(files) > 0 {
+			return usageError("export takes no arguments, use --output")
+		}
+		format, err := pickFormat(*formatName, *output)
+		if err != nil {
+			return err
+		}
+		return exportCommand(ctx, stdout, *output, format, log, open)
+	default:
+		if len(files) != 1 {
+			return usageError("import requires exactly one FILE")
+		}
+		format, err := pickFormat(*formatName, files[0])
+		if err != nil {
+			return err
+		}
+		return importCommand(ctx, stdin, stdout, files[0], format, *dryRun, log, open)
+	}
+}
+
+// pickFormat берёт формат из флага, иначе по расширению файла, иначе ndjson.
+func pickFormat(name, path string) (transfer.Format, error) {
+	if name != "" {
+		f, err := transfer.ParseFormat(name)
+		if err != nil {
+			return "", usageError(fmt.Sprintf("--format: %v", err))
+		}
+		return f, nil
+	}
+	if f, ok := transfer.FormatFromPath(path); ok {
+		return f, nil
+	}
+	return transfer.FormatNDJSON, nil
+}
+
+func exportCommand(ctx context.Context, stdout io.Writer, output string, format transfer.Format, log *logger.Logger, open openFunc) (err error) {
+	svc, closeFn, err := open(ctx, log)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	w := stdout
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}()
+		w = f
+	}
+
+	start := time.Now()
+	n, err := svc.Export(ctx, w, format)
+	if err != nil {
+		return err
+	}
+	log.Info("export finished",
+		zap.String("format", string(format)),
+		zap.Int("questions", n),
+		zap.Duration("duration", time.Since(start)),
+	)
+	return nil
+}
+
+func importCommand(ctx context.Context, stdin io.Reader, stdout io.Writer, path string, format transfer.Format, dryRun bool, log *logger.Logger, open openFunc) error {
+	r := stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	svc, closeFn, err := open(ctx, log)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	start := time.Now()
+	report, err := svc.Import(ctx, r, format, transfer.ImportOptions{DryRun: dryRun})
+	if err != nil {
+		return err
+	}
+	log.Info("import finished",
+		zap.String("format", string(format)),
+		zap.Bool("dry_run", report.DryRun),
+		zap.Int("created", report.Created),
+		zap.Int("updated", report.Updated),
+		zap.Int("failed", report.Failed),
+		zap.Duration("duration", time.Since(start)),
+	)
+
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// openPostgres подключается к базе из конфигурации API.
+func openPostgres(ctx context.Context, log *logger.Logger) (*transfer.Service, func() error, error) {
+	cfg := config.Load()
+
+	conn, err := db.New(ctx, cfg, log)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := db.CheckSchema(ctx, conn, log); err != nil {
+		if sqlDB, dbErr := conn.DB(); dbErr == nil {
+			_ = sqlDB.Close()
+		}
+		return nil, nil, err
+	}
+
+	cluster, err := db.NewCluster(conn, cfg, log)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	svc := transfer.NewService(
+		repository.NewQuestionRepository(cluster),
+		repository.NewAnswerRepository(cluster),
+		db.NewTxManager(cluster),
+	)
+	return svc, cluster.Close, nil
+}
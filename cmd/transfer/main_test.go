@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"question-service/internal/logger"
+	"question-service/internal/repository"
+	"question-service/internal/transfer"
+)
+
+// memoryOpen возвращает openFunc, которая всегда отдаёт одно хранилище в памяти.
+func memoryOpen() openFunc {
+	store := repository.NewMemoryStore()
+	svc := transfer.NewService(
+		repository.NewMemoryQuestionRepository(store),
+		repository.NewMemoryAnswerRepository(store),
+		store,
+	)
+	return func(context.Context, *logger.Logger) (*transfer.Service, func() error, error) {
+		return svc, func() error { return nil }, nil
+	}
+}
+
+func TestRun_ImportThenExport(t *testing.T) {
+	open := memoryOpen()
+	dir := t.TempDir()
+
+	input := filepath.Join(dir, "legacy.csv")
+	require.NoError(t, os.WriteFile(input, []byte(
+		"external_id,question_text,answer_user_id,answer_text\n"+
+			"q-1,First,u1,A1\n"+
+			"q-1,First,u2,A2\n"+
+			"q-2,Second,,\n"+
+			",,u3,orphan\n"), 0o644))
+
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), []string{"import", input, "--dry-run"}, nil, &stdout, &stderr, open)
+	require.Equal(t, exitOK, code, stderr.String())
+
+	var report transfer.ImportReport
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &report))
+	require.True(t, report.DryRun)
+	require.Equal(t, 2, report.Created)
+	require.Equal(t, 1, report.Failed)
+
+	stdout.Reset()
+	code = run(context.Background(), []string{"import", input}, nil, &stdout, &stderr, open)
+	require.Equal(t, exitOK, code, stderr.String())
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &report))
+	require.False(t, report.DryRun)
+	require.Equal(t, 2, report.Answers)
+
+	output := filepath.Join(dir, "backup.jsonl")
+	code = run(context.Background(), []string{"export", "--output", output}, nil, &stdout, &stderr, open)
+	require.Equal(t, exitOK, code, stderr.String())
+
+	data, err := os.ReadFile(output)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	require.Contains(t, lines[0], `"external_id":"q-1"`)
+
+	// повторная загрузка выгрузки со stdin ничего не меняет
+	stdout.Reset()
+	code = run(context.Background(), []string{"import", "--format", "ndjson", "-"}, bytes.NewReader(data), &stdout, &stderr, open)
+	require.Equal(t, exitOK, code, stderr.String())
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &report))
+	require.Equal(t, 2, report.Unchanged)
+	require.Zero(t, report.Created)
+}
+
+func TestRun_ExportToStdout(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), []string{"export", "--format", "json"}, nil, &stdout, &stderr, memoryOpen())
+	require.Equal(t, exitOK, code, stderr.String())
+	require.Equal(t, "[]\n", stdout.String())
+}
+
+func TestRun_UsageErrors(t *testing.T) {
+	for _, args := range [][]string{
+		nil,
+		{"backup"},
+		{"export", "--format", "xml"},
+		{"export", "file.csv"},
+		{"import"},
+		{"import", "a.csv", "b.csv"},
+	} {
+		var stdout, stderr bytes.Buffer
+		code := run(context.Background(), args, nil, &stdout, &stderr, memoryOpen())
+		require.Equal(t, exitUsage, code, "%v", args)
+		require.Contains(t, stderr.String(), "Usage: transfer")
+	}
+}
+
+func TestRun_InvalidInputFails(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "broken.json")
+	require.NoError(t, os.WriteFile(input, []byte(`{"text":"not an array"}`), 0o644))
+
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), []string{"import", input}, nil, &stdout, &stderr, memoryOpen())
+	require.Equal(t, exitError, code)
+	require.Contains(t, stderr.String(), "expected a JSON array")
+}
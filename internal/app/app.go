@@ -3,10 +3,13 @@ package app
 import (
 	"context"
 	"errors"
+	"net"
 	"net/http"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 	"gorm.io/gorm"
 
 	"question-service/internal/logger"
@@ -15,6 +18,16 @@ import (
 // Config описывает конфигурацию HTTP-приложения.
 type Config struct {
 	Address string
+	// GRPCAddress — адрес gRPC-сервера; используется, если задан App.GRPCServer.
+	GRPCAddress string
+}
+
+// Worker — фоновая задача, которая работает, пока не отменён переданный ей контекст.
+type Worker func(ctx context.Context) error
+
+type namedWorker struct {
+	name string
+	run  Worker
 }
 
 // App представляет собой HTTP-приложение.
@@ -23,6 +36,11 @@ type App struct {
 	Router     http.Handler
 	HTTPServer *http.Server
 	DB         *gorm.DB
+	// GRPCServer — необязательный gRPC-сервер, который работает рядом с HTTP на отдельном порту.
+	GRPCServer *grpc.Server
+
+	grpcAddress string
+	workers     []namedWorker
 }
 
 // NewApp создаёт новый экземпляр App на основе переданных зависимостей и конфигурации.
@@ -30,6 +48,9 @@ func NewApp(logger *logger.Logger, cfg Config, router http.Handler, db *gorm.DB)
 	if cfg.Address == "" {
 		cfg.Address = ":8080"
 	}
+	if cfg.GRPCAddress == "" {
+		cfg.GRPCAddress = ":9090"
+	}
 
 	server := &http.Server{
 		Addr:    cfg.Address,
@@ -37,16 +58,69 @@ func NewApp(logger *logger.Logger, cfg Config, router http.Handler, db *gorm.DB)
 	}
 
 	return &App{
-		Logger:     logger,
-		Router:     router,
-		HTTPServer: server,
-		DB:         db,
+		Logger:      logger,
+		Router:      router,
+		HTTPServer:  server,
+		DB:          db,
+		grpcAddress: cfg.GRPCAddress,
 	}
 }
 
-// Run запускает HTTP-сервер и блокируется до отмены контекста или ошибки сервера.
+// AddWorker регистрирует фоновую задачу, которая запускается вместе с HTTP-сервером
+// и останавливается при его завершении.
+func (a *App) AddWorker(name string, w Worker) {
+	a.workers = append(a.workers, namedWorker{name: name, run: w})
+}
+
+// OnShutdown регистрирует функцию, которая вызывается в начале остановки сервера.
+// Нужна для долгоживущих соединений (потоков событий), которых Shutdown не закрывает сам.
+func (a *App) OnShutdown(f func()) {
+	a.HTTPServer.RegisterOnShutdown(f)
+}
+
+// Run запускает HTTP-сервер (и gRPC-сервер, если он задан) и блокируется до отмены контекста или ошибки сервера.
 func (a *App) Run(ctx context.Context) error {
-	serverErr := make(chan error, 1)
+	workersCtx, stopWorkers := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer func() {
+		stopWorkers()
+		wg.Wait()
+	}()
+
+	for _, w := range a.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			a.Logger.Info("starting worker", zap.String("worker", w.name))
+			if err := w.run(workersCtx); err != nil && !errors.Is(err, context.Canceled) {
+				a.Logger.Error("worker stopped with error",
+					zap.String("worker", w.name),
+					zap.Error(err),
+				)
+			}
+		}()
+	}
+
+	serverErr := make(chan error, 2)
+
+	if a.GRPCServer != nil {
+		lis, err := net.Listen("tcp", a.grpcAddress)
+		if err != nil {
+			return err
+		}
+
+		go func() {
+			a.Logger.Info("starting gRPC server",
+				zap.String("address", a.grpcAddress),
+			)
+
+			if err := a.GRPCServer.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+				serverErr <- err
+			}
+		}()
+		defer a.GRPCServer.Stop()
+	}
 
 	go func() {
 		a.Logger.Info("starting HTTP server",
@@ -66,6 +140,16 @@ func (a *App) Run(ctx context.Context) error {
 
 		a.Logger.Info("shutting down HTTP server")
 
+		// gRPC останавливается параллельно с HTTP, в пределах того же таймаута
+		grpcStopped := make(chan struct{})
+		go func() {
+			defer close(grpcStopped)
+			if a.GRPCServer != nil {
+				a.stopGRPC(shutdownCtx)
+			}
+		}()
+		defer func() { <-grpcStopped }()
+
 		if err := a.HTTPServer.Shutdown(shutdownCtx); err != nil && !errors.Is(err, context.Canceled) {
 			return err
 		}
@@ -76,3 +160,20 @@ func (a *App) Run(ctx context.Context) error {
 		return err
 	}
 }
+
+// stopGRPC дожидается завершения текущих gRPC-вызовов, но не дольше ctx.
+func (a *App) stopGRPC(ctx context.Context) {
+	a.Logger.Info("shutting down gRPC server")
+
+	done := make(chan struct{})
+	go func() {
+		a.GRPCServer.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		a.GRPCServer.Stop()
+	}
+}
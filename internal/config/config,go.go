@@ -1,36 +1,280 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
 	HTTPPort string
-	DBHost   string
-	DBPort   string
-	DBUser   string
-	DBPass   string
-	DBName   string
-	DBSSL    string
+	// GRPCEnabled включает gRPC API на адресе GRPCPort.
+	GRPCEnabled bool
+	GRPCPort    string
+	// HTTPRequireIfMatch — требовать If-Match для изменений и удалений (иначе 428).
+	HTTPRequireIfMatch bool
+	// HTTPBatchMaxItems — сколько элементов принимают пакетные запросы создания.
+	HTTPBatchMaxItems int
+
+	// StorageBackend — хранилище данных: postgres или memory.
+	StorageBackend string
+
+	// DatabaseURL — полный DSN (DATABASE_URL). Если задан, дискретные поля DB_* игнорируются.
+	DatabaseURL string
+	DBHost      string
+	DBPort      string
+	DBUser      string
+	DBPass      string
+	DBName      string
+	DBSSL       string
+
+	// настройки пула соединений
+	DBMaxOpenConns    int
+	DBMaxIdleConns    int
+	DBConnMaxLifetime time.Duration
+	DBConnMaxIdleTime time.Duration
+
+	// настройки повторных попыток подключения
+	DBConnectTimeout    time.Duration
+	DBMaxAttempts       int
+	DBRetryInitialDelay time.Duration
+	DBRetryMaxDelay     time.Duration
+
+	// реплики для чтения
+	DBReplicaURLs           []string
+	DBReplicaPolicy         string
+	DBReadYourWritesWindow  time.Duration
+	DBReplicaHealthInterval time.Duration
+
+	// IdempotencyTTL — сколько хранятся ответы для повторов с Idempotency-Key.
+	IdempotencyTTL time.Duration
+	// IdempotencyLease — сколько выполняющийся запрос держит ключ, прежде чем повтор займёт его.
+	IdempotencyLease           time.Duration
+	IdempotencyJanitorInterval time.Duration
+
+	// публикация доменных событий из outbox
+	OutboxPublisher      string
+	OutboxWebhookURL     string
+	OutboxWebhookTimeout time.Duration
+	OutboxPollInterval   time.Duration
+	OutboxBatchSize      int
+	// OutboxMaxAttempts — после стольких неудачных публикаций событие отбрасывается (0 — без ограничения).
+	OutboxMaxAttempts int
+
+	// доставка вебхуков подписчикам
+	WebhookPollInterval         time.Duration
+	WebhookBatchSize            int
+	WebhookTimeout              time.Duration
+	WebhookMaxAttempts          int
+	WebhookDisableAfterFailures int
+	WebhookRetryInitialDelay    time.Duration
+	WebhookRetryMaxDelay        time.Duration
+	// WebhookAllowPrivateTargets разрешает подписки на адреса во внутренней сети.
+	WebhookAllowPrivateTargets bool
+
+	// поток событий вопроса (SSE)
+	SSEHeartbeatInterval       time.Duration
+	SSEReplayBufferSize        int
+	SSEMaxConnectionsPerClient int
+
+	// WebSocket API
+	WSAllowedOrigins []string
+	WSSendQueueSize  int
+	WSWriteTimeout   time.Duration
+	WSPingInterval   time.Duration
+
+	// AutoMigrate — применять встроенные миграции при старте API (под advisory lock).
+	// Без него API не стартует, если схема базы отстаёт от бинарника.
+	AutoMigrate bool
+
+	// AnswerMaxReplyDepth — допустимая глубина реплик на ответы (0 — реплики запрещены).
+	AnswerMaxReplyDepth int
+
+	// DuplicateThreshold — минимальное сходство текстов (от 0 до 1), с которого вопрос
+	// считается возможным дубликатом.
+	DuplicateThreshold float64
+
+	// AdminToken — токен Bearer административных маршрутов; пустой — маршруты выключены.
+	AdminToken string
+
+	// корзина удалённых вопросов и ответов
+	TrashRetention     time.Duration
+	TrashPurgeInterval time.Duration
 }
 
 func Load() *Config {
 	cfg := &Config{
-		HTTPPort: getEnv("HTTP_PORT", ":8080"),
-		DBHost:   getEnv("DB_HOST", "localhost"),
-		DBPort:   getEnv("DB_PORT", "5432"),
-		DBUser:   getEnv("DB_USER", "postgres"),
-		DBPass:   getEnv("DB_PASS", "postgres"),
-		DBName:   getEnv("DB_NAME", "qna"),
-		DBSSL:    getEnv("DB_SSLMODE", "disable"),
+		HTTPPort:           getEnv("HTTP_PORT", ":8080"),
+		GRPCEnabled:        getEnvBool("GRPC_ENABLED", true),
+		GRPCPort:           getEnv("GRPC_PORT", ":9090"),
+		HTTPRequireIfMatch: getEnvBool("HTTP_REQUIRE_IF_MATCH", false),
+		HTTPBatchMaxItems:  getEnvInt("HTTP_BATCH_MAX_ITEMS", 100),
+
+		StorageBackend: getEnv("STORAGE_BACKEND", "postgres"),
+
+		DatabaseURL: getEnv("DATABASE_URL", ""),
+		DBHost:      getEnv("DB_HOST", "localhost"),
+		DBPort:      getEnv("DB_PORT", "5432"),
+		DBUser:      getEnv("DB_USER", "postgres"),
+		DBPass:      getEnv("DB_PASS", "postgres"),
+		DBName:      getEnv("DB_NAME", "qna"),
+		DBSSL:       getEnv("DB_SSLMODE", "disable"),
+
+		DBMaxOpenConns:    getEnvInt("DB_MAX_OPEN_CONNS", 10),
+		DBMaxIdleConns:    getEnvInt("DB_MAX_IDLE_CONNS", 5),
+		DBConnMaxLifetime: getEnvDuration("DB_CONN_MAX_LIFETIME", time.Hour),
+		DBConnMaxIdleTime: getEnvDuration("DB_CONN_MAX_IDLE_TIME", 0),
+
+		DBConnectTimeout:    getEnvDuration("DB_CONNECT_TIMEOUT", 30*time.Second),
+		DBMaxAttempts:       getEnvInt("DB_MAX_ATTEMPTS", 0),
+		DBRetryInitialDelay: getEnvDuration("DB_RETRY_INITIAL_DELAY", 500*time.Millisecond),
+		DBRetryMaxDelay:     getEnvDuration("DB_RETRY_MAX_DELAY", 10*time.Second),
+
+		DBReplicaURLs:           getEnvList("DB_REPLICA_URLS"),
+		DBReplicaPolicy:         getEnv("DB_REPLICA_POLICY", "round_robin"),
+		DBReadYourWritesWindow:  getEnvDuration("DB_READ_YOUR_WRITES_WINDOW", time.Second),
+		DBReplicaHealthInterval: getEnvDuration("DB_REPLICA_HEALTH_INTERVAL", 5*time.Second),
+
+		IdempotencyTTL:             getEnvDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+		IdempotencyLease:           getEnvDuration("IDEMPOTENCY_LEASE", time.Minute),
+		IdempotencyJanitorInterval: getEnvInterval("IDEMPOTENCY_JANITOR_INTERVAL", 10*time.Minute),
+
+		OutboxPublisher:      getEnv("OUTBOX_PUBLISHER", "log"),
+		OutboxWebhookURL:     getEnv("OUTBOX_WEBHOOK_URL", ""),
+		OutboxWebhookTimeout: getEnvDuration("OUTBOX_WEBHOOK_TIMEOUT", 5*time.Second),
+		OutboxPollInterval:   getEnvDuration("OUTBOX_POLL_INTERVAL", time.Second),
+		OutboxBatchSize:      getEnvInt("OUTBOX_BATCH_SIZE", 100),
+		OutboxMaxAttempts:    getEnvInt("OUTBOX_MAX_ATTEMPTS", 20),
+
+		WebhookPollInterval:         getEnvDuration("WEBHOOK_POLL_INTERVAL", time.Second),
+		WebhookBatchSize:            getEnvInt("WEBHOOK_BATCH_SIZE", 50),
+		WebhookTimeout:              getEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+		WebhookMaxAttempts:          getEnvInt("WEBHOOK_MAX_ATTEMPTS", 10),
+		WebhookDisableAfterFailures: getEnvInt("WEBHOOK_DISABLE_AFTER_FAILURES", 20),
+		WebhookRetryInitialDelay:    getEnvDuration("WEBHOOK_RETRY_INITIAL_DELAY", 10*time.Second),
+		WebhookRetryMaxDelay:        getEnvDuration("WEBHOOK_RETRY_MAX_DELAY", time.Hour),
+		WebhookAllowPrivateTargets:  getEnvBool("WEBHOOK_ALLOW_PRIVATE_TARGETS", false),
+
+		SSEHeartbeatInterval:       getEnvInterval("SSE_HEARTBEAT_INTERVAL", 15*time.Second),
+		SSEReplayBufferSize:        getEnvInt("SSE_REPLAY_BUFFER_SIZE", 1000),
+		SSEMaxConnectionsPerClient: getEnvInt("SSE_MAX_CONNECTIONS_PER_CLIENT", 5),
+
+		WSAllowedOrigins: getEnvList("WS_ALLOWED_ORIGINS"),
+		WSSendQueueSize:  getEnvInt("WS_SEND_QUEUE_SIZE", 64),
+		WSWriteTimeout:   getEnvDuration("WS_WRITE_TIMEOUT", 5*time.Second),
+		WSPingInterval:   getEnvDuration("WS_PING_INTERVAL", 30*time.Second),
+
+		AutoMigrate: getEnvBool("AUTO_MIGRATE", false),
+
+		AnswerMaxReplyDepth: getEnvInt("ANSWER_MAX_REPLY_DEPTH", 5),
+
+		DuplicateThreshold: getEnvFloat("DUPLICATE_THRESHOLD", 0.4),
+
+		AdminToken: getEnv("ADMIN_TOKEN", ""),
+
+		TrashRetention:     getEnvDuration("TRASH_RETENTION", 30*24*time.Hour),
+		TrashPurgeInterval: getEnvInterval("TRASH_PURGE_INTERVAL", time.Hour),
 	}
 
 	return cfg
 }
 
+// DSN возвращает строку подключения к PostgreSQL: DATABASE_URL, если он задан,
+// иначе DSN, собранный из дискретных полей DB_*.
+func (c *Config) DSN() string {
+	if c.DatabaseURL != "" {
+		return c.DatabaseURL
+	}
+
+	return fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s TimeZone=UTC",
+		c.DBHost,
+		c.DBPort,
+		c.DBUser,
+		c.DBPass,
+		c.DBName,
+		c.DBSSL,
+	)
+}
+
 func getEnv(key, defaultVal string) string {
 	if v := os.Getenv(key); v != "" {
 		return v
 	}
 	return defaultVal
 }
+
+// getEnvList читает список значений, разделённых запятыми; пустые элементы отбрасываются.
+func getEnvList(key string) []string {
+	var out []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func getEnvInt(key string, defaultVal int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return defaultVal
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return defaultVal
+	}
+	return n
+}
+
+func getEnvFloat(key string, defaultVal float64) float64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return defaultVal
+	}
+
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return defaultVal
+	}
+	return f
+}
+
+func getEnvBool(key string, defaultVal bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return defaultVal
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return defaultVal
+	}
+	return b
+}
+
+func getEnvDuration(key string, defaultVal time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return defaultVal
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return defaultVal
+	}
+	return d
+}
+
+// getEnvInterval читает период фоновой задачи или пингов. Период должен быть положительным:
+// по нему заводится time.Ticker, поэтому ноль и отрицательные значения заменяются
+// значением по умолчанию.
+func getEnvInterval(key string, defaultVal time.Duration) time.Duration {
+	if d := getEnvDuration(key, defaultVal); d > 0 {
+		return d
+	}
+	return defaultVal
+}
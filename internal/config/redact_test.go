@@ -0,0 +1,22 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"question-service/internal/config"
+)
+
+func TestRedactDSN(t *testing.T) {
+	for in, want := range map[string]string{
+		"postgres://app:s3cret@db:5432/qna?sslmode=disable":      "postgres://app:xxxxx@db:5432/qna?sslmode=disable",
+		"postgres://app@db/qna":                                  "postgres://app@db/qna",
+		"postgres://db/qna?user=app&password=s3cret&x=1":         "postgres://db/qna?user=app&password=xxxxx&x=1",
+		"host=db user=app password=s3cret dbname=qna":            "host=db user=app password=xxxxx dbname=qna",
+		"host=db password='with space' dbname=qna":               "host=db password=xxxxx dbname=qna",
+		"connecting to postgres://a:b@h/x and host=h password=p": "connecting to postgres://a:xxxxx@h/x and host=h password=xxxxx",
+	} {
+		require.Equal(t, want, config.RedactDSN(in), in)
+	}
+}
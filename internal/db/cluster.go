@@ -0,0 +1,223 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"question-service/internal/config"
+	"question-service/internal/logger"
+)
+
+// Policy — политика распределения чтений между репликами.
+type Policy string
+
+const (
+	PolicyRoundRobin Policy = "round_robin"
+	PolicyRandom     Policy = "random"
+)
+
+// ClusterOptions описывает поведение маршрутизации запросов между primary и репликами.
+// Чтобы клиент читал свои записи, контекст его запросов помечают WithPrimary.
+type ClusterOptions struct {
+	Policy Policy
+	// HealthCheckInterval — период проверки доступности реплик.
+	HealthCheckInterval time.Duration
+}
+
+// Cluster маршрутизирует операции: записи — в primary, чтения — в здоровые реплики.
+// Без реплик все операции идут в primary.
+type Cluster struct {
+	primary  *gorm.DB
+	replicas []*replica
+	opts     ClusterOptions
+	log      *logger.Logger
+
+	next atomic.Uint64
+	ping func(ctx context.Context, db *gorm.DB) error
+}
+
+type replica struct {
+	name    string
+	db      *gorm.DB
+	healthy atomic.Bool
+}
+
+// NewCluster открывает пулы соединений к репликам из cfg.DBReplicaURLs.
+// Реплики считаются недоступными до первой успешной проверки, поэтому
+// до запуска RunHealthChecks все чтения идут в primary.
+func NewCluster(primary *gorm.DB, cfg *config.Config, log *logger.Logger) (*Cluster, error) {
+	replicas := make([]*gorm.DB, 0, len(cfg.DBReplicaURLs))
+	for i, dsn := range cfg.DBReplicaURLs {
+		db, err := openPool(dsn, cfg)
+		if err != nil {
+			for _, r := range replicas {
+				_ = closeDB(r)
+			}
+			return nil, fmt.Errorf("open replica #%d: %w", i, err)
+		}
+		replicas = append(replicas, db)
+	}
+
+	return newCluster(primary, replicas, ClusterOptions{
+		Policy:              Policy(cfg.DBReplicaPolicy),
+		HealthCheckInterval: cfg.DBReplicaHealthInterval,
+	}, log), nil
+}
+
+func newCluster(primary *gorm.DB, replicas []*gorm.DB, opts ClusterOptions, log *logger.Logger) *Cluster {
+	if opts.Policy == "" {
+		opts.Policy = PolicyRoundRobin
+	}
+	if opts.HealthCheckInterval <= 0 {
+		opts.HealthCheckInterval = 5 * time.Second
+	}
+
+	c := &Cluster{
+		primary: primary,
+		opts:    opts,
+		log:     log,
+		ping:    pingDB,
+	}
+	for i, db := range replicas {
+		c.replicas = append(c.replicas, &replica{name: fmt.Sprintf("replica-%d", i), db: db})
+	}
+
+	return c
+}
+
+// Primary возвращает подключение к primary без привязки к контексту.
+func (c *Cluster) Primary() *gorm.DB {
+	return c.primary
+}
+
+// Writer возвращает подключение для операции записи.
+// Внутри TxManager.WithinTx возвращается текущая транзакция.
+func (c *Cluster) Writer(ctx context.Context) *gorm.DB {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.WithContext(ctx)
+	}
+	return c.primary.WithContext(ctx)
+}
+
+// Reader возвращает подключение для операции чтения.
+// Внутри TxManager.WithinTx возвращается текущая транзакция.
+func (c *Cluster) Reader(ctx context.Context) *gorm.DB {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.WithContext(ctx)
+	}
+	return c.pickReader(ctx).WithContext(ctx)
+}
+
+func (c *Cluster) pickReader(ctx context.Context) *gorm.DB {
+	if len(c.replicas) == 0 || UsePrimary(ctx) {
+		return c.primary
+	}
+
+	healthy := make([]*replica, 0, len(c.replicas))
+	for _, r := range c.replicas {
+		if r.healthy.Load() {
+			healthy = append(healthy, r)
+		}
+	}
+	if len(healthy) == 0 {
+		return c.primary
+	}
+
+	switch c.opts.Policy {
+	case PolicyRandom:
+		return healthy[rand.IntN(len(healthy))].db
+	default:
+		n := c.next.Add(1) - 1
+		return healthy[n%uint64(len(healthy))].db
+	}
+}
+
+// RunHealthChecks периодически пингует реплики и исключает недоступные из балансировки.
+// Блокируется до отмены ctx.
+func (c *Cluster) RunHealthChecks(ctx context.Context) error {
+	if len(c.replicas) == 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(c.opts.HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		c.checkReplicas(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Cluster) checkReplicas(ctx context.Context) {
+	for _, r := range c.replicas {
+		pingCtx, cancel := context.WithTimeout(ctx, c.opts.HealthCheckInterval)
+		err := c.ping(pingCtx, r.db)
+		cancel()
+
+		healthy := err == nil
+		if r.healthy.Swap(healthy) == healthy {
+			continue
+		}
+
+		if healthy {
+			c.log.Info("database replica is healthy", zap.String("replica", r.name))
+		} else {
+			c.log.Warn("database replica is unhealthy, falling back",
+				zap.String("replica", r.name),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// Close закрывает пулы соединений реплик и primary.
+func (c *Cluster) Close() error {
+	var errs []error
+	for _, r := range c.replicas {
+		errs = append(errs, closeDB(r.db))
+	}
+	errs = append(errs, closeDB(c.primary))
+	return errors.Join(errs...)
+}
+
+func pingDB(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+func closeDB(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+type usePrimaryKey struct{}
+
+// WithPrimary помечает контекст так, что все чтения в нём идут в primary.
+// Используется там, где нужно прочитать только что записанные данные.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, usePrimaryKey{}, true)
+}
+
+// UsePrimary сообщает, требует ли контекст чтения из primary.
+func UsePrimary(ctx context.Context) bool {
+	v, _ := ctx.Value(usePrimaryKey{}).(bool)
+	return v
+}
@@ -0,0 +1,79 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"question-service/internal/logger"
+)
+
+// fakeDB открывает пул без подключения к серверу: для маршрутизации нужен только указатель.
+func fakeDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(postgres.Open("host=127.0.0.1 port=1 user=x dbname=x sslmode=disable"), &gorm.Config{
+		DisableAutomaticPing: true,
+	})
+	require.NoError(t, err)
+	return db
+}
+
+func newTestCluster(t *testing.T, replicas int, opts ClusterOptions) (*Cluster, *gorm.DB, []*gorm.DB) {
+	t.Helper()
+
+	primary := fakeDB(t)
+	var rs []*gorm.DB
+	for i := 0; i < replicas; i++ {
+		rs = append(rs, fakeDB(t))
+	}
+
+	c := newCluster(primary, rs, opts, &logger.Logger{Logger: zap.NewNop()})
+	c.ping = func(context.Context, *gorm.DB) error { return nil }
+	c.checkReplicas(context.Background())
+	return c, primary, rs
+}
+
+func TestCluster_RoundRobinOverHealthyReplicas(t *testing.T) {
+	c, _, rs := newTestCluster(t, 2, ClusterOptions{Policy: PolicyRoundRobin})
+	ctx := context.Background()
+
+	require.Same(t, rs[0], c.pickReader(ctx))
+	require.Same(t, rs[1], c.pickReader(ctx))
+	require.Same(t, rs[0], c.pickReader(ctx))
+}
+
+func TestCluster_UnhealthyReplicasFallBackToPrimary(t *testing.T) {
+	c, primary, rs := newTestCluster(t, 2, ClusterOptions{})
+	ctx := context.Background()
+
+	c.ping = func(_ context.Context, db *gorm.DB) error {
+		if db == rs[0] {
+			return errors.New("down")
+		}
+		return nil
+	}
+	c.checkReplicas(ctx)
+	require.Same(t, rs[1], c.pickReader(ctx))
+	require.Same(t, rs[1], c.pickReader(ctx))
+
+	c.ping = func(context.Context, *gorm.DB) error { return errors.New("down") }
+	c.checkReplicas(ctx)
+	require.Same(t, primary, c.pickReader(ctx))
+}
+
+func TestCluster_WithPrimary(t *testing.T) {
+	c, primary, rs := newTestCluster(t, 1, ClusterOptions{})
+
+	require.Same(t, rs[0], c.pickReader(context.Background()))
+	require.Same(t, primary, c.pickReader(WithPrimary(context.Background())))
+
+	// запись без пометки контекста не уводит чтения других клиентов в primary
+	c.Writer(context.Background())
+	require.Same(t, rs[0], c.pickReader(context.Background()))
+}
@@ -1,7 +1,9 @@
 package db
 
 import (
+	"context"
 	"fmt"
+	"math/rand/v2"
 	"time"
 
 	"go.uber.org/zap"
@@ -12,52 +14,59 @@ import (
 	"question-service/internal/logger"
 )
 
-const maxAttempts = 5
-
 // New создаёт новое подключение к PostgreSQL с помощью GORM.
-func New(cfg *config.Config, log *logger.Logger) (*gorm.DB, error) {
-	dsn := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s TimeZone=UTC",
-		cfg.DBHost,
-		cfg.DBPort,
-		cfg.DBUser,
-		cfg.DBPass,
-		cfg.DBName,
-		cfg.DBSSL,
-	)
+//
+// Подключение повторяется с экспоненциальной задержкой и джиттером, пока не
+// истечёт DBConnectTimeout, не будет исчерпан DBMaxAttempts (0 — без ограничения)
+// или не будет отменён ctx. Успехом считается только успешный ping.
+func New(ctx context.Context, cfg *config.Config, log *logger.Logger) (*gorm.DB, error) {
+	if cfg.DBConnectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.DBConnectTimeout)
+		defer cancel()
+	}
 
-	var (
-		db  *gorm.DB
-		err error
-	)
+	dsn := cfg.DSN()
 
+	// DB_HOST и остальные поля игнорируются, если задан DATABASE_URL, поэтому
+	// в журнал пишется итоговый DSN без пароля
 	log.Info("connecting to database",
-		zap.String("host", cfg.DBHost),
-		zap.String("port", cfg.DBPort),
-		zap.String("db", cfg.DBName),
+		zap.String("dsn", config.RedactDSN(dsn)),
+		zap.Bool("database_url", cfg.DatabaseURL != ""),
 	)
 
-	for attempt := 1; attempt <= maxAttempts; attempt++ {
-		log.Info("connecting to database",
-			zap.String("host", cfg.DBHost),
-			zap.String("port", cfg.DBPort),
-			zap.String("db", cfg.DBName),
-			zap.Int("attempt", attempt),
-		)
-
-		db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	for attempt := 1; ; attempt++ {
+		db, err := open(ctx, dsn, cfg)
 		if err == nil {
-			break
+			log.Info("database connection established", zap.Int("attempt", attempt))
+			return db, nil
+		}
+
+		if cfg.DBMaxAttempts > 0 && attempt >= cfg.DBMaxAttempts {
+			return nil, fmt.Errorf("connect to database: giving up after %d attempts: %w", attempt, err)
 		}
 
+		delay := backoff(attempt, cfg.DBRetryInitialDelay, cfg.DBRetryMaxDelay, rand.Float64)
+
 		log.Warn("failed to connect to database, will retry",
 			zap.Error(err),
 			zap.Int("attempt", attempt),
+			zap.Duration("delay", delay),
 		)
 
-		time.Sleep(time.Duration(attempt) * time.Second)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("connect to database: %w (last error: %v)", ctx.Err(), err)
+		case <-timer.C:
+		}
 	}
+}
 
+// open выполняет одну попытку подключения: открывает пул, применяет его настройки и пингует БД.
+func open(ctx context.Context, dsn string, cfg *config.Config) (*gorm.DB, error) {
+	db, err := openPool(dsn, cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -67,12 +76,58 @@ func New(cfg *config.Config, log *logger.Logger) (*gorm.DB, error) {
 		return nil, err
 	}
 
-	// базовые настройки пула соединений
-	sqlDB.SetMaxOpenConns(10)
-	sqlDB.SetMaxIdleConns(5)
-	sqlDB.SetConnMaxLifetime(time.Hour)
+	if err := sqlDB.PingContext(ctx); err != nil {
+		_ = sqlDB.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// openPool открывает пул соединений без обращения к БД.
+func openPool(dsn string, cfg *config.Config) (*gorm.DB, error) {
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		// пингуем сами, с учётом контекста
+		DisableAutomaticPing: true,
+		// ошибки драйвера (например, нарушение FK) переводятся в gorm.Err*
+		TranslateError: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
 
-	log.Info("database connection established")
+	sqlDB.SetMaxOpenConns(cfg.DBMaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.DBMaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.DBConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.DBConnMaxIdleTime)
 
 	return db, nil
 }
+
+// backoff возвращает задержку перед следующей попыткой: экспоненциальный рост
+// от initial до max и «equal jitter» — половина задержки фиксирована, половина случайна.
+// Если max не больше initial, задержка не растёт.
+func backoff(attempt int, initial, max time.Duration, random func() float64) time.Duration {
+	if initial <= 0 {
+		return 0
+	}
+	if max < initial {
+		max = initial
+	}
+
+	d := initial
+	for i := 1; i < attempt && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+
+	half := d / 2
+	return half + time.Duration(random()*float64(d-half))
+}
@@ -0,0 +1,24 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoff_GrowsExponentiallyUpToMax(t *testing.T) {
+	noJitter := func() float64 { return 1 }
+
+	require.Equal(t, 100*time.Millisecond, backoff(1, 100*time.Millisecond, time.Second, noJitter))
+	require.Equal(t, 200*time.Millisecond, backoff(2, 100*time.Millisecond, time.Second, noJitter))
+	require.Equal(t, 800*time.Millisecond, backoff(4, 100*time.Millisecond, time.Second, noJitter))
+	require.Equal(t, time.Second, backoff(5, 100*time.Millisecond, time.Second, noJitter))
+	require.Equal(t, time.Second, backoff(100, 100*time.Millisecond, time.Second, noJitter))
+}
+
+func TestBackoff_JitterKeepsAtLeastHalf(t *testing.T) {
+	zeroJitter := func() float64 { return 0 }
+
+	require.Equal(t, 200*time.Millisecond, backoff(3, 100*time.Millisecond, time.Second, zeroJitter))
+}
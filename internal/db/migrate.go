@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"question-service/internal/logger"
+	"question-service/migrations"
+)
+
+// ErrSchemaOutdated — в базе применены не все миграции, встроенные в бинарник.
+var ErrSchemaOutdated = errors.New("database schema is outdated")
+
+// newProvider создаёт goose.Provider для встроенных миграций. С withLock миграции
+// выполняются под session-level advisory lock PostgreSQL.
+func newProvider(conn *gorm.DB, withLock bool) (*goose.Provider, error) {
+	sqlDB, err := conn.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []goose.ProviderOption
+	if withLock {
+		locker, err := lock.NewPostgresSessionLocker()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, goose.WithSessionLocker(locker))
+	}
+
+	// Provider.Close не вызываем: он закрыл бы общий пул соединений
+	return goose.NewProvider(goose.DialectPostgres, sqlDB, migrations.FS, opts...)
+}
+
+// Migrate применяет встроенные миграции. Если несколько экземпляров стартуют
+// одновременно, мигрирует тот, кто первым взял advisory lock; остальные ждут
+// его и находят схему актуальной.
+func Migrate(ctx context.Context, conn *gorm.DB, log *logger.Logger) error {
+	provider, err := newProvider(conn, true)
+	if err != nil {
+		return fmt.Errorf("create migration provider: %w", err)
+	}
+
+	log.Info("applying database migrations")
+
+	results, err := provider.Up(ctx)
+	if err != nil {
+		return fmt.Errorf("apply migrations: %w", err)
+	}
+	for _, r := range results {
+		log.Info("migration applied",
+			zap.Int64("version", r.Source.Version),
+			zap.Duration("duration", r.Duration),
+		)
+	}
+
+	version, err := provider.GetDBVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("get schema version: %w", err)
+	}
+	log.Info("database schema is up to date", zap.Int64("version", version), zap.Int("applied", len(results)))
+	return nil
+}
+
+// CheckSchema возвращает ErrSchemaOutdated, если версия схемы в базе ниже
+// последней встроенной миграции: такой бинарник не должен обслуживать запросы.
+// Более новая схема допустима — её мог применить следующий релиз при выкатке.
+func CheckSchema(ctx context.Context, conn *gorm.DB, log *logger.Logger) error {
+	provider, err := newProvider(conn, false)
+	if err != nil {
+		return fmt.Errorf("create migration provider: %w", err)
+	}
+
+	current, target, err := provider.GetVersions(ctx)
+	if err != nil {
+		return fmt.Errorf("get schema version: %w", err)
+	}
+
+	switch {
+	case current < target:
+		return fmt.Errorf("%w: database is at version %d, binary expects %d; run migrations or set AUTO_MIGRATE=true",
+			ErrSchemaOutdated, current, target)
+	case current > target:
+		log.Warn("database schema is newer than this binary",
+			zap.Int64("version", current),
+			zap.Int64("expected", target),
+		)
+	}
+	return nil
+}
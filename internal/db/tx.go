@@ -0,0 +1,37 @@
+package db
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+// TxManager выполняет функции в транзакции primary. Транзакция передаётся через
+// контекст, и Cluster.Reader/Writer возвращают её вместо пула, поэтому репозитории
+// участвуют в ней без изменения сигнатур.
+type TxManager struct {
+	cluster *Cluster
+}
+
+func NewTxManager(cluster *Cluster) *TxManager {
+	return &TxManager{cluster: cluster}
+}
+
+// WithinTx выполняет fn в транзакции: коммит при nil, откат при ошибке или панике.
+// Вложенный вызов присоединяется к уже открытой транзакции.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if txFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+
+	return m.cluster.primary.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+func txFromContext(ctx context.Context) *gorm.DB {
+	tx, _ := ctx.Value(txKey{}).(*gorm.DB)
+	return tx
+}
@@ -1,11 +1,27 @@
 package domain
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Answer struct {
-	ID         int       `gorm:"primaryKey;autoIncrement" json:"id"`
-	QuestionID int       `gorm:"not null;index"           json:"question_id"`
-	UserID     string    `gorm:"type:varchar(64);not null" json:"user_id"`
-	Text       string    `gorm:"type:text;not null"        json:"text"`
-	CreatedAt  time.Time `gorm:"not null;autoCreateTime"   json:"created_at"`
+	ID         int `gorm:"primaryKey;autoIncrement" json:"id"`
+	QuestionID int `gorm:"not null;index"           json:"question_id"`
+	// ParentAnswerID — ответ того же вопроса, на который это реплика; nil у ответов верхнего уровня.
+	ParentAnswerID *int      `gorm:"index"                     json:"parent_answer_id,omitempty"`
+	UserID         string    `gorm:"type:varchar(64);not null" json:"user_id"`
+	Text           string    `gorm:"type:text;not null"        json:"text"`
+	CreatedAt      time.Time `gorm:"not null;autoCreateTime"   json:"created_at"`
+	Version        int       `gorm:"not null;default:1"        json:"version"`
+	// DeletedAt — время удаления в корзину; удалённые ответы не видны обычным запросам.
+	DeletedAt gorm.DeletedAt `json:"-"`
+	// Hidden — ответ скрыт модератором и вместе с репликами не виден пользователям.
+	Hidden bool `gorm:"not null;default:false" json:"-"`
+	// Depth — глубина реплики в ветке (0 у ответов верхнего уровня). Не хранится:
+	// заполняется только при выборке ветки обсуждения.
+	Depth int `gorm:"->" json:"depth,omitempty"`
+	// Comments — комментарии к ответу; заполняются только по запросу.
+	Comments []Comment `gorm:"-" json:"comments,omitempty"`
 }
@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// Comment — уточнение к вопросу или ответу. Заполнено ровно одно из QuestionID и AnswerID.
+type Comment struct {
+	ID         int       `gorm:"primaryKey;autoIncrement"  json:"id"`
+	QuestionID *int      `gorm:"index"                     json:"question_id,omitempty"`
+	AnswerID   *int      `gorm:"index"                     json:"answer_id,omitempty"`
+	UserID     string    `gorm:"type:varchar(64);not null" json:"user_id"`
+	Text       string    `gorm:"type:text;not null"        json:"text"`
+	CreatedAt  time.Time `gorm:"not null;autoCreateTime"   json:"created_at"`
+}
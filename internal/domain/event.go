@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Типы доменных событий.
+const (
+	EventQuestionCreated  = "question.created"
+	EventQuestionDeleted  = "question.deleted"
+	EventQuestionRestored = "question.restored"
+	EventQuestionClosed   = "question.closed"
+	EventQuestionReopened = "question.reopened"
+	EventQuestionLocked   = "question.locked"
+	EventQuestionUnlocked = "question.unlocked"
+	EventQuestionMerged   = "question.merged"
+	EventAnswerCreated    = "answer.created"
+	EventAnswerDeleted    = "answer.deleted"
+	EventAnswerRestored   = "answer.restored"
+)
+
+// AggregateQuestion — агрегат «вопрос с ответами». События ответов относятся
+// к агрегату их вопроса, поэтому порядок доставки сохраняется в пределах вопроса.
+const AggregateQuestion = "question"
+
+// Event — доменное событие. Хранится в таблице outbox до публикации.
+type Event struct {
+	ID            int64           `gorm:"primaryKey;autoIncrement"      json:"id"`
+	Type          string          `gorm:"column:event_type;not null"    json:"type"`
+	AggregateType string          `gorm:"type:varchar(32);not null"     json:"aggregate_type"`
+	AggregateID   int             `gorm:"not null"                      json:"aggregate_id"`
+	Payload       json.RawMessage `gorm:"type:jsonb;not null"           json:"data"`
+	CreatedAt     time.Time       `gorm:"not null;autoCreateTime"       json:"occurred_at"`
+	PublishedAt   *time.Time      `json:"-"`
+	// DeadAt — когда relay перестал публиковать событие, исчерпав попытки.
+	DeadAt *time.Time `json:"-"`
+	// FannedOutAt — когда событие поставлено в очередь доставки вебхуков.
+	// Раздача не зависит от публикации: её ведёт отдельный воркер.
+	FannedOutAt *time.Time `json:"-"`
+	Attempts    int        `gorm:"not null;default:0"            json:"-"`
+	LastError   string     `gorm:"type:text;not null;default:''" json:"-"`
+}
+
+func (Event) TableName() string {
+	return "outbox"
+}
+
+// NewEvent создаёт событие агрегата «вопрос» с данными data в формате JSON.
+func NewEvent(eventType string, questionID int, data any) (Event, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, err
+	}
+
+	return Event{
+		Type:          eventType,
+		AggregateType: AggregateQuestion,
+		AggregateID:   questionID,
+		Payload:       payload,
+	}, nil
+}
@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+const (
+	IdempotencyInProgress = "in_progress"
+	IdempotencyCompleted  = "completed"
+)
+
+// IdempotencyRecord хранит результат запроса с заголовком Idempotency-Key,
+// чтобы повтор того же запроса получил тот же ответ. Ключ действует в пределах
+// метода и пути запроса.
+//
+// Пока запрос выполняется (in_progress), ExpiresAt — срок аренды ключа: если
+// обработчик не завершился к этому сроку (например, процесс упал), повтор
+// занимает ключ заново. После завершения ExpiresAt — срок хранения ответа.
+type IdempotencyRecord struct {
+	Key          string    `gorm:"primaryKey;type:varchar(255)"`
+	Method       string    `gorm:"primaryKey;type:varchar(16)"`
+	Path         string    `gorm:"primaryKey;type:varchar(2048)"`
+	Fingerprint  string    `gorm:"type:char(64);not null"`
+	Status       string    `gorm:"type:varchar(16);not null"`
+	ResponseCode int       `gorm:"not null;default:0"`
+	ContentType  string    `gorm:"type:varchar(255);not null;default:''"`
+	ResponseBody []byte    `gorm:"type:bytea"`
+	CreatedAt    time.Time `gorm:"not null;autoCreateTime"`
+	ExpiresAt    time.Time `gorm:"not null;index"`
+}
+
+func (IdempotencyRecord) TableName() string {
+	return "idempotency_keys"
+}
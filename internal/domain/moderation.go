@@ -0,0 +1,84 @@
+package domain
+
+import "time"
+
+// Причины жалоб.
+const (
+	FlagReasonSpam      = "spam"
+	FlagReasonAbuse     = "abuse"
+	FlagReasonOffTopic  = "off_topic"
+	FlagReasonDuplicate = "duplicate"
+	FlagReasonOther     = "other"
+)
+
+// FlagReasons — все допустимые причины жалоб.
+var FlagReasons = []string{
+	FlagReasonSpam,
+	FlagReasonAbuse,
+	FlagReasonOffTopic,
+	FlagReasonDuplicate,
+	FlagReasonOther,
+}
+
+// Действия модератора.
+const (
+	// ModerationHide скрывает вопрос или ответ от пользователей и закрывает жалобы на него.
+	ModerationHide = "hide"
+	// ModerationUnhide снова показывает скрытый вопрос или ответ.
+	ModerationUnhide = "unhide"
+	// ModerationLock запрещает новые ответы на вопрос.
+	ModerationLock = "lock"
+	// ModerationUnlock снова разрешает ответы на вопрос.
+	ModerationUnlock = "unlock"
+	// ModerationDismiss закрывает жалобы, ничего не меняя.
+	ModerationDismiss = "dismiss"
+)
+
+// Цели модерации.
+const (
+	TargetQuestion = "question"
+	TargetAnswer   = "answer"
+)
+
+// Flag — жалоба пользователя на вопрос или ответ. Заполнено ровно одно из
+// QuestionID и AnswerID.
+type Flag struct {
+	ID         int       `gorm:"primaryKey;autoIncrement"  json:"id"`
+	QuestionID *int      `gorm:"index"                     json:"question_id,omitempty"`
+	AnswerID   *int      `gorm:"index"                     json:"answer_id,omitempty"`
+	UserID     string    `gorm:"type:varchar(64);not null" json:"user_id"`
+	Reason     string    `gorm:"type:varchar(32);not null" json:"reason"`
+	CreatedAt  time.Time `gorm:"not null;autoCreateTime"   json:"created_at"`
+	// ResolvedAt — когда модератор разобрал жалобу; nil — жалоба ждёт в очереди.
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+// QueueItem — вопрос или ответ в очереди модерации.
+type QueueItem struct {
+	// Target — question или answer; ID — id вопроса или ответа.
+	Target string `json:"target"`
+	ID     int    `json:"id"`
+	// QuestionID — вопрос, к которому относится цель; у вопроса совпадает с ID.
+	QuestionID int    `json:"question_id"`
+	Text       string `json:"text"`
+	// Flags — число открытых жалоб, Reasons — они же по причинам.
+	Flags          int            `json:"flags"`
+	Reasons        map[string]int `gorm:"-" json:"reasons"`
+	FirstFlaggedAt time.Time      `json:"first_flagged_at"`
+	LastFlaggedAt  time.Time      `json:"last_flagged_at"`
+}
+
+// ModerationAction — запись журнала действий модераторов. Не ссылается на
+// вопросы и ответы внешними ключами и переживает их окончательное удаление.
+type ModerationAction struct {
+	ID         int    `gorm:"primaryKey;autoIncrement"  json:"id"`
+	Action     string `gorm:"type:varchar(16);not null" json:"action"`
+	Target     string `gorm:"type:varchar(16);not null" json:"target"`
+	TargetID   int    `gorm:"not null"                  json:"target_id"`
+	QuestionID int    `gorm:"not null;index"            json:"question_id"`
+	Moderator  string `gorm:"type:varchar(64);not null" json:"moderator"`
+	Note       string `gorm:"type:text;not null"        json:"note,omitempty"`
+	// ResolvedFlags — сколько открытых жалоб закрыло действие.
+	ResolvedFlags int       `gorm:"not null;default:0"      json:"resolved_flags"`
+	CreatedAt     time.Time `gorm:"not null;autoCreateTime" json:"created_at"`
+}
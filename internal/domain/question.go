@@ -1,10 +1,77 @@
 package domain
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Статусы вопроса.
+const (
+	QuestionOpen = "open"
+	// QuestionClosed — автор или участник закрыл вопрос, его можно открыть снова.
+	QuestionClosed = "closed"
+	// QuestionLocked — модератор заблокировал вопрос, снять блокировку может только модератор.
+	QuestionLocked = "locked"
+)
+
+// QuestionStatuses — все статусы вопроса.
+var QuestionStatuses = []string{QuestionOpen, QuestionClosed, QuestionLocked}
+
+// Причины закрытия вопроса.
+const (
+	CloseReasonResolved  = "resolved"
+	CloseReasonOffTopic  = "off_topic"
+	CloseReasonDuplicate = "duplicate"
+	CloseReasonOther     = "other"
+)
+
+// CloseReasons — все допустимые причины закрытия вопроса.
+var CloseReasons = []string{
+	CloseReasonResolved,
+	CloseReasonOffTopic,
+	CloseReasonDuplicate,
+	CloseReasonOther,
+}
 
 type Question struct {
-	ID        int       `gorm:"primaryKey;autoIncrement" json:"id"`
-	Text      string    `gorm:"type:text;not null"       json:"text"`
-	CreatedAt time.Time `gorm:"not null;autoCreateTime"  json:"created_at"`
-	Answers   []Answer  `gorm:"foreignKey:QuestionID" json:"answers,omitempty"`
+	ID int `gorm:"primaryKey;autoIncrement" json:"id"`
+	// ExternalID — идентификатор вопроса во внешней системе, по нему импорт обновляет вопросы.
+	ExternalID *string   `gorm:"type:varchar(255);uniqueIndex" json:"external_id,omitempty"`
+	Text       string    `gorm:"type:text;not null"            json:"text"`
+	CreatedAt  time.Time `gorm:"not null;autoCreateTime"       json:"created_at"`
+	Version    int       `gorm:"not null;default:1"            json:"version"`
+	Answers    []Answer  `gorm:"foreignKey:QuestionID" json:"answers,omitempty"`
+	// DeletedAt — время удаления в корзину; удалённые вопросы не видны обычным запросам.
+	DeletedAt gorm.DeletedAt `json:"-"`
+	// Hidden — вопрос скрыт модератором и не виден пользователям.
+	Hidden bool `gorm:"not null;default:false" json:"-"`
+	// Status — open, closed или locked; ответить можно только на открытый вопрос.
+	Status string `gorm:"type:varchar(16);not null;default:open;index" json:"status"`
+	// CloseReason, ClosedAt и ClosedBy — почему, когда и кем вопрос закрыт; у открытого
+	// вопроса пусты. Блокировка их не меняет, поэтому после её снятия закрытый
+	// вопрос остаётся закрытым.
+	CloseReason string     `gorm:"type:varchar(32);not null;default:''" json:"close_reason,omitempty"`
+	ClosedAt    *time.Time `json:"closed_at,omitempty"`
+	ClosedBy    string     `gorm:"type:varchar(64);not null;default:''" json:"closed_by,omitempty"`
+	// LockedAt и LockedBy — когда и каким модератором вопрос заблокирован; есть
+	// только у вопроса в статусе locked.
+	LockedAt *time.Time `json:"locked_at,omitempty"`
+	LockedBy string     `gorm:"type:varchar(64);not null;default:''" json:"locked_by,omitempty"`
+	// MergedIntoID — вопрос, в который перенесены ответы этого; такой вопрос остаётся
+	// заглушкой, GET /questions/{id} перенаправляет на него.
+	MergedIntoID *int `gorm:"index" json:"merged_into_id,omitempty"`
+	// Comments — комментарии к вопросу; заполняются только по запросу.
+	Comments []Comment `gorm:"-" json:"comments,omitempty"`
+	// PossibleDuplicates — похожие вопросы, найденные при создании этого.
+	PossibleDuplicates []DuplicateCandidate `gorm:"-" json:"possible_duplicates,omitempty"`
+}
+
+// DuplicateCandidate — существующий вопрос, похожий на новый.
+type DuplicateCandidate struct {
+	ID     int    `json:"id"`
+	Text   string `json:"text"`
+	Status string `json:"status"`
+	// Score — сходство текстов от 0 до 1.
+	Score float64 `json:"score"`
 }
@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+// Trash — содержимое корзины: удалённые вопросы и ответы, удалённые отдельно
+// от своих вопросов. Ответы, удалённые вместе с вопросом или родительским
+// ответом, не перечисляются — они восстанавливаются вместе с ним.
+type Trash struct {
+	Questions []TrashedQuestion `json:"questions"`
+	Answers   []TrashedAnswer   `json:"answers"`
+}
+
+// TrashedQuestion — вопрос в корзине.
+type TrashedQuestion struct {
+	ID         int       `json:"id"`
+	ExternalID *string   `json:"external_id,omitempty"`
+	Text       string    `json:"text"`
+	CreatedAt  time.Time `json:"created_at"`
+	DeletedAt  time.Time `json:"deleted_at"`
+}
+
+// TrashedAnswer — ответ в корзине.
+type TrashedAnswer struct {
+	ID             int       `json:"id"`
+	QuestionID     int       `json:"question_id"`
+	ParentAnswerID *int      `json:"parent_answer_id,omitempty"`
+	UserID         string    `json:"user_id"`
+	Text           string    `json:"text"`
+	CreatedAt      time.Time `json:"created_at"`
+	DeletedAt      time.Time `json:"deleted_at"`
+}
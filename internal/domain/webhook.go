@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+)
+
+// Статусы доставки вебхука.
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	// DeliveryDead — попытки исчерпаны, доставка больше не повторяется.
+	DeliveryDead = "dead"
+)
+
+// EventTypes — все типы событий, на которые можно подписаться.
+var EventTypes = []string{
+	EventQuestionCreated,
+	EventQuestionDeleted,
+	EventQuestionRestored,
+	EventQuestionClosed,
+	EventQuestionReopened,
+	EventQuestionLocked,
+	EventQuestionUnlocked,
+	EventQuestionMerged,
+	EventAnswerCreated,
+	EventAnswerDeleted,
+	EventAnswerRestored,
+}
+
+// WebhookSubscription — подписка внешней системы на доменные события.
+type WebhookSubscription struct {
+	ID                  int64      `gorm:"primaryKey;autoIncrement"   json:"id"`
+	URL                 string     `gorm:"type:text;not null"         json:"url"`
+	EventTypes          StringList `gorm:"type:jsonb;not null"        json:"event_types"`
+	Secret              string     `gorm:"type:text;not null"         json:"-"`
+	Active              bool       `gorm:"not null;default:true"      json:"active"`
+	ConsecutiveFailures int        `gorm:"not null;default:0"         json:"consecutive_failures"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt           time.Time  `gorm:"not null;autoCreateTime"    json:"created_at"`
+	UpdatedAt           time.Time  `gorm:"not null;autoUpdateTime"    json:"updated_at"`
+}
+
+// Matches сообщает, подписана ли подписка на событие данного типа.
+func (s *WebhookSubscription) Matches(eventType string) bool {
+	return slices.Contains(s.EventTypes, eventType)
+}
+
+// WebhookDelivery — доставка одного события одной подписке.
+type WebhookDelivery struct {
+	ID             int64           `gorm:"primaryKey;autoIncrement"          json:"id"`
+	SubscriptionID int64           `gorm:"not null"                          json:"subscription_id"`
+	EventID        int64           `gorm:"not null"                          json:"event_id"`
+	EventType      string          `gorm:"type:varchar(64);not null"         json:"event_type"`
+	Payload        json.RawMessage `gorm:"type:jsonb;not null"               json:"-"`
+	Status         string          `gorm:"type:varchar(16);not null"         json:"status"`
+	Attempts       int             `gorm:"not null;default:0"                json:"attempts"`
+	NextAttemptAt  time.Time       `gorm:"not null"                          json:"next_attempt_at"`
+	LastStatusCode int             `gorm:"not null;default:0"                json:"last_status_code,omitempty"`
+	LastError      string          `gorm:"type:text;not null;default:''"     json:"last_error,omitempty"`
+	CreatedAt      time.Time       `gorm:"not null;autoCreateTime"           json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+}
+
+// StringList — список строк, хранящийся в колонке JSONB.
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal([]string(l))
+	return string(b), err
+}
+
+func (l *StringList) Scan(src any) error {
+	var data []byte
+	switch v := src.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	case nil:
+		*l = nil
+		return nil
+	default:
+		return errors.New("unsupported type for StringList")
+	}
+	return json.Unmarshal(data, (*[]string)(l))
+}
@@ -0,0 +1,73 @@
+package gqlapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+
+	"github.com/graph-gophers/graphql-go"
+	"go.uber.org/zap"
+
+	"question-service/internal/logger"
+	"question-service/internal/service"
+	"question-service/internal/transport"
+)
+
+//go:embed schema.graphql
+var schemaSDL string
+
+const (
+	maxRequestSize = 1 << 20
+	maxQueryDepth  = 10
+)
+
+// Handler обслуживает POST /graphql.
+type Handler struct {
+	schema  *graphql.Schema
+	answers *service.AnswerService
+	log     *logger.Logger
+}
+
+func NewHandler(questions *service.QuestionService, answers *service.AnswerService, log *logger.Logger) (*Handler, error) {
+	schema, err := graphql.ParseSchema(schemaSDL,
+		&resolver{questions: questions, answers: answers, log: log},
+		graphql.MaxDepth(maxQueryDepth),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Handler{schema: schema, answers: answers, log: log}, nil
+}
+
+type request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		transport.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	defer r.Body.Close()
+
+	var req request
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&req); err != nil {
+		h.log.Warn("invalid json in graphql request", zap.Error(err))
+		transport.WriteError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if req.Query == "" {
+		transport.WriteError(w, http.StatusBadRequest, "query is required")
+		return
+	}
+
+	// загрузчик живёт один запрос: ответы не кешируются между запросами
+	ctx := withLoader(r.Context(), newAnswerLoader(h.answers))
+	resp := h.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+
+	transport.WriteJSON(w, http.StatusOK, resp)
+}
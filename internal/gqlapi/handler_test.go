@@ -0,0 +1,168 @@
+package gqlapi_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"question-service/internal/domain"
+	"question-service/internal/gqlapi"
+	"question-service/internal/logger"
+	"question-service/internal/repository"
+	"question-service/internal/service"
+)
+
+// countingAnswers считает запросы ответов к репозиторию.
+type countingAnswers struct {
+	repository.AnswerRepository
+	single atomic.Int32
+	batch  atomic.Int32
+}
+
+func (c *countingAnswers) ListByQuestionID(ctx context.Context, questionID int) ([]domain.Answer, error) {
+	c.single.Add(1)
+	return c.AnswerRepository.ListByQuestionID(ctx, questionID)
+}
+
+func (c *countingAnswers) ListByQuestionIDs(ctx context.Context, questionIDs []int, perQuestion int) ([]domain.Answer, error) {
+	c.batch.Add(1)
+	return c.AnswerRepository.ListByQuestionIDs(ctx, questionIDs, perQuestion)
+}
+
+type env struct {
+	handler   http.Handler
+	questions *service.QuestionService
+	answers   *service.AnswerService
+	repo      *countingAnswers
+}
+
+func newEnv(t *testing.T) *env {
+	t.Helper()
+
+	store := repository.NewMemoryStore()
+	qRepo := repository.NewMemoryQuestionRepository(store)
+	aRepo := &countingAnswers{AnswerRepository: repository.NewMemoryAnswerRepository(store)}
+	events := repository.NewMemoryOutboxRepository(store)
+
+	qSvc := service.NewQuestionService(qRepo, events, store)
+	aSvc := service.NewAnswerService(aRepo, qRepo, events, store, nil)
+
+	h, err := gqlapi.NewHandler(qSvc, aSvc, &logger.Logger{Logger: zap.NewNop()})
+	require.NoError(t, err)
+
+	return &env{handler: h, questions: qSvc, answers: aSvc, repo: aRepo}
+}
+
+type gqlResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func (e *env) do(t *testing.T, query string, vars map[string]any) gqlResponse {
+	t.Helper()
+
+	body, err := json.Marshal(map[string]any{"query": query, "variables": vars})
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	e.handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body)))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp gqlResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp
+}
+
+func TestQuestions_BatchesAnswerLoading(t *testing.T) {
+	e := newEnv(t)
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		q, err := e.questions.CreateQuestion(ctx, "question")
+		require.NoError(t, err)
+		for j := 0; j < 4; j++ {
+			_, err := e.answers.CreateAnswer(ctx, q.ID, "user", "answer")
+			require.NoError(t, err)
+		}
+	}
+
+	resp := e.do(t, `{
+		questions(first: 3) {
+			edges { node { id text answers(first: 3) { id userId } } }
+			pageInfo { hasNextPage endCursor }
+		}
+	}`, nil)
+	require.Empty(t, resp.Errors)
+
+	var data struct {
+		Questions struct {
+			Edges []struct {
+				Node struct {
+					ID      string
+					Answers []struct{ ID string }
+				}
+			}
+			PageInfo struct {
+				HasNextPage bool
+				EndCursor   string
+			}
+		}
+	}
+	require.NoError(t, json.Unmarshal(resp.Data, &data))
+	require.Len(t, data.Questions.Edges, 3)
+	for _, edge := range data.Questions.Edges {
+		require.Len(t, edge.Node.Answers, 3)
+	}
+	require.True(t, data.Questions.PageInfo.HasNextPage)
+
+	require.EqualValues(t, 1, e.repo.batch.Load())
+	require.Zero(t, e.repo.single.Load())
+
+	resp = e.do(t, `query($after: String) { questions(first: 10, after: $after) { edges { node { id } } pageInfo { hasNextPage } } }`,
+		map[string]any{"after": data.Questions.PageInfo.EndCursor})
+	require.Empty(t, resp.Errors)
+	require.JSONEq(t, `{"questions":{"edges":[{"node":{"id":"4"}},{"node":{"id":"5"}}],"pageInfo":{"hasNextPage":false}}}`, string(resp.Data))
+}
+
+func TestMutations(t *testing.T) {
+	e := newEnv(t)
+
+	resp := e.do(t, `mutation { createQuestion(text: "What is GraphQL?") { id version } }`, nil)
+	require.Empty(t, resp.Errors)
+	require.JSONEq(t, `{"createQuestion":{"id":"1","version":1}}`, string(resp.Data))
+
+	resp = e.do(t, `mutation { createAnswer(questionId: "1", userId: "u1", text: "A query language") { id questionId } }`, nil)
+	require.Empty(t, resp.Errors)
+	require.JSONEq(t, `{"createAnswer":{"id":"1","questionId":"1"}}`, string(resp.Data))
+
+	resp = e.do(t, `{ question(id: "1") { version answers { text } } }`, nil)
+	require.Empty(t, resp.Errors)
+	require.JSONEq(t, `{"question":{"version":2,"answers":[{"text":"A query language"}]}}`, string(resp.Data))
+
+	resp = e.do(t, `mutation { deleteQuestion(id: "1", version: 1) }`, nil)
+	require.Len(t, resp.Errors, 1)
+	require.Equal(t, "version mismatch", resp.Errors[0].Message)
+
+	resp = e.do(t, `mutation { deleteAnswer(id: "1") }`, nil)
+	require.Empty(t, resp.Errors)
+
+	resp = e.do(t, `mutation { deleteQuestion(id: "1") }`, nil)
+	require.Empty(t, resp.Errors)
+
+	resp = e.do(t, `{ question(id: "1") { id } }`, nil)
+	require.Empty(t, resp.Errors)
+	require.JSONEq(t, `{"question":null}`, string(resp.Data))
+
+	resp = e.do(t, `mutation { createAnswer(questionId: "1", userId: "u1", text: "late") { id } }`, nil)
+	require.Len(t, resp.Errors, 1)
+	require.Equal(t, "question not found", resp.Errors[0].Message)
+}
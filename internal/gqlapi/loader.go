@@ -0,0 +1,89 @@
+package gqlapi
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"question-service/internal/domain"
+	"question-service/internal/service"
+)
+
+type loaderKey struct{}
+
+// answerLoader загружает ответы вопросов пачками в пределах одного GraphQL-запроса.
+// Вопросы, полученные списком, регистрируются через prime; первое обращение к
+// answers любого из них загружает ответы всех зарегистрированных вопросов одним
+// запросом к AnswerRepository вместо запроса на каждый вопрос.
+type answerLoader struct {
+	answers *service.AnswerService
+
+	mu     sync.Mutex
+	primed []int
+	// batches — пачки по значению аргумента first: у разных алиасов поля оно может отличаться.
+	batches map[int][]*answerBatch
+}
+
+type answerBatch struct {
+	ids    []int
+	once   sync.Once
+	result map[int][]domain.Answer
+	err    error
+}
+
+func newAnswerLoader(answers *service.AnswerService) *answerLoader {
+	return &answerLoader{answers: answers, batches: make(map[int][]*answerBatch)}
+}
+
+func withLoader(ctx context.Context, l *answerLoader) context.Context {
+	return context.WithValue(ctx, loaderKey{}, l)
+}
+
+func loaderFrom(ctx context.Context) *answerLoader {
+	l, _ := ctx.Value(loaderKey{}).(*answerLoader)
+	return l
+}
+
+// prime регистрирует вопросы, ответы которых, скорее всего, понадобятся.
+func (l *answerLoader) prime(ids ...int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.primed = append(l.primed, ids...)
+}
+
+// load возвращает не больше perQuestion ответов вопроса (0 — все).
+func (l *answerLoader) load(ctx context.Context, perQuestion, questionID int) ([]domain.Answer, error) {
+	b := l.batchFor(perQuestion, questionID)
+
+	b.once.Do(func() {
+		b.result, b.err = l.answers.ListAnswersByQuestionIDs(ctx, b.ids, perQuestion)
+	})
+	return b.result[questionID], b.err
+}
+
+// batchFor находит пачку с вопросом или создаёт новую из всех ещё не загруженных
+// зарегистрированных вопросов.
+func (l *answerLoader) batchFor(perQuestion, questionID int) *answerBatch {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	loaded := make(map[int]bool)
+	for _, b := range l.batches[perQuestion] {
+		if slices.Contains(b.ids, questionID) {
+			return b
+		}
+		for _, id := range b.ids {
+			loaded[id] = true
+		}
+	}
+
+	b := &answerBatch{ids: []int{questionID}}
+	for _, id := range l.primed {
+		if id != questionID && !loaded[id] && !slices.Contains(b.ids, id) {
+			b.ids = append(b.ids, id)
+		}
+	}
+	l.batches[perQuestion] = append(l.batches[perQuestion], b)
+	return b
+}
@@ -0,0 +1,300 @@
+package gqlapi
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go"
+	"go.uber.org/zap"
+
+	"question-service/internal/domain"
+	"question-service/internal/logger"
+	"question-service/internal/service"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+	cursorPrefix    = "question:"
+)
+
+var (
+	errInvalidID     = errors.New("invalid id")
+	errInvalidCursor = errors.New("invalid cursor")
+	errInternal      = errors.New("internal error")
+)
+
+// resolver — корневой резолвер схемы.
+type resolver struct {
+	questions *service.QuestionService
+	answers   *service.AnswerService
+	log       *logger.Logger
+}
+
+type questionsArgs struct {
+	First *int32
+	After *string
+}
+
+func (r *resolver) Questions(ctx context.Context, args questionsArgs) (*questionConnection, error) {
+	first := defaultPageSize
+	if args.First != nil {
+		first = int(*args.First)
+	}
+	if first < 1 || first > maxPageSize {
+		return nil, errors.New("first must be between 1 and 100")
+	}
+
+	afterID := 0
+	if args.After != nil {
+		id, err := decodeCursor(*args.After)
+		if err != nil {
+			return nil, err
+		}
+		afterID = id
+	}
+
+	questions, hasNext, err := r.questions.ListQuestionsPage(ctx, afterID, first, "")
+	if err != nil {
+		return nil, r.internal("failed to list questions", err)
+	}
+
+	conn := &questionConnection{hasNext: hasNext}
+	ids := make([]int, 0, len(questions))
+	for i := range questions {
+		ids = append(ids, questions[i].ID)
+		conn.edges = append(conn.edges, &questionEdge{node: &questionResolver{q: &questions[i], r: r}})
+	}
+	if l := loaderFrom(ctx); l != nil {
+		l.prime(ids...)
+	}
+	return conn, nil
+}
+
+func (r *resolver) Question(ctx context.Context, args struct{ ID graphql.ID }) (*questionResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	q, err := r.questions.GetQuestionWithAnswers(ctx, id)
+	if err != nil {
+		if errors.Is(err, service.ErrQuestionNotFound) {
+			return nil, nil
+		}
+		return nil, r.internal("failed to get question", err)
+	}
+	return &questionResolver{q: q, r: r, answersLoaded: true}, nil
+}
+
+func (r *resolver) CreateQuestion(ctx context.Context, args struct{ Text string }) (*questionResolver, error) {
+	if strings.TrimSpace(args.Text) == "" {
+		return nil, errors.New("text is required")
+	}
+
+	q, err := r.questions.CreateQuestion(ctx, args.Text)
+	if err != nil {
+		return nil, r.internal("failed to create question", err)
+	}
+	return &questionResolver{q: q, r: r, answersLoaded: true}, nil
+}
+
+type deleteArgs struct {
+	ID      graphql.ID
+	Version *int32
+}
+
+func (r *resolver) DeleteQuestion(ctx context.Context, args deleteArgs) (bool, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return false, err
+	}
+
+	if err := r.questions.DeleteQuestion(ctx, id, version(args.Version)); err != nil {
+		return false, r.serviceError("failed to delete question", err)
+	}
+	return true, nil
+}
+
+type createAnswerArgs struct {
+	QuestionID graphql.ID
+	UserID     string
+	Text       string
+}
+
+func (r *resolver) CreateAnswer(ctx context.Context, args createAnswerArgs) (*answerResolver, error) {
+	questionID, err := parseID(args.QuestionID)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(args.UserID) == "" || strings.TrimSpace(args.Text) == "" {
+		return nil, errors.New("userId and text are required")
+	}
+
+	a, err := r.answers.CreateAnswer(ctx, questionID, args.UserID, args.Text)
+	if err != nil {
+		return nil, r.serviceError("failed to create answer", err)
+	}
+	return &answerResolver{a: a}, nil
+}
+
+func (r *resolver) DeleteAnswer(ctx context.Context, args deleteArgs) (bool, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return false, err
+	}
+
+	if err := r.answers.DeleteAnswer(ctx, id, version(args.Version)); err != nil {
+		return false, r.serviceError("failed to delete answer", err)
+	}
+	return true, nil
+}
+
+// serviceError пропускает клиенту ошибки сервисного слоя, а остальные скрывает.
+func (r *resolver) serviceError(msg string, err error) error {
+	switch {
+	case errors.Is(err, service.ErrQuestionNotFound),
+		errors.Is(err, service.ErrAnswerNotFound),
+		errors.Is(err, service.ErrVersionMismatch),
+		errors.Is(err, service.ErrQuestionLocked),
+		errors.Is(err, service.ErrQuestionClosed):
+		return err
+	default:
+		return r.internal(msg, err)
+	}
+}
+
+func (r *resolver) internal(msg string, err error) error {
+	r.log.Error(msg, zap.Error(err))
+	return errInternal
+}
+
+type questionResolver struct {
+	q *domain.Question
+	r *resolver
+	// answersLoaded — q.Answers уже содержит все ответы вопроса.
+	answersLoaded bool
+}
+
+func (q *questionResolver) ID() graphql.ID          { return formatID(q.q.ID) }
+func (q *questionResolver) Text() string            { return q.q.Text }
+func (q *questionResolver) CreatedAt() graphql.Time { return graphql.Time{Time: q.q.CreatedAt} }
+func (q *questionResolver) Version() int32          { return int32(q.q.Version) }
+
+func (q *questionResolver) Answers(ctx context.Context, args struct{ First *int32 }) ([]*answerResolver, error) {
+	limit := 0
+	if args.First != nil {
+		if *args.First < 0 {
+			return nil, errors.New("first must not be negative")
+		}
+		limit = int(*args.First)
+	}
+
+	answers := q.q.Answers
+	if !q.answersLoaded {
+		l := loaderFrom(ctx)
+		if l == nil {
+			l = newAnswerLoader(q.r.answers)
+		}
+
+		var err error
+		answers, err = l.load(ctx, limit, q.q.ID)
+		if err != nil {
+			return nil, q.r.internal("failed to load answers", err)
+		}
+	}
+	if limit > 0 && len(answers) > limit {
+		answers = answers[:limit]
+	}
+
+	out := make([]*answerResolver, 0, len(answers))
+	for i := range answers {
+		out = append(out, &answerResolver{a: &answers[i]})
+	}
+	return out, nil
+}
+
+type answerResolver struct {
+	a *domain.Answer
+}
+
+func (a *answerResolver) ID() graphql.ID          { return formatID(a.a.ID) }
+func (a *answerResolver) QuestionID() graphql.ID  { return formatID(a.a.QuestionID) }
+func (a *answerResolver) UserID() string          { return a.a.UserID }
+func (a *answerResolver) Text() string            { return a.a.Text }
+func (a *answerResolver) CreatedAt() graphql.Time { return graphql.Time{Time: a.a.CreatedAt} }
+func (a *answerResolver) Version() int32          { return int32(a.a.Version) }
+
+type questionConnection struct {
+	edges   []*questionEdge
+	hasNext bool
+}
+
+func (c *questionConnection) Edges() []*questionEdge { return c.edges }
+
+func (c *questionConnection) PageInfo() *pageInfo {
+	p := &pageInfo{hasNext: c.hasNext}
+	if len(c.edges) > 0 {
+		cursor := c.edges[len(c.edges)-1].Cursor()
+		p.endCursor = &cursor
+	}
+	return p
+}
+
+type questionEdge struct {
+	node *questionResolver
+}
+
+func (e *questionEdge) Cursor() string          { return encodeCursor(e.node.q.ID) }
+func (e *questionEdge) Node() *questionResolver { return e.node }
+
+type pageInfo struct {
+	hasNext   bool
+	endCursor *string
+}
+
+func (p *pageInfo) HasNextPage() bool  { return p.hasNext }
+func (p *pageInfo) EndCursor() *string { return p.endCursor }
+
+func formatID(id int) graphql.ID {
+	return graphql.ID(strconv.Itoa(id))
+}
+
+func parseID(id graphql.ID) (int, error) {
+	n, err := strconv.Atoi(string(id))
+	if err != nil || n <= 0 {
+		return 0, errInvalidID
+	}
+	return n, nil
+}
+
+// encodeCursor возвращает непрозрачный курсор страницы вопросов.
+func encodeCursor(id int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(id)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, errInvalidCursor
+	}
+	idStr, ok := strings.CutPrefix(string(raw), cursorPrefix)
+	if !ok {
+		return 0, errInvalidCursor
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id < 0 {
+		return 0, errInvalidCursor
+	}
+	return id, nil
+}
+
+func version(v *int32) int {
+	if v == nil {
+		return 0
+	}
+	return int(*v)
+}
@@ -0,0 +1,217 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	questionv1 "question-service/api/question/v1"
+	"question-service/internal/domain"
+	"question-service/internal/logger"
+	"question-service/internal/service"
+)
+
+// Server реализует questionv1.QuestionServiceServer поверх сервисного слоя.
+type Server struct {
+	questionv1.UnimplementedQuestionServiceServer
+
+	questions *service.QuestionService
+	answers   *service.AnswerService
+}
+
+func NewServer(questions *service.QuestionService, answers *service.AnswerService) *Server {
+	return &Server{questions: questions, answers: answers}
+}
+
+// New создаёт grpc.Server с QuestionService, протоколом health checking и reflection.
+// Возвращённый health.Server нужно перевести в NOT_SERVING при остановке.
+func New(questions *service.QuestionService, answers *service.AnswerService, log *logger.Logger) (*grpc.Server, *health.Server) {
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(loggingInterceptor(log)))
+
+	questionv1.RegisterQuestionServiceServer(srv, NewServer(questions, answers))
+
+	healthSrv := health.NewServer()
+	healthSrv.SetServingStatus(questionv1.QuestionService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(srv, healthSrv)
+
+	reflection.Register(srv)
+
+	return srv, healthSrv
+}
+
+func (s *Server) CreateQuestion(ctx context.Context, req *questionv1.CreateQuestionRequest) (*questionv1.CreateQuestionResponse, error) {
+	if strings.TrimSpace(req.GetText()) == "" {
+		return nil, status.Error(codes.InvalidArgument, "text is required")
+	}
+
+	q, err := s.questions.CreateQuestion(ctx, req.GetText())
+	if err != nil {
+		return nil, toStatus(err, "failed to create question")
+	}
+	return &questionv1.CreateQuestionResponse{Question: toProtoQuestion(q)}, nil
+}
+
+func (s *Server) ListQuestions(ctx context.Context, _ *questionv1.ListQuestionsRequest) (*questionv1.ListQuestionsResponse, error) {
+	questions, err := s.questions.ListQuestions(ctx, "")
+	if err != nil {
+		return nil, toStatus(err, "failed to list questions")
+	}
+
+	resp := &questionv1.ListQuestionsResponse{Questions: make([]*questionv1.Question, 0, len(questions))}
+	for i := range questions {
+		resp.Questions = append(resp.Questions, toProtoQuestion(&questions[i]))
+	}
+	return resp, nil
+}
+
+func (s *Server) GetQuestion(ctx context.Context, req *questionv1.GetQuestionRequest) (*questionv1.GetQuestionResponse, error) {
+	id, err := toID(req.GetId(), "invalid question id")
+	if err != nil {
+		return nil, err
+	}
+
+	q, err := s.questions.GetQuestionWithAnswers(ctx, id)
+	if err != nil {
+		return nil, toStatus(err, "failed to get question")
+	}
+	return &questionv1.GetQuestionResponse{Question: toProtoQuestion(q)}, nil
+}
+
+func (s *Server) DeleteQuestion(ctx context.Context, req *questionv1.DeleteQuestionRequest) (*questionv1.DeleteQuestionResponse, error) {
+	id, err := toID(req.GetId(), "invalid question id")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.questions.DeleteQuestion(ctx, id, int(req.GetVersion())); err != nil {
+		return nil, toStatus(err, "failed to delete question")
+	}
+	return &questionv1.DeleteQuestionResponse{}, nil
+}
+
+func (s *Server) CreateAnswer(ctx context.Context, req *questionv1.CreateAnswerRequest) (*questionv1.CreateAnswerResponse, error) {
+	questionID, err := toID(req.GetQuestionId(), "invalid question id")
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.GetUserId()) == "" || strings.TrimSpace(req.GetText()) == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id and text are required")
+	}
+
+	a, err := s.answers.CreateAnswer(ctx, questionID, req.GetUserId(), req.GetText())
+	if err != nil {
+		return nil, toStatus(err, "failed to create answer")
+	}
+	return &questionv1.CreateAnswerResponse{Answer: toProtoAnswer(a)}, nil
+}
+
+func (s *Server) GetAnswer(ctx context.Context, req *questionv1.GetAnswerRequest) (*questionv1.GetAnswerResponse, error) {
+	id, err := toID(req.GetId(), "invalid answer id")
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := s.answers.GetAnswer(ctx, id)
+	if err != nil {
+		return nil, toStatus(err, "failed to get answer")
+	}
+	return &questionv1.GetAnswerResponse{Answer: toProtoAnswer(a)}, nil
+}
+
+func (s *Server) DeleteAnswer(ctx context.Context, req *questionv1.DeleteAnswerRequest) (*questionv1.DeleteAnswerResponse, error) {
+	id, err := toID(req.GetId(), "invalid answer id")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.answers.DeleteAnswer(ctx, id, int(req.GetVersion())); err != nil {
+		return nil, toStatus(err, "failed to delete answer")
+	}
+	return &questionv1.DeleteAnswerResponse{}, nil
+}
+
+// toStatus переводит ошибку сервисного слоя в gRPC-статус. Неизвестные ошибки
+// скрываются за message, как и в REST API.
+func toStatus(err error, message string) error {
+	switch {
+	case errors.Is(err, service.ErrQuestionNotFound):
+		return status.Error(codes.NotFound, "question not found")
+	case errors.Is(err, service.ErrAnswerNotFound):
+		return status.Error(codes.NotFound, "answer not found")
+	case errors.Is(err, service.ErrVersionMismatch):
+		return status.Error(codes.FailedPrecondition, "version mismatch")
+	case errors.Is(err, service.ErrQuestionLocked):
+		return status.Error(codes.FailedPrecondition, "question is locked")
+	case errors.Is(err, service.ErrQuestionClosed):
+		return status.Error(codes.FailedPrecondition, "question is closed")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		return status.Error(codes.Internal, message)
+	}
+}
+
+func toID(id int64, message string) (int, error) {
+	if id <= 0 || id > int64(^uint32(0)>>1) {
+		return 0, status.Error(codes.InvalidArgument, message)
+	}
+	return int(id), nil
+}
+
+func toProtoQuestion(q *domain.Question) *questionv1.Question {
+	out := &questionv1.Question{
+		Id:        int64(q.ID),
+		Text:      q.Text,
+		CreatedAt: timestamppb.New(q.CreatedAt),
+		Version:   int64(q.Version),
+	}
+	for i := range q.Answers {
+		out.Answers = append(out.Answers, toProtoAnswer(&q.Answers[i]))
+	}
+	return out
+}
+
+func toProtoAnswer(a *domain.Answer) *questionv1.Answer {
+	return &questionv1.Answer{
+		Id:         int64(a.ID),
+		QuestionId: int64(a.QuestionID),
+		UserId:     a.UserID,
+		Text:       a.Text,
+		CreatedAt:  timestamppb.New(a.CreatedAt),
+		Version:    int64(a.Version),
+	}
+}
+
+// loggingInterceptor пишет в лог каждый вызов; внутренние ошибки — с уровнем error.
+func loggingInterceptor(log *logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		code := status.Code(err)
+		fields := []zap.Field{
+			zap.String("method", info.FullMethod),
+			zap.String("code", code.String()),
+			zap.Duration("duration", time.Since(start)),
+		}
+		switch code {
+		case codes.Internal, codes.Unknown:
+			log.Error("grpc call failed", append(fields, zap.Error(err))...)
+		default:
+			log.Info("grpc call", fields...)
+		}
+		return resp, err
+	}
+}
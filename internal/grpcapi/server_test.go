@@ -0,0 +1,111 @@
+package grpcapi_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	questionv1 "question-service/api/question/v1"
+	"question-service/internal/grpcapi"
+	"question-service/internal/logger"
+	"question-service/internal/repository"
+	"question-service/internal/service"
+)
+
+func newClient(t *testing.T) *grpc.ClientConn {
+	t.Helper()
+
+	store := repository.NewMemoryStore()
+	qRepo := repository.NewMemoryQuestionRepository(store)
+	aRepo := repository.NewMemoryAnswerRepository(store)
+	events := repository.NewMemoryOutboxRepository(store)
+
+	srv, _ := grpcapi.New(
+		service.NewQuestionService(qRepo, events, store),
+		service.NewAnswerService(aRepo, qRepo, events, store, nil),
+		&logger.Logger{Logger: zap.NewNop()},
+	)
+
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func TestServer_QuestionsAndAnswers(t *testing.T) {
+	client := questionv1.NewQuestionServiceClient(newClient(t))
+	ctx := context.Background()
+
+	created, err := client.CreateQuestion(ctx, &questionv1.CreateQuestionRequest{Text: "What is gRPC?"})
+	require.NoError(t, err)
+	q := created.GetQuestion()
+	require.NotZero(t, q.GetId())
+	require.EqualValues(t, 1, q.GetVersion())
+
+	ans, err := client.CreateAnswer(ctx, &questionv1.CreateAnswerRequest{QuestionId: q.GetId(), UserId: "user-1", Text: "RPC framework"})
+	require.NoError(t, err)
+
+	got, err := client.GetQuestion(ctx, &questionv1.GetQuestionRequest{Id: q.GetId()})
+	require.NoError(t, err)
+	require.Len(t, got.GetQuestion().GetAnswers(), 1)
+	require.Equal(t, "RPC framework", got.GetQuestion().GetAnswers()[0].GetText())
+
+	list, err := client.ListQuestions(ctx, &questionv1.ListQuestionsRequest{})
+	require.NoError(t, err)
+	require.Len(t, list.GetQuestions(), 1)
+
+	_, err = client.DeleteAnswer(ctx, &questionv1.DeleteAnswerRequest{Id: ans.GetAnswer().GetId(), Version: 5})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = client.DeleteAnswer(ctx, &questionv1.DeleteAnswerRequest{Id: ans.GetAnswer().GetId()})
+	require.NoError(t, err)
+
+	_, err = client.GetAnswer(ctx, &questionv1.GetAnswerRequest{Id: ans.GetAnswer().GetId()})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.DeleteQuestion(ctx, &questionv1.DeleteQuestionRequest{Id: q.GetId()})
+	require.NoError(t, err)
+}
+
+func TestServer_ErrorCodes(t *testing.T) {
+	client := questionv1.NewQuestionServiceClient(newClient(t))
+	ctx := context.Background()
+
+	_, err := client.GetQuestion(ctx, &questionv1.GetQuestionRequest{Id: 42})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.CreateAnswer(ctx, &questionv1.CreateAnswerRequest{QuestionId: 42, UserId: "u", Text: "t"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.CreateQuestion(ctx, &questionv1.CreateQuestionRequest{Text: "  "})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.GetAnswer(ctx, &questionv1.GetAnswerRequest{Id: -1})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestServer_Health(t *testing.T) {
+	client := healthpb.NewHealthClient(newClient(t))
+
+	resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{
+		Service: questionv1.QuestionService_ServiceDesc.ServiceName,
+	})
+	require.NoError(t, err)
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+}
@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 
+	"question-service/internal/domain"
 	"question-service/internal/service"
 	"question-service/internal/transport"
 )
@@ -16,10 +17,15 @@ import (
 type AnswerHandler struct {
 	svc *service.AnswerService
 	log *logger.Logger
+
+	// requireIfMatch — строгий режим: изменения без If-Match отклоняются с 428.
+	requireIfMatch bool
+	// batchMaxItems — сколько элементов принимает пакетный запрос.
+	batchMaxItems int
 }
 
 func NewAnswerHandler(svc *service.AnswerService, log *logger.Logger) *AnswerHandler {
-	return &AnswerHandler{svc: svc, log: log}
+	return &AnswerHandler{svc: svc, log: log, batchMaxItems: DefaultBatchMaxItems}
 }
 
 // HandleCreateForQuestion обрабатывает POST /questions/{id}/answers
@@ -49,10 +55,7 @@ func (h *AnswerHandler) HandleCreateForQuestion(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	var req struct {
-		UserID string `json:"user_id"`
-		Text   string `json:"text"`
-	}
+	var req createAnswerRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.log.Warn("invalid json in answer creation",
 			zap.Error(err),
@@ -70,7 +73,12 @@ func (h *AnswerHandler) HandleCreateForQuestion(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	ans, err := h.svc.CreateAnswer(r.Context(), id, req.UserID, req.Text)
+	var ans *domain.Answer
+	if req.ParentAnswerID != nil {
+		ans, err = h.svc.CreateReply(r.Context(), id, *req.ParentAnswerID, req.UserID, req.Text)
+	} else {
+		ans, err = h.svc.CreateAnswer(r.Context(), id, req.UserID, req.Text)
+	}
 	if err != nil {
 		if errors.Is(err, service.ErrQuestionNotFound) {
 			h.log.Info("attempt to create answer for non-existing question",
@@ -80,6 +88,22 @@ func (h *AnswerHandler) HandleCreateForQuestion(w http.ResponseWriter, r *http.R
 			transport.WriteError(w, http.StatusNotFound, "question not found")
 			return
 		}
+		if errors.Is(err, service.ErrQuestionLocked) || errors.Is(err, service.ErrQuestionClosed) {
+			h.log.Info("attempt to answer question that is not open", zap.Int("question_id", id), zap.Error(err))
+			transport.WriteError(w, http.StatusConflict, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrParentAnswerNotFound) ||
+			errors.Is(err, service.ErrParentAnswerMismatch) ||
+			errors.Is(err, service.ErrReplyTooDeep) {
+			h.log.Info("invalid reply",
+				zap.Error(err),
+				zap.Int("question_id", id),
+				zap.Int("parent_answer_id", *req.ParentAnswerID),
+			)
+			transport.WriteError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
 		h.log.Error("failed to create answer",
 			zap.Error(err),
 			zap.Int("question_id", id),
@@ -134,18 +158,37 @@ func (h *AnswerHandler) getAnswer(w http.ResponseWriter, r *http.Request, id int
 		return
 	}
 
+	tag := etag(ans.Version)
+	w.Header().Set("ETag", tag)
+	if notModified(r, tag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
 	h.log.Info("answer fetched", zap.Int("answer_id", ans.ID))
 	transport.WriteJSON(w, http.StatusOK, ans)
 }
 
 func (h *AnswerHandler) deleteAnswer(w http.ResponseWriter, r *http.Request, id int) {
-	err := h.svc.DeleteAnswer(r.Context(), id)
+	version, err := ifMatchVersion(r, h.requireIfMatch)
+	if err != nil {
+		h.log.Info("precondition failed for answer deletion", zap.Error(err), zap.Int("answer_id", id))
+		writePreconditionError(w, err)
+		return
+	}
+
+	err = h.svc.DeleteAnswer(r.Context(), id, version)
 	if err != nil {
 		if errors.Is(err, service.ErrAnswerNotFound) {
 			h.log.Info("attempt to delete non-existing answer", zap.Int("answer_id", id))
 			transport.WriteError(w, http.StatusNotFound, "answer not found")
 			return
 		}
+		if errors.Is(err, service.ErrVersionMismatch) {
+			h.log.Info("answer version mismatch on delete", zap.Int("answer_id", id), zap.Int("version", version))
+			transport.WriteError(w, http.StatusPreconditionFailed, "version mismatch")
+			return
+		}
 
 		h.log.Error("failed to delete answer", zap.Error(err), zap.Int("answer_id", id))
 		transport.WriteError(w, http.StatusInternalServerError, "failed to delete answer")
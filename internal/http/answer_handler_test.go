@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"question-service/internal/domain"
 	httptransport "question-service/internal/http"
@@ -31,6 +32,19 @@ func (f *mockAnswerRepo) Create(_ context.Context, q *domain.Answer) error {
 	return nil
 }
 
+func (f *mockAnswerRepo) CreateBatch(ctx context.Context, answers []domain.Answer, _ int) error {
+	for i := range answers {
+		if err := f.Create(ctx, &answers[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *mockAnswerRepo) CreateMany(ctx context.Context, answers []domain.Answer) error {
+	return f.CreateBatch(ctx, answers, len(answers))
+}
+
 func (f *mockAnswerRepo) GetByID(_ context.Context, id int) (*domain.Answer, error) {
 	for _, q := range f.answer {
 		if q.ID == id {
@@ -41,13 +55,46 @@ func (f *mockAnswerRepo) GetByID(_ context.Context, id int) (*domain.Answer, err
 	return nil, gorm.ErrRecordNotFound
 }
 
-func (f *mockAnswerRepo) Delete(_ context.Context, id int) error {
+func (f *mockAnswerRepo) Delete(_ context.Context, id, version int) error {
+	return nil
+}
+
+func (f *mockAnswerRepo) Update(_ context.Context, a *domain.Answer) error {
 	return nil
 }
 
 func (f *mockAnswerRepo) ListByQuestionID(_ context.Context, questionID int) ([]domain.Answer, error) {
 	return nil, nil
 }
+
+func (f *mockAnswerRepo) ListByQuestionIDs(_ context.Context, questionIDs []int, perQuestion int) ([]domain.Answer, error) {
+	return nil, nil
+}
+
+func (f *mockAnswerRepo) ListThread(_ context.Context, questionID, maxDepth int) ([]domain.Answer, error) {
+	return nil, nil
+}
+
+func (f *mockAnswerRepo) Depth(_ context.Context, id int) (int, error) {
+	return 0, nil
+}
+
+func (f *mockAnswerRepo) Restore(_ context.Context, id int) (*domain.Answer, error) {
+	return nil, nil
+}
+
+func (f *mockAnswerRepo) ListDeleted(_ context.Context, _, _ int) ([]domain.Answer, error) {
+	return nil, nil
+}
+
+func (f *mockAnswerRepo) Purge(_ context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (f *mockAnswerRepo) SetHidden(_ context.Context, id int, hidden bool) error {
+	return nil
+}
+
 func TestCreateAnswer_Success(t *testing.T) {
 	aRepo := &mockAnswerRepo{}
 	qRepo := &mockQuestionRepo{
@@ -55,8 +102,8 @@ func TestCreateAnswer_Success(t *testing.T) {
 			{ID: 1, Text: "What is GORM?"},
 		},
 	}
-	svc := service.NewAnswerService(aRepo, qRepo)
-	handler := httptransport.NewAnswerHandler(svc)
+	svc := service.NewAnswerService(aRepo, qRepo, &mockOutbox{}, noopTx{}, nil)
+	handler := httptransport.NewAnswerHandler(svc, testLogger())
 
 	body := []byte(`{"user_id":"user-123","text":"Answer text"}`)
 	req := httptest.NewRequest(http.MethodPost, "/questions/1/answers", bytes.NewReader(body))
@@ -84,8 +131,8 @@ func TestCreateAnswer_QuestionNotFound(t *testing.T) {
 	qRepo := &mockQuestionRepo{}
 	aRepo := &mockAnswerRepo{}
 
-	svc := service.NewAnswerService(aRepo, qRepo)
-	handler := httptransport.NewAnswerHandler(svc)
+	svc := service.NewAnswerService(aRepo, qRepo, &mockOutbox{}, noopTx{}, nil)
+	handler := httptransport.NewAnswerHandler(svc, testLogger())
 
 	body := []byte(`{"user_id":"user-123","text":"Answer text"}`)
 	req := httptest.NewRequest(http.MethodPost, "/questions/999/answers", bytes.NewReader(body))
@@ -0,0 +1,311 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"question-service/internal/domain"
+	"question-service/internal/service"
+	"question-service/internal/transport"
+)
+
+// DefaultBatchMaxItems — сколько элементов принимают пакетные запросы, если
+// Options.BatchMaxItems не задан.
+const DefaultBatchMaxItems = 100
+
+// Режимы пакетного создания.
+const (
+	// batchAllOrNothing — при ошибке в любом элементе не создаётся ничего.
+	batchAllOrNothing = "all_or_nothing"
+	// batchBestEffort — создаются все корректные элементы.
+	batchBestEffort = "best_effort"
+)
+
+// maxUserIDLength — длина колонок user_id. Длинный user_id провалил бы
+// многострочный INSERT целиком, поэтому проверяется заранее.
+const maxUserIDLength = 64
+
+// batchRequest — тело пакетного запроса.
+type batchRequest[T any] struct {
+	Mode  string `json:"mode"`
+	Items []T    `json:"items"`
+}
+
+// BatchResponse — ответ на пакетный запрос. Results идут в порядке элементов запроса.
+type BatchResponse[T any] struct {
+	Mode    string           `json:"mode"`
+	Created int              `json:"created"`
+	Failed  int              `json:"failed"`
+	Results []BatchResult[T] `json:"results"`
+}
+
+// BatchResult — итог по одному элементу: Status — HTTP-статус, который получил бы
+// элемент отдельным запросом; Item заполнен у созданных, Error — у остальных.
+type BatchResult[T any] struct {
+	Index  int    `json:"index"`
+	Status int    `json:"status"`
+	Item   *T     `json:"item,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// batch собирает результаты по элементам, пока запрос проверяется и выполняется.
+type batch[T any] struct {
+	resp  BatchResponse[T]
+	valid []int // индексы прошедших проверку элементов
+}
+
+func newBatch[T any](mode string, n int) *batch[T] {
+	b := &batch[T]{resp: BatchResponse[T]{Mode: mode, Results: make([]BatchResult[T], n)}}
+	for i := range b.resp.Results {
+		b.resp.Results[i].Index = i
+	}
+	return b
+}
+
+func (b *batch[T]) check(i int, err error) {
+	if err != nil {
+		b.resp.Results[i].Status = http.StatusBadRequest
+		b.resp.Results[i].Error = err.Error()
+		b.resp.Failed++
+		return
+	}
+	b.valid = append(b.valid, i)
+}
+
+// rejected сообщает, что в режиме all_or_nothing создавать нечего: корректные
+// элементы получают 424 Failed Dependency.
+func (b *batch[T]) rejected() bool {
+	if b.resp.Failed == 0 || b.resp.Mode != batchAllOrNothing {
+		return false
+	}
+	for _, i := range b.valid {
+		b.resp.Results[i].Status = http.StatusFailedDependency
+		b.resp.Results[i].Error = "not created: another item in the batch is invalid"
+		b.resp.Failed++
+	}
+	return true
+}
+
+// created записывает созданные элементы по порядку корректных.
+func (b *batch[T]) created(items []T) {
+	for k, i := range b.valid {
+		b.resp.Results[i].Status = http.StatusCreated
+		b.resp.Results[i].Item = &items[k]
+	}
+	b.resp.Created = len(items)
+}
+
+// write отвечает 201, если созданы все элементы; 400, если пакет отклонён
+// целиком; 207 Multi-Status, если часть элементов не создана.
+func (b *batch[T]) write(w http.ResponseWriter) {
+	status := http.StatusMultiStatus
+	switch {
+	case b.resp.Failed == 0:
+		status = http.StatusCreated
+	case b.resp.Created == 0 && b.resp.Mode == batchAllOrNothing:
+		status = http.StatusBadRequest
+	}
+	transport.WriteJSON(w, status, b.resp)
+}
+
+// decodeBatch разбирает тело пакетного запроса и проверяет режим и число элементов.
+func decodeBatch[T any](r *http.Request, maxItems int) (*batchRequest[T], error) {
+	var req batchRequest[T]
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errors.New("invalid json")
+	}
+
+	switch req.Mode {
+	case "":
+		req.Mode = batchAllOrNothing
+	case batchAllOrNothing, batchBestEffort:
+	default:
+		return nil, fmt.Errorf("mode must be %s or %s", batchAllOrNothing, batchBestEffort)
+	}
+
+	if len(req.Items) == 0 {
+		return nil, errors.New("items are required")
+	}
+	if len(req.Items) > maxItems {
+		return nil, fmt.Errorf("too many items: at most %d are allowed", maxItems)
+	}
+	return &req, nil
+}
+
+// HandleCreateBatch обрабатывает POST /questions:batch
+func (h *QuestionHandler) HandleCreateBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		transport.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	defer r.Body.Close()
+
+	req, err := decodeBatch[createQuestionRequest](r, h.batchMaxItems)
+	if err != nil {
+		h.log.Warn("invalid batch question request",
+			zap.Error(err),
+			zap.String("path", r.URL.Path),
+		)
+		transport.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	b := newBatch[domain.Question](req.Mode, len(req.Items))
+	for i, item := range req.Items {
+		if strings.TrimSpace(item.Text) == "" {
+			b.check(i, errors.New("text is required"))
+			continue
+		}
+		b.check(i, nil)
+	}
+
+	if len(b.valid) > 0 && !b.rejected() {
+		texts := make([]string, len(b.valid))
+		candidates := make([][]domain.DuplicateCandidate, len(b.valid))
+		for k, i := range b.valid {
+			texts[k] = req.Items[i].Text
+
+			// как и в POST /questions, похожие ищутся до создания пакета, поэтому
+			// вопросы из одного пакета не находят друг друга
+			if candidates[k], err = h.svc.FindDuplicates(r.Context(), texts[k]); err != nil {
+				h.log.Warn("failed to find duplicate questions", zap.Error(err))
+			}
+		}
+
+		questions, err := h.svc.CreateQuestions(r.Context(), texts)
+		if err != nil {
+			h.log.Error("failed to create questions",
+				zap.Error(err),
+				zap.Int("count", len(texts)),
+			)
+			transport.WriteError(w, http.StatusInternalServerError, "failed to create questions")
+			return
+		}
+		for k := range questions {
+			questions[k].PossibleDuplicates = candidates[k]
+		}
+		b.created(questions)
+	}
+
+	h.log.Info("question batch processed",
+		zap.String("mode", req.Mode),
+		zap.Int("created", b.resp.Created),
+		zap.Int("failed", b.resp.Failed),
+	)
+	b.write(w)
+}
+
+// HandleCreateBatchForQuestion обрабатывает POST /questions/{id}/answers:batch
+func (h *AnswerHandler) HandleCreateBatchForQuestion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		transport.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	defer r.Body.Close()
+
+	path := strings.TrimPrefix(r.URL.Path, "/questions/")
+	parts := strings.Split(path, "/")
+	if len(parts) != 2 || parts[1] != "answers:batch" {
+		http.NotFound(w, r)
+		return
+	}
+
+	id, err := strconv.Atoi(parts[0])
+	if err != nil || id <= 0 {
+		h.log.Warn("invalid question id",
+			zap.String("path", r.URL.Path),
+			zap.String("method", r.Method),
+			zap.String("id_raw", parts[0]),
+		)
+		transport.WriteError(w, http.StatusBadRequest, "invalid question id")
+		return
+	}
+
+	req, err := decodeBatch[createAnswerRequest](r, h.batchMaxItems)
+	if err != nil {
+		h.log.Warn("invalid batch answer request",
+			zap.Error(err),
+			zap.String("path", r.URL.Path),
+		)
+		transport.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	b := newBatch[domain.Answer](req.Mode, len(req.Items))
+	for i, item := range req.Items {
+		err := item.validate()
+		if err == nil && item.ParentAnswerID != nil {
+			err = errors.New("replies cannot be created in a batch")
+		}
+		b.check(i, err)
+	}
+
+	if len(b.valid) > 0 && !b.rejected() {
+		answers := make([]domain.Answer, len(b.valid))
+		for k, i := range b.valid {
+			answers[k] = domain.Answer{UserID: req.Items[i].UserID, Text: req.Items[i].Text}
+		}
+
+		created, err := h.svc.CreateAnswers(r.Context(), id, answers)
+		if err != nil {
+			if errors.Is(err, service.ErrQuestionNotFound) {
+				h.log.Info("attempt to create answers for non-existing question",
+					zap.Int("question_id", id),
+				)
+				transport.WriteError(w, http.StatusNotFound, "question not found")
+				return
+			}
+			if errors.Is(err, service.ErrQuestionLocked) || errors.Is(err, service.ErrQuestionClosed) {
+				h.log.Info("attempt to answer question that is not open", zap.Int("question_id", id), zap.Error(err))
+				transport.WriteError(w, http.StatusConflict, err.Error())
+				return
+			}
+			h.log.Error("failed to create answers",
+				zap.Error(err),
+				zap.Int("question_id", id),
+				zap.Int("count", len(answers)),
+			)
+			transport.WriteError(w, http.StatusInternalServerError, "failed to create answers")
+			return
+		}
+		b.created(created)
+	}
+
+	h.log.Info("answer batch processed",
+		zap.Int("question_id", id),
+		zap.String("mode", req.Mode),
+		zap.Int("created", b.resp.Created),
+		zap.Int("failed", b.resp.Failed),
+	)
+	b.write(w)
+}
+
+type createAnswerRequest struct {
+	UserID string `json:"user_id"`
+	Text   string `json:"text"`
+	// ParentAnswerID — ответ, на который пишется реплика; nil — ответ на сам вопрос.
+	ParentAnswerID *int `json:"parent_answer_id"`
+}
+
+func (req createAnswerRequest) validate() error {
+	return validateAuthoredText(req.UserID, req.Text)
+}
+
+// validateAuthoredText проверяет автора и текст ответа или комментария.
+func validateAuthoredText(userID, text string) error {
+	if strings.TrimSpace(userID) == "" || strings.TrimSpace(text) == "" {
+		return errors.New("user_id and text are required")
+	}
+	if len(userID) > maxUserIDLength {
+		return fmt.Errorf("user_id must be at most %d characters", maxUserIDLength)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"question-service/internal/domain"
+	httptransport "question-service/internal/http"
+)
+
+func postBatch(t *testing.T, router http.Handler, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+	return w
+}
+
+func statuses[T any](resp httptransport.BatchResponse[T]) []int {
+	out := make([]int, len(resp.Results))
+	for i, r := range resp.Results {
+		out[i] = r.Status
+	}
+	return out
+}
+
+func TestCreateQuestionsBatch(t *testing.T) {
+	router, qSvc := newMemoryRouter(t, httptransport.Options{})
+
+	w := postBatch(t, router, "/questions:batch", `{"items":[{"text":"First"},{"text":"Second"}]}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var resp httptransport.BatchResponse[domain.Question]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, "all_or_nothing", resp.Mode)
+	require.Equal(t, 2, resp.Created)
+	require.Equal(t, []int{201, 201}, statuses(resp))
+	require.Equal(t, "Second", resp.Results[1].Item.Text)
+	require.Positive(t, resp.Results[1].Item.ID)
+
+	all, err := qSvc.ListQuestions(context.Background(), "")
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+}
+
+func TestCreateQuestionsBatch_PossibleDuplicates(t *testing.T) {
+	router, _ := newMemoryRouter(t, httptransport.Options{})
+	require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "/questions", `{"text":"How to use GORM with postgres?"}`).Code)
+
+	w := postBatch(t, router, "/questions:batch", `{"items":[{"text":"How to use GORM with sqlite?"},{"text":"Completely unrelated text"}]}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var resp httptransport.BatchResponse[domain.Question]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Results[0].Item.PossibleDuplicates, 1)
+	require.Equal(t, 1, resp.Results[0].Item.PossibleDuplicates[0].ID)
+	require.Empty(t, resp.Results[1].Item.PossibleDuplicates)
+}
+
+func TestCreateQuestionsBatch_Modes(t *testing.T) {
+	const body = `{"mode":%q,"items":[{"text":"ok"},{"text":"  "},{"text":"also ok"}]}`
+
+	router, qSvc := newMemoryRouter(t, httptransport.Options{})
+	w := postBatch(t, router, "/questions:batch", fmt.Sprintf(body, "all_or_nothing"))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	var resp httptransport.BatchResponse[domain.Question]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, []int{424, 400, 424}, statuses(resp))
+	require.Equal(t, "text is required", resp.Results[1].Error)
+	require.Zero(t, resp.Created)
+	require.Equal(t, 3, resp.Failed)
+
+	all, err := qSvc.ListQuestions(context.Background(), "")
+	require.NoError(t, err)
+	require.Empty(t, all)
+
+	w = postBatch(t, router, "/questions:batch", fmt.Sprintf(body, "best_effort"))
+	require.Equal(t, http.StatusMultiStatus, w.Code)
+
+	resp = httptransport.BatchResponse[domain.Question]{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, []int{201, 400, 201}, statuses(resp))
+	require.Equal(t, "also ok", resp.Results[2].Item.Text)
+	require.Nil(t, resp.Results[1].Item)
+
+	all, err = qSvc.ListQuestions(context.Background(), "")
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+}
+
+func TestCreateAnswersBatch(t *testing.T) {
+	router, qSvc := newMemoryRouter(t, httptransport.Options{})
+	q, err := qSvc.CreateQuestion(context.Background(), "What is GORM?")
+	require.NoError(t, err)
+
+	body := `{"mode":"best_effort","items":[
+		{"user_id":"u1","text":"An ORM"},
+		{"user_id":"","text":"no user"},
+		{"user_id":"` + strings.Repeat("x", 65) + `","text":"long user"},
+		{"user_id":"u2","text":"A library"}
+	]}`
+	w := postBatch(t, router, "/questions/1/answers:batch", body)
+	require.Equal(t, http.StatusMultiStatus, w.Code, w.Body.String())
+
+	var resp httptransport.BatchResponse[domain.Answer]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, []int{201, 400, 400, 201}, statuses(resp))
+	require.Equal(t, q.ID, resp.Results[3].Item.QuestionID)
+
+	// версия вопроса увеличивается один раз на пакет
+	got, err := qSvc.GetQuestionWithAnswers(context.Background(), q.ID)
+	require.NoError(t, err)
+	require.Equal(t, 2, got.Version)
+	require.Len(t, got.Answers, 2)
+}
+
+func TestCreateAnswersBatch_QuestionNotFound(t *testing.T) {
+	router, _ := newMemoryRouter(t, httptransport.Options{})
+
+	w := postBatch(t, router, "/questions/42/answers:batch", `{"items":[{"user_id":"u1","text":"hi"}]}`)
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestBatch_InvalidRequests(t *testing.T) {
+	router, _ := newMemoryRouter(t, httptransport.Options{BatchMaxItems: 2})
+
+	for _, tc := range []struct {
+		path, body string
+	}{
+		{"/questions:batch", `not json`},
+		{"/questions:batch", `{"items":[]}`},
+		{"/questions:batch", `{"mode":"some","items":[{"text":"a"}]}`},
+		{"/questions:batch", `{"items":[{"text":"a"},{"text":"b"},{"text":"c"}]}`},
+		{"/questions/abc/answers:batch", `{"items":[{"user_id":"u1","text":"a"}]}`},
+	} {
+		w := postBatch(t, router, tc.path, tc.body)
+		require.Equal(t, http.StatusBadRequest, w.Code, "%s %s", tc.path, tc.body)
+
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.NotEmpty(t, body["error"])
+	}
+}
@@ -0,0 +1,190 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"question-service/internal/domain"
+	"question-service/internal/logger"
+	"question-service/internal/service"
+	"question-service/internal/transport"
+)
+
+type CommentHandler struct {
+	svc *service.CommentService
+	log *logger.Logger
+}
+
+func NewCommentHandler(svc *service.CommentService, log *logger.Logger) *CommentHandler {
+	return &CommentHandler{svc: svc, log: log}
+}
+
+type createCommentRequest struct {
+	UserID string `json:"user_id"`
+	Text   string `json:"text"`
+}
+
+// HandleQuestionComments обрабатывает /questions/{id}/comments (GET, POST)
+func (h *CommentHandler) HandleQuestionComments(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.parentID(w, r, "/questions/", "question")
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		comments, err := h.svc.ListQuestionComments(r.Context(), id)
+		h.writeList(w, comments, err, zap.Int("question_id", id))
+	case http.MethodPost:
+		h.create(w, r, zap.Int("question_id", id), func(req createCommentRequest) (*domain.Comment, error) {
+			return h.svc.CommentOnQuestion(r.Context(), id, req.UserID, req.Text)
+		})
+	default:
+		transport.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// HandleAnswerComments обрабатывает /answers/{id}/comments (GET, POST)
+func (h *CommentHandler) HandleAnswerComments(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.parentID(w, r, "/answers/", "answer")
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		comments, err := h.svc.ListAnswerComments(r.Context(), id)
+		h.writeList(w, comments, err, zap.Int("answer_id", id))
+	case http.MethodPost:
+		h.create(w, r, zap.Int("answer_id", id), func(req createCommentRequest) (*domain.Comment, error) {
+			return h.svc.CommentOnAnswer(r.Context(), id, req.UserID, req.Text)
+		})
+	default:
+		transport.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// HandleCommentByID обрабатывает /comments/{id} (DELETE)
+func (h *CommentHandler) HandleCommentByID(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/comments/")
+	if idStr == "" || strings.Contains(idStr, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		h.log.Warn("invalid comment id",
+			zap.String("path", r.URL.Path),
+			zap.String("id_raw", idStr),
+		)
+		transport.WriteError(w, http.StatusBadRequest, "invalid comment id")
+		return
+	}
+
+	if r.Method != http.MethodDelete {
+		transport.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if err := h.svc.DeleteComment(r.Context(), id); err != nil {
+		if errors.Is(err, service.ErrCommentNotFound) {
+			h.log.Info("attempt to delete non-existing comment", zap.Int("comment_id", id))
+			transport.WriteError(w, http.StatusNotFound, "comment not found")
+			return
+		}
+		h.log.Error("failed to delete comment", zap.Error(err), zap.Int("comment_id", id))
+		transport.WriteError(w, http.StatusInternalServerError, "failed to delete comment")
+		return
+	}
+
+	h.log.Info("comment deleted", zap.Int("comment_id", id))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parentID разбирает id из пути вида {prefix}{id}/comments. При ошибке ответ уже записан.
+func (h *CommentHandler) parentID(w http.ResponseWriter, r *http.Request, prefix, kind string) (int, bool) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, prefix), "/")
+	if len(parts) != 2 || parts[1] != "comments" {
+		http.NotFound(w, r)
+		return 0, false
+	}
+
+	id, err := strconv.Atoi(parts[0])
+	if err != nil || id <= 0 {
+		h.log.Warn("invalid "+kind+" id",
+			zap.String("path", r.URL.Path),
+			zap.String("method", r.Method),
+			zap.String("id_raw", parts[0]),
+		)
+		transport.WriteError(w, http.StatusBadRequest, "invalid "+kind+" id")
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *CommentHandler) create(w http.ResponseWriter, r *http.Request, parent zap.Field, add func(createCommentRequest) (*domain.Comment, error)) {
+	defer r.Body.Close()
+
+	var req createCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Warn("invalid json in comment creation",
+			zap.Error(err),
+			zap.String("path", r.URL.Path),
+		)
+		transport.WriteError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if err := validateAuthoredText(req.UserID, req.Text); err != nil {
+		h.log.Warn("invalid comment", zap.Error(err), parent)
+		transport.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c, err := add(req)
+	if err != nil {
+		if status, msg, ok := parentNotFound(err); ok {
+			h.log.Info("attempt to comment on non-existing target", zap.String("error", msg), parent)
+			transport.WriteError(w, status, msg)
+			return
+		}
+		h.log.Error("failed to create comment", zap.Error(err), parent)
+		transport.WriteError(w, http.StatusInternalServerError, "failed to create comment")
+		return
+	}
+
+	h.log.Info("comment created", zap.Int("comment_id", c.ID), parent)
+	transport.WriteJSON(w, http.StatusCreated, c)
+}
+
+func (h *CommentHandler) writeList(w http.ResponseWriter, comments []domain.Comment, err error, parent zap.Field) {
+	if err != nil {
+		if status, msg, ok := parentNotFound(err); ok {
+			transport.WriteError(w, status, msg)
+			return
+		}
+		h.log.Error("failed to list comments", zap.Error(err), parent)
+		transport.WriteError(w, http.StatusInternalServerError, "failed to list comments")
+		return
+	}
+	if comments == nil {
+		comments = []domain.Comment{}
+	}
+	transport.WriteJSON(w, http.StatusOK, comments)
+}
+
+// parentNotFound переводит отсутствие вопроса или ответа в ответ 404.
+func parentNotFound(err error) (int, string, bool) {
+	switch {
+	case errors.Is(err, service.ErrQuestionNotFound):
+		return http.StatusNotFound, "question not found", true
+	case errors.Is(err, service.ErrAnswerNotFound):
+		return http.StatusNotFound, "answer not found", true
+	}
+	return 0, "", false
+}
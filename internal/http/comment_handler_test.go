@@ -0,0 +1,164 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"question-service/internal/domain"
+	httptransport "question-service/internal/http"
+	"question-service/internal/repository"
+	"question-service/internal/service"
+)
+
+type commentFixture struct {
+	router    http.Handler
+	questions *repository.MemoryQuestionRepository
+	answers   *repository.MemoryAnswerRepository
+	question  *domain.Question
+	answer    *domain.Answer
+}
+
+func newCommentFixture(t *testing.T) *commentFixture {
+	t.Helper()
+
+	app := newMemoryApp(t)
+	q, err := app.qSvc.CreateQuestion(context.Background(), "What is GORM?")
+	require.NoError(t, err)
+	a, err := app.aSvc.CreateAnswer(context.Background(), q.ID, "u1", "An ORM")
+	require.NoError(t, err)
+
+	router := app.router(httptransport.Options{
+		Comments: service.NewCommentService(repository.NewMemoryCommentRepository(app.store), app.questions, app.answers, app.store),
+	})
+	return &commentFixture{router: router, questions: app.questions, answers: app.answers, question: q, answer: a}
+}
+
+func TestComments_CreateListDelete(t *testing.T) {
+	f := newCommentFixture(t)
+
+	w := serve(f.router, http.MethodPost, "/questions/1/comments", `{"user_id":"u2","text":"Which version?"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var onQuestion domain.Comment
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &onQuestion))
+	require.Equal(t, f.question.ID, *onQuestion.QuestionID)
+	require.Nil(t, onQuestion.AnswerID)
+
+	w = serve(f.router, http.MethodPost, "/answers/1/comments", `{"user_id":"u3","text":"Source?"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var onAnswer domain.Comment
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &onAnswer))
+	require.Equal(t, f.answer.ID, *onAnswer.AnswerID)
+
+	w = serve(f.router, http.MethodGet, "/questions/1/comments", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var list []domain.Comment
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list, 1)
+	require.Equal(t, "Which version?", list[0].Text)
+
+	w = serve(f.router, http.MethodGet, "/answers/1/comments", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list, 1)
+	require.Equal(t, "Source?", list[0].Text)
+
+	w = serve(f.router, http.MethodDelete, "/comments/2", "")
+	require.Equal(t, http.StatusNoContent, w.Code)
+	w = serve(f.router, http.MethodDelete, "/comments/2", "")
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	w = serve(f.router, http.MethodGet, "/answers/1/comments", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `[]`, w.Body.String())
+}
+
+func TestComments_IncludedInQuestion(t *testing.T) {
+	f := newCommentFixture(t)
+
+	require.Equal(t, http.StatusCreated, serve(f.router, http.MethodPost, "/questions/1/comments", `{"user_id":"u2","text":"Q?"}`).Code)
+	require.Equal(t, http.StatusCreated, serve(f.router, http.MethodPost, "/answers/1/comments", `{"user_id":"u2","text":"A?"}`).Code)
+
+	w := serve(f.router, http.MethodGet, "/questions/1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotContains(t, w.Body.String(), `"comments"`)
+	// ответ и два комментария увеличили версию вопроса
+	require.Equal(t, `"4"`, w.Header().Get("ETag"))
+
+	// у представления с комментариями свой ETag: 304 по ETag без них было бы неверным
+	w = serve(f.router, http.MethodGet, "/questions/1?include=comments", "", http.Header{"If-None-Match": {`"4"`}})
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, `"4-c"`, w.Header().Get("ETag"))
+	require.Equal(t, http.StatusNotModified,
+		serve(f.router, http.MethodGet, "/questions/1?include=comments", "", http.Header{"If-None-Match": {`"4-c"`}}).Code)
+	require.Equal(t, http.StatusOK,
+		serve(f.router, http.MethodGet, "/questions/1", "", http.Header{"If-None-Match": {`"4-c"`}}).Code)
+
+	var q domain.Question
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &q))
+	require.Len(t, q.Comments, 1)
+	require.Equal(t, "Q?", q.Comments[0].Text)
+	require.Len(t, q.Answers, 1)
+	require.Len(t, q.Answers[0].Comments, 1)
+	require.Equal(t, "A?", q.Answers[0].Comments[0].Text)
+
+	w = serve(f.router, http.MethodGet, "/questions/1?include=votes", "")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	// ETag с комментариями годится для условного удаления
+	w = serve(f.router, http.MethodDelete, "/questions/1", "", http.Header{"If-Match": {`"4-c"`}})
+	require.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestComments_Errors(t *testing.T) {
+	f := newCommentFixture(t)
+
+	for _, tc := range []struct {
+		method, path, body string
+		status             int
+	}{
+		{http.MethodPost, "/questions/42/comments", `{"user_id":"u1","text":"?"}`, http.StatusNotFound},
+		{http.MethodPost, "/answers/42/comments", `{"user_id":"u1","text":"?"}`, http.StatusNotFound},
+		{http.MethodGet, "/questions/42/comments", "", http.StatusNotFound},
+		{http.MethodGet, "/answers/42/comments", "", http.StatusNotFound},
+		{http.MethodPost, "/questions/1/comments", `{"user_id":"","text":"?"}`, http.StatusBadRequest},
+		{http.MethodPost, "/questions/1/comments", `{"user_id":"u1","text":" "}`, http.StatusBadRequest},
+		{http.MethodPost, "/questions/1/comments", `not json`, http.StatusBadRequest},
+		{http.MethodGet, "/questions/abc/comments", "", http.StatusBadRequest},
+		{http.MethodGet, "/comments/1", "", http.StatusMethodNotAllowed},
+		{http.MethodDelete, "/comments/abc", "", http.StatusBadRequest},
+	} {
+		w := serve(f.router, tc.method, tc.path, tc.body)
+		require.Equal(t, tc.status, w.Code, "%s %s: %s", tc.method, tc.path, w.Body.String())
+	}
+}
+
+func TestComments_HiddenTargets(t *testing.T) {
+	ctx := context.Background()
+
+	f := newCommentFixture(t)
+	require.NoError(t, f.answers.SetHidden(ctx, f.answer.ID, true))
+	require.Equal(t, http.StatusNotFound, serve(f.router, http.MethodGet, "/answers/1/comments", "").Code)
+	require.Equal(t, http.StatusNotFound, serve(f.router, http.MethodPost, "/answers/1/comments", `{"user_id":"u1","text":"?"}`).Code)
+
+	f = newCommentFixture(t)
+	require.NoError(t, f.questions.SetHidden(ctx, f.question.ID, true))
+	for _, path := range []string{"/questions/1/comments", "/answers/1/comments"} {
+		require.Equal(t, http.StatusNotFound, serve(f.router, http.MethodGet, path, "").Code, path)
+		require.Equal(t, http.StatusNotFound, serve(f.router, http.MethodPost, path, `{"user_id":"u1","text":"?"}`).Code, path)
+	}
+}
+
+func TestComments_DisabledWithoutOption(t *testing.T) {
+	router, _ := newMemoryRouter(t, httptransport.Options{})
+
+	for _, path := range []string{"/questions/1/comments", "/answers/1/comments", "/comments/1"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusNotFound, w.Code, path)
+	}
+}
@@ -0,0 +1,91 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"question-service/internal/transport"
+)
+
+var (
+	errPreconditionRequired = errors.New("If-Match header is required")
+	errPreconditionFailed   = errors.New("precondition failed")
+)
+
+// commentsSuffix отличает ETag вопроса с комментариями (include=comments) от ETag
+// вопроса без них: версия у представлений общая, а тела разные.
+const commentsSuffix = "-c"
+
+// etag формирует строгий ETag по версии ресурса.
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// etagWithComments формирует ETag вопроса с комментариями.
+func etagWithComments(version int) string {
+	return `"` + strconv.Itoa(version) + commentsSuffix + `"`
+}
+
+// parseETag извлекает версию из строгого ETag любого представления. Слабые ETag
+// (W/"...") для условных изменений не подходят.
+func parseETag(tag string) (int, bool) {
+	tag = strings.TrimSpace(tag)
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+
+	v, err := strconv.Atoi(strings.TrimSuffix(tag[1:len(tag)-1], commentsSuffix))
+	if err != nil || v <= 0 {
+		return 0, false
+	}
+	return v, true
+}
+
+// notModified сообщает, совпадает ли If-None-Match с текущим ETag ресурса
+// (слабое сравнение, как требует RFC 9110).
+func notModified(r *http.Request, current string) bool {
+	header := r.Header.Get("If-None-Match")
+	if header == "" {
+		return false
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == current {
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatchVersion возвращает ожидаемую версию ресурса из If-Match.
+// 0 означает, что версия не проверяется: заголовка нет (и он не обязателен) или он равен "*".
+func ifMatchVersion(r *http.Request, required bool) (int, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	switch header {
+	case "":
+		if required {
+			return 0, errPreconditionRequired
+		}
+		return 0, nil
+	case "*":
+		return 0, nil
+	}
+
+	v, ok := parseETag(header)
+	if !ok {
+		return 0, errPreconditionFailed
+	}
+	return v, nil
+}
+
+// writePreconditionError отвечает 428 или 412 на ошибку ifMatchVersion.
+func writePreconditionError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errPreconditionRequired) {
+		transport.WriteError(w, http.StatusPreconditionRequired, err.Error())
+		return
+	}
+	transport.WriteError(w, http.StatusPreconditionFailed, "version mismatch")
+}
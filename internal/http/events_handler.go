@@ -0,0 +1,204 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"question-service/internal/logger"
+	"question-service/internal/realtime"
+	"question-service/internal/service"
+	"question-service/internal/transport"
+)
+
+// EventsHandler отдаёт события ответов вопроса потоком Server-Sent Events.
+type EventsHandler struct {
+	questions *service.QuestionService
+	broker    *realtime.Broker
+	log       *logger.Logger
+
+	heartbeat time.Duration
+	limiter   *connLimiter
+}
+
+// defaultEventsHeartbeat — период пингов, если он не задан.
+const defaultEventsHeartbeat = 15 * time.Second
+
+func NewEventsHandler(questions *service.QuestionService, broker *realtime.Broker, log *logger.Logger, heartbeat time.Duration, maxConnsPerClient int) *EventsHandler {
+	if heartbeat <= 0 {
+		heartbeat = defaultEventsHeartbeat
+	}
+	return &EventsHandler{
+		questions: questions,
+		broker:    broker,
+		log:       log,
+		heartbeat: heartbeat,
+		limiter:   &connLimiter{max: maxConnsPerClient, conns: make(map[string]int)},
+	}
+}
+
+// HandleQuestionEvents обрабатывает GET /questions/{id}/events
+func (h *EventsHandler) HandleQuestionEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		transport.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	idStr, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/questions/"), "/events")
+	if !ok || strings.Contains(idStr, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		h.log.Warn("invalid question id",
+			zap.String("path", r.URL.Path),
+			zap.String("id_raw", idStr),
+		)
+		transport.WriteError(w, http.StatusBadRequest, "invalid question id")
+		return
+	}
+
+	var lastEventID int64
+	if v := r.Header.Get("Last-Event-ID"); v != "" {
+		lastEventID, err = strconv.ParseInt(v, 10, 64)
+		if err != nil || lastEventID < 0 {
+			transport.WriteError(w, http.StatusBadRequest, "invalid Last-Event-ID")
+			return
+		}
+	}
+
+	if _, err := h.questions.GetQuestion(r.Context(), id); err != nil {
+		if errors.Is(err, service.ErrQuestionNotFound) {
+			transport.WriteError(w, http.StatusNotFound, "question not found")
+			return
+		}
+		h.log.Error("failed to get question for event stream",
+			zap.Error(err),
+			zap.Int("question_id", id),
+		)
+		transport.WriteError(w, http.StatusInternalServerError, "failed to open event stream")
+		return
+	}
+
+	client := clientAddr(r)
+	if !h.limiter.acquire(client) {
+		h.log.Info("too many event streams from client",
+			zap.String("client", client),
+		)
+		transport.WriteError(w, http.StatusTooManyRequests, "too many open event streams")
+		return
+	}
+	defer h.limiter.release(client)
+
+	rc := http.NewResponseController(w)
+	// поток живёт дольше обычного таймаута записи сервера
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	sub, replay, complete := h.broker.Subscribe(id, lastEventID)
+	defer h.broker.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	h.log.Info("event stream opened",
+		zap.Int("question_id", id),
+		zap.Int64("last_event_id", lastEventID),
+		zap.Int("replayed", len(replay)),
+	)
+	defer h.log.Info("event stream closed", zap.Int("question_id", id))
+
+	if !complete {
+		writeReset(w)
+	}
+	for _, msg := range replay {
+		writeMessage(w, msg)
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+
+		case msg, ok := <-sub.Messages():
+			if !ok {
+				if errors.Is(sub.Err(), realtime.ErrReset) {
+					writeReset(w)
+					_ = rc.Flush()
+				}
+				return
+			}
+			writeMessage(w, msg)
+
+		case <-ticker.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeMessage(w http.ResponseWriter, msg realtime.Message) {
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", msg.ID, msg.Type, msg.Data)
+}
+
+// writeReset сообщает клиенту, что часть событий пропущена и вопрос нужно
+// перечитать. Пустой id сбрасывает Last-Event-ID у EventSource.
+func writeReset(w http.ResponseWriter) {
+	fmt.Fprint(w, "id\nevent: reset\ndata: {}\n\n")
+}
+
+// clientAddr возвращает IP клиента без порта.
+func clientAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// connLimiter ограничивает число одновременных потоков одного клиента; max <= 0 — без ограничения.
+type connLimiter struct {
+	mu    sync.Mutex
+	max   int
+	conns map[string]int
+}
+
+func (l *connLimiter) acquire(client string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.max > 0 && l.conns[client] >= l.max {
+		return false
+	}
+	l.conns[client]++
+	return true
+}
+
+func (l *connLimiter) release(client string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conns[client]--; l.conns[client] <= 0 {
+		delete(l.conns, client)
+	}
+}
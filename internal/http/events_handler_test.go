@@ -0,0 +1,175 @@
+package http_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	httptransport "question-service/internal/http"
+	"question-service/internal/realtime"
+	"question-service/internal/repository"
+	"question-service/internal/service"
+)
+
+type eventsEnv struct {
+	server    *httptest.Server
+	questions *service.QuestionService
+	answers   *service.AnswerService
+	broker    *realtime.Broker
+	ws        *httptransport.WSHandler
+}
+
+func newEventsEnv(t *testing.T, maxConns int) *eventsEnv {
+	t.Helper()
+
+	store := repository.NewMemoryStore()
+	qRepo := repository.NewMemoryQuestionRepository(store)
+	aRepo := repository.NewMemoryAnswerRepository(store)
+	events := repository.NewMemoryOutboxRepository(store)
+	broker := realtime.NewBroker(100)
+
+	qSvc := service.NewQuestionService(qRepo, events, store)
+	aSvc := service.NewAnswerService(aRepo, qRepo, events, store, broker)
+
+	ws := httptransport.NewWSHandler(qSvc, aSvc, broker, testLogger(), httptransport.WSConfig{
+		SendQueueSize: 16,
+		WriteTimeout:  time.Second,
+	})
+
+	srv := httptest.NewServer(httptransport.NewRouter(qSvc, aSvc, testLogger(), httptransport.Options{
+		Events:                  broker,
+		EventsHeartbeat:         time.Hour,
+		EventsMaxConnsPerClient: maxConns,
+		WebSocket:               ws,
+	}))
+	t.Cleanup(func() {
+		broker.Close()
+		ws.Close()
+		srv.Close()
+	})
+
+	return &eventsEnv{server: srv, questions: qSvc, answers: aSvc, broker: broker, ws: ws}
+}
+
+// openStream открывает поток событий и возвращает функцию чтения следующего события.
+func (e *eventsEnv) openStream(t *testing.T, questionID int, lastEventID string) (*http.Response, func() map[string]string) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/questions/%d/events", e.server.URL, questionID), nil)
+	require.NoError(t, err)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+
+	reader := bufio.NewReader(resp.Body)
+	next := func() map[string]string {
+		fields := map[string]string{}
+		for {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			line = strings.TrimSuffix(line, "\n")
+			if line == "" {
+				if len(fields) > 0 {
+					return fields
+				}
+				continue
+			}
+			if strings.HasPrefix(line, ":") {
+				continue
+			}
+			k, v, _ := strings.Cut(line, ":")
+			fields[k] = strings.TrimPrefix(v, " ")
+		}
+	}
+	return resp, next
+}
+
+func TestQuestionEvents_StreamsAnswers(t *testing.T) {
+	env := newEventsEnv(t, 0)
+	ctx := context.Background()
+
+	q, err := env.questions.CreateQuestion(ctx, "Live question")
+	require.NoError(t, err)
+
+	resp, next := env.openStream(t, q.ID, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	a, err := env.answers.CreateAnswer(ctx, q.ID, "user-1", "first")
+	require.NoError(t, err)
+	ev := next()
+	require.Equal(t, "answer.created", ev["event"])
+	require.Contains(t, ev["data"], `"text":"first"`)
+
+	require.NoError(t, env.answers.DeleteAnswer(ctx, a.ID, 0))
+	ev = next()
+	require.Equal(t, "answer.deleted", ev["event"])
+}
+
+func TestQuestionEvents_ResumesFromLastEventID(t *testing.T) {
+	env := newEventsEnv(t, 0)
+	ctx := context.Background()
+
+	q, err := env.questions.CreateQuestion(ctx, "Live question")
+	require.NoError(t, err)
+
+	_, err = env.answers.CreateAnswer(ctx, q.ID, "user-1", "first")
+	require.NoError(t, err)
+	_, err = env.answers.CreateAnswer(ctx, q.ID, "user-1", "second")
+	require.NoError(t, err)
+
+	// событие 1 — создание вопроса, 2 и 3 — ответы
+	_, next := env.openStream(t, q.ID, "2")
+	ev := next()
+	require.Equal(t, "3", ev["id"])
+	require.Contains(t, ev["data"], `"text":"second"`)
+
+	_, next = env.openStream(t, q.ID, "1")
+	require.Equal(t, "2", next()["id"])
+	require.Equal(t, "3", next()["id"])
+}
+
+func TestQuestionEvents_ResetsWhenHistoryIsUnknown(t *testing.T) {
+	env := newEventsEnv(t, 0)
+
+	q, err := env.questions.CreateQuestion(context.Background(), "Live question")
+	require.NoError(t, err)
+
+	// брокер ещё не видел ни одного события: пропущенное восстановить нельзя
+	_, next := env.openStream(t, q.ID, "7")
+	ev := next()
+	require.Equal(t, "reset", ev["event"])
+	require.Contains(t, ev, "id")
+	require.Empty(t, ev["id"])
+}
+
+func TestQuestionEvents_Errors(t *testing.T) {
+	env := newEventsEnv(t, 1)
+
+	resp, err := http.Get(env.server.URL + "/questions/42/events")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	q, err := env.questions.CreateQuestion(context.Background(), "Live question")
+	require.NoError(t, err)
+
+	resp, _ = env.openStream(t, q.ID, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = http.Get(fmt.Sprintf("%s/questions/%d/events", env.server.URL, q.ID))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+}
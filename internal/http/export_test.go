@@ -0,0 +1,14 @@
+package http
+
+// RequestFingerprint открывает requestFingerprint для тестов.
+var RequestFingerprint = requestFingerprint
+
+// Route открывает route для тестов.
+type Route = route
+
+// NewRouterWithRoutes открывает newRouter для тестов: роутер и маршруты, которые
+// он зарегистрировал.
+var NewRouterWithRoutes = newRouter
+
+// ReadYourWrites открывает readYourWrites для тестов.
+var ReadYourWrites = readYourWrites
@@ -0,0 +1,55 @@
+//go:build ignore
+
+// gen_redoc скачивает бандл Redoc, который встраивается в бинарник и
+// отдаётся на GET /docs/redoc.standalone.js. Запускается через go generate.
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// redocVersion — версия Redoc; при обновлении бандл нужно скачать заново.
+const redocVersion = "2.1.5"
+
+const output = "docs/redoc.standalone.js"
+
+func main() {
+	if err := fetch(); err != nil {
+		fmt.Fprintln(os.Stderr, "gen_redoc:", err)
+		os.Exit(1)
+	}
+}
+
+func fetch() error {
+	url := "https://cdn.redoc.ly/redoc/v" + redocVersion + "/bundles/redoc.standalone.js"
+	client := &http.Client{Timeout: time.Minute}
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s responded with status %d", url, resp.StatusCode)
+	}
+
+	tmp := output + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, output)
+}
@@ -0,0 +1,163 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"question-service/internal/domain"
+	"question-service/internal/logger"
+	"question-service/internal/repository"
+	"question-service/internal/transport"
+)
+
+const (
+	idempotencyKeyHeader    = "Idempotency-Key"
+	idempotencyMaxKeyLength = 255
+
+	// defaultIdempotencyLease — аренда ключа выполняющимся запросом по умолчанию.
+	defaultIdempotencyLease = time.Minute
+)
+
+// idempotency обрабатывает заголовок Idempotency-Key для POST-запросов:
+// повтор с тем же ключом и телом получает сохранённый ответ, тот же ключ с другим
+// телом — 422, а повтор, пока первый запрос ещё выполняется, — 409. Ключ действует
+// в пределах метода и пути. Выполняющийся запрос держит ключ не дольше lease:
+// если он так и не завершился (процесс упал), повтор выполняется заново.
+type idempotency struct {
+	repo  repository.IdempotencyRepository
+	ttl   time.Duration
+	lease time.Duration
+	log   *logger.Logger
+}
+
+func (m *idempotency) wrap(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyKeyHeader)
+		if key == "" || r.Method != http.MethodPost {
+			next(w, r)
+			return
+		}
+
+		if len(key) > idempotencyMaxKeyLength {
+			transport.WriteError(w, http.StatusBadRequest, "Idempotency-Key is too long")
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			transport.WriteError(w, http.StatusBadRequest, "failed to read request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		fingerprint := requestFingerprint(r, body)
+
+		reserved := &domain.IdempotencyRecord{
+			Key:         key,
+			Method:      r.Method,
+			Path:        r.URL.Path,
+			Fingerprint: fingerprint,
+			Status:      domain.IdempotencyInProgress,
+			ExpiresAt:   time.Now().Add(m.lease),
+		}
+		existing, err := m.repo.Reserve(r.Context(), reserved)
+		if err != nil {
+			m.log.Error("failed to reserve idempotency key", zap.Error(err), zap.String("key", key))
+			transport.WriteError(w, http.StatusInternalServerError, "failed to process Idempotency-Key")
+			return
+		}
+
+		if existing != nil {
+			m.replay(w, r, existing, fingerprint)
+			return
+		}
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next(rec, r)
+
+		// запрос уже выполнен: результат сохраняем, даже если клиент отключился
+		ctx := context.WithoutCancel(r.Context())
+
+		if rec.status >= http.StatusInternalServerError {
+			// ошибку сервера не запоминаем, чтобы клиент мог повторить запрос
+			if err := m.repo.Release(ctx, reserved); err != nil {
+				m.log.Error("failed to release idempotency key", zap.Error(err), zap.String("key", key))
+			}
+			return
+		}
+
+		reserved.ResponseCode = rec.status
+		reserved.ContentType = rec.Header().Get("Content-Type")
+		reserved.ResponseBody = rec.body.Bytes()
+		reserved.ExpiresAt = time.Now().Add(m.ttl)
+		if err := m.repo.Complete(ctx, reserved); err != nil {
+			m.log.Error("failed to store idempotent response", zap.Error(err), zap.String("key", key))
+		}
+	}
+}
+
+func (m *idempotency) replay(w http.ResponseWriter, r *http.Request, rec *domain.IdempotencyRecord, fingerprint string) {
+	switch {
+	case rec.Fingerprint != fingerprint:
+		m.log.Info("idempotency key reused with different payload",
+			zap.String("key", rec.Key),
+			zap.String("path", r.URL.Path),
+		)
+		transport.WriteError(w, http.StatusUnprocessableEntity, "Idempotency-Key was used with a different request")
+
+	case rec.Status != domain.IdempotencyCompleted:
+		transport.WriteError(w, http.StatusConflict, "request with this Idempotency-Key is still in progress")
+
+	default:
+		m.log.Info("replaying idempotent response",
+			zap.String("key", rec.Key),
+			zap.String("path", r.URL.Path),
+		)
+		if rec.ContentType != "" {
+			w.Header().Set("Content-Type", rec.ContentType)
+		}
+		w.Header().Set("Idempotent-Replayed", "true")
+		w.WriteHeader(rec.ResponseCode)
+		_, _ = w.Write(rec.ResponseBody)
+	}
+}
+
+// requestFingerprint — SHA-256 от метода, пути с query и тела запроса.
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method))
+	h.Write([]byte{0})
+	h.Write([]byte(r.URL.RequestURI()))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder пропускает ответ клиенту и одновременно запоминает статус и тело.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(p)
+	return r.ResponseWriter.Write(p)
+}
@@ -0,0 +1,114 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"question-service/internal/domain"
+	httptransport "question-service/internal/http"
+	"question-service/internal/repository"
+)
+
+func newIdempotentRouter(t *testing.T) (http.Handler, *repository.MemoryQuestionRepository, *repository.MemoryIdempotencyRepository) {
+	t.Helper()
+
+	app := newMemoryApp(t)
+	idem := repository.NewMemoryIdempotencyRepository(app.store)
+	router := app.router(httptransport.Options{Idempotency: idem, IdempotencyTTL: time.Hour})
+	return router, app.questions, idem
+}
+
+func postQuestion(router http.Handler, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/questions", bytes.NewReader([]byte(body)))
+	req.Header.Set("Idempotency-Key", key)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotency_ReplaysStoredResponse(t *testing.T) {
+	router, qRepo, _ := newIdempotentRouter(t)
+
+	first := postQuestion(router, "key-1", `{"text":"What is GORM?"}`)
+	require.Equal(t, http.StatusCreated, first.Code)
+
+	second := postQuestion(router, "key-1", `{"text":"What is GORM?"}`)
+	require.Equal(t, http.StatusCreated, second.Code)
+	require.Equal(t, "true", second.Header().Get("Idempotent-Replayed"))
+	require.JSONEq(t, first.Body.String(), second.Body.String())
+
+	all, err := qRepo.List(context.Background(), "", 0, 0)
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+}
+
+func TestIdempotency_DifferentPayload(t *testing.T) {
+	router, _, _ := newIdempotentRouter(t)
+
+	require.Equal(t, http.StatusCreated, postQuestion(router, "key-1", `{"text":"first"}`).Code)
+	require.Equal(t, http.StatusUnprocessableEntity, postQuestion(router, "key-1", `{"text":"second"}`).Code)
+}
+
+func TestIdempotency_InFlight(t *testing.T) {
+	router, _, idem := newIdempotentRouter(t)
+
+	// первый запрос ещё выполняется: ключ занят, ответа нет
+	first := httptest.NewRequest(http.MethodPost, "/questions", bytes.NewReader([]byte(`{"text":"q"}`)))
+	_, err := idem.Reserve(context.Background(), &domain.IdempotencyRecord{
+		Key:         "key-1",
+		Method:      http.MethodPost,
+		Path:        "/questions",
+		Fingerprint: httptransport.RequestFingerprint(first, []byte(`{"text":"q"}`)),
+		Status:      domain.IdempotencyInProgress,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, http.StatusConflict, postQuestion(router, "key-1", `{"text":"q"}`).Code)
+}
+
+func TestIdempotency_ExpiredLeaseIsTakenOver(t *testing.T) {
+	router, qRepo, idem := newIdempotentRouter(t)
+
+	// первый запрос занял ключ и не завершился: аренда истекла
+	first := httptest.NewRequest(http.MethodPost, "/questions", bytes.NewReader([]byte(`{"text":"q"}`)))
+	_, err := idem.Reserve(context.Background(), &domain.IdempotencyRecord{
+		Key:         "key-1",
+		Method:      http.MethodPost,
+		Path:        "/questions",
+		Fingerprint: httptransport.RequestFingerprint(first, []byte(`{"text":"q"}`)),
+		Status:      domain.IdempotencyInProgress,
+		ExpiresAt:   time.Now().Add(-time.Second),
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, http.StatusCreated, postQuestion(router, "key-1", `{"text":"q"}`).Code)
+	// завершённый запрос хранится IDEMPOTENCY_TTL, а не срок аренды
+	w := postQuestion(router, "key-1", `{"text":"q"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.Equal(t, "true", w.Header().Get("Idempotent-Replayed"))
+
+	all, err := qRepo.List(context.Background(), "", 0, 0)
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+}
+
+func TestIdempotency_ScopedToRoute(t *testing.T) {
+	router, _, _ := newIdempotentRouter(t)
+
+	require.Equal(t, http.StatusCreated, postQuestion(router, "key-1", `{"text":"q"}`).Code)
+
+	// тот же ключ на другом маршруте — другой запрос, а не 422
+	req := httptest.NewRequest(http.MethodPost, "/questions/1/answers", bytes.NewReader([]byte(`{"user_id":"u1","text":"a"}`)))
+	req.Header.Set("Idempotency-Key", "key-1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	require.Empty(t, w.Header().Get("Idempotent-Replayed"))
+}
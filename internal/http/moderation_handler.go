@@ -0,0 +1,308 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"question-service/internal/domain"
+	"question-service/internal/logger"
+	"question-service/internal/service"
+	"question-service/internal/transport"
+)
+
+const (
+	defaultModerationPageSize = 50
+	maxModerationPageSize     = 500
+)
+
+type ModerationHandler struct {
+	svc *service.ModerationService
+	log *logger.Logger
+
+	// adminToken — токен Bearer для маршрутов /moderation/...; пустой — без проверки.
+	adminToken string
+}
+
+func NewModerationHandler(svc *service.ModerationService, log *logger.Logger) *ModerationHandler {
+	return &ModerationHandler{svc: svc, log: log}
+}
+
+type createFlagRequest struct {
+	UserID string `json:"user_id"`
+	Reason string `json:"reason"`
+}
+
+type moderationActionRequest struct {
+	Moderator string `json:"moderator"`
+	Note      string `json:"note"`
+}
+
+// HandleQuestionFlags обрабатывает POST /questions/{id}/flags
+func (h *ModerationHandler) HandleQuestionFlags(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.flagTargetID(w, r, "/questions/", "question")
+	if !ok {
+		return
+	}
+	h.createFlag(w, r, zap.Int("question_id", id), func(req createFlagRequest) (*domain.Flag, error) {
+		return h.svc.FlagQuestion(r.Context(), id, req.UserID, req.Reason)
+	})
+}
+
+// HandleAnswerFlags обрабатывает POST /answers/{id}/flags
+func (h *ModerationHandler) HandleAnswerFlags(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.flagTargetID(w, r, "/answers/", "answer")
+	if !ok {
+		return
+	}
+	h.createFlag(w, r, zap.Int("answer_id", id), func(req createFlagRequest) (*domain.Flag, error) {
+		return h.svc.FlagAnswer(r.Context(), id, req.UserID, req.Reason)
+	})
+}
+
+// flagTargetID проверяет метод и разбирает id из пути вида {prefix}{id}/flags.
+// При ошибке ответ уже записан.
+func (h *ModerationHandler) flagTargetID(w http.ResponseWriter, r *http.Request, prefix, kind string) (int, bool) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, prefix), "/")
+	if len(parts) != 2 || parts[1] != "flags" {
+		http.NotFound(w, r)
+		return 0, false
+	}
+	if r.Method != http.MethodPost {
+		transport.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return 0, false
+	}
+
+	id, err := strconv.Atoi(parts[0])
+	if err != nil || id <= 0 {
+		h.log.Warn("invalid "+kind+" id",
+			zap.String("path", r.URL.Path),
+			zap.String("id_raw", parts[0]),
+		)
+		transport.WriteError(w, http.StatusBadRequest, "invalid "+kind+" id")
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *ModerationHandler) createFlag(w http.ResponseWriter, r *http.Request, target zap.Field, add func(createFlagRequest) (*domain.Flag, error)) {
+	defer r.Body.Close()
+
+	var req createFlagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Warn("invalid json in flag creation",
+			zap.Error(err),
+			zap.String("path", r.URL.Path),
+		)
+		transport.WriteError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if strings.TrimSpace(req.UserID) == "" {
+		transport.WriteError(w, http.StatusBadRequest, "user_id is required")
+		return
+	}
+	if len(req.UserID) > maxUserIDLength {
+		transport.WriteError(w, http.StatusBadRequest, fmt.Sprintf("user_id must be at most %d characters", maxUserIDLength))
+		return
+	}
+
+	f, err := add(req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidFlag):
+			transport.WriteError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrAlreadyFlagged):
+			transport.WriteError(w, http.StatusConflict, err.Error())
+		default:
+			if status, msg, ok := parentNotFound(err); ok {
+				h.log.Info("attempt to flag non-existing target", zap.String("error", msg), target)
+				transport.WriteError(w, status, msg)
+				return
+			}
+			h.log.Error("failed to create flag", zap.Error(err), target)
+			transport.WriteError(w, http.StatusInternalServerError, "failed to create flag")
+		}
+		return
+	}
+
+	h.log.Info("flag created",
+		zap.Int("flag_id", f.ID),
+		zap.String("reason", f.Reason),
+		target,
+	)
+	transport.WriteJSON(w, http.StatusCreated, f)
+}
+
+// HandleQueue обрабатывает GET /moderation/queue
+func (h *ModerationHandler) HandleQueue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		transport.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if !h.authorize(w, r) {
+		return
+	}
+
+	limit, ok := moderationLimit(w, r)
+	if !ok {
+		return
+	}
+
+	items, err := h.svc.Queue(r.Context(), limit)
+	if err != nil {
+		h.log.Error("failed to list moderation queue", zap.Error(err))
+		transport.WriteError(w, http.StatusInternalServerError, "failed to list moderation queue")
+		return
+	}
+	if items == nil {
+		items = []domain.QueueItem{}
+	}
+	transport.WriteJSON(w, http.StatusOK, items)
+}
+
+// HandleActions обрабатывает GET /moderation/actions
+func (h *ModerationHandler) HandleActions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		transport.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if !h.authorize(w, r) {
+		return
+	}
+
+	limit, ok := moderationLimit(w, r)
+	if !ok {
+		return
+	}
+	questionID := 0
+	if v := r.URL.Query().Get("question_id"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			transport.WriteError(w, http.StatusBadRequest, "invalid question_id")
+			return
+		}
+		questionID = n
+	}
+
+	actions, err := h.svc.ListActions(r.Context(), questionID, limit)
+	if err != nil {
+		h.log.Error("failed to list moderation actions", zap.Error(err))
+		transport.WriteError(w, http.StatusInternalServerError, "failed to list moderation actions")
+		return
+	}
+	if actions == nil {
+		actions = []domain.ModerationAction{}
+	}
+	transport.WriteJSON(w, http.StatusOK, actions)
+}
+
+// HandleModerate обрабатывает POST /moderation/questions/{id}/{action} и
+// POST /moderation/answers/{id}/{action}
+func (h *ModerationHandler) HandleModerate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		transport.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if !h.authorize(w, r) {
+		return
+	}
+
+	defer r.Body.Close()
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/moderation/"), "/")
+	if len(parts) != 3 || parts[0] != "questions" && parts[0] != "answers" {
+		http.NotFound(w, r)
+		return
+	}
+	kind := strings.TrimSuffix(parts[0], "s")
+	id, err := strconv.Atoi(parts[1])
+	if err != nil || id <= 0 {
+		transport.WriteError(w, http.StatusBadRequest, "invalid "+kind+" id")
+		return
+	}
+	action := parts[2]
+
+	var req moderationActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Warn("invalid json in moderation action",
+			zap.Error(err),
+			zap.String("path", r.URL.Path),
+		)
+		transport.WriteError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if strings.TrimSpace(req.Moderator) == "" {
+		transport.WriteError(w, http.StatusBadRequest, "moderator is required")
+		return
+	}
+	if len(req.Moderator) > maxUserIDLength {
+		transport.WriteError(w, http.StatusBadRequest, fmt.Sprintf("moderator must be at most %d characters", maxUserIDLength))
+		return
+	}
+
+	var rec *domain.ModerationAction
+	if kind == domain.TargetQuestion {
+		rec, err = h.svc.ModerateQuestion(r.Context(), id, action, req.Moderator, req.Note)
+	} else {
+		rec, err = h.svc.ModerateAnswer(r.Context(), id, action, req.Moderator, req.Note)
+	}
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidModerationAction) {
+			transport.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if status, msg, ok := parentNotFound(err); ok {
+			transport.WriteError(w, status, msg)
+			return
+		}
+		h.log.Error("failed to apply moderation action",
+			zap.Error(err),
+			zap.String("action", action),
+			zap.String("target", kind),
+			zap.Int("target_id", id),
+		)
+		transport.WriteError(w, http.StatusInternalServerError, "failed to apply moderation action")
+		return
+	}
+
+	h.log.Info("moderation action applied",
+		zap.Int("action_id", rec.ID),
+		zap.String("action", rec.Action),
+		zap.String("target", rec.Target),
+		zap.Int("target_id", rec.TargetID),
+		zap.String("moderator", rec.Moderator),
+		zap.Int("resolved_flags", rec.ResolvedFlags),
+	)
+	transport.WriteJSON(w, http.StatusOK, rec)
+}
+
+func (h *ModerationHandler) authorize(w http.ResponseWriter, r *http.Request) bool {
+	if !authorizeAdmin(w, r, h.adminToken) {
+		h.log.Warn("unauthorized moderation request",
+			zap.String("path", r.URL.Path),
+			zap.String("remote_addr", r.RemoteAddr),
+		)
+		return false
+	}
+	return true
+}
+
+// moderationLimit разбирает параметр limit списков модерации. При ошибке ответ уже записан.
+func moderationLimit(w http.ResponseWriter, r *http.Request) (int, bool) {
+	v := r.URL.Query().Get("limit")
+	if v == "" {
+		return defaultModerationPageSize, true
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 || n > maxModerationPageSize {
+		transport.WriteError(w, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxModerationPageSize))
+		return 0, false
+	}
+	return n, true
+}
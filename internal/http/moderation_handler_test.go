@@ -0,0 +1,247 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"question-service/internal/domain"
+	httptransport "question-service/internal/http"
+	"question-service/internal/repository"
+	"question-service/internal/service"
+)
+
+func newModerationRouter(t *testing.T, adminToken string) http.Handler {
+	t.Helper()
+
+	app := newMemoryApp(t)
+	return app.router(httptransport.Options{
+		Moderation: service.NewModerationService(
+			repository.NewMemoryModerationRepository(app.store), app.questions, app.answers, app.qSvc, app.store,
+		),
+		AdminToken: adminToken,
+	})
+}
+
+func moderationQueue(t *testing.T, router http.Handler) []domain.QueueItem {
+	t.Helper()
+
+	w := serve(router, http.MethodGet, "/moderation/queue", "", adminHeader)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var items []domain.QueueItem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &items))
+	return items
+}
+
+func TestModeration_FlagsAndQueue(t *testing.T) {
+	router := newModerationRouter(t, "secret")
+
+	require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "/questions", `{"text":"What is GORM?"}`).Code)
+	require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "/questions/1/answers", `{"user_id":"u1","text":"Buy now"}`).Code)
+
+	w := serve(router, http.MethodPost, "/questions/1/flags", `{"user_id":"u2","reason":"off_topic"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var f domain.Flag
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &f))
+	require.Equal(t, 1, *f.QuestionID)
+	require.Nil(t, f.AnswerID)
+
+	for _, user := range []string{"u2", "u3"} {
+		w = serve(router, http.MethodPost, "/answers/1/flags", `{"user_id":"`+user+`","reason":"spam"}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	}
+	w = serve(router, http.MethodPost, "/answers/1/flags", `{"user_id":"u3","reason":"abuse"}`)
+	require.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+
+	// ответ с двумя жалобами идёт раньше вопроса с одной
+	items := moderationQueue(t, router)
+	require.Len(t, items, 2)
+	require.Equal(t, domain.TargetAnswer, items[0].Target)
+	require.Equal(t, 1, items[0].ID)
+	require.Equal(t, 1, items[0].QuestionID)
+	require.Equal(t, "Buy now", items[0].Text)
+	require.Equal(t, 2, items[0].Flags)
+	require.Equal(t, map[string]int{"spam": 2}, items[0].Reasons)
+	require.Equal(t, domain.TargetQuestion, items[1].Target)
+	require.Equal(t, 1, items[1].Flags)
+
+	w = serve(router, http.MethodPost, "/moderation/questions/1/dismiss", `{"moderator":"mod","note":"fine"}`, adminHeader)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var rec domain.ModerationAction
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rec))
+	require.Equal(t, 1, rec.ResolvedFlags)
+
+	items = moderationQueue(t, router)
+	require.Len(t, items, 1)
+	require.Equal(t, domain.TargetAnswer, items[0].Target)
+
+	// после разбора жалобы пользователь может пожаловаться снова
+	w = serve(router, http.MethodPost, "/questions/1/flags", `{"user_id":"u2","reason":"duplicate"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+}
+
+func TestModeration_HideAnswer(t *testing.T) {
+	router := newModerationRouter(t, "secret")
+
+	require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "/questions", `{"text":"What is GORM?"}`).Code)
+	for _, body := range []string{
+		`{"user_id":"u1","text":"Buy now"}`,                     // 1
+		`{"user_id":"u2","text":"An ORM"}`,                      // 2
+		`{"user_id":"u3","text":"Cheap!","parent_answer_id":1}`, // 3
+	} {
+		require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "/questions/1/answers", body).Code)
+	}
+	require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "/answers/1/flags", `{"user_id":"u2","reason":"spam"}`).Code)
+
+	w := serve(router, http.MethodPost, "/moderation/answers/1/hide", `{"moderator":"mod"}`, adminHeader)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var rec domain.ModerationAction
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rec))
+	require.Equal(t, domain.ModerationHide, rec.Action)
+	require.Equal(t, 1, rec.QuestionID)
+	require.Equal(t, 1, rec.ResolvedFlags)
+	require.Empty(t, moderationQueue(t, router))
+
+	// реплика на скрытый ответ скрывается вместе с ним
+	w = serve(router, http.MethodGet, "/questions/1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var q domain.Question
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &q))
+	require.Len(t, q.Answers, 1)
+	require.Equal(t, 2, q.Answers[0].ID)
+	require.Equal(t, http.StatusNotFound, serve(router, http.MethodGet, "/answers/1", "").Code)
+	require.Equal(t, http.StatusNotFound, serve(router, http.MethodGet, "/answers/3", "").Code)
+	require.Equal(t, http.StatusNotFound, serve(router, http.MethodPost, "/answers/1/flags", `{"user_id":"u4","reason":"spam"}`).Code)
+
+	w = serve(router, http.MethodPost, "/moderation/answers/1/unhide", `{"moderator":"mod"}`, adminHeader)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Equal(t, http.StatusOK, serve(router, http.MethodGet, "/answers/3", "").Code)
+
+	w = serve(router, http.MethodGet, "/moderation/actions?question_id=1", "", adminHeader)
+	require.Equal(t, http.StatusOK, w.Code)
+	var actions []domain.ModerationAction
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &actions))
+	require.Len(t, actions, 2)
+	require.Equal(t, domain.ModerationUnhide, actions[0].Action)
+	require.Equal(t, domain.ModerationHide, actions[1].Action)
+}
+
+func TestModeration_HideAndLockQuestion(t *testing.T) {
+	router := newModerationRouter(t, "secret")
+
+	require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "/questions", `{"text":"Spam"}`).Code)
+	require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "/questions", `{"text":"What is GORM?"}`).Code)
+
+	require.Equal(t, http.StatusOK, serve(router, http.MethodPost, "/moderation/questions/1/hide", `{"moderator":"mod"}`, adminHeader).Code)
+	require.Equal(t, http.StatusNotFound, serve(router, http.MethodGet, "/questions/1", "").Code)
+	w := serve(router, http.MethodGet, "/questions", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var list []domain.Question
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list, 1)
+	require.Equal(t, 2, list[0].ID)
+
+	require.Equal(t, http.StatusOK, serve(router, http.MethodPost, "/moderation/questions/2/lock", `{"moderator":"mod"}`, adminHeader).Code)
+	w = serve(router, http.MethodGet, "/questions/2", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), `"status":"locked"`)
+
+	w = serve(router, http.MethodPost, "/questions/2/answers", `{"user_id":"u1","text":"An ORM"}`)
+	require.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+
+	require.Equal(t, http.StatusOK, serve(router, http.MethodPost, "/moderation/questions/2/unlock", `{"moderator":"mod"}`, adminHeader).Code)
+	w = serve(router, http.MethodPost, "/questions/2/answers", `{"user_id":"u1","text":"An ORM"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+}
+
+func TestModeration_HiddenNotRestorable(t *testing.T) {
+	ctx := context.Background()
+	app := newMemoryApp(t)
+	router := app.router(httptransport.Options{
+		Moderation: service.NewModerationService(
+			repository.NewMemoryModerationRepository(app.store), app.questions, app.answers, app.qSvc, app.store,
+		),
+		AdminToken: "secret",
+	})
+
+	require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "/questions", `{"text":"Hidden question"}`).Code)
+	require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "/questions", `{"text":"Q"}`).Code)
+	require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "/questions/2/answers", `{"user_id":"u1","text":"Hidden answer"}`).Code)
+	require.Equal(t, http.StatusOK, serve(router, http.MethodPost, "/moderation/questions/1/hide", `{"moderator":"mod"}`, adminHeader).Code)
+	require.Equal(t, http.StatusOK, serve(router, http.MethodPost, "/moderation/answers/1/hide", `{"moderator":"mod"}`, adminHeader).Code)
+
+	// скрытое нельзя удалить и восстановить, чтобы прочитать его текст
+	require.Equal(t, http.StatusNotFound, serve(router, http.MethodDelete, "/questions/1", "").Code)
+	require.Equal(t, http.StatusNotFound, serve(router, http.MethodDelete, "/answers/1", "").Code)
+
+	// даже если скрытое попало в корзину до скрытия или вместе с вопросом
+	require.NoError(t, app.questions.Delete(ctx, 1, 0))
+	require.NoError(t, app.answers.Delete(ctx, 1, 0))
+	for _, path := range []string{"/questions/1/restore", "/answers/1/restore"} {
+		w := serve(router, http.MethodPost, path, "", adminHeader)
+		require.Equal(t, http.StatusNotFound, w.Code, path)
+		require.NotContains(t, w.Body.String(), "Hidden")
+	}
+
+	// и событие восстановления с текстом не уходит подписчикам
+	events, err := repository.NewMemoryOutboxRepository(app.store).FetchUnpublished(ctx, 100)
+	require.NoError(t, err)
+	for _, ev := range events {
+		require.NotContains(t, []string{domain.EventQuestionRestored, domain.EventAnswerRestored}, ev.Type)
+	}
+	_, err = app.questions.GetWithoutAnswers(ctx, 1)
+	require.Error(t, err)
+}
+
+func TestModeration_Errors(t *testing.T) {
+	router := newModerationRouter(t, "secret")
+
+	require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "/questions", `{"text":"Q"}`).Code)
+	require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "/questions/1/answers", `{"user_id":"u1","text":"A"}`).Code)
+
+	for _, tc := range []struct {
+		method, path, body string
+		status             int
+	}{
+		{http.MethodPost, "/questions/1/flags", `{"user_id":"u2","reason":"boring"}`, http.StatusBadRequest},
+		{http.MethodPost, "/questions/1/flags", `{"reason":"spam"}`, http.StatusBadRequest},
+		{http.MethodPost, "/questions/1/flags", `{`, http.StatusBadRequest},
+		{http.MethodPost, "/questions/42/flags", `{"user_id":"u2","reason":"spam"}`, http.StatusNotFound},
+		{http.MethodPost, "/answers/42/flags", `{"user_id":"u2","reason":"spam"}`, http.StatusNotFound},
+		{http.MethodGet, "/questions/1/flags", "", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/moderation/answers/1/lock", `{"moderator":"mod"}`, http.StatusBadRequest},
+		{http.MethodPost, "/moderation/questions/1/delete", `{"moderator":"mod"}`, http.StatusBadRequest},
+		{http.MethodPost, "/moderation/questions/1/hide", `{}`, http.StatusBadRequest},
+		{http.MethodPost, "/moderation/questions/abc/hide", `{"moderator":"mod"}`, http.StatusBadRequest},
+		{http.MethodPost, "/moderation/questions/42/hide", `{"moderator":"mod"}`, http.StatusNotFound},
+		{http.MethodPost, "/moderation/answers/42/dismiss", `{"moderator":"mod"}`, http.StatusNotFound},
+		{http.MethodGet, "/moderation/queue?limit=0", "", http.StatusBadRequest},
+		{http.MethodGet, "/moderation/actions?question_id=x", "", http.StatusBadRequest},
+		{http.MethodPost, "/moderation/queue", "", http.StatusMethodNotAllowed},
+	} {
+		w := serve(router, tc.method, tc.path, tc.body, adminHeader)
+		require.Equal(t, tc.status, w.Code, "%s %s: %s", tc.method, tc.path, w.Body.String())
+	}
+
+	for _, path := range []string{"/moderation/queue", "/moderation/actions"} {
+		require.Equal(t, http.StatusUnauthorized, serve(router, http.MethodGet, path, "").Code, path)
+	}
+	w := serve(router, http.MethodPost, "/moderation/questions/1/hide", `{"moderator":"mod"}`)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	require.Equal(t, http.StatusOK, serve(router, http.MethodGet, "/questions/1", "").Code)
+}
+
+func TestModeration_DisabledWithoutOption(t *testing.T) {
+	router, _ := newMemoryRouter(t, httptransport.Options{})
+
+	require.Equal(t, http.StatusNotFound, serve(router, http.MethodGet, "/moderation/queue", "").Code)
+	require.Equal(t, http.StatusNotFound, serve(router, http.MethodPost, "/questions/1/flags", `{"user_id":"u1","reason":"spam"}`).Code)
+
+	// без токена модерация не открывается всем подряд
+	router = newModerationRouter(t, "")
+	require.Equal(t, http.StatusNotFound, serve(router, http.MethodGet, "/moderation/queue", "").Code)
+	require.Equal(t, http.StatusNotFound, serve(router, http.MethodPost, "/moderation/questions/1/hide", `{"moderator":"mod"}`).Code)
+}
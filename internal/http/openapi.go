@@ -0,0 +1,82 @@
+package http
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+
+	"question-service/internal/transport"
+)
+
+// openAPISpec — спецификация OpenAPI 3.1 всех маршрутов NewRouter.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// docsFS — страница документации и бандл Redoc, который она подключает. Бандл
+// встроен в бинарник, поэтому документации не нужен доступ к CDN; скачать или
+// обновить его — go generate ./internal/http.
+//
+//go:generate go run gen_redoc.go
+//go:embed docs
+var docsFS embed.FS
+
+// redocBundle — путь бандла Redoc в docsFS.
+const redocBundle = "docs/redoc.standalone.js"
+
+// OpenAPISpec возвращает спецификацию OpenAPI в формате JSON.
+func OpenAPISpec() []byte {
+	return openAPISpec
+}
+
+// handleOpenAPI обрабатывает GET /openapi.json
+func handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		transport.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(openAPISpec)
+}
+
+// handleDocs обрабатывает GET /docs
+func handleDocs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		transport.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	// без бандла страница осталась бы пустой
+	if _, err := fs.Stat(docsFS, redocBundle); err != nil {
+		transport.WriteError(w, http.StatusServiceUnavailable, "docs bundle is not built: run go generate ./internal/http")
+		return
+	}
+
+	page, err := docsFS.ReadFile("docs/index.html")
+	if err != nil {
+		transport.WriteError(w, http.StatusInternalServerError, "failed to load docs page")
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(page)
+}
+
+// handleRedoc обрабатывает GET /docs/redoc.standalone.js
+func handleRedoc(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		transport.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	bundle, err := docsFS.ReadFile(redocBundle)
+	if err != nil {
+		transport.WriteError(w, http.StatusNotFound, "docs bundle is not built")
+		return
+	}
+	w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(bundle)
+}
@@ -0,0 +1,190 @@
+package http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"question-service/internal/domain"
+	"question-service/internal/gqlapi"
+	httptransport "question-service/internal/http"
+	"question-service/internal/realtime"
+	"question-service/internal/repository"
+	"question-service/internal/service"
+	"question-service/internal/transfer"
+)
+
+type openAPIDoc struct {
+	OpenAPI    string                                `json:"openapi"`
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"schemas"`
+	} `json:"components"`
+}
+
+func loadOpenAPI(t *testing.T) openAPIDoc {
+	t.Helper()
+
+	var doc openAPIDoc
+	require.NoError(t, json.Unmarshal(httptransport.OpenAPISpec(), &doc))
+	require.Equal(t, "3.1.0", doc.OpenAPI)
+	return doc
+}
+
+// newFullRouter собирает роутер со всеми необязательными маршрутами и возвращает
+// маршруты, которые он зарегистрировал.
+func newFullRouter(t *testing.T) (http.Handler, []httptransport.Route) {
+	t.Helper()
+
+	app := newMemoryApp(t)
+	store, qRepo, aRepo, qSvc, aSvc := app.store, app.questions, app.answers, app.qSvc, app.aSvc
+
+	broker := realtime.NewBroker(10)
+	t.Cleanup(broker.Close)
+
+	gql, err := gqlapi.NewHandler(qSvc, aSvc, testLogger())
+	require.NoError(t, err)
+
+	opts := httptransport.Options{
+		Events:    broker,
+		WebSocket: httptransport.NewWSHandler(qSvc, aSvc, broker, testLogger(), httptransport.WSConfig{}),
+		GraphQL:   gql,
+		Webhooks:  service.NewWebhookService(repository.NewMemoryWebhookRepository(store)),
+		Comments:  service.NewCommentService(repository.NewMemoryCommentRepository(store), qRepo, aRepo, store),
+		Transfer:  transfer.NewService(qRepo, aRepo, store),
+		Trash:     service.NewTrashService(qRepo, aRepo),
+		Moderation: service.NewModerationService(
+			repository.NewMemoryModerationRepository(store), qRepo, aRepo, qSvc, store,
+		),
+		// с токеном /trash и /moderation/... отвечают 401 в JSON и считается маршрутизированным
+		AdminToken: "secret",
+	}
+	return httptransport.NewRouterWithRoutes(qSvc, aSvc, testLogger(), opts)
+}
+
+func TestOpenAPI_DocumentsEveryRoute(t *testing.T) {
+	doc := loadOpenAPI(t)
+	_, routes := newFullRouter(t)
+
+	for _, rt := range routes {
+		ops, ok := doc.Paths[rt.Path]
+		require.Truef(t, ok, "path %s is missing from openapi.json", rt.Path)
+		require.Containsf(t, ops, strings.ToLower(rt.Method), "%s %s is missing from openapi.json", rt.Method, rt.Path)
+	}
+
+	// и наоборот: спецификация не описывает несуществующих маршрутов
+	for path, ops := range doc.Paths {
+		for method := range ops {
+			if method == "parameters" {
+				continue
+			}
+			want := httptransport.Route{Method: strings.ToUpper(method), Path: path}
+			require.Truef(t, slices.Contains(routes, want), "%s %s is documented but not routed", want.Method, path)
+		}
+	}
+}
+
+func TestOpenAPI_RouteTableMatchesRouter(t *testing.T) {
+	router, routes := newFullRouter(t)
+
+	methods := []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	paths := make(map[string]bool)
+	for _, rt := range routes {
+		paths[rt.Path] = true
+	}
+
+	for path := range paths {
+		for _, method := range methods {
+			if path == "/ws" && method != http.MethodGet {
+				// handshake с другим методом отклоняет сама библиотека WebSocket
+				continue
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(method, strings.ReplaceAll(path, "{id}", "1"), nil))
+
+			routed := w.Code != http.StatusMethodNotAllowed &&
+				!(w.Code == http.StatusNotFound && !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json"))
+			listed := slices.Contains(routes, httptransport.Route{Method: method, Path: path})
+			require.Equalf(t, listed, routed, "%s %s: status %d", method, path, w.Code)
+		}
+	}
+}
+
+func TestOpenAPI_SchemasMatchDomain(t *testing.T) {
+	doc := loadOpenAPI(t)
+
+	for name, v := range map[string]any{
+		"Question":              domain.Question{},
+		"Answer":                domain.Answer{},
+		"Comment":               domain.Comment{},
+		"WebhookSubscription":   domain.WebhookSubscription{},
+		"WebhookDelivery":       domain.WebhookDelivery{},
+		"Trash":                 domain.Trash{},
+		"TrashedQuestion":       domain.TrashedQuestion{},
+		"TrashedAnswer":         domain.TrashedAnswer{},
+		"Flag":                  domain.Flag{},
+		"QueueItem":             domain.QueueItem{},
+		"ModerationAction":      domain.ModerationAction{},
+		"DuplicateCandidate":    domain.DuplicateCandidate{},
+		"ImportReport":          transfer.ImportReport{},
+		"ImportLineError":       transfer.LineError{},
+		"QuestionBatchResponse": httptransport.BatchResponse[domain.Question]{},
+		"QuestionBatchResult":   httptransport.BatchResult[domain.Question]{},
+		"AnswerBatchResponse":   httptransport.BatchResponse[domain.Answer]{},
+		"AnswerBatchResult":     httptransport.BatchResult[domain.Answer]{},
+	} {
+		schema, ok := doc.Components.Schemas[name]
+		require.Truef(t, ok, "schema %s is missing", name)
+
+		var fields []string
+		typ := reflect.TypeOf(v)
+		for i := 0; i < typ.NumField(); i++ {
+			tag, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+			if tag != "" && tag != "-" {
+				fields = append(fields, tag)
+			}
+		}
+
+		var props []string
+		for p := range schema.Properties {
+			props = append(props, p)
+		}
+		require.ElementsMatchf(t, fields, props, "schema %s", name)
+	}
+}
+
+func TestOpenAPI_Served(t *testing.T) {
+	router, _ := newFullRouter(t)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	require.JSONEq(t, string(httptransport.OpenAPISpec()), w.Body.String())
+
+	// бандл Redoc встраивается после go generate ./internal/http
+	bundle := httptest.NewRecorder()
+	router.ServeHTTP(bundle, httptest.NewRequest(http.MethodGet, "/docs/redoc.standalone.js", nil))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	if bundle.Code == http.StatusNotFound {
+		require.Equal(t, http.StatusServiceUnavailable, w.Code)
+		return
+	}
+	require.Equal(t, http.StatusOK, bundle.Code)
+	require.Equal(t, "text/javascript; charset=utf-8", bundle.Header().Get("Content-Type"))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), `spec-url="/openapi.json"`)
+	require.Contains(t, w.Body.String(), `src="/docs/redoc.standalone.js"`)
+	require.NotContains(t, w.Body.String(), "https://")
+}
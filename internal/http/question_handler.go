@@ -3,12 +3,15 @@ package http
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 
 	"go.uber.org/zap"
 
+	"question-service/internal/domain"
 	"question-service/internal/logger"
 	"question-service/internal/service"
 	"question-service/internal/transport"
@@ -17,10 +20,22 @@ import (
 type QuestionHandler struct {
 	svc *service.QuestionService
 	log *logger.Logger
+
+	// requireIfMatch — строгий режим: изменения без If-Match отклоняются с 428.
+	requireIfMatch bool
+	// batchMaxItems — сколько элементов принимает пакетный запрос.
+	batchMaxItems int
+	// comments подгружает комментарии для include=comments; nil — параметр не поддерживается.
+	comments *service.CommentService
+	// threads отдаёт ответы деревом обсуждения с глубиной реплик; nil — плоским
+	// списком в порядке создания.
+	threads *service.AnswerService
+	// adminToken — токен Bearer для POST /questions/{id}/merge.
+	adminToken string
 }
 
 func NewQuestionHandler(svc *service.QuestionService, log *logger.Logger) *QuestionHandler {
-	return &QuestionHandler{svc: svc, log: log}
+	return &QuestionHandler{svc: svc, log: log, batchMaxItems: DefaultBatchMaxItems}
 }
 
 // HandleQuestions обрабатывает /questions (GET, POST)
@@ -93,6 +108,25 @@ func (h *QuestionHandler) createQuestion(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if v := r.URL.Query().Get("check_duplicates"); v != "" {
+		check, err := strconv.ParseBool(v)
+		if err != nil {
+			transport.WriteError(w, http.StatusBadRequest, "check_duplicates must be a boolean")
+			return
+		}
+		if check {
+			h.checkDuplicates(w, r, req.Text)
+			return
+		}
+	}
+
+	// похожие вопросы ищутся до создания, чтобы новый не нашёл сам себя; без них
+	// вопрос всё равно создаётся
+	candidates, err := h.svc.FindDuplicates(r.Context(), req.Text)
+	if err != nil {
+		h.log.Warn("failed to find duplicate questions", zap.Error(err))
+	}
+
 	q, err := h.svc.CreateQuestion(r.Context(), req.Text)
 	if err != nil {
 		h.log.Error("failed to create question",
@@ -102,17 +136,35 @@ func (h *QuestionHandler) createQuestion(w http.ResponseWriter, r *http.Request)
 		transport.WriteError(w, http.StatusInternalServerError, "failed to create question")
 		return
 	}
+	q.PossibleDuplicates = candidates
 
 	h.log.Info("question created",
 		zap.Int("question_id", q.ID),
+		zap.Int("possible_duplicates", len(candidates)),
 	)
 
 	transport.WriteJSON(w, http.StatusCreated, q)
 }
 
+const (
+	defaultQuestionsPageSize = 20
+	maxQuestionsPageSize     = 100
+)
+
 func (h *QuestionHandler) listQuestions(w http.ResponseWriter, r *http.Request) {
-	questions, err := h.svc.ListQuestions(r.Context())
+	query := r.URL.Query()
+	if query.Has("limit") || query.Has("after") {
+		h.listQuestionsPage(w, r)
+		return
+	}
+
+	status := query.Get("status")
+	questions, err := h.svc.ListQuestions(r.Context(), status)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidStatus) {
+			transport.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		h.log.Error("failed to list questions",
 			zap.Error(err),
 		)
@@ -126,8 +178,104 @@ func (h *QuestionHandler) listQuestions(w http.ResponseWriter, r *http.Request)
 	transport.WriteJSON(w, http.StatusOK, questions)
 }
 
+// listQuestionsPage отдаёт страницу вопросов по возрастанию id: limit — размер
+// страницы, after — id последнего вопроса предыдущей страницы, status — фильтр по
+// статусу. Ссылка на следующую страницу передаётся в заголовке Link с rel="next".
+func (h *QuestionHandler) listQuestionsPage(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit := defaultQuestionsPageSize
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxQuestionsPageSize {
+			transport.WriteError(w, http.StatusBadRequest, "limit must be between 1 and 100")
+			return
+		}
+		limit = n
+	}
+
+	after := 0
+	if v := query.Get("after"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			transport.WriteError(w, http.StatusBadRequest, "invalid after")
+			return
+		}
+		after = n
+	}
+
+	status := query.Get("status")
+	questions, hasNext, err := h.svc.ListQuestionsPage(r.Context(), after, limit, status)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidStatus) {
+			transport.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.log.Error("failed to list questions",
+			zap.Error(err),
+			zap.Int("after", after),
+		)
+		transport.WriteError(w, http.StatusInternalServerError, "failed to list questions")
+		return
+	}
+	if questions == nil {
+		questions = []domain.Question{}
+	}
+
+	if hasNext {
+		next := fmt.Sprintf("/questions?limit=%d&after=%d", limit, questions[len(questions)-1].ID)
+		if status != "" {
+			next += "&status=" + url.QueryEscape(status)
+		}
+		w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"next\"", next))
+	}
+
+	transport.WriteJSON(w, http.StatusOK, questions)
+}
+
+// checkDuplicates отвечает списком похожих вопросов вместо создания нового.
+func (h *QuestionHandler) checkDuplicates(w http.ResponseWriter, r *http.Request, text string) {
+	candidates, err := h.svc.FindDuplicates(r.Context(), text)
+	if err != nil {
+		h.log.Error("failed to find duplicate questions", zap.Error(err))
+		transport.WriteError(w, http.StatusInternalServerError, "failed to find duplicate questions")
+		return
+	}
+	if candidates == nil {
+		candidates = []domain.DuplicateCandidate{}
+	}
+
+	h.log.Info("duplicate questions checked",
+		zap.Int("candidates", len(candidates)),
+	)
+	transport.WriteJSON(w, http.StatusOK, candidates)
+}
+
 func (h *QuestionHandler) getQuestion(w http.ResponseWriter, r *http.Request, id int) {
-	q, err := h.svc.GetQuestionWithAnswers(r.Context(), id)
+	withComments := false
+	for _, v := range r.URL.Query()["include"] {
+		for _, part := range strings.Split(v, ",") {
+			switch strings.TrimSpace(part) {
+			case "":
+			case "comments":
+				if h.comments == nil {
+					transport.WriteError(w, http.StatusBadRequest, "comments are not enabled")
+					return
+				}
+				withComments = true
+			default:
+				transport.WriteError(w, http.StatusBadRequest, "unsupported include: "+part)
+				return
+			}
+		}
+	}
+
+	// ответы деревом загружаются отдельно, после проверки ETag
+	get := h.svc.GetQuestionWithAnswers
+	if h.threads != nil {
+		get = h.svc.GetQuestion
+	}
+	q, err := get(r.Context(), id)
 	if err != nil {
 		if errors.Is(err, service.ErrQuestionNotFound) {
 			h.log.Info("question not found",
@@ -144,15 +292,74 @@ func (h *QuestionHandler) getQuestion(w http.ResponseWriter, r *http.Request, id
 		return
 	}
 
+	if q.MergedIntoID != nil {
+		location := fmt.Sprintf("/questions/%d", *q.MergedIntoID)
+		if r.URL.RawQuery != "" {
+			location += "?" + r.URL.RawQuery
+		}
+		h.log.Info("merged question redirected",
+			zap.Int("question_id", id),
+			zap.Int("merged_into_id", *q.MergedIntoID),
+		)
+		w.Header().Set("Location", location)
+		transport.WriteJSON(w, http.StatusMovedPermanently, q)
+		return
+	}
+
+	tag := etag(q.Version)
+	if withComments {
+		tag = etagWithComments(q.Version)
+	}
+	w.Header().Set("ETag", tag)
+	if notModified(r, tag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	if h.threads != nil {
+		answers, err := h.threads.ListThread(r.Context(), id)
+		if err != nil {
+			h.log.Error("failed to load answer thread",
+				zap.Error(err),
+				zap.Int("question_id", id),
+			)
+			transport.WriteError(w, http.StatusInternalServerError, "failed to get question")
+			return
+		}
+		q.Answers = answers
+	}
+
+	if withComments {
+		if err := h.comments.AttachComments(r.Context(), q); err != nil {
+			h.log.Error("failed to load comments",
+				zap.Error(err),
+				zap.Int("question_id", id),
+			)
+			transport.WriteError(w, http.StatusInternalServerError, "failed to get question")
+			return
+		}
+	}
+
 	h.log.Info("question fetched",
 		zap.Int("question_id", q.ID),
+		zap.Bool("comments", withComments),
 	)
 
 	transport.WriteJSON(w, http.StatusOK, q)
 }
 
 func (h *QuestionHandler) deleteQuestion(w http.ResponseWriter, r *http.Request, id int) {
-	err := h.svc.DeleteQuestion(r.Context(), id)
+	version, err := ifMatchVersion(r, h.requireIfMatch)
+	if err != nil {
+		h.log.Info("precondition failed for question deletion",
+			zap.Error(err),
+			zap.Int("question_id", id),
+		)
+		writePreconditionError(w, err)
+		return
+	}
+
+	err = h.svc.DeleteQuestion(r.Context(), id, version)
 	if err != nil {
 		if errors.Is(err, service.ErrQuestionNotFound) {
 			h.log.Info("attempt to delete non-existing question",
@@ -161,6 +368,14 @@ func (h *QuestionHandler) deleteQuestion(w http.ResponseWriter, r *http.Request,
 			transport.WriteError(w, http.StatusNotFound, "question not found")
 			return
 		}
+		if errors.Is(err, service.ErrVersionMismatch) {
+			h.log.Info("question version mismatch on delete",
+				zap.Int("question_id", id),
+				zap.Int("version", version),
+			)
+			transport.WriteError(w, http.StatusPreconditionFailed, "version mismatch")
+			return
+		}
 		h.log.Error("failed to delete question",
 			zap.Error(err),
 			zap.Int("question_id", id),
@@ -4,18 +4,29 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"gorm.io/gorm"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"question-service/internal/domain"
 	httptransport "question-service/internal/http"
+	"question-service/internal/repository"
 	"question-service/internal/service"
 
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"question-service/internal/logger"
 )
 
+func testLogger() *logger.Logger {
+	return &logger.Logger{Logger: zap.NewNop()}
+}
+
 type mockQuestionRepo struct {
 	nextID    int
 	questions []domain.Question
@@ -31,7 +42,20 @@ func (f *mockQuestionRepo) Create(_ context.Context, q *domain.Question) error {
 	return nil
 }
 
-func (f *mockQuestionRepo) GetAll(_ context.Context) ([]domain.Question, error) {
+func (f *mockQuestionRepo) CreateBatch(ctx context.Context, questions []domain.Question, _ int) error {
+	for i := range questions {
+		if err := f.Create(ctx, &questions[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *mockQuestionRepo) ListPage(_ context.Context, afterID, limit int) ([]domain.Question, error) {
+	return nil, nil
+}
+
+func (f *mockQuestionRepo) List(_ context.Context, status string, afterID, limit int) ([]domain.Question, error) {
 	return f.questions, nil
 }
 
@@ -45,14 +69,76 @@ func (f *mockQuestionRepo) GetByID(_ context.Context, id int) (*domain.Question,
 	return nil, gorm.ErrRecordNotFound
 }
 
-func (f *mockQuestionRepo) Delete(_ context.Context, id int) error {
+func (f *mockQuestionRepo) FindByExternalIDs(_ context.Context, externalIDs []string) ([]domain.Question, error) {
+	return nil, nil
+}
+
+func (f *mockQuestionRepo) Update(_ context.Context, q *domain.Question) error {
+	return nil
+}
+
+func (f *mockQuestionRepo) BumpVersion(ctx context.Context, id int) error {
+	_, err := f.GetByID(ctx, id)
+	return err
+}
+
+func (f *mockQuestionRepo) Delete(_ context.Context, id, version int) error {
+	return nil
+}
+
+func (f *mockQuestionRepo) Restore(_ context.Context, id int) error {
+	return nil
+}
+
+func (f *mockQuestionRepo) ListDeleted(_ context.Context, _, _ int) ([]domain.Question, error) {
+	return nil, nil
+}
+
+func (f *mockQuestionRepo) Purge(_ context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (f *mockQuestionRepo) GetWithoutAnswers(ctx context.Context, id int) (*domain.Question, error) {
+	return f.GetByID(ctx, id)
+}
+
+func (f *mockQuestionRepo) SetHidden(_ context.Context, id int, hidden bool) error {
+	return nil
+}
+
+func (f *mockQuestionRepo) SetStatus(_ context.Context, q *domain.Question) error {
+	return nil
+}
+
+func (f *mockQuestionRepo) FindSimilar(_ context.Context, text string, threshold float64, limit int) ([]domain.DuplicateCandidate, error) {
+	return nil, nil
+}
+
+func (f *mockQuestionRepo) Merge(_ context.Context, id, targetID int) (int64, error) {
+	return 0, nil
+}
+
+// noopTx выполняет функцию без транзакции.
+type noopTx struct{}
+
+func (noopTx) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// mockOutbox запоминает записанные доменные события.
+type mockOutbox struct {
+	events []domain.Event
+}
+
+func (m *mockOutbox) Append(_ context.Context, events ...domain.Event) error {
+	m.events = append(m.events, events...)
 	return nil
 }
 
 func TestCreateQuestion_Success(t *testing.T) {
 	repo := &mockQuestionRepo{}
-	svc := service.NewQuestionService(repo)
-	handler := httptransport.NewQuestionHandler(svc)
+	svc := service.NewQuestionService(repo, &mockOutbox{}, noopTx{})
+	handler := httptransport.NewQuestionHandler(svc, testLogger())
 
 	body := []byte(`{"text":"What is GORM?"}`)
 	req := httptest.NewRequest(http.MethodPost, "/questions", bytes.NewReader(body))
@@ -73,8 +159,8 @@ func TestCreateQuestion_Success(t *testing.T) {
 }
 func TestCreateQuestion_InvalidJSON(t *testing.T) {
 	repo := &mockQuestionRepo{}
-	svc := service.NewQuestionService(repo)
-	handler := httptransport.NewQuestionHandler(svc)
+	svc := service.NewQuestionService(repo, &mockOutbox{}, noopTx{})
+	handler := httptransport.NewQuestionHandler(svc, testLogger())
 
 	body := []byte(`{"text":`)
 	req := httptest.NewRequest(http.MethodPost, "/questions", bytes.NewReader(body))
@@ -96,8 +182,8 @@ func TestCreateQuestion_InvalidJSON(t *testing.T) {
 }
 func TestCreateQuestion_EmptyText(t *testing.T) {
 	repo := &mockQuestionRepo{}
-	svc := service.NewQuestionService(repo)
-	handler := httptransport.NewQuestionHandler(svc)
+	svc := service.NewQuestionService(repo, &mockOutbox{}, noopTx{})
+	handler := httptransport.NewQuestionHandler(svc, testLogger())
 
 	body := []byte(`{"text":""}`)
 	req := httptest.NewRequest(http.MethodPost, "/questions", bytes.NewReader(body))
@@ -117,3 +203,152 @@ func TestCreateQuestion_EmptyText(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "text is required", errResp.Error)
 }
+
+// memoryApp — репозитории и сервисы роутера поверх одного хранилища memory.
+type memoryApp struct {
+	store     *repository.MemoryStore
+	questions *repository.MemoryQuestionRepository
+	answers   *repository.MemoryAnswerRepository
+	qSvc      *service.QuestionService
+	aSvc      *service.AnswerService
+}
+
+func newMemoryApp(t *testing.T) *memoryApp {
+	t.Helper()
+
+	store := repository.NewMemoryStore()
+	qRepo := repository.NewMemoryQuestionRepository(store)
+	aRepo := repository.NewMemoryAnswerRepository(store)
+	events := repository.NewMemoryOutboxRepository(store)
+
+	return &memoryApp{
+		store:     store,
+		questions: qRepo,
+		answers:   aRepo,
+		qSvc:      service.NewQuestionService(qRepo, events, store),
+		aSvc:      service.NewAnswerService(aRepo, qRepo, events, store, nil),
+	}
+}
+
+// router собирает роутер поверх сервисов app.
+func (app *memoryApp) router(opts httptransport.Options) http.Handler {
+	return httptransport.NewRouter(app.qSvc, app.aSvc, testLogger(), opts)
+}
+
+func newMemoryRouter(t *testing.T, opts httptransport.Options) (http.Handler, *service.QuestionService) {
+	t.Helper()
+
+	app := newMemoryApp(t)
+	return app.router(opts), app.qSvc
+}
+
+// adminHeader — заголовок с токеном администратора "secret".
+var adminHeader = http.Header{"Authorization": {"Bearer secret"}}
+
+// serve выполняет запрос к router с заголовками header.
+func serve(router http.Handler, method, path, body string, header ...http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	for _, h := range header {
+		for k, v := range h {
+			req.Header[k] = v
+		}
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestGetQuestion_ETagAndNotModified(t *testing.T) {
+	router, qSvc := newMemoryRouter(t, httptransport.Options{})
+	q, err := qSvc.CreateQuestion(context.Background(), "What is GORM?")
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/questions/%d", q.ID), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	tag := w.Header().Get("ETag")
+	require.Equal(t, `"1"`, tag)
+
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/questions/%d", q.ID), nil)
+	req.Header.Set("If-None-Match", tag)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNotModified, w.Code)
+	require.Empty(t, w.Body.Bytes())
+
+	// новый ответ меняет представление вопроса, а значит и ETag
+	body := []byte(`{"user_id":"user-123","text":"Answer text"}`)
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/questions/%d/answers", q.ID), bytes.NewReader(body))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/questions/%d", q.ID), nil)
+	req.Header.Set("If-None-Match", tag)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, `"2"`, w.Header().Get("ETag"))
+}
+
+func TestDeleteQuestion_IfMatch(t *testing.T) {
+	router, qSvc := newMemoryRouter(t, httptransport.Options{RequireIfMatch: true})
+	q, err := qSvc.CreateQuestion(context.Background(), "What is GORM?")
+	require.NoError(t, err)
+	path := fmt.Sprintf("/questions/%d", q.ID)
+
+	req := httptest.NewRequest(http.MethodDelete, path, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusPreconditionRequired, w.Code)
+
+	req = httptest.NewRequest(http.MethodDelete, path, nil)
+	req.Header.Set("If-Match", `"7"`)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	req = httptest.NewRequest(http.MethodDelete, path, nil)
+	req.Header.Set("If-Match", `"1"`)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	req = httptest.NewRequest(http.MethodDelete, path, nil)
+	req.Header.Set("If-Match", `"1"`)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestListQuestions_Page(t *testing.T) {
+	router, qSvc := newMemoryRouter(t, httptransport.Options{})
+	for i := 0; i < 3; i++ {
+		_, err := qSvc.CreateQuestion(context.Background(), fmt.Sprintf("question %d", i))
+		require.NoError(t, err)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/questions?limit=2", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, `</questions?limit=2&after=2>; rel="next"`, w.Header().Get("Link"))
+
+	var page []domain.Question
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	require.Len(t, page, 2)
+	require.Equal(t, 1, page[0].ID)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/questions?limit=2&after=2", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Empty(t, w.Header().Get("Link"))
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	require.Len(t, page, 1)
+	require.Equal(t, 3, page[0].ID)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/questions?limit=500", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
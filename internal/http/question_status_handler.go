@@ -0,0 +1,178 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"question-service/internal/service"
+	"question-service/internal/transport"
+)
+
+type closeQuestionRequest struct {
+	ClosedBy string `json:"closed_by"`
+	Reason   string `json:"reason"`
+}
+
+type mergeQuestionRequest struct {
+	TargetID int    `json:"target_id"`
+	MergedBy string `json:"merged_by"`
+}
+
+// HandleClose обрабатывает POST /questions/{id}/close. Маршрут без аутентификации:
+// closed_by приходит от клиента и не проверяется, как user_id ответов.
+func (h *QuestionHandler) HandleClose(w http.ResponseWriter, r *http.Request) {
+	id, ok := actionID(w, r, "/questions/", "close", "question", h.log)
+	if !ok {
+		return
+	}
+
+	defer r.Body.Close()
+
+	var req closeQuestionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Warn("invalid json in close question",
+			zap.Error(err),
+			zap.String("path", r.URL.Path),
+		)
+		transport.WriteError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if strings.TrimSpace(req.ClosedBy) == "" {
+		transport.WriteError(w, http.StatusBadRequest, "closed_by is required")
+		return
+	}
+	if len(req.ClosedBy) > maxUserIDLength {
+		transport.WriteError(w, http.StatusBadRequest, fmt.Sprintf("closed_by must be at most %d characters", maxUserIDLength))
+		return
+	}
+
+	q, err := h.svc.CloseQuestion(r.Context(), id, req.ClosedBy, req.Reason)
+	if err != nil {
+		h.writeStatusError(w, err, "close", id)
+		return
+	}
+
+	h.log.Info("question closed",
+		zap.Int("question_id", id),
+		zap.String("reason", q.CloseReason),
+		zap.String("closed_by", q.ClosedBy),
+	)
+	w.Header().Set("ETag", etag(q.Version))
+	transport.WriteJSON(w, http.StatusOK, q)
+}
+
+// HandleReopen обрабатывает POST /questions/{id}/reopen
+func (h *QuestionHandler) HandleReopen(w http.ResponseWriter, r *http.Request) {
+	id, ok := actionID(w, r, "/questions/", "reopen", "question", h.log)
+	if !ok {
+		return
+	}
+
+	q, err := h.svc.ReopenQuestion(r.Context(), id)
+	if err != nil {
+		h.writeStatusError(w, err, "reopen", id)
+		return
+	}
+
+	h.log.Info("question reopened", zap.Int("question_id", id))
+	w.Header().Set("ETag", etag(q.Version))
+	transport.WriteJSON(w, http.StatusOK, q)
+}
+
+func (h *QuestionHandler) writeStatusError(w http.ResponseWriter, err error, op string, id int) {
+	switch {
+	case errors.Is(err, service.ErrInvalidCloseReason):
+		transport.WriteError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrQuestionNotFound):
+		h.log.Info("attempt to "+op+" non-existing question", zap.Int("question_id", id))
+		transport.WriteError(w, http.StatusNotFound, "question not found")
+	case errors.Is(err, service.ErrQuestionClosed),
+		errors.Is(err, service.ErrQuestionNotClosed),
+		errors.Is(err, service.ErrQuestionLocked),
+		errors.Is(err, service.ErrQuestionMerged):
+		h.log.Info("question status conflict", zap.Error(err), zap.String("op", op), zap.Int("question_id", id))
+		transport.WriteError(w, http.StatusConflict, err.Error())
+	default:
+		h.log.Error("failed to "+op+" question", zap.Error(err), zap.Int("question_id", id))
+		transport.WriteError(w, http.StatusInternalServerError, "failed to "+op+" question")
+	}
+}
+
+// HandleMerge обрабатывает POST /questions/{id}/merge
+func (h *QuestionHandler) HandleMerge(w http.ResponseWriter, r *http.Request) {
+	id, ok := actionID(w, r, "/questions/", "merge", "question", h.log)
+	if !ok {
+		return
+	}
+	if !authorizeAdmin(w, r, h.adminToken) {
+		h.log.Warn("unauthorized merge request",
+			zap.String("path", r.URL.Path),
+			zap.String("remote_addr", r.RemoteAddr),
+		)
+		return
+	}
+
+	defer r.Body.Close()
+
+	var req mergeQuestionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Warn("invalid json in merge question",
+			zap.Error(err),
+			zap.String("path", r.URL.Path),
+		)
+		transport.WriteError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if req.TargetID <= 0 {
+		transport.WriteError(w, http.StatusBadRequest, "target_id is required")
+		return
+	}
+	if strings.TrimSpace(req.MergedBy) == "" {
+		transport.WriteError(w, http.StatusBadRequest, "merged_by is required")
+		return
+	}
+	if len(req.MergedBy) > maxUserIDLength {
+		transport.WriteError(w, http.StatusBadRequest, fmt.Sprintf("merged_by must be at most %d characters", maxUserIDLength))
+		return
+	}
+
+	q, err := h.svc.MergeQuestion(r.Context(), id, req.TargetID, req.MergedBy)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidMerge):
+			transport.WriteError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrQuestionNotFound):
+			h.log.Info("attempt to merge non-existing question", zap.Int("question_id", id))
+			transport.WriteError(w, http.StatusNotFound, "question not found")
+		case errors.Is(err, service.ErrMergeTargetNotFound):
+			h.log.Info("attempt to merge into non-existing question",
+				zap.Int("question_id", id),
+				zap.Int("target_id", req.TargetID),
+			)
+			transport.WriteError(w, http.StatusUnprocessableEntity, err.Error())
+		case errors.Is(err, service.ErrQuestionMerged):
+			transport.WriteError(w, http.StatusConflict, err.Error())
+		default:
+			h.log.Error("failed to merge question",
+				zap.Error(err),
+				zap.Int("question_id", id),
+				zap.Int("target_id", req.TargetID),
+			)
+			transport.WriteError(w, http.StatusInternalServerError, "failed to merge question")
+		}
+		return
+	}
+
+	h.log.Info("question merged",
+		zap.Int("question_id", id),
+		zap.Int("target_id", q.ID),
+		zap.String("merged_by", req.MergedBy),
+	)
+	w.Header().Set("ETag", etag(q.Version))
+	transport.WriteJSON(w, http.StatusOK, q)
+}
@@ -0,0 +1,269 @@
+package http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"question-service/internal/domain"
+	httptransport "question-service/internal/http"
+)
+
+func TestQuestionStatus_CloseAndReopen(t *testing.T) {
+	router, _ := newMemoryRouter(t, httptransport.Options{})
+
+	require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "/questions", `{"text":"What is GORM?"}`).Code)
+
+	w := serve(router, http.MethodPost, "/questions/1/close", `{"closed_by":"u1","reason":"resolved"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Equal(t, `"2"`, w.Header().Get("ETag"))
+	var q domain.Question
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &q))
+	require.Equal(t, domain.QuestionClosed, q.Status)
+	require.Equal(t, domain.CloseReasonResolved, q.CloseReason)
+	require.Equal(t, "u1", q.ClosedBy)
+	require.NotNil(t, q.ClosedAt)
+
+	for _, path := range []string{"/questions/1/answers", "/questions/1/answers:batch"} {
+		body := `{"user_id":"u2","text":"Late answer"}`
+		if path == "/questions/1/answers:batch" {
+			body = `{"items":[` + body + `]}`
+		}
+		w = serve(router, http.MethodPost, path, body)
+		require.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+		require.Contains(t, w.Body.String(), "question is closed")
+	}
+
+	w = serve(router, http.MethodPost, "/questions/1/close", `{"closed_by":"u1","reason":"resolved"}`)
+	require.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+
+	w = serve(router, http.MethodPost, "/questions/1/reopen", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	q = domain.Question{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &q))
+	require.Equal(t, domain.QuestionOpen, q.Status)
+	require.Empty(t, q.CloseReason)
+	require.Nil(t, q.ClosedAt)
+
+	w = serve(router, http.MethodPost, "/questions/1/reopen", "")
+	require.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+
+	w = serve(router, http.MethodPost, "/questions/1/answers", `{"user_id":"u2","text":"An ORM"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+}
+
+func TestQuestionStatus_ListFilter(t *testing.T) {
+	router, _ := newMemoryRouter(t, httptransport.Options{})
+
+	for _, text := range []string{"Q1", "Q2", "Q3", "Q4"} {
+		require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "/questions", `{"text":"`+text+`"}`).Code)
+	}
+	for _, id := range []string{"1", "3", "4"} {
+		w := serve(router, http.MethodPost, "/questions/"+id+"/close", `{"closed_by":"u1","reason":"off_topic"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	}
+
+	list := func(path string) []int {
+		t.Helper()
+		w := serve(router, http.MethodGet, path, "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var questions []domain.Question
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &questions))
+		ids := make([]int, 0, len(questions))
+		for _, q := range questions {
+			ids = append(ids, q.ID)
+		}
+		return ids
+	}
+
+	require.Equal(t, []int{2}, list("/questions?status=open"))
+	require.Equal(t, []int{1, 3, 4}, list("/questions?status=closed"))
+	require.Empty(t, list("/questions?status=locked"))
+	require.Equal(t, []int{1, 2, 3, 4}, list("/questions"))
+
+	// открытый вопрос между закрытыми не сокращает страницу
+	w := serve(router, http.MethodGet, "/questions?status=closed&limit=2", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, `</questions?limit=2&after=3&status=closed>; rel="next"`, w.Header().Get("Link"))
+	require.Equal(t, []int{4}, list("/questions?status=closed&limit=2&after=3"))
+
+	require.Equal(t, http.StatusBadRequest, serve(router, http.MethodGet, "/questions?status=archived", "").Code)
+	require.Equal(t, http.StatusBadRequest, serve(router, http.MethodGet, "/questions?status=archived&limit=2", "").Code)
+}
+
+func TestQuestionStatus_Errors(t *testing.T) {
+	router, _ := newMemoryRouter(t, httptransport.Options{})
+
+	require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "/questions", `{"text":"Q"}`).Code)
+
+	for _, tc := range []struct {
+		method, path, body string
+		status             int
+	}{
+		{http.MethodPost, "/questions/1/close", `{"closed_by":"u1","reason":"boring"}`, http.StatusBadRequest},
+		{http.MethodPost, "/questions/1/close", `{"reason":"resolved"}`, http.StatusBadRequest},
+		{http.MethodPost, "/questions/1/close", `{`, http.StatusBadRequest},
+		{http.MethodPost, "/questions/abc/close", `{"closed_by":"u1","reason":"resolved"}`, http.StatusBadRequest},
+		{http.MethodPost, "/questions/42/close", `{"closed_by":"u1","reason":"resolved"}`, http.StatusNotFound},
+		{http.MethodPost, "/questions/42/reopen", "", http.StatusNotFound},
+		{http.MethodGet, "/questions/1/close", "", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/questions/1/reopen", "", http.StatusMethodNotAllowed},
+	} {
+		w := serve(router, tc.method, tc.path, tc.body)
+		require.Equal(t, tc.status, w.Code, "%s %s: %s", tc.method, tc.path, w.Body.String())
+	}
+}
+
+func TestQuestionStatus_LockedByModerator(t *testing.T) {
+	router := newModerationRouter(t, "secret")
+
+	require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "/questions", `{"text":"Q"}`).Code)
+	require.Equal(t, http.StatusOK, serve(router, http.MethodPost, "/questions/1/close", `{"closed_by":"u1","reason":"resolved"}`).Code)
+	require.Equal(t, http.StatusOK, serve(router, http.MethodPost, "/moderation/questions/1/lock", `{"moderator":"mod"}`, adminHeader).Code)
+
+	// блокировку снимает только модератор
+	w := serve(router, http.MethodPost, "/questions/1/reopen", "")
+	require.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	require.Contains(t, w.Body.String(), "question is locked")
+
+	w = serve(router, http.MethodGet, "/questions?status=locked", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var questions []domain.Question
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &questions))
+	require.Len(t, questions, 1)
+	require.Equal(t, 3, questions[0].Version)
+	require.Equal(t, "mod", questions[0].LockedBy)
+	require.NotNil(t, questions[0].LockedAt)
+	// сведения о закрытии блокировка не трогает
+	require.Equal(t, "u1", questions[0].ClosedBy)
+	require.Equal(t, domain.CloseReasonResolved, questions[0].CloseReason)
+
+	require.Equal(t, http.StatusBadRequest, serve(router, http.MethodPost, "/moderation/questions/1/lock", `{"moderator":"mod"}`, adminHeader).Code)
+	require.Equal(t, http.StatusOK, serve(router, http.MethodPost, "/moderation/questions/1/unlock", `{"moderator":"mod"}`, adminHeader).Code)
+	require.Equal(t, http.StatusBadRequest, serve(router, http.MethodPost, "/moderation/questions/1/unlock", `{"moderator":"mod"}`, adminHeader).Code)
+
+	// вопрос был закрыт до блокировки и остаётся закрытым
+	w = serve(router, http.MethodGet, "/questions/1", "")
+	require.Equal(t, `"4"`, w.Header().Get("ETag"))
+	var q domain.Question
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &q))
+	require.Equal(t, domain.QuestionClosed, q.Status)
+	require.Empty(t, q.LockedBy)
+	require.Nil(t, q.LockedAt)
+
+	w = serve(router, http.MethodPost, "/questions/1/answers", `{"user_id":"u2","text":"An ORM"}`)
+	require.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+}
+
+func TestQuestionMerge_CheckDuplicates(t *testing.T) {
+	router, _ := newMemoryRouter(t, httptransport.Options{})
+
+	require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "/questions", `{"text":"How to use GORM with postgres?"}`).Code)
+	require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "/questions", `{"text":"What is a goroutine?"}`).Code)
+
+	w := serve(router, http.MethodPost, "/questions?check_duplicates=true", `{"text":"How to use GORM with mysql?"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var candidates []domain.DuplicateCandidate
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &candidates))
+	require.Len(t, candidates, 1)
+	require.Equal(t, 1, candidates[0].ID)
+	require.Equal(t, domain.QuestionOpen, candidates[0].Status)
+	require.Greater(t, candidates[0].Score, 0.4)
+
+	// режим проверки ничего не создаёт
+	w = serve(router, http.MethodPost, "/questions?check_duplicates=true", `{"text":"Completely unrelated text"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.JSONEq(t, `[]`, w.Body.String())
+	require.Equal(t, http.StatusNotFound, serve(router, http.MethodGet, "/questions/3", "").Code)
+
+	require.Equal(t, http.StatusBadRequest, serve(router, http.MethodPost, "/questions?check_duplicates=maybe", `{"text":"Q"}`).Code)
+
+	// без check_duplicates вопрос создаётся, а похожие приходят вместе с ним
+	w = serve(router, http.MethodPost, "/questions", `{"text":"How to use GORM with sqlite?"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created domain.Question
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	require.Equal(t, 3, created.ID)
+	require.Len(t, created.PossibleDuplicates, 1)
+	require.Equal(t, 1, created.PossibleDuplicates[0].ID)
+
+	w = serve(router, http.MethodPost, "/questions", `{"text":"Completely unrelated text"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	require.NotContains(t, w.Body.String(), "possible_duplicates")
+}
+
+func TestQuestionMerge_MovesAnswersAndRedirects(t *testing.T) {
+	router, _ := newMemoryRouter(t, httptransport.Options{AdminToken: "secret"})
+
+	require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "/questions", `{"text":"What is GORM?"}`).Code)
+	require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "/questions", `{"text":"What's GORM?"}`).Code)
+	require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "/questions/1/answers", `{"user_id":"u1","text":"An ORM"}`).Code)
+	require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "/questions/2/answers", `{"user_id":"u2","text":"A Go ORM"}`).Code)
+
+	w := serve(router, http.MethodPost, "/questions/2/merge", `{"target_id":1,"merged_by":"mod"}`, adminHeader)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var q domain.Question
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &q))
+	require.Equal(t, 1, q.ID)
+	require.Len(t, q.Answers, 2)
+
+	w = serve(router, http.MethodGet, "/questions/2", "")
+	require.Equal(t, http.StatusMovedPermanently, w.Code, w.Body.String())
+	require.Equal(t, "/questions/1", w.Header().Get("Location"))
+	q = domain.Question{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &q))
+	require.Equal(t, domain.QuestionClosed, q.Status)
+	require.Equal(t, domain.CloseReasonDuplicate, q.CloseReason)
+	require.Equal(t, "mod", q.ClosedBy)
+	require.NotNil(t, q.MergedIntoID)
+	require.Empty(t, q.Answers)
+
+	// заглушки не попадают в список и не открываются снова
+	w = serve(router, http.MethodGet, "/questions", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var questions []domain.Question
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &questions))
+	require.Len(t, questions, 1)
+	require.Equal(t, http.StatusConflict, serve(router, http.MethodPost, "/questions/2/reopen", "").Code)
+	require.Equal(t, http.StatusConflict, serve(router, http.MethodPost, "/questions/2/merge", `{"target_id":1,"merged_by":"mod"}`, adminHeader).Code)
+
+	// объединение в заглушку переносит ответы в её целевой вопрос
+	require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "/questions", `{"text":"GORM?"}`).Code)
+	w = serve(router, http.MethodPost, "/questions/3/merge", `{"target_id":2,"merged_by":"mod"}`, adminHeader)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	q = domain.Question{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &q))
+	require.Equal(t, 1, q.ID)
+	require.Equal(t, "/questions/1", serve(router, http.MethodGet, "/questions/3", "").Header().Get("Location"))
+}
+
+func TestQuestionMerge_Errors(t *testing.T) {
+	router, _ := newMemoryRouter(t, httptransport.Options{AdminToken: "secret"})
+
+	require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "/questions", `{"text":"Q1"}`).Code)
+	require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "/questions", `{"text":"Q2"}`).Code)
+
+	require.Equal(t, http.StatusUnauthorized, serve(router, http.MethodPost, "/questions/2/merge", `{"target_id":1,"merged_by":"mod"}`).Code)
+
+	for _, tc := range []struct {
+		method, path, body string
+		status             int
+	}{
+		{http.MethodPost, "/questions/2/merge", `{"target_id":2,"merged_by":"mod"}`, http.StatusBadRequest},
+		{http.MethodPost, "/questions/2/merge", `{"merged_by":"mod"}`, http.StatusBadRequest},
+		{http.MethodPost, "/questions/2/merge", `{"target_id":1}`, http.StatusBadRequest},
+		{http.MethodPost, "/questions/2/merge", `{`, http.StatusBadRequest},
+		{http.MethodPost, "/questions/42/merge", `{"target_id":1,"merged_by":"mod"}`, http.StatusNotFound},
+		{http.MethodPost, "/questions/2/merge", `{"target_id":42,"merged_by":"mod"}`, http.StatusUnprocessableEntity},
+		{http.MethodGet, "/questions/2/merge", "", http.StatusMethodNotAllowed},
+	} {
+		w := serve(router, tc.method, tc.path, tc.body, adminHeader)
+		require.Equal(t, tc.status, w.Code, "%s %s: %s", tc.method, tc.path, w.Body.String())
+	}
+
+	// без ADMIN_TOKEN маршрута нет
+	router, _ = newMemoryRouter(t, httptransport.Options{})
+	require.Equal(t, http.StatusNotFound, serve(router, http.MethodPost, "/questions/1/merge", `{"target_id":2,"merged_by":"mod"}`).Code)
+}
@@ -4,15 +4,96 @@ import (
 	"encoding/json"
 	"net/http"
 	"question-service/internal/transport"
+	"strconv"
+	"time"
 
+	"question-service/internal/db"
 	"question-service/internal/logger"
+	"question-service/internal/realtime"
+	"question-service/internal/repository"
 	"question-service/internal/service"
+	"question-service/internal/transfer"
 )
 
-func NewRouter(qSvc *service.QuestionService, aSvc *service.AnswerService, log *logger.Logger) *http.ServeMux {
-	mux := http.NewServeMux()
+// Options описывает необязательные настройки HTTP-слоя.
+type Options struct {
+	// RequireIfMatch включает строгий режим: изменения и удаления без If-Match
+	// отклоняются с 428 Precondition Required.
+	RequireIfMatch bool
+
+	// BatchMaxItems — сколько элементов принимают POST /questions:batch и
+	// POST /questions/{id}/answers:batch (0 — DefaultBatchMaxItems).
+	BatchMaxItems int
+
+	// Idempotency включает обработку Idempotency-Key для POST /questions,
+	// POST /questions/{id}/answers и пакетных запросов; nil — заголовок игнорируется.
+	Idempotency repository.IdempotencyRepository
+	// IdempotencyTTL — сколько хранится ответ для повторов.
+	IdempotencyTTL time.Duration
+	// IdempotencyLease — сколько выполняющийся запрос держит ключ; потом повтор
+	// выполняется заново. 0 — минута.
+	IdempotencyLease time.Duration
+
+	// Events включает поток SSE GET /questions/{id}/events; nil — маршрут не регистрируется.
+	Events *realtime.Broker
+	// EventsHeartbeat — период комментариев-пингов в потоке событий; 0 — 15 секунд.
+	EventsHeartbeat time.Duration
+	// EventsMaxConnsPerClient — сколько потоков событий может держать один IP (0 — без ограничения).
+	EventsMaxConnsPerClient int
+
+	// WebSocket обслуживает /ws; nil — маршрут не регистрируется. Создаётся снаружи,
+	// чтобы при остановке сервера закрыть соединения через WSHandler.Close.
+	WebSocket *WSHandler
+
+	// GraphQL обслуживает /graphql; nil — маршрут не регистрируется.
+	GraphQL http.Handler
+
+	// Webhooks включает административное управление подписками на /webhooks; nil или
+	// пустой AdminToken — маршруты не регистрируются.
+	Webhooks *service.WebhookService
+
+	// Comments включает комментарии /questions/{id}/comments, /answers/{id}/comments
+	// и /comments/{id}, а также include=comments в GET /questions/{id}; nil — маршруты
+	// не регистрируются.
+	Comments *service.CommentService
+
+	// Transfer включает административные выгрузку GET /export и загрузку POST /import;
+	// nil или пустой AdminToken — маршруты не регистрируются.
+	Transfer *transfer.Service
+
+	// Trash включает административный просмотр корзины GET /trash; nil или пустой
+	// AdminToken — маршрут не регистрируется. Восстановление из корзины доступно всегда.
+	Trash *service.TrashService
+	// Moderation включает жалобы POST /questions/{id}/flags и POST /answers/{id}/flags
+	// и административные маршруты /moderation/...; nil или пустой AdminToken —
+	// маршруты не регистрируются.
+	// Скрытие и блокировка действуют и без них.
+	Moderation *service.ModerationService
+
+	// ReadYourWritesWindow — сколько после изменяющего запроса чтения этого клиента
+	// идут в primary. Клиент узнаётся по cookie, которую ставит изменяющий запрос;
+	// 0 — только чтения внутри самого изменяющего запроса.
+	ReadYourWritesWindow time.Duration
+
+	// AdminToken — токен Bearer, который требуют административные маршруты.
+	// Корзина, модерация, управление вебхуками, выгрузка и загрузка, объединение
+	// вопросов POST /questions/{id}/merge регистрируются только с непустым токеном; с пустым
+	// токеном административный маршрут отвечает 401.
+	AdminToken string
+}
+
+func NewRouter(qSvc *service.QuestionService, aSvc *service.AnswerService, log *logger.Logger, opts Options) http.Handler {
+	h, _ := newRouter(qSvc, aSvc, log, opts)
+	return h
+}
+
+// newRouter собирает роутер и возвращает вместе с ним маршруты, которые он
+// обслуживает. Маршруты записываются при регистрации обработчиков, поэтому список
+// не расходится с роутером; тест сверяет его с openapi.json.
+func newRouter(qSvc *service.QuestionService, aSvc *service.AnswerService, log *logger.Logger, opts Options) (http.Handler, []route) {
+	t := &routeTable{mux: http.NewServeMux()}
 	// healthcheck
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+	t.handle("/health", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
 			transport.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
 			return
@@ -21,35 +102,276 @@ func NewRouter(qSvc *service.QuestionService, aSvc *service.AnswerService, log *
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 		_ = json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
-	})
+	}, route{http.MethodGet, "/health"})
 
 	qh := NewQuestionHandler(qSvc, log)
+	qh.requireIfMatch = opts.RequireIfMatch
 	ah := NewAnswerHandler(aSvc, log)
+	ah.requireIfMatch = opts.RequireIfMatch
+	qh.comments = opts.Comments
+	qh.threads = aSvc
+	qh.adminToken = opts.AdminToken
+	if opts.BatchMaxItems > 0 {
+		qh.batchMaxItems = opts.BatchMaxItems
+		ah.batchMaxItems = opts.BatchMaxItems
+	}
 
-	// /questions (GET, POST)
-	mux.HandleFunc("/questions", qh.HandleQuestions)
+	handleQuestions := qh.HandleQuestions
+	handleCreateAnswer := ah.HandleCreateForQuestion
+	handleCreateQuestions := qh.HandleCreateBatch
+	handleCreateAnswers := ah.HandleCreateBatchForQuestion
+	if opts.Idempotency != nil {
+		idem := &idempotency{repo: opts.Idempotency, ttl: opts.IdempotencyTTL, lease: opts.IdempotencyLease, log: log}
+		if idem.lease <= 0 {
+			idem.lease = defaultIdempotencyLease
+		}
+		handleQuestions = idem.wrap(handleQuestions)
+		handleCreateAnswer = idem.wrap(handleCreateAnswer)
+		handleCreateQuestions = idem.wrap(handleCreateQuestions)
+		handleCreateAnswers = idem.wrap(handleCreateAnswers)
+	}
+
+	t.handle("/questions", handleQuestions,
+		route{http.MethodGet, "/questions"},
+		route{http.MethodPost, "/questions"},
+	)
+	t.handle("/questions:batch", handleCreateQuestions, route{http.MethodPost, "/questions:batch"})
+
+	// /questions/{id}/...: обработчик выбирается по окончанию пути, остальное — /questions/{id}
+	questions := t.suffixes("/questions/")
+	questions.handle("/answers:batch", handleCreateAnswers, route{http.MethodPost, "/questions/{id}/answers:batch"})
+	questions.handle("/answers", handleCreateAnswer, route{http.MethodPost, "/questions/{id}/answers"})
+
+	if opts.Events != nil {
+		eh := NewEventsHandler(qSvc, opts.Events, log, opts.EventsHeartbeat, opts.EventsMaxConnsPerClient)
+		questions.handle("/events", eh.HandleQuestionEvents, route{http.MethodGet, "/questions/{id}/events"})
+	} else {
+		questions.handle("/events", http.NotFound)
+	}
+
+	answers := t.suffixes("/answers/")
+	if opts.Comments != nil {
+		ch := NewCommentHandler(opts.Comments, log)
+		questions.handle("/comments", ch.HandleQuestionComments,
+			route{http.MethodGet, "/questions/{id}/comments"},
+			route{http.MethodPost, "/questions/{id}/comments"},
+		)
+		answers.handle("/comments", ch.HandleAnswerComments,
+			route{http.MethodGet, "/answers/{id}/comments"},
+			route{http.MethodPost, "/answers/{id}/comments"},
+		)
+		t.handle("/comments/", ch.HandleCommentByID, route{http.MethodDelete, "/comments/{id}"})
+	} else {
+		questions.handle("/comments", http.NotFound)
+		answers.handle("/comments", http.NotFound)
+	}
+
+	if opts.Moderation != nil && opts.AdminToken != "" {
+		mh := NewModerationHandler(opts.Moderation, log)
+		mh.adminToken = opts.AdminToken
+		questions.handle("/flags", mh.HandleQuestionFlags, route{http.MethodPost, "/questions/{id}/flags"})
+		answers.handle("/flags", mh.HandleAnswerFlags, route{http.MethodPost, "/answers/{id}/flags"})
+		t.handle("/moderation/queue", mh.HandleQueue, route{http.MethodGet, "/moderation/queue"})
+		t.handle("/moderation/actions", mh.HandleActions, route{http.MethodGet, "/moderation/actions"})
+		t.handle("/moderation/questions/", mh.HandleModerate, route{http.MethodPost, "/moderation/questions/{id}/{action}"})
+		t.handle("/moderation/answers/", mh.HandleModerate, route{http.MethodPost, "/moderation/answers/{id}/{action}"})
+	} else {
+		questions.handle("/flags", http.NotFound)
+		answers.handle("/flags", http.NotFound)
+	}
 
-	mux.HandleFunc("/questions/", func(w http.ResponseWriter, r *http.Request) {
-		// /questions (GET, POST)
+	questions.handle("/close", qh.HandleClose, route{http.MethodPost, "/questions/{id}/close"})
+	questions.handle("/reopen", qh.HandleReopen, route{http.MethodPost, "/questions/{id}/reopen"})
+	if opts.AdminToken != "" {
+		questions.handle("/merge", qh.HandleMerge, route{http.MethodPost, "/questions/{id}/merge"})
+	}
+	questions.handle("/restore", qh.HandleRestore, route{http.MethodPost, "/questions/{id}/restore"})
+	questions.serve(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == "/questions/" {
-			qh.HandleQuestions(w, r)
+			handleQuestions(w, r)
 			return
 		}
+		qh.HandleQuestionByID(w, r)
+	},
+		route{http.MethodGet, "/questions/{id}"},
+		route{http.MethodDelete, "/questions/{id}"},
+	)
 
-		// /questions/{id}/answers (POST)
-		if len(r.URL.Path) > len("/questions/") && hasSuffix(r.URL.Path, "/answers") {
-			ah.HandleCreateForQuestion(w, r)
-			return
+	answers.handle("/restore", ah.HandleRestore, route{http.MethodPost, "/answers/{id}/restore"})
+	answers.serve(ah.HandleAnswerByID,
+		route{http.MethodGet, "/answers/{id}"},
+		route{http.MethodDelete, "/answers/{id}"},
+	)
+
+	if opts.WebSocket != nil {
+		t.handle("/ws", opts.WebSocket.HandleWS, route{http.MethodGet, "/ws"})
+	}
+
+	if opts.GraphQL != nil {
+		t.handle("/graphql", opts.GraphQL.ServeHTTP, route{http.MethodPost, "/graphql"})
+	}
+
+	if opts.Webhooks != nil && opts.AdminToken != "" {
+		wh := NewWebhookHandler(opts.Webhooks, log)
+		wh.adminToken = opts.AdminToken
+		t.handle("/webhooks", wh.HandleWebhooks,
+			route{http.MethodGet, "/webhooks"},
+			route{http.MethodPost, "/webhooks"},
+		)
+		t.handle("/webhooks/", wh.HandleWebhookByID,
+			route{http.MethodGet, "/webhooks/{id}"},
+			route{http.MethodPatch, "/webhooks/{id}"},
+			route{http.MethodDelete, "/webhooks/{id}"},
+			route{http.MethodGet, "/webhooks/{id}/deliveries"},
+		)
+	}
+
+	if opts.Transfer != nil && opts.AdminToken != "" {
+		th := NewTransferHandler(opts.Transfer, log)
+		th.adminToken = opts.AdminToken
+		t.handle("/export", th.HandleExport, route{http.MethodGet, "/export"})
+		t.handle("/import", th.HandleImport, route{http.MethodPost, "/import"})
+	}
+
+	if opts.Trash != nil && opts.AdminToken != "" {
+		th := NewTrashHandler(opts.Trash, log)
+		th.adminToken = opts.AdminToken
+		t.handle("/trash", th.HandleTrash, route{http.MethodGet, "/trash"})
+	}
+
+	t.handle("/openapi.json", handleOpenAPI, route{http.MethodGet, "/openapi.json"})
+	t.handle("/docs", handleDocs, route{http.MethodGet, "/docs"})
+	t.handle("/docs/redoc.standalone.js", handleRedoc, route{http.MethodGet, "/docs/redoc.standalone.js"})
+
+	return readYourWrites(t.mux, opts.ReadYourWritesWindow), t.routes
+}
+
+// route — метод и шаблон пути, который обслуживает роутер.
+type route struct {
+	Method string
+	Path   string
+}
+
+// routeTable регистрирует обработчики в mux и запоминает маршруты, которые они
+// обслуживают. Каждый маршрут должен быть описан в openapi.json — это проверяет тест.
+type routeTable struct {
+	mux    *http.ServeMux
+	routes []route
+}
+
+// handle регистрирует h на шаблоне pattern и запоминает маршруты routes.
+func (t *routeTable) handle(pattern string, h http.HandlerFunc, routes ...route) {
+	t.mux.HandleFunc(pattern, h)
+	t.routes = append(t.routes, routes...)
+}
+
+// suffixes начинает набор обработчиков путей prefix{id}/..., которые выбираются
+// по окончанию пути. Набор регистрируется в mux вызовом serve.
+func (t *routeTable) suffixes(prefix string) *suffixRoutes {
+	return &suffixRoutes{table: t, prefix: prefix}
+}
+
+type suffixRoutes struct {
+	table    *routeTable
+	prefix   string
+	suffixes []string
+	handlers []http.HandlerFunc
+}
+
+// handle отдаёт пути с окончанием suffix обработчику h и запоминает маршруты routes.
+// Окончания проверяются в порядке регистрации. Выключенную возможность регистрируют
+// с http.NotFound без маршрутов, чтобы её путь не ушёл в обработчик по умолчанию.
+func (s *suffixRoutes) handle(suffix string, h http.HandlerFunc, routes ...route) {
+	s.suffixes = append(s.suffixes, suffix)
+	s.handlers = append(s.handlers, h)
+	s.table.routes = append(s.table.routes, routes...)
+}
+
+// serve регистрирует набор на prefix; пути без известного окончания получает fallback.
+func (s *suffixRoutes) serve(fallback http.HandlerFunc, routes ...route) {
+	s.table.handle(s.prefix, func(w http.ResponseWriter, r *http.Request) {
+		if len(r.URL.Path) > len(s.prefix) {
+			for i, suffix := range s.suffixes {
+				if hasSuffix(r.URL.Path, suffix) {
+					s.handlers[i](w, r)
+					return
+				}
+			}
 		}
+		fallback(w, r)
+	}, routes...)
+}
 
-		// /questions/{id} (GET, DELETE)
-		qh.HandleQuestionByID(w, r)
+// primaryCookie — cookie с моментом (Unix, мс), до которого чтения клиента идут в primary.
+const primaryCookie = "qs_read_primary_until"
+
+// readYourWrites направляет все чтения изменяющих запросов в primary: проверки
+// перед записью не должны упираться в отставание реплик. С window > 0 успешный
+// изменяющий запрос ставит клиенту cookie, и его чтения следующие window тоже идут
+// в primary, чтобы он видел свои записи. Чтения остальных клиентов это не затрагивает.
+// Cookie задаёт клиент, поэтому отметка дальше window от текущего момента не действует.
+func readYourWrites(next http.Handler, window time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			if c, err := r.Cookie(primaryCookie); err == nil {
+				until, err := strconv.ParseInt(c.Value, 10, 64)
+				now := time.Now().UnixMilli()
+				if err == nil && now < until && until-now <= window.Milliseconds() {
+					r = r.WithContext(db.WithPrimary(r.Context()))
+				}
+			}
+		default:
+			r = r.WithContext(db.WithPrimary(r.Context()))
+			if window > 0 {
+				cw := &primaryCookieWriter{ResponseWriter: w, window: window}
+				next.ServeHTTP(cw, r)
+				// обработчик без тела отвечает 200 неявно
+				if !cw.wroteHeader {
+					cw.WriteHeader(http.StatusOK)
+				}
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
 	})
+}
 
-	// /answers/{id} (GET, DELETE)
-	mux.HandleFunc("/answers/", ah.HandleAnswerByID)
+// primaryCookieWriter ставит cookie primaryCookie перед заголовками ответа, если
+// запрос выполнен успешно: неудачная запись ничего не изменила.
+type primaryCookieWriter struct {
+	http.ResponseWriter
+	window      time.Duration
+	wroteHeader bool
+}
+
+func (w *primaryCookieWriter) WriteHeader(status int) {
+	if !w.wroteHeader && status >= 200 {
+		w.wroteHeader = true
+		if status < 400 {
+			http.SetCookie(w.ResponseWriter, &http.Cookie{
+				Name:     primaryCookie,
+				Value:    strconv.FormatInt(time.Now().Add(w.window).UnixMilli(), 10),
+				Path:     "/",
+				MaxAge:   int((w.window + time.Second - 1) / time.Second),
+				HttpOnly: true,
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *primaryCookieWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(p)
+}
 
-	return mux
+// Unwrap открывает исходный ResponseWriter для http.ResponseController.
+func (w *primaryCookieWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
 }
 
 // вместо strings.HasSuffix
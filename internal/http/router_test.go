@@ -0,0 +1,60 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"question-service/internal/db"
+	httptransport "question-service/internal/http"
+)
+
+func TestReadYourWrites_StickyPerClient(t *testing.T) {
+	var primary bool
+	handler := httptransport.ReadYourWrites(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primary = db.UsePrimary(r.Context())
+	}), time.Minute)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/questions", nil))
+	require.True(t, primary)
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+
+	// клиент с cookie читает из primary
+	req := httptest.NewRequest(http.MethodGet, "/questions", nil)
+	req.AddCookie(cookies[0])
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	require.True(t, primary)
+
+	// остальные клиенты — из реплик
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/questions", nil))
+	require.False(t, primary)
+
+	// истёкшая отметка не действует
+	req = httptest.NewRequest(http.MethodGet, "/questions", nil)
+	req.AddCookie(&http.Cookie{Name: cookies[0].Name, Value: "1"})
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	require.False(t, primary)
+
+	// отметку дальше окна клиент поставил сам — она тоже не действует
+	req = httptest.NewRequest(http.MethodGet, "/questions", nil)
+	req.AddCookie(&http.Cookie{Name: cookies[0].Name, Value: strconv.FormatInt(time.Now().Add(time.Hour).UnixMilli(), 10)})
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	require.False(t, primary)
+}
+
+func TestReadYourWrites_NoCookieOnFailedWrite(t *testing.T) {
+	handler := httptransport.ReadYourWrites(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad request", http.StatusBadRequest)
+	}), time.Minute)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/questions", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Empty(t, w.Result().Cookies())
+}
@@ -0,0 +1,87 @@
+package http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"question-service/internal/domain"
+	httptransport "question-service/internal/http"
+)
+
+func newThreadRouter(t *testing.T, maxDepth int) http.Handler {
+	t.Helper()
+
+	app := newMemoryApp(t)
+	app.aSvc.SetMaxReplyDepth(maxDepth)
+	return app.router(httptransport.Options{})
+}
+
+func TestReplies_ThreadInQuestion(t *testing.T) {
+	router := newThreadRouter(t, 2)
+
+	require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "/questions", `{"text":"What is GORM?"}`).Code)
+	for _, body := range []string{
+		`{"user_id":"u1","text":"An ORM"}`,                          // 1
+		`{"user_id":"u2","text":"A library"}`,                       // 2
+		`{"user_id":"u3","text":"Which one?","parent_answer_id":1}`, // 3
+		`{"user_id":"u1","text":"For Go","parent_answer_id":3}`,     // 4
+		`{"user_id":"u4","text":"Agreed","parent_answer_id":1}`,     // 5
+	} {
+		w := serve(router, http.MethodPost, "/questions/1/answers", body)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	w := serve(router, http.MethodGet, "/questions/1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var q domain.Question
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &q))
+
+	var ids, depths []int
+	for _, a := range q.Answers {
+		ids = append(ids, a.ID)
+		depths = append(depths, a.Depth)
+	}
+	require.Equal(t, []int{1, 3, 4, 5, 2}, ids)
+	require.Equal(t, []int{0, 1, 2, 1, 0}, depths)
+	require.Equal(t, 3, *q.Answers[2].ParentAnswerID)
+
+	// удаление ответа удаляет и реплики на него
+	require.Equal(t, http.StatusNoContent, serve(router, http.MethodDelete, "/answers/1", "").Code)
+	require.Equal(t, http.StatusNotFound, serve(router, http.MethodGet, "/answers/4", "").Code)
+
+	w = serve(router, http.MethodGet, "/questions/1", "")
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &q))
+	require.Len(t, q.Answers, 1)
+	require.Equal(t, 2, q.Answers[0].ID)
+}
+
+func TestReplies_Rejected(t *testing.T) {
+	router := newThreadRouter(t, 1)
+
+	require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "/questions", `{"text":"First"}`).Code)
+	require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "/questions", `{"text":"Second"}`).Code)
+	require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "/questions/1/answers", `{"user_id":"u1","text":"a"}`).Code)
+	require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "/questions/1/answers", `{"user_id":"u1","text":"b","parent_answer_id":1}`).Code)
+
+	for _, tc := range []struct {
+		path, body string
+		status     int
+		err        string
+	}{
+		{"/questions/1/answers", `{"user_id":"u1","text":"c","parent_answer_id":42}`, http.StatusUnprocessableEntity, "parent answer not found"},
+		{"/questions/2/answers", `{"user_id":"u1","text":"c","parent_answer_id":1}`, http.StatusUnprocessableEntity, "parent answer belongs to another question"},
+		{"/questions/1/answers", `{"user_id":"u1","text":"c","parent_answer_id":2}`, http.StatusUnprocessableEntity, "reply is nested too deep"},
+		{"/questions/42/answers", `{"user_id":"u1","text":"c","parent_answer_id":1}`, http.StatusNotFound, "question not found"},
+	} {
+		w := serve(router, http.MethodPost, tc.path, tc.body)
+		require.Equal(t, tc.status, w.Code, tc.body)
+		require.Contains(t, w.Body.String(), tc.err)
+	}
+
+	w := serve(router, http.MethodPost, "/questions/1/answers:batch", `{"items":[{"user_id":"u1","text":"c","parent_answer_id":1}]}`)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "replies cannot be created in a batch")
+}
@@ -0,0 +1,164 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"question-service/internal/logger"
+	"question-service/internal/transfer"
+	"question-service/internal/transport"
+)
+
+type TransferHandler struct {
+	svc *transfer.Service
+	log *logger.Logger
+
+	// adminToken — токен Bearer для выгрузки и загрузки; пустой — без проверки.
+	adminToken string
+}
+
+func NewTransferHandler(svc *transfer.Service, log *logger.Logger) *TransferHandler {
+	return &TransferHandler{svc: svc, log: log}
+}
+
+// HandleExport обрабатывает /export (GET): отдаёт все вопросы с ответами
+// в формате из параметра format (по умолчанию ndjson).
+func (h *TransferHandler) HandleExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		transport.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if !h.authorize(w, r) {
+		return
+	}
+
+	format := transfer.FormatNDJSON
+	if v := r.URL.Query().Get("format"); v != "" {
+		f, err := transfer.ParseFormat(v)
+		if err != nil {
+			transport.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		format = f
+	}
+
+	filename := "questions-" + time.Now().UTC().Format("20060102-150405") + format.Ext()
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	out := &writeTracker{ResponseWriter: w}
+	n, err := h.svc.Export(r.Context(), out, format)
+	if err != nil {
+		h.log.Error("failed to export questions",
+			zap.Error(err),
+			zap.Int("exported", n),
+		)
+		if !out.wrote {
+			w.Header().Del("Content-Disposition")
+			transport.WriteError(w, http.StatusInternalServerError, "failed to export questions")
+			return
+		}
+		// выгрузка уже началась: обрываем ответ, чтобы клиент не принял его за полный
+		panic(http.ErrAbortHandler)
+	}
+
+	h.log.Info("questions exported",
+		zap.String("format", string(format)),
+		zap.Int("count", n),
+	)
+}
+
+// HandleImport обрабатывает /import (POST). Формат берётся из параметра format
+// или заголовка Content-Type; dry_run=true проверяет данные, ничего не сохраняя.
+func (h *TransferHandler) HandleImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		transport.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if !h.authorize(w, r) {
+		return
+	}
+	defer r.Body.Close()
+
+	query := r.URL.Query()
+
+	var format transfer.Format
+	if v := query.Get("format"); v != "" {
+		f, err := transfer.ParseFormat(v)
+		if err != nil {
+			transport.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		format = f
+	} else if f, ok := transfer.FormatFromContentType(r.Header.Get("Content-Type")); ok {
+		format = f
+	} else {
+		transport.WriteError(w, http.StatusUnsupportedMediaType, "unsupported content type: use application/x-ndjson, text/csv or application/json, or set format")
+		return
+	}
+
+	var opts transfer.ImportOptions
+	if v := query.Get("dry_run"); v != "" {
+		dryRun, err := strconv.ParseBool(v)
+		if err != nil {
+			transport.WriteError(w, http.StatusBadRequest, "invalid dry_run")
+			return
+		}
+		opts.DryRun = dryRun
+	}
+
+	report, err := h.svc.Import(r.Context(), r.Body, format, opts)
+	if err != nil {
+		if errors.Is(err, transfer.ErrInvalidInput) {
+			h.log.Info("invalid import input",
+				zap.Error(err),
+				zap.String("format", string(format)),
+			)
+			transport.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.log.Error("failed to import questions",
+			zap.Error(err),
+			zap.String("format", string(format)),
+		)
+		transport.WriteError(w, http.StatusInternalServerError, "failed to import questions")
+		return
+	}
+
+	h.log.Info("questions imported",
+		zap.String("format", string(format)),
+		zap.Bool("dry_run", report.DryRun),
+		zap.Int("created", report.Created),
+		zap.Int("updated", report.Updated),
+		zap.Int("unchanged", report.Unchanged),
+		zap.Int("failed", report.Failed),
+	)
+	transport.WriteJSON(w, http.StatusOK, report)
+}
+
+func (h *TransferHandler) authorize(w http.ResponseWriter, r *http.Request) bool {
+	if !authorizeAdmin(w, r, h.adminToken) {
+		h.log.Warn("unauthorized transfer request",
+			zap.String("path", r.URL.Path),
+			zap.String("remote_addr", r.RemoteAddr),
+		)
+		return false
+	}
+	return true
+}
+
+// writeTracker запоминает, начал ли обработчик писать тело ответа.
+type writeTracker struct {
+	http.ResponseWriter
+	wrote bool
+}
+
+func (w *writeTracker) Write(p []byte) (int, error) {
+	w.wrote = true
+	return w.ResponseWriter.Write(p)
+}
@@ -0,0 +1,109 @@
+package http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	httptransport "question-service/internal/http"
+	"question-service/internal/transfer"
+)
+
+func newTransferRouter(t *testing.T) http.Handler {
+	t.Helper()
+
+	app := newMemoryApp(t)
+	return app.router(httptransport.Options{
+		Transfer:   transfer.NewService(app.questions, app.answers, app.store),
+		AdminToken: "secret",
+	})
+}
+
+func TestTransfer_ImportThenExport(t *testing.T) {
+	router := newTransferRouter(t)
+
+	body := `{"external_id":"q-1","text":"First","answers":[{"user_id":"u1","text":"A1"}]}
+{"text":""}
+{"text":"Second"}`
+	req := httptest.NewRequest(http.MethodPost, "/import", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var report transfer.ImportReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	require.Equal(t, 2, report.Created)
+	require.Equal(t, 1, report.Answers)
+	require.Equal(t, []transfer.LineError{{Line: 2, Error: "text is required"}}, report.Errors)
+
+	w = serve(router, http.MethodGet, "/export?format=csv", "", adminHeader)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	require.Contains(t, w.Header().Get("Content-Disposition"), ".csv")
+
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	require.Len(t, lines, 3)
+	require.True(t, strings.HasPrefix(lines[1], "1,q-1,First,"))
+	require.True(t, strings.HasPrefix(lines[2], "2,,Second,"))
+}
+
+func TestTransfer_DryRunDoesNotSave(t *testing.T) {
+	router := newTransferRouter(t)
+
+	w := serve(router, http.MethodPost, "/import?format=json&dry_run=true", `[{"text":"Q"}]`, adminHeader)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `{"dry_run":true,"created":1,"updated":0,"unchanged":0,"answers":0,"failed":0,"errors":[]}`, w.Body.String())
+
+	w = serve(router, http.MethodGet, "/export?format=json", "", adminHeader)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "[]\n", w.Body.String())
+}
+
+func TestTransfer_Errors(t *testing.T) {
+	router := newTransferRouter(t)
+
+	cases := []struct {
+		method, target, contentType, body string
+		status                            int
+	}{
+		{http.MethodGet, "/export?format=xml", "", "", http.StatusBadRequest},
+		{http.MethodPost, "/import", "text/plain", "x", http.StatusUnsupportedMediaType},
+		{http.MethodPost, "/import?format=csv&dry_run=maybe", "", "question_text\nq\n", http.StatusBadRequest},
+		{http.MethodPost, "/import?format=json", "", `{"text":"not an array"}`, http.StatusBadRequest},
+		{http.MethodDelete, "/export", "", "", http.StatusMethodNotAllowed},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+		if tc.contentType != "" {
+			req.Header.Set("Content-Type", tc.contentType)
+		}
+		req.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, tc.status, w.Code, "%s %s: %s", tc.method, tc.target, w.Body.String())
+	}
+}
+
+func TestTransfer_AdminToken(t *testing.T) {
+	router := newTransferRouter(t)
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/export", nil),
+		httptest.NewRequest(http.MethodPost, "/import?format=json", strings.NewReader(`[{"text":"Q"}]`)),
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusUnauthorized, w.Code, req.URL.Path)
+	}
+
+	// без токена маршруты не регистрируются
+	app := newMemoryApp(t)
+	router = app.router(httptransport.Options{Transfer: transfer.NewService(app.questions, app.answers, app.store)})
+	require.Equal(t, http.StatusNotFound, serve(router, http.MethodGet, "/export", "").Code)
+}
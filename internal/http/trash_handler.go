@@ -0,0 +1,184 @@
+package http
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"question-service/internal/logger"
+	"question-service/internal/service"
+	"question-service/internal/transport"
+)
+
+type TrashHandler struct {
+	svc *service.TrashService
+	log *logger.Logger
+
+	// adminToken — токен Bearer для доступа к корзине; пустой — без проверки.
+	adminToken string
+}
+
+func NewTrashHandler(svc *service.TrashService, log *logger.Logger) *TrashHandler {
+	return &TrashHandler{svc: svc, log: log}
+}
+
+// HandleTrash обрабатывает GET /trash. Вопросы и ответы листаются страницами, как
+// GET /questions: limit — сколько вопросов и сколько ответов вернуть, after_question
+// и after_answer — id последних вопроса и ответа предыдущей страницы. Ссылка на
+// следующую страницу передаётся в заголовке Link с rel="next".
+func (h *TrashHandler) HandleTrash(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		transport.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if !authorizeAdmin(w, r, h.adminToken) {
+		h.log.Warn("unauthorized trash request", zap.String("remote_addr", r.RemoteAddr))
+		return
+	}
+
+	query := r.URL.Query()
+	page := service.TrashPage{Limit: defaultQuestionsPageSize}
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxQuestionsPageSize {
+			transport.WriteError(w, http.StatusBadRequest, "limit must be between 1 and 100")
+			return
+		}
+		page.Limit = n
+	}
+	for name, dst := range map[string]*int{"after_question": &page.AfterQuestion, "after_answer": &page.AfterAnswer} {
+		if v := query.Get(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				transport.WriteError(w, http.StatusBadRequest, "invalid "+name)
+				return
+			}
+			*dst = n
+		}
+	}
+
+	trash, hasNext, err := h.svc.List(r.Context(), page)
+	if err != nil {
+		h.log.Error("failed to list trash", zap.Error(err))
+		transport.WriteError(w, http.StatusInternalServerError, "failed to list trash")
+		return
+	}
+
+	if hasNext {
+		// закончившийся список остаётся на прежней позиции
+		if n := len(trash.Questions); n > 0 {
+			page.AfterQuestion = trash.Questions[n-1].ID
+		}
+		if n := len(trash.Answers); n > 0 {
+			page.AfterAnswer = trash.Answers[n-1].ID
+		}
+		next := fmt.Sprintf("/trash?limit=%d&after_question=%d&after_answer=%d", page.Limit, page.AfterQuestion, page.AfterAnswer)
+		w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"next\"", next))
+	}
+
+	h.log.Info("trash listed",
+		zap.Int("questions", len(trash.Questions)),
+		zap.Int("answers", len(trash.Answers)),
+	)
+	transport.WriteJSON(w, http.StatusOK, trash)
+}
+
+// HandleRestore обрабатывает POST /questions/{id}/restore
+func (h *QuestionHandler) HandleRestore(w http.ResponseWriter, r *http.Request) {
+	id, ok := actionID(w, r, "/questions/", "restore", "question", h.log)
+	if !ok {
+		return
+	}
+
+	q, err := h.svc.RestoreQuestion(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrQuestionNotFound) {
+			h.log.Info("attempt to restore question that is not in trash", zap.Int("question_id", id))
+			transport.WriteError(w, http.StatusNotFound, "question not found in trash")
+			return
+		}
+		h.log.Error("failed to restore question", zap.Error(err), zap.Int("question_id", id))
+		transport.WriteError(w, http.StatusInternalServerError, "failed to restore question")
+		return
+	}
+
+	h.log.Info("question restored",
+		zap.Int("question_id", id),
+		zap.Int("answers", len(q.Answers)),
+	)
+	w.Header().Set("ETag", etag(q.Version))
+	transport.WriteJSON(w, http.StatusOK, q)
+}
+
+// HandleRestore обрабатывает POST /answers/{id}/restore
+func (h *AnswerHandler) HandleRestore(w http.ResponseWriter, r *http.Request) {
+	id, ok := actionID(w, r, "/answers/", "restore", "answer", h.log)
+	if !ok {
+		return
+	}
+
+	a, err := h.svc.RestoreAnswer(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrAnswerNotFound):
+			h.log.Info("attempt to restore answer that is not in trash", zap.Int("answer_id", id))
+			transport.WriteError(w, http.StatusNotFound, "answer not found in trash")
+		case errors.Is(err, service.ErrParentDeleted):
+			h.log.Info("attempt to restore answer with deleted parent", zap.Int("answer_id", id))
+			transport.WriteError(w, http.StatusConflict, err.Error())
+		default:
+			h.log.Error("failed to restore answer", zap.Error(err), zap.Int("answer_id", id))
+			transport.WriteError(w, http.StatusInternalServerError, "failed to restore answer")
+		}
+		return
+	}
+
+	h.log.Info("answer restored",
+		zap.Int("answer_id", id),
+		zap.Int("question_id", a.QuestionID),
+	)
+	transport.WriteJSON(w, http.StatusOK, a)
+}
+
+// actionID проверяет метод POST и разбирает id из пути вида {prefix}{id}/{action}.
+// При ошибке ответ уже записан.
+func actionID(w http.ResponseWriter, r *http.Request, prefix, action, kind string, log *logger.Logger) (int, bool) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, prefix), "/")
+	if len(parts) != 2 || parts[1] != action {
+		http.NotFound(w, r)
+		return 0, false
+	}
+	if r.Method != http.MethodPost {
+		transport.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return 0, false
+	}
+
+	id, err := strconv.Atoi(parts[0])
+	if err != nil || id <= 0 {
+		log.Warn("invalid "+kind+" id",
+			zap.String("path", r.URL.Path),
+			zap.String("id_raw", parts[0]),
+		)
+		transport.WriteError(w, http.StatusBadRequest, "invalid "+kind+" id")
+		return 0, false
+	}
+	return id, true
+}
+
+// authorizeAdmin проверяет заголовок Authorization: Bearer token административного
+// маршрута и при несовпадении отвечает 401. С пустым token в доступе отказывается
+// всегда.
+func authorizeAdmin(w http.ResponseWriter, r *http.Request, token string) bool {
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+		transport.WriteError(w, http.StatusUnauthorized, "admin token required")
+		return false
+	}
+	return true
+}
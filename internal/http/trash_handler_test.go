@@ -0,0 +1,159 @@
+package http_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"question-service/internal/domain"
+	httptransport "question-service/internal/http"
+	"question-service/internal/service"
+)
+
+func newTrashRouter(t *testing.T) http.Handler {
+	t.Helper()
+
+	app := newMemoryApp(t)
+	return app.router(httptransport.Options{
+		Trash:      service.NewTrashService(app.questions, app.answers),
+		AdminToken: "secret",
+	})
+}
+
+func TestTrash_RestoreQuestion(t *testing.T) {
+	router := newTrashRouter(t)
+
+	require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "/questions", `{"text":"What is GORM?"}`).Code)
+	require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "/questions/1/answers", `{"user_id":"u1","text":"An ORM"}`).Code)
+	require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "/questions/1/answers", `{"user_id":"u2","text":"Spam"}`).Code)
+
+	// ответ 2 удалён раньше вопроса и вместе с ним не восстанавливается
+	require.Equal(t, http.StatusNoContent, serve(router, http.MethodDelete, "/answers/2", "").Code)
+	require.Equal(t, http.StatusNoContent, serve(router, http.MethodDelete, "/questions/1", "").Code)
+	require.Equal(t, http.StatusNotFound, serve(router, http.MethodGet, "/questions/1", "").Code)
+	require.Equal(t, http.StatusNotFound, serve(router, http.MethodGet, "/answers/1", "").Code)
+
+	w := serve(router, http.MethodPost, "/answers/2/restore", "")
+	require.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+
+	w = serve(router, http.MethodGet, "/trash", "", adminHeader)
+	require.Equal(t, http.StatusOK, w.Code)
+	var trash domain.Trash
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &trash))
+	require.Len(t, trash.Questions, 1)
+	require.Empty(t, trash.Answers)
+
+	w = serve(router, http.MethodPost, "/questions/1/restore", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var q domain.Question
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &q))
+	require.Len(t, q.Answers, 1)
+	require.Equal(t, 1, q.Answers[0].ID)
+	require.NotEmpty(t, w.Header().Get("ETag"))
+
+	require.Equal(t, http.StatusOK, serve(router, http.MethodGet, "/questions/1", "").Code)
+	require.Equal(t, http.StatusNotFound, serve(router, http.MethodPost, "/questions/1/restore", "").Code)
+
+	w = serve(router, http.MethodGet, "/trash", "", adminHeader)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &trash))
+	require.Empty(t, trash.Questions)
+	require.Len(t, trash.Answers, 1)
+	require.Equal(t, 2, trash.Answers[0].ID)
+
+	w = serve(router, http.MethodPost, "/answers/2/restore", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Equal(t, http.StatusOK, serve(router, http.MethodGet, "/answers/2", "").Code)
+}
+
+func TestTrash_Pages(t *testing.T) {
+	router := newTrashRouter(t)
+
+	for i, text := range []string{"What is GORM?", "How do goroutines work?", "Why use channels?"} {
+		require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "/questions", `{"text":"`+text+`"}`).Code)
+		path := fmt.Sprintf("/questions/%d/answers", i+1)
+		require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, path, `{"user_id":"u1","text":"Answer"}`).Code)
+	}
+	require.Equal(t, http.StatusNoContent, serve(router, http.MethodDelete, "/answers/1", "").Code)
+	require.Equal(t, http.StatusNoContent, serve(router, http.MethodDelete, "/answers/2", "").Code)
+	require.Equal(t, http.StatusNoContent, serve(router, http.MethodDelete, "/questions/3", "").Code)
+
+	w := serve(router, http.MethodGet, "/trash?limit=1", "", adminHeader)
+	require.Equal(t, http.StatusOK, w.Code)
+	var trash domain.Trash
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &trash))
+	require.Len(t, trash.Questions, 1)
+	require.Equal(t, 3, trash.Questions[0].ID)
+	require.Len(t, trash.Answers, 1)
+	require.Equal(t, 1, trash.Answers[0].ID)
+	require.Equal(t, `</trash?limit=1&after_question=3&after_answer=1>; rel="next"`, w.Header().Get("Link"))
+
+	w = serve(router, http.MethodGet, "/trash?limit=1&after_question=3&after_answer=1", "", adminHeader)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &trash))
+	require.Empty(t, trash.Questions)
+	require.Len(t, trash.Answers, 1)
+	require.Equal(t, 2, trash.Answers[0].ID)
+	require.Empty(t, w.Header().Get("Link"))
+
+	for _, query := range []string{"limit=0", "limit=101", "after_question=x", "after_answer=-1"} {
+		require.Equal(t, http.StatusBadRequest, serve(router, http.MethodGet, "/trash?"+query, "", adminHeader).Code, query)
+	}
+}
+
+func TestTrash_Errors(t *testing.T) {
+	router := newTrashRouter(t)
+
+	require.Equal(t, http.StatusCreated, serve(router, http.MethodPost, "/questions", `{"text":"Q"}`).Code)
+
+	for _, tc := range []struct {
+		method, path string
+		status       int
+	}{
+		{http.MethodPost, "/questions/1/restore", http.StatusNotFound},
+		{http.MethodPost, "/questions/42/restore", http.StatusNotFound},
+		{http.MethodPost, "/answers/42/restore", http.StatusNotFound},
+		{http.MethodPost, "/questions/abc/restore", http.StatusBadRequest},
+		{http.MethodGet, "/questions/1/restore", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/trash", http.StatusMethodNotAllowed},
+	} {
+		w := serve(router, tc.method, tc.path, "", adminHeader)
+		require.Equal(t, tc.status, w.Code, "%s %s: %s", tc.method, tc.path, w.Body.String())
+	}
+}
+
+func TestTrash_AdminToken(t *testing.T) {
+	router := newTrashRouter(t)
+
+	w := serve(router, http.MethodGet, "/trash", "")
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	require.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+
+	for _, auth := range []string{"Bearer wrong", "secret", "Basic c2VjcmV0"} {
+		req := httptest.NewRequest(http.MethodGet, "/trash", nil)
+		req.Header.Set("Authorization", auth)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusUnauthorized, w.Code, auth)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/trash", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `{"questions":[],"answers":[]}`, w.Body.String())
+}
+
+func TestTrash_DisabledWithoutOption(t *testing.T) {
+	router, _ := newMemoryRouter(t, httptransport.Options{})
+	require.Equal(t, http.StatusNotFound, serve(router, http.MethodGet, "/trash", "").Code)
+
+	// без токена корзина не открывается всем подряд
+	app := newMemoryApp(t)
+	router = app.router(httptransport.Options{Trash: service.NewTrashService(app.questions, app.answers)})
+	require.Equal(t, http.StatusNotFound, serve(router, http.MethodGet, "/trash", "").Code)
+}
@@ -0,0 +1,249 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"question-service/internal/domain"
+	"question-service/internal/logger"
+	"question-service/internal/service"
+	"question-service/internal/transport"
+)
+
+type WebhookHandler struct {
+	svc *service.WebhookService
+	log *logger.Logger
+
+	// adminToken — токен Bearer для управления подписками; пустой — без проверки.
+	adminToken string
+}
+
+func NewWebhookHandler(svc *service.WebhookService, log *logger.Logger) *WebhookHandler {
+	return &WebhookHandler{svc: svc, log: log}
+}
+
+type webhookRequest struct {
+	URL        *string  `json:"url"`
+	EventTypes []string `json:"event_types"`
+	Secret     *string  `json:"secret"`
+	Active     *bool    `json:"active"`
+}
+
+func (r webhookRequest) input() service.WebhookInput {
+	return service.WebhookInput{
+		URL:        r.URL,
+		EventTypes: r.EventTypes,
+		Secret:     r.Secret,
+		Active:     r.Active,
+	}
+}
+
+// createdWebhookResponse — ответ на создание подписки: единственное место,
+// где клиенту возвращается секрет.
+type createdWebhookResponse struct {
+	*domain.WebhookSubscription
+	Secret string `json:"secret"`
+}
+
+// HandleWebhooks обрабатывает /webhooks (GET, POST)
+func (h *WebhookHandler) HandleWebhooks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		transport.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if !h.authorize(w, r) {
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		h.listWebhooks(w, r)
+		return
+	}
+	h.createWebhook(w, r)
+}
+
+// HandleWebhookByID обрабатывает /webhooks/{id} (GET, PATCH, DELETE)
+// и /webhooks/{id}/deliveries (GET)
+func (h *WebhookHandler) HandleWebhookByID(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/webhooks/")
+	idStr, rest, _ := strings.Cut(path, "/")
+	if idStr == "" || (rest != "" && rest != "deliveries") {
+		http.NotFound(w, r)
+		return
+	}
+
+	allowed := r.Method == http.MethodGet
+	if rest == "" {
+		allowed = allowed || r.Method == http.MethodPatch || r.Method == http.MethodDelete
+	}
+	if !allowed {
+		transport.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if !h.authorize(w, r) {
+		return
+	}
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		h.log.Warn("invalid webhook id",
+			zap.String("path", r.URL.Path),
+			zap.String("id_raw", idStr),
+		)
+		transport.WriteError(w, http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	if rest == "deliveries" {
+		h.listDeliveries(w, r, id)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.getWebhook(w, r, id)
+	case http.MethodPatch:
+		h.updateWebhook(w, r, id)
+	default:
+		h.deleteWebhook(w, r, id)
+	}
+}
+
+func (h *WebhookHandler) authorize(w http.ResponseWriter, r *http.Request) bool {
+	if !authorizeAdmin(w, r, h.adminToken) {
+		h.log.Warn("unauthorized webhook request",
+			zap.String("path", r.URL.Path),
+			zap.String("remote_addr", r.RemoteAddr),
+		)
+		return false
+	}
+	return true
+}
+
+func (h *WebhookHandler) createWebhook(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var req webhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Warn("invalid json in create webhook",
+			zap.Error(err),
+			zap.String("path", r.URL.Path),
+		)
+		transport.WriteError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	sub, err := h.svc.CreateSubscription(r.Context(), req.input())
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidWebhook) {
+			transport.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.log.Error("failed to create webhook",
+			zap.Error(err),
+		)
+		transport.WriteError(w, http.StatusInternalServerError, "failed to create webhook")
+		return
+	}
+
+	h.log.Info("webhook created",
+		zap.Int64("webhook_id", sub.ID),
+		zap.String("url", sub.URL),
+	)
+	transport.WriteJSON(w, http.StatusCreated, createdWebhookResponse{WebhookSubscription: sub, Secret: sub.Secret})
+}
+
+func (h *WebhookHandler) listWebhooks(w http.ResponseWriter, r *http.Request) {
+	subs, err := h.svc.ListSubscriptions(r.Context())
+	if err != nil {
+		h.log.Error("failed to list webhooks",
+			zap.Error(err),
+		)
+		transport.WriteError(w, http.StatusInternalServerError, "failed to list webhooks")
+		return
+	}
+	if subs == nil {
+		subs = []domain.WebhookSubscription{}
+	}
+	transport.WriteJSON(w, http.StatusOK, subs)
+}
+
+func (h *WebhookHandler) getWebhook(w http.ResponseWriter, r *http.Request, id int64) {
+	sub, err := h.svc.GetSubscription(r.Context(), id)
+	if err != nil {
+		h.writeError(w, err, id, "failed to get webhook")
+		return
+	}
+	transport.WriteJSON(w, http.StatusOK, sub)
+}
+
+func (h *WebhookHandler) updateWebhook(w http.ResponseWriter, r *http.Request, id int64) {
+	defer r.Body.Close()
+
+	var req webhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Warn("invalid json in update webhook",
+			zap.Error(err),
+			zap.Int64("webhook_id", id),
+		)
+		transport.WriteError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	sub, err := h.svc.UpdateSubscription(r.Context(), id, req.input())
+	if err != nil {
+		h.writeError(w, err, id, "failed to update webhook")
+		return
+	}
+
+	h.log.Info("webhook updated",
+		zap.Int64("webhook_id", id),
+		zap.Bool("active", sub.Active),
+	)
+	transport.WriteJSON(w, http.StatusOK, sub)
+}
+
+func (h *WebhookHandler) deleteWebhook(w http.ResponseWriter, r *http.Request, id int64) {
+	if err := h.svc.DeleteSubscription(r.Context(), id); err != nil {
+		h.writeError(w, err, id, "failed to delete webhook")
+		return
+	}
+
+	h.log.Info("webhook deleted",
+		zap.Int64("webhook_id", id),
+	)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *WebhookHandler) listDeliveries(w http.ResponseWriter, r *http.Request, id int64) {
+	deliveries, err := h.svc.ListDeliveries(r.Context(), id)
+	if err != nil {
+		h.writeError(w, err, id, "failed to list webhook deliveries")
+		return
+	}
+	if deliveries == nil {
+		deliveries = []domain.WebhookDelivery{}
+	}
+	transport.WriteJSON(w, http.StatusOK, deliveries)
+}
+
+// writeError отвечает на ошибку сервиса подписок: 404, 400 или 500 с message.
+func (h *WebhookHandler) writeError(w http.ResponseWriter, err error, id int64, message string) {
+	switch {
+	case errors.Is(err, service.ErrWebhookNotFound):
+		transport.WriteError(w, http.StatusNotFound, "webhook not found")
+	case errors.Is(err, service.ErrInvalidWebhook):
+		transport.WriteError(w, http.StatusBadRequest, err.Error())
+	default:
+		h.log.Error(message,
+			zap.Error(err),
+			zap.Int64("webhook_id", id),
+		)
+		transport.WriteError(w, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,100 @@
+package http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	httptransport "question-service/internal/http"
+	"question-service/internal/repository"
+	"question-service/internal/service"
+)
+
+func newWebhookRouter(t *testing.T) http.Handler {
+	t.Helper()
+
+	store := repository.NewMemoryStore()
+	router, _ := newMemoryRouter(t, httptransport.Options{
+		Webhooks:   service.NewWebhookService(repository.NewMemoryWebhookRepository(store)),
+		AdminToken: "secret",
+	})
+	return router
+}
+
+func TestWebhooks_CreateReturnsSecretOnce(t *testing.T) {
+	router := newWebhookRouter(t)
+
+	body := `{"url":"https://203.0.113.10/hook","event_types":["question.created"]}`
+	w := serve(router, http.MethodPost, "/webhooks", body, adminHeader)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var created map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	require.NotEmpty(t, created["secret"])
+	require.Equal(t, true, created["active"])
+
+	w = serve(router, http.MethodGet, "/webhooks/1", "", adminHeader)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	require.NotContains(t, got, "secret")
+
+	w = serve(router, http.MethodGet, "/webhooks/1/deliveries", "", adminHeader)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `[]`, w.Body.String())
+}
+
+func TestWebhooks_Validation(t *testing.T) {
+	router := newWebhookRouter(t)
+
+	for _, body := range []string{
+		`{"url":"ftp://203.0.113.10","event_types":["question.created"]}`,
+		`{"url":"https://203.0.113.10/hook","event_types":["question.updated"]}`,
+		`{"url":"https://203.0.113.10/hook"}`,
+		// адреса во внутренней сети
+		`{"url":"http://127.0.0.1:8080/hook","event_types":["question.created"]}`,
+		`{"url":"http://localhost/hook","event_types":["question.created"]}`,
+		`{"url":"http://[::1]/hook","event_types":["question.created"]}`,
+		`{"url":"http://169.254.169.254/latest/meta-data","event_types":["question.created"]}`,
+		`{"url":"http://10.0.0.5/hook","event_types":["question.created"]}`,
+		`{"url":"http://192.168.1.1/hook","event_types":["question.created"]}`,
+	} {
+		w := serve(router, http.MethodPost, "/webhooks", body, adminHeader)
+		require.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+
+	w := serve(router, http.MethodPost, "/webhooks", `{"url":"https://203.0.113.10/hook","event_types":["question.created"]}`, adminHeader)
+	require.Equal(t, http.StatusCreated, w.Code)
+	w = serve(router, http.MethodPatch, "/webhooks/1", `{"url":"http://172.16.0.1/hook"}`, adminHeader)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = serve(router, http.MethodPatch, "/webhooks/42", `{"active":false}`, adminHeader)
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestWebhooks_AdminToken(t *testing.T) {
+	router := newWebhookRouter(t)
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/webhooks", nil),
+		httptest.NewRequest(http.MethodGet, "/webhooks/1", nil),
+		httptest.NewRequest(http.MethodDelete, "/webhooks/1", nil),
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusUnauthorized, w.Code, req.URL.Path)
+	}
+
+	// без токена маршруты не регистрируются
+	store := repository.NewMemoryStore()
+	router, _ = newMemoryRouter(t, httptransport.Options{
+		Webhooks: service.NewWebhookService(repository.NewMemoryWebhookRepository(store)),
+	})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/webhooks", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,431 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"go.uber.org/zap"
+
+	"question-service/internal/domain"
+	"question-service/internal/logger"
+	"question-service/internal/realtime"
+	"question-service/internal/service"
+)
+
+const (
+	wsMaxMessageSize      = 64 << 10
+	wsMaxSubscriptions    = 100
+	wsCloseHandshakeLimit = 5 * time.Second
+)
+
+// Типы сообщений протокола /ws.
+const (
+	wsSubscribe    = "subscribe"
+	wsUnsubscribe  = "unsubscribe"
+	wsCreateAnswer = "create_answer"
+	wsAck          = "ack"
+	wsError        = "error"
+	wsEvent        = "event"
+	wsReset        = "reset"
+)
+
+// WSConfig — настройки WebSocket API.
+type WSConfig struct {
+	// OriginPatterns — разрешённые Origin помимо собственного хоста.
+	OriginPatterns []string
+	// SendQueueSize — сколько исходящих сообщений может ждать отправки; клиент,
+	// который отстал сильнее, отключается.
+	SendQueueSize int
+	WriteTimeout  time.Duration
+	PingInterval  time.Duration
+}
+
+// wsRequest — сообщение клиента. ID — произвольный идентификатор запроса,
+// который возвращается в ack или error.
+type wsRequest struct {
+	Type        string `json:"type"`
+	ID          string `json:"id,omitempty"`
+	QuestionIDs []int  `json:"question_ids,omitempty"`
+	QuestionID  int    `json:"question_id,omitempty"`
+	UserID      string `json:"user_id,omitempty"`
+	Text        string `json:"text,omitempty"`
+}
+
+// wsResponse — сообщение сервера.
+type wsResponse struct {
+	Type        string          `json:"type"`
+	ID          string          `json:"id,omitempty"`
+	Error       string          `json:"error,omitempty"`
+	QuestionIDs []int           `json:"question_ids,omitempty"`
+	Answer      *domain.Answer  `json:"answer,omitempty"`
+	QuestionID  int             `json:"question_id,omitempty"`
+	EventID     int64           `json:"event_id,omitempty"`
+	Event       string          `json:"event,omitempty"`
+	Data        json.RawMessage `json:"data,omitempty"`
+}
+
+// WSHandler обслуживает /ws: клиенты подписываются на вопросы, получают события
+// ответов и создают ответы через AnswerService.
+type WSHandler struct {
+	questions *service.QuestionService
+	answers   *service.AnswerService
+	broker    *realtime.Broker
+	log       *logger.Logger
+	cfg       WSConfig
+
+	mu     sync.Mutex
+	conns  map[*wsConn]struct{}
+	closed bool
+}
+
+func NewWSHandler(questions *service.QuestionService, answers *service.AnswerService, broker *realtime.Broker, log *logger.Logger, cfg WSConfig) *WSHandler {
+	return &WSHandler{
+		questions: questions,
+		answers:   answers,
+		broker:    broker,
+		log:       log,
+		cfg:       cfg,
+		conns:     make(map[*wsConn]struct{}),
+	}
+}
+
+// HandleWS обрабатывает GET /ws
+func (h *WSHandler) HandleWS(w http.ResponseWriter, r *http.Request) {
+	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{OriginPatterns: h.cfg.OriginPatterns})
+	if err != nil {
+		h.log.Warn("websocket handshake failed", zap.Error(err))
+		return
+	}
+	conn.SetReadLimit(wsMaxMessageSize)
+
+	ctx, cancel := context.WithCancel(r.Context())
+	c := &wsConn{
+		h:      h,
+		conn:   conn,
+		send:   make(chan wsResponse, h.cfg.SendQueueSize),
+		subs:   make(map[int]*realtime.Subscription),
+		cancel: cancel,
+	}
+
+	if !h.register(c) {
+		cancel()
+		_ = conn.Close(websocket.StatusGoingAway, "server shutting down")
+		return
+	}
+	defer h.unregister(c)
+
+	client := clientAddr(r)
+	h.log.Info("websocket connected", zap.String("client", client))
+	defer h.log.Info("websocket disconnected", zap.String("client", client))
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c.writeLoop(ctx)
+	}()
+
+	c.readLoop(ctx)
+
+	cancel()
+	c.unsubscribeAll()
+	wg.Wait()
+	_ = conn.CloseNow()
+}
+
+// Close закрывает все соединения со статусом 1001 Going Away. Вызывается при
+// остановке сервера: Shutdown не закрывает перехваченные соединения сам.
+func (h *WSHandler) Close() {
+	h.mu.Lock()
+	h.closed = true
+	conns := make([]*wsConn, 0, len(h.conns))
+	for c := range h.conns {
+		conns = append(conns, c)
+	}
+	h.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, c := range conns {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.close(websocket.StatusGoingAway, "server shutting down")
+		}()
+	}
+	wg.Wait()
+}
+
+func (h *WSHandler) register(c *wsConn) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return false
+	}
+	h.conns[c] = struct{}{}
+	return true
+}
+
+func (h *WSHandler) unregister(c *wsConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.conns, c)
+}
+
+// wsConn — одно WebSocket-соединение. Все исходящие сообщения идут через
+// очередь send, которую пишет единственная горутина writeLoop.
+type wsConn struct {
+	h      *WSHandler
+	conn   *websocket.Conn
+	send   chan wsResponse
+	cancel context.CancelFunc
+
+	mu   sync.Mutex
+	subs map[int]*realtime.Subscription
+	// done — соединение завершается, новые подписки не создаются.
+	done bool
+
+	closeOnce sync.Once
+}
+
+func (c *wsConn) readLoop(ctx context.Context) {
+	for {
+		_, data, err := c.conn.Read(ctx)
+		if err != nil {
+			return
+		}
+
+		var req wsRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			c.enqueue(wsResponse{Type: wsError, Error: "invalid json"})
+			continue
+		}
+
+		switch req.Type {
+		case wsSubscribe:
+			c.subscribe(ctx, req)
+		case wsUnsubscribe:
+			c.unsubscribe(req)
+		case wsCreateAnswer:
+			c.createAnswer(ctx, req)
+		default:
+			c.enqueue(wsResponse{Type: wsError, ID: req.ID, Error: "unknown message type"})
+		}
+	}
+}
+
+func (c *wsConn) writeLoop(ctx context.Context) {
+	var ping <-chan time.Time
+	if c.h.cfg.PingInterval > 0 {
+		t := time.NewTicker(c.h.cfg.PingInterval)
+		defer t.Stop()
+		ping = t.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case msg := <-c.send:
+			wctx, cancel := context.WithTimeout(ctx, c.h.cfg.WriteTimeout)
+			err := wsjson.Write(wctx, c.conn, msg)
+			cancel()
+			if err != nil {
+				c.cancel()
+				return
+			}
+
+		case <-ping:
+			pctx, cancel := context.WithTimeout(ctx, c.h.cfg.WriteTimeout)
+			err := c.conn.Ping(pctx)
+			cancel()
+			if err != nil {
+				c.cancel()
+				return
+			}
+		}
+	}
+}
+
+// enqueue ставит сообщение в очередь отправки. Если очередь заполнена, клиент
+// не успевает читать, и соединение закрывается, а не копит сообщения в памяти.
+func (c *wsConn) enqueue(msg wsResponse) {
+	select {
+	case c.send <- msg:
+	default:
+		c.h.log.Warn("websocket client is too slow, closing connection")
+		go c.close(websocket.StatusPolicyViolation, "send queue overflow")
+	}
+}
+
+// close закрывает соединение с рукопожатием; повторные вызовы ничего не делают.
+func (c *wsConn) close(code websocket.StatusCode, reason string) {
+	c.closeOnce.Do(func() {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_ = c.conn.Close(code, reason)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(wsCloseHandshakeLimit):
+			_ = c.conn.CloseNow()
+		}
+	})
+}
+
+func (c *wsConn) subscribe(ctx context.Context, req wsRequest) {
+	if len(req.QuestionIDs) == 0 {
+		c.enqueue(wsResponse{Type: wsError, ID: req.ID, Error: "question_ids is required"})
+		return
+	}
+
+	var subscribed []int
+	for _, id := range req.QuestionIDs {
+		if _, err := c.h.questions.GetQuestion(ctx, id); err != nil {
+			if errors.Is(err, service.ErrQuestionNotFound) {
+				c.enqueue(wsResponse{Type: wsError, ID: req.ID, QuestionID: id, Error: "question not found"})
+				continue
+			}
+			c.h.log.Error("failed to get question for websocket subscription",
+				zap.Error(err),
+				zap.Int("question_id", id),
+			)
+			c.enqueue(wsResponse{Type: wsError, ID: req.ID, QuestionID: id, Error: "failed to subscribe"})
+			continue
+		}
+
+		if !c.addSubscription(id) {
+			c.enqueue(wsResponse{Type: wsError, ID: req.ID, QuestionID: id, Error: "too many subscriptions"})
+			continue
+		}
+		subscribed = append(subscribed, id)
+	}
+
+	if len(subscribed) > 0 {
+		c.enqueue(wsResponse{Type: wsAck, ID: req.ID, QuestionIDs: subscribed})
+	}
+}
+
+func (c *wsConn) unsubscribe(req wsRequest) {
+	c.mu.Lock()
+	for _, id := range req.QuestionIDs {
+		if sub, ok := c.subs[id]; ok {
+			delete(c.subs, id)
+			c.h.broker.Unsubscribe(sub)
+		}
+	}
+	c.mu.Unlock()
+
+	c.enqueue(wsResponse{Type: wsAck, ID: req.ID, QuestionIDs: req.QuestionIDs})
+}
+
+func (c *wsConn) createAnswer(ctx context.Context, req wsRequest) {
+	if req.QuestionID <= 0 {
+		c.enqueue(wsResponse{Type: wsError, ID: req.ID, Error: "invalid question id"})
+		return
+	}
+	if strings.TrimSpace(req.UserID) == "" || strings.TrimSpace(req.Text) == "" {
+		c.enqueue(wsResponse{Type: wsError, ID: req.ID, Error: "user_id and text are required"})
+		return
+	}
+
+	ans, err := c.h.answers.CreateAnswer(ctx, req.QuestionID, req.UserID, req.Text)
+	if err != nil {
+		if errors.Is(err, service.ErrQuestionNotFound) {
+			c.enqueue(wsResponse{Type: wsError, ID: req.ID, QuestionID: req.QuestionID, Error: "question not found"})
+			return
+		}
+		if errors.Is(err, service.ErrQuestionLocked) || errors.Is(err, service.ErrQuestionClosed) {
+			c.enqueue(wsResponse{Type: wsError, ID: req.ID, QuestionID: req.QuestionID, Error: err.Error()})
+			return
+		}
+		c.h.log.Error("failed to create answer via websocket",
+			zap.Error(err),
+			zap.Int("question_id", req.QuestionID),
+			zap.String("user_id", req.UserID),
+		)
+		c.enqueue(wsResponse{Type: wsError, ID: req.ID, Error: "failed to create answer"})
+		return
+	}
+
+	c.enqueue(wsResponse{Type: wsAck, ID: req.ID, Answer: ans})
+}
+
+// addSubscription подписывает соединение на вопрос, если оно ещё не подписано.
+func (c *wsConn) addSubscription(questionID int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.subs[questionID]; ok {
+		return true
+	}
+	if len(c.subs) >= wsMaxSubscriptions {
+		return false
+	}
+
+	c.subscribeLocked(questionID)
+	return true
+}
+
+// subscribeLocked создаёт подписку брокера и пересылку её событий. Вызывается под c.mu.
+func (c *wsConn) subscribeLocked(questionID int) {
+	if c.done {
+		return
+	}
+	sub, _, _ := c.h.broker.Subscribe(questionID, 0)
+	c.subs[questionID] = sub
+	go c.forward(questionID, sub)
+}
+
+// forward пересылает события подписки в очередь отправки.
+func (c *wsConn) forward(questionID int, sub *realtime.Subscription) {
+	for msg := range sub.Messages() {
+		c.enqueue(wsResponse{
+			Type:       wsEvent,
+			QuestionID: msg.QuestionID,
+			EventID:    msg.ID,
+			Event:      msg.Type,
+			Data:       msg.Data,
+		})
+	}
+
+	switch err := sub.Err(); {
+	case errors.Is(err, realtime.ErrReset):
+		// события могли пропасть: клиенту нужно перечитать вопрос, подписка продолжается
+		c.mu.Lock()
+		resubscribe := c.subs[questionID] == sub
+		if resubscribe {
+			c.subscribeLocked(questionID)
+		}
+		c.mu.Unlock()
+		if resubscribe {
+			c.enqueue(wsResponse{Type: wsReset, QuestionID: questionID})
+		}
+	case errors.Is(err, realtime.ErrSlowConsumer):
+		c.close(websocket.StatusPolicyViolation, "send queue overflow")
+	case errors.Is(err, realtime.ErrBrokerClosed):
+		c.close(websocket.StatusGoingAway, "server shutting down")
+	}
+}
+
+func (c *wsConn) unsubscribeAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.done = true
+	for id, sub := range c.subs {
+		delete(c.subs, id)
+		c.h.broker.Unsubscribe(sub)
+	}
+}
@@ -0,0 +1,121 @@
+package http_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"github.com/stretchr/testify/require"
+)
+
+type wsMessage struct {
+	Type        string         `json:"type"`
+	ID          string         `json:"id"`
+	Error       string         `json:"error"`
+	QuestionIDs []int          `json:"question_ids"`
+	QuestionID  int            `json:"question_id"`
+	Event       string         `json:"event"`
+	EventID     int64          `json:"event_id"`
+	Answer      map[string]any `json:"answer"`
+	Data        map[string]any `json:"data"`
+}
+
+func dialWS(t *testing.T, env *eventsEnv) *websocket.Conn {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(env.server.URL, "http")+"/ws", nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.CloseNow() })
+	return conn
+}
+
+func wsRoundTrip(t *testing.T, conn *websocket.Conn, req map[string]any) wsMessage {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	require.NoError(t, wsjson.Write(ctx, conn, req))
+	return wsRead(t, conn)
+}
+
+func wsRead(t *testing.T, conn *websocket.Conn) wsMessage {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var msg wsMessage
+	require.NoError(t, wsjson.Read(ctx, conn, &msg))
+	return msg
+}
+
+func TestWS_SubscribeAndCreateAnswer(t *testing.T) {
+	env := newEventsEnv(t, 0)
+	q, err := env.questions.CreateQuestion(context.Background(), "AMA")
+	require.NoError(t, err)
+
+	viewer := dialWS(t, env)
+	author := dialWS(t, env)
+
+	ack := wsRoundTrip(t, viewer, map[string]any{"type": "subscribe", "id": "s1", "question_ids": []int{q.ID}})
+	require.Equal(t, "ack", ack.Type)
+	require.Equal(t, "s1", ack.ID)
+	require.Equal(t, []int{q.ID}, ack.QuestionIDs)
+
+	ack = wsRoundTrip(t, author, map[string]any{
+		"type": "create_answer", "id": "a1", "question_id": q.ID, "user_id": "user-1", "text": "hello",
+	})
+	require.Equal(t, "ack", ack.Type)
+	require.Equal(t, "a1", ack.ID)
+	require.Equal(t, "hello", ack.Answer["text"])
+
+	ev := wsRead(t, viewer)
+	require.Equal(t, "event", ev.Type)
+	require.Equal(t, "answer.created", ev.Event)
+	require.Equal(t, q.ID, ev.QuestionID)
+	require.Equal(t, "hello", ev.Data["text"])
+}
+
+func TestWS_Errors(t *testing.T) {
+	env := newEventsEnv(t, 0)
+	conn := dialWS(t, env)
+
+	msg := wsRoundTrip(t, conn, map[string]any{"type": "subscribe", "id": "s1", "question_ids": []int{42}})
+	require.Equal(t, "error", msg.Type)
+	require.Equal(t, "question not found", msg.Error)
+
+	msg = wsRoundTrip(t, conn, map[string]any{"type": "create_answer", "id": "a1", "question_id": 42, "user_id": "u", "text": "t"})
+	require.Equal(t, "error", msg.Type)
+	require.Equal(t, "a1", msg.ID)
+
+	msg = wsRoundTrip(t, conn, map[string]any{"type": "create_answer", "id": "a2", "question_id": 1})
+	require.Equal(t, "user_id and text are required", msg.Error)
+
+	msg = wsRoundTrip(t, conn, map[string]any{"type": "dance"})
+	require.Equal(t, "unknown message type", msg.Error)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, conn.Write(ctx, websocket.MessageText, []byte("{")))
+	require.Equal(t, "invalid json", wsRead(t, conn).Error)
+}
+
+func TestWS_CloseOnShutdown(t *testing.T) {
+	env := newEventsEnv(t, 0)
+	conn := dialWS(t, env)
+
+	// соединение зарегистрировано, когда сервер ответил на первое сообщение
+	wsRoundTrip(t, conn, map[string]any{"type": "dance"})
+
+	go env.ws.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, _, err := conn.Read(ctx)
+	require.Equal(t, websocket.StatusGoingAway, websocket.CloseStatus(err))
+}
@@ -0,0 +1,84 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"question-service/internal/domain"
+	"question-service/internal/logger"
+)
+
+// Publisher доставляет доменное событие во внешнюю систему. Ошибка означает,
+// что событие не доставлено и будет отправлено повторно.
+type Publisher interface {
+	Publish(ctx context.Context, ev domain.Event) error
+}
+
+// LogPublisher пишет события в лог. Подходит для локального запуска и отладки.
+type LogPublisher struct {
+	log *logger.Logger
+}
+
+func NewLogPublisher(log *logger.Logger) *LogPublisher {
+	return &LogPublisher{log: log}
+}
+
+func (p *LogPublisher) Publish(_ context.Context, ev domain.Event) error {
+	p.log.Info("domain event published",
+		zap.Int64("event_id", ev.ID),
+		zap.String("type", ev.Type),
+		zap.String("aggregate_type", ev.AggregateType),
+		zap.Int("aggregate_id", ev.AggregateID),
+		zap.ByteString("data", ev.Payload),
+	)
+	return nil
+}
+
+// WebhookPublisher отправляет событие POST-запросом с JSON-телом на заданный URL.
+// Получатель должен быть идемпотентным по заголовку X-Event-ID: доставка «хотя бы один раз».
+type WebhookPublisher struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookPublisher(url string, timeout time.Duration) *WebhookPublisher {
+	return &WebhookPublisher{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (p *WebhookPublisher) Publish(ctx context.Context, ev domain.Event) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", strconv.FormatInt(ev.ID, 10))
+	req.Header.Set("X-Event-Type", ev.Type)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"question-service/internal/logger"
+	"question-service/internal/repository"
+)
+
+// Relay публикует события из outbox через Publisher.
+//
+// Доставка «хотя бы один раз»: событие помечается опубликованным только после
+// успешной публикации. Порядок сохраняется в пределах агрегата: если событие
+// не удалось опубликовать, следующие события того же агрегата ждут повтора.
+// Событие, которое не удалось опубликовать за maxAttempts попыток, отбрасывается
+// (dead_at), и следующие события агрегата публикуются дальше.
+// Публикует только один экземпляр сервиса — тот, что держит блокировку relay.
+type Relay struct {
+	repo        repository.OutboxRepository
+	publisher   Publisher
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+	log         *logger.Logger
+}
+
+// DefaultMaxAttempts — сколько раз relay пытается опубликовать событие по умолчанию.
+const DefaultMaxAttempts = 20
+
+func NewRelay(repo repository.OutboxRepository, publisher Publisher, interval time.Duration, batchSize int, log *logger.Logger) *Relay {
+	return &Relay{
+		repo:        repo,
+		publisher:   publisher,
+		interval:    interval,
+		batchSize:   batchSize,
+		maxAttempts: DefaultMaxAttempts,
+		log:         log,
+	}
+}
+
+// SetMaxAttempts задаёт, после скольких неудачных попыток событие отбрасывается;
+// 0 и меньше — попытки не ограничены.
+func (r *Relay) SetMaxAttempts(n int) {
+	r.maxAttempts = n
+}
+
+// Run публикует события, пока не отменён ctx.
+func (r *Relay) Run(ctx context.Context) error {
+	for {
+		acquired, err := r.repo.WithRelayLock(ctx, r.drain)
+		if err != nil && ctx.Err() == nil {
+			r.log.Error("outbox relay failed", zap.Error(err))
+		}
+		if !acquired {
+			r.log.Debug("outbox relay lock is held by another instance")
+		}
+
+		if !sleep(ctx, r.interval) {
+			return nil
+		}
+	}
+}
+
+// drain публикует пачки событий, пока relay держит блокировку.
+func (r *Relay) drain(ctx context.Context) error {
+	for {
+		published, err := r.PublishBatch(ctx)
+		if err != nil {
+			return err
+		}
+
+		if published == 0 && !sleep(ctx, r.interval) {
+			return nil
+		}
+	}
+}
+
+// PublishBatch публикует одну пачку неопубликованных событий и возвращает
+// количество успешно опубликованных.
+func (r *Relay) PublishBatch(ctx context.Context) (int, error) {
+	events, err := r.repo.FetchUnpublished(ctx, r.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	type aggregateKey struct {
+		typ string
+		id  int
+	}
+	blocked := make(map[aggregateKey]bool)
+	published := 0
+
+	for _, ev := range events {
+		key := aggregateKey{ev.AggregateType, ev.AggregateID}
+		if blocked[key] {
+			continue
+		}
+
+		if err := r.publisher.Publish(ctx, ev); err != nil {
+			if r.maxAttempts > 0 && ev.Attempts+1 >= r.maxAttempts {
+				r.log.Error("failed to publish domain event, giving up",
+					zap.Int64("event_id", ev.ID),
+					zap.String("type", ev.Type),
+					zap.Int("attempt", ev.Attempts+1),
+					zap.Error(err),
+				)
+				if err := r.repo.MarkDead(ctx, ev.ID, err.Error(), time.Now().UTC()); err != nil {
+					return published, err
+				}
+				continue
+			}
+
+			blocked[key] = true
+			r.log.Warn("failed to publish domain event, will retry",
+				zap.Int64("event_id", ev.ID),
+				zap.String("type", ev.Type),
+				zap.Int("attempt", ev.Attempts+1),
+				zap.Error(err),
+			)
+			if err := r.repo.MarkFailed(ctx, ev.ID, err.Error()); err != nil {
+				return published, err
+			}
+			continue
+		}
+
+		if err := r.repo.MarkPublished(ctx, ev.ID, time.Now().UTC()); err != nil {
+			return published, err
+		}
+		published++
+	}
+
+	return published, nil
+}
+
+// sleep ждёт d и возвращает false, если за это время отменён ctx.
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
@@ -0,0 +1,153 @@
+package outbox_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"question-service/internal/domain"
+	"question-service/internal/logger"
+	"question-service/internal/outbox"
+	"question-service/internal/repository"
+)
+
+type fakePublisher struct {
+	failFor   map[int64]bool
+	published []int64
+}
+
+func (p *fakePublisher) Publish(_ context.Context, ev domain.Event) error {
+	if p.failFor[ev.ID] {
+		return errors.New("unavailable")
+	}
+	p.published = append(p.published, ev.ID)
+	return nil
+}
+
+func appendEvents(t *testing.T, repo repository.OutboxRepository, questionIDs ...int) {
+	t.Helper()
+	for _, id := range questionIDs {
+		ev, err := domain.NewEvent(domain.EventAnswerCreated, id, map[string]int{"question_id": id})
+		require.NoError(t, err)
+		require.NoError(t, repo.Append(context.Background(), ev))
+	}
+}
+
+func TestRelay_KeepsOrderPerAggregate(t *testing.T) {
+	repo := repository.NewMemoryOutboxRepository(repository.NewMemoryStore())
+	// события 1 и 3 относятся к вопросу 10, 2 — к вопросу 20
+	appendEvents(t, repo, 10, 20, 10)
+
+	pub := &fakePublisher{failFor: map[int64]bool{1: true}}
+	relay := outbox.NewRelay(repo, pub, time.Millisecond, 10, &logger.Logger{Logger: zap.NewNop()})
+
+	n, err := relay.PublishBatch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Equal(t, []int64{2}, pub.published)
+
+	// событие 3 ждёт повтора события 1 и в пачку не попадает
+	pending, err := repo.FetchUnpublished(context.Background(), 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	require.Equal(t, 1, pending[0].Attempts)
+
+	pub.failFor = nil
+	for range 2 {
+		n, err = relay.PublishBatch(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+	}
+	require.Equal(t, []int64{2, 1, 3}, pub.published)
+}
+
+func TestRelay_BlockedAggregateDoesNotFillBatch(t *testing.T) {
+	repo := repository.NewMemoryOutboxRepository(repository.NewMemoryStore())
+	// события 1–3 относятся к вопросу 10, 4 — к вопросу 20
+	appendEvents(t, repo, 10, 10, 10, 20)
+
+	pub := &fakePublisher{failFor: map[int64]bool{1: true}}
+	relay := outbox.NewRelay(repo, pub, time.Millisecond, 2, &logger.Logger{Logger: zap.NewNop()})
+
+	_, err := relay.PublishBatch(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, pub.published)
+
+	// пачка из двух событий: повтор первого события вопроса 10 и событие вопроса 20
+	pending, err := repo.FetchUnpublished(context.Background(), 2)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	require.Equal(t, []int64{1, 4}, []int64{pending[0].ID, pending[1].ID})
+
+	n, err := relay.PublishBatch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Equal(t, []int64{4}, pub.published)
+}
+
+func TestRelay_GivesUpAfterMaxAttempts(t *testing.T) {
+	repo := repository.NewMemoryOutboxRepository(repository.NewMemoryStore())
+	appendEvents(t, repo, 10, 10)
+
+	pub := &fakePublisher{failFor: map[int64]bool{1: true}}
+	relay := outbox.NewRelay(repo, pub, time.Millisecond, 10, &logger.Logger{Logger: zap.NewNop()})
+	relay.SetMaxAttempts(2)
+
+	for range 2 {
+		_, err := relay.PublishBatch(context.Background())
+		require.NoError(t, err)
+	}
+	require.Empty(t, pub.published)
+
+	// отброшенное событие больше не задерживает следующие события вопроса
+	n, err := relay.PublishBatch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Equal(t, []int64{2}, pub.published)
+
+	events, err := repo.FindByIDs(context.Background(), []int64{1})
+	require.NoError(t, err)
+	require.Equal(t, 2, events[0].Attempts)
+	require.NotNil(t, events[0].DeadAt)
+	require.Equal(t, "unavailable", events[0].LastError)
+
+	pending, err := repo.FetchUnpublished(context.Background(), 10)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+}
+
+func TestWebhookPublisher(t *testing.T) {
+	var got domain.Event
+	var eventType string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		eventType = r.Header.Get("X-Event-Type")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	ev, err := domain.NewEvent(domain.EventQuestionCreated, 5, map[string]any{"id": 5, "text": "q"})
+	require.NoError(t, err)
+	ev.ID = 42
+
+	pub := outbox.NewWebhookPublisher(srv.URL, time.Second)
+	require.NoError(t, pub.Publish(context.Background(), ev))
+	require.Equal(t, domain.EventQuestionCreated, eventType)
+	require.Equal(t, int64(42), got.ID)
+	require.Equal(t, 5, got.AggregateID)
+	require.JSONEq(t, `{"id":5,"text":"q"}`, string(got.Payload))
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+
+	require.Error(t, outbox.NewWebhookPublisher(failing.URL, time.Second).Publish(context.Background(), ev))
+}
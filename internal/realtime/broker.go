@@ -0,0 +1,206 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"question-service/internal/domain"
+)
+
+// subscriptionBuffer — сколько сообщений может ждать отправки одному подписчику.
+// Подписчик, который отстал сильнее, отключается.
+const subscriptionBuffer = 64
+
+var (
+	// ErrSlowConsumer — подписчик не успевал читать сообщения и был отключён.
+	ErrSlowConsumer = errors.New("subscriber is too slow")
+	// ErrReset — брокер мог пропустить события; подписчику нужно перечитать состояние.
+	ErrReset = errors.New("event stream reset")
+	// ErrBrokerClosed — брокер остановлен.
+	ErrBrokerClosed = errors.New("broker closed")
+)
+
+// Message — событие вопроса, доставляемое подписчикам. ID совпадает с ID события
+// в outbox, поэтому одинаков на всех экземплярах сервиса.
+type Message struct {
+	ID         int64
+	QuestionID int
+	Type       string
+	Data       json.RawMessage
+}
+
+// Broker — in-process pub/sub событий вопросов с ограниченным буфером для
+// повторной отправки пропущенных сообщений.
+type Broker struct {
+	mu         sync.Mutex
+	bufferSize int
+	buffer     []Message
+	// horizon — события с ID <= horizon могли не попасть в буфер; seeded — horizon известен.
+	horizon int64
+	seeded  bool
+	subs    map[int]map[*Subscription]struct{}
+	closed  bool
+}
+
+func NewBroker(bufferSize int) *Broker {
+	return &Broker{
+		bufferSize: bufferSize,
+		subs:       make(map[int]map[*Subscription]struct{}),
+	}
+}
+
+// Subscription — подписка на события одного вопроса.
+type Subscription struct {
+	questionID int
+	ch         chan Message
+	err        error
+}
+
+// Messages возвращает канал сообщений. Канал закрывается, когда брокер отключает
+// подписку; причину возвращает Err.
+func (s *Subscription) Messages() <-chan Message {
+	return s.ch
+}
+
+// Err возвращает причину отключения после закрытия канала.
+func (s *Subscription) Err() error {
+	return s.err
+}
+
+// Notify реализует service.Notifier для запуска в одном экземпляре.
+func (b *Broker) Notify(_ context.Context, ev domain.Event) {
+	b.Publish(ev)
+}
+
+// Publish рассылает событие подписчикам его вопроса и сохраняет его в буфере.
+func (b *Broker) Publish(ev domain.Event) {
+	msg := Message{
+		ID:         ev.ID,
+		QuestionID: ev.AggregateID,
+		Type:       ev.Type,
+		Data:       ev.Payload,
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	if !b.seeded {
+		// всё, что было до первого события, этот экземпляр не видел
+		b.horizon = msg.ID - 1
+		b.seeded = true
+	}
+
+	b.buffer = append(b.buffer, msg)
+	if len(b.buffer) > b.bufferSize {
+		evicted := b.buffer[0]
+		b.buffer = b.buffer[1:]
+		b.horizon = max(b.horizon, evicted.ID)
+	}
+
+	for s := range b.subs[msg.QuestionID] {
+		select {
+		case s.ch <- msg:
+		default:
+			b.drop(s, ErrSlowConsumer)
+		}
+	}
+}
+
+// Subscribe подписывает на события вопроса. Если lastEventID > 0, возвращает
+// события после него из буфера; complete = false означает, что часть событий
+// могла выпасть из буфера и клиенту нужно перечитать вопрос целиком.
+func (b *Broker) Subscribe(questionID int, lastEventID int64) (sub *Subscription, replay []Message, complete bool) {
+	sub = &Subscription{
+		questionID: questionID,
+		ch:         make(chan Message, subscriptionBuffer),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		sub.err = ErrBrokerClosed
+		close(sub.ch)
+		return sub, nil, true
+	}
+
+	if lastEventID > 0 {
+		complete = b.seeded && lastEventID >= b.horizon
+		for _, msg := range b.buffer {
+			if msg.QuestionID == questionID && msg.ID > lastEventID {
+				replay = append(replay, msg)
+			}
+		}
+	} else {
+		complete = true
+	}
+
+	if b.subs[questionID] == nil {
+		b.subs[questionID] = make(map[*Subscription]struct{})
+	}
+	b.subs[questionID][sub] = struct{}{}
+
+	return sub, replay, complete
+}
+
+// Unsubscribe отменяет подписку. Повторный вызов безопасен.
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.drop(sub, nil)
+}
+
+// Reset отключает всех подписчиков с ErrReset и забывает буфер. Вызывается, когда
+// брокер мог пропустить события, например после переподключения к PostgreSQL.
+func (b *Broker) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.buffer = nil
+	b.seeded = false
+	b.dropAll(ErrReset)
+}
+
+// Close отключает всех подписчиков. Вызывается при остановке сервера, чтобы
+// открытые потоки не задерживали её.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	b.dropAll(ErrBrokerClosed)
+}
+
+// drop удаляет подписку и закрывает её канал. Вызывается под блокировкой.
+func (b *Broker) drop(sub *Subscription, reason error) {
+	subs, ok := b.subs[sub.questionID]
+	if !ok {
+		return
+	}
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(b.subs, sub.questionID)
+	}
+	sub.err = reason
+	close(sub.ch)
+}
+
+// dropAll отключает всех подписчиков. Вызывается под блокировкой.
+func (b *Broker) dropAll(reason error) {
+	for _, subs := range b.subs {
+		for s := range subs {
+			b.drop(s, reason)
+		}
+	}
+}
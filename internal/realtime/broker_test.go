@@ -0,0 +1,107 @@
+package realtime_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"question-service/internal/domain"
+	"question-service/internal/realtime"
+)
+
+func event(id int64, questionID int) domain.Event {
+	return domain.Event{
+		ID:            id,
+		Type:          domain.EventAnswerCreated,
+		AggregateType: domain.AggregateQuestion,
+		AggregateID:   questionID,
+		Payload:       []byte(`{}`),
+	}
+}
+
+func ids(msgs []realtime.Message) []int64 {
+	var out []int64
+	for _, m := range msgs {
+		out = append(out, m.ID)
+	}
+	return out
+}
+
+func TestBroker_DeliversToQuestionSubscribers(t *testing.T) {
+	b := realtime.NewBroker(10)
+	sub, _, _ := b.Subscribe(1, 0)
+
+	b.Publish(event(1, 1))
+	b.Publish(event(2, 2))
+	b.Publish(event(3, 1))
+
+	require.Equal(t, int64(1), (<-sub.Messages()).ID)
+	require.Equal(t, int64(3), (<-sub.Messages()).ID)
+	require.Empty(t, sub.Messages())
+}
+
+func TestBroker_ReplaysFromBuffer(t *testing.T) {
+	b := realtime.NewBroker(3)
+	for id := int64(10); id <= 14; id++ {
+		b.Publish(event(id, 1))
+	}
+
+	// в буфере остались 12, 13, 14
+	_, replay, complete := b.Subscribe(1, 12)
+	require.True(t, complete)
+	require.Equal(t, []int64{13, 14}, ids(replay))
+
+	_, replay, complete = b.Subscribe(1, 10)
+	require.False(t, complete)
+	require.Equal(t, []int64{12, 13, 14}, ids(replay))
+}
+
+func TestBroker_UnknownHistoryIsIncomplete(t *testing.T) {
+	b := realtime.NewBroker(3)
+
+	_, _, complete := b.Subscribe(1, 5)
+	require.False(t, complete)
+
+	b.Publish(event(7, 1))
+	_, _, complete = b.Subscribe(1, 5)
+	require.False(t, complete)
+	_, replay, complete := b.Subscribe(1, 6)
+	require.True(t, complete)
+	require.Equal(t, []int64{7}, ids(replay))
+}
+
+func TestBroker_DropsSlowConsumer(t *testing.T) {
+	b := realtime.NewBroker(1000)
+	sub, _, _ := b.Subscribe(1, 0)
+
+	for id := int64(1); id <= 100; id++ {
+		b.Publish(event(id, 1))
+	}
+
+	n := 0
+	for range sub.Messages() {
+		n++
+	}
+	require.Less(t, n, 100)
+	require.ErrorIs(t, sub.Err(), realtime.ErrSlowConsumer)
+}
+
+func TestBroker_CloseAndReset(t *testing.T) {
+	b := realtime.NewBroker(10)
+	sub, _, _ := b.Subscribe(1, 0)
+
+	b.Reset()
+	_, ok := <-sub.Messages()
+	require.False(t, ok)
+	require.ErrorIs(t, sub.Err(), realtime.ErrReset)
+
+	sub, _, _ = b.Subscribe(1, 0)
+	b.Close()
+	_, ok = <-sub.Messages()
+	require.False(t, ok)
+	require.ErrorIs(t, sub.Err(), realtime.ErrBrokerClosed)
+
+	sub, _, _ = b.Subscribe(1, 0)
+	_, ok = <-sub.Messages()
+	require.False(t, ok)
+}
@@ -0,0 +1,135 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"question-service/internal/domain"
+	"question-service/internal/logger"
+	"question-service/internal/repository"
+)
+
+// Channel — канал PostgreSQL LISTEN/NOTIFY, по которому экземпляры сервиса
+// сообщают друг другу ID новых событий. Само событие читается из outbox:
+// размер payload у NOTIFY ограничен.
+const Channel = "question_events"
+
+const (
+	listenerRetryInitialDelay = time.Second
+	listenerRetryMaxDelay     = 30 * time.Second
+)
+
+// PGNotifier реализует service.Notifier через NOTIFY: событие получат брокеры
+// всех экземпляров, включая текущий.
+type PGNotifier struct {
+	conn repository.Connector
+	log  *logger.Logger
+}
+
+func NewPGNotifier(conn repository.Connector, log *logger.Logger) *PGNotifier {
+	return &PGNotifier{conn: conn, log: log}
+}
+
+func (n *PGNotifier) Notify(ctx context.Context, ev domain.Event) {
+	err := n.conn.Writer(ctx).Exec("SELECT pg_notify(?, ?)", Channel, strconv.FormatInt(ev.ID, 10)).Error
+	if err != nil {
+		n.log.Warn("failed to notify about event",
+			zap.Int64("event_id", ev.ID),
+			zap.String("type", ev.Type),
+			zap.Error(err),
+		)
+	}
+}
+
+// Listener слушает канал Channel на выделенном соединении и передаёт события в брокер.
+type Listener struct {
+	dsn    string
+	events repository.OutboxRepository
+	broker *Broker
+	log    *logger.Logger
+}
+
+func NewListener(dsn string, events repository.OutboxRepository, broker *Broker, log *logger.Logger) *Listener {
+	return &Listener{dsn: dsn, events: events, broker: broker, log: log}
+}
+
+// Run слушает уведомления, пока не отменён ctx, и переподключается при обрыве.
+// Пока соединения не было, уведомления могли потеряться, поэтому после
+// переподключения брокер сбрасывается.
+func (l *Listener) Run(ctx context.Context) error {
+	delay := listenerRetryInitialDelay
+	connectedBefore := false
+
+	for {
+		err := l.listen(ctx, func() {
+			if connectedBefore {
+				l.broker.Reset()
+			}
+			connectedBefore = true
+			delay = listenerRetryInitialDelay
+		})
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		l.log.Warn("event listener disconnected, reconnecting",
+			zap.Duration("delay", delay),
+			zap.Error(err),
+		)
+
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil
+		case <-t.C:
+		}
+		delay = min(2*delay, listenerRetryMaxDelay)
+	}
+}
+
+func (l *Listener) listen(ctx context.Context, onConnected func()) error {
+	conn, err := pgx.Connect(ctx, l.dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{Channel}.Sanitize()); err != nil {
+		return err
+	}
+	onConnected()
+	l.log.Info("listening for events", zap.String("channel", Channel))
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		id, err := strconv.ParseInt(n.Payload, 10, 64)
+		if err != nil {
+			l.log.Warn("invalid event notification", zap.String("payload", n.Payload))
+			continue
+		}
+
+		events, err := l.events.FindByIDs(ctx, []int64{id})
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return err
+			}
+			// событие потеряно: подписчикам нужно перечитать состояние
+			l.log.Error("failed to load notified event", zap.Int64("event_id", id), zap.Error(err))
+			l.broker.Reset()
+			continue
+		}
+		for _, ev := range events {
+			l.broker.Publish(ev)
+		}
+	}
+}
@@ -2,45 +2,271 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
 	"question-service/internal/domain"
 )
 
 type AnswerRepository interface {
 	Create(ctx context.Context, a *domain.Answer) error
+	// CreateBatch вставляет ответы пачками по batchSize и заполняет их ID.
+	CreateBatch(ctx context.Context, answers []domain.Answer, batchSize int) error
+	// CreateMany вставляет ответы одним многострочным INSERT и заполняет их ID.
+	CreateMany(ctx context.Context, answers []domain.Answer) error
 	GetByID(ctx context.Context, id int) (*domain.Answer, error)
-	Delete(ctx context.Context, id int) error
+	// Delete переносит ответ в корзину вместе с репликами на него, если версия ответа
+	// равна version (0 — без проверки). Возвращает ErrStaleVersion при несовпадении версии.
+	Delete(ctx context.Context, id, version int) error
+	// Update перезаписывает автора, текст, время создания и родительский ответ и
+	// увеличивает версию ответа.
+	// Возвращает gorm.ErrRecordNotFound, если ответа нет.
+	Update(ctx context.Context, a *domain.Answer) error
+	// Restore возвращает ответ из корзины вместе с репликами, удалёнными вместе с ним,
+	// и возвращает восстановленный ответ. Возвращает gorm.ErrRecordNotFound, если
+	// такого ответа в корзине нет.
+	Restore(ctx context.Context, id int) (*domain.Answer, error)
+	// ListDeleted возвращает до limit ответов в корзине с id > afterID по возрастанию
+	// id — только удалённые отдельно от своего вопроса и родительского ответа.
+	ListDeleted(ctx context.Context, afterID, limit int) ([]domain.Answer, error)
+	// Purge окончательно удаляет ответы, удалённые раньше before, и возвращает их число.
+	Purge(ctx context.Context, before time.Time) (int64, error)
 	ListByQuestionID(ctx context.Context, questionID int) ([]domain.Answer, error)
+	// ListByQuestionIDs возвращает ответы сразу на несколько вопросов одним запросом,
+	// не больше perQuestion первых ответов на вопрос (0 — все), по возрастанию id.
+	// Скрытые модератором ответы и все реплики на них не возвращаются и в perQuestion
+	// не считаются.
+	ListByQuestionIDs(ctx context.Context, questionIDs []int, perQuestion int) ([]domain.Answer, error)
+	// ListThread возвращает ответы на вопрос деревом обсуждения в порядке обхода:
+	// ответы верхнего уровня по возрастанию id, за каждым — его реплики. Depth
+	// заполнен; реплики глубже maxDepth не возвращаются. Скрытые модератором
+	// ответы не возвращаются вместе со всеми репликами на них.
+	ListThread(ctx context.Context, questionID, maxDepth int) ([]domain.Answer, error)
+	// Depth возвращает глубину ответа в ветке: 0 у ответа верхнего уровня.
+	Depth(ctx context.Context, id int) (int, error)
+	// SetHidden скрывает ответ от пользователей или снова показывает его.
+	// Возвращает gorm.ErrRecordNotFound, если ответа нет.
+	SetHidden(ctx context.Context, id int, hidden bool) error
 }
 
 type GormAnswerRepository struct {
-	db *gorm.DB
+	conn Connector
 }
 
-func NewAnswerRepository(db *gorm.DB) *GormAnswerRepository {
-	return &GormAnswerRepository{db: db}
+func NewAnswerRepository(conn Connector) *GormAnswerRepository {
+	return &GormAnswerRepository{conn: conn}
 }
 
 func (r *GormAnswerRepository) Create(ctx context.Context, a *domain.Answer) error {
-	return r.db.WithContext(ctx).Create(a).Error
+	return r.conn.Writer(ctx).Create(a).Error
+}
+
+func (r *GormAnswerRepository) CreateBatch(ctx context.Context, answers []domain.Answer, batchSize int) error {
+	if len(answers) == 0 {
+		return nil
+	}
+	return r.conn.Writer(ctx).CreateInBatches(answers, batchSize).Error
+}
+
+func (r *GormAnswerRepository) CreateMany(ctx context.Context, answers []domain.Answer) error {
+	if len(answers) == 0 {
+		return nil
+	}
+	return r.conn.Writer(ctx).Create(&answers).Error
 }
 
 func (r *GormAnswerRepository) GetByID(ctx context.Context, id int) (*domain.Answer, error) {
 	var ans domain.Answer
-	err := r.db.WithContext(ctx).First(&ans, id).Error
+	err := r.conn.Reader(ctx).First(&ans, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &ans, nil
 }
 
-func (r *GormAnswerRepository) Delete(ctx context.Context, id int) error {
-	return r.db.WithContext(ctx).Delete(&domain.Answer{}, id).Error
+func (r *GormAnswerRepository) Delete(ctx context.Context, id, version int) error {
+	db := r.conn.Writer(ctx)
+	now := time.Now().UTC()
+	if err := softDeleteVersioned(db, &domain.Answer{}, id, version, now); err != nil {
+		return err
+	}
+
+	// реплики получают ту же отметку времени, что и ответ: по ней Restore
+	// отличает их от удалённых раньше
+	return db.Exec(`
+		WITH RECURSIVE replies AS (
+			SELECT id FROM answers WHERE parent_answer_id = ? AND deleted_at IS NULL
+			UNION ALL
+			SELECT a.id FROM answers a JOIN replies r ON a.parent_answer_id = r.id
+			WHERE a.deleted_at IS NULL
+		)
+		UPDATE answers SET deleted_at = ? WHERE id IN (SELECT id FROM replies)`,
+		id, now,
+	).Error
+}
+
+func (r *GormAnswerRepository) Update(ctx context.Context, a *domain.Answer) error {
+	res := r.conn.Writer(ctx).
+		Model(a).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "version"}}}).
+		Updates(map[string]any{
+			"user_id":          a.UserID,
+			"text":             a.Text,
+			"created_at":       a.CreatedAt,
+			"parent_answer_id": a.ParentAnswerID,
+			"version":          gorm.Expr("version + 1"),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *GormAnswerRepository) Restore(ctx context.Context, id int) (*domain.Answer, error) {
+	db := r.conn.Writer(ctx)
+
+	var a domain.Answer
+	err := db.Unscoped().
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("deleted_at IS NOT NULL").
+		First(&a, id).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Exec(`
+		WITH RECURSIVE replies AS (
+			SELECT id FROM answers WHERE parent_answer_id = ? AND deleted_at = ?
+			UNION ALL
+			SELECT a.id FROM answers a JOIN replies r ON a.parent_answer_id = r.id
+			WHERE a.deleted_at = ?
+		)
+		UPDATE answers SET deleted_at = NULL WHERE id = ? OR id IN (SELECT id FROM replies)`,
+		id, a.DeletedAt, a.DeletedAt, id,
+	).Error
+	if err != nil {
+		return nil, err
+	}
+
+	a.DeletedAt = gorm.DeletedAt{}
+	return &a, nil
+}
+
+func (r *GormAnswerRepository) ListDeleted(ctx context.Context, afterID, limit int) ([]domain.Answer, error) {
+	var answers []domain.Answer
+	err := r.conn.Reader(ctx).Raw(`
+		SELECT a.id, a.question_id, a.parent_answer_id, a.user_id, a.text, a.created_at, a.version, a.deleted_at
+		FROM answers a
+		JOIN questions q ON q.id = a.question_id AND q.deleted_at IS NULL
+		LEFT JOIN answers p ON p.id = a.parent_answer_id
+		WHERE a.deleted_at IS NOT NULL AND a.id > ?
+			AND (p.id IS NULL OR p.deleted_at IS DISTINCT FROM a.deleted_at)
+		ORDER BY a.id
+		LIMIT ?`, afterID, limit,
+	).Scan(&answers).Error
+	return answers, err
+}
+
+func (r *GormAnswerRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	// реплики, удалённые позже родителя, удаляет каскад по FK
+	res := r.conn.Writer(ctx).
+		Unscoped().
+		Where("deleted_at < ?", before).
+		Delete(&domain.Answer{})
+	return res.RowsAffected, res.Error
 }
 
 func (r *GormAnswerRepository) ListByQuestionID(ctx context.Context, questionID int) ([]domain.Answer, error) {
 	var answers []domain.Answer
-	err := r.db.WithContext(ctx).Where("question_id = ?", questionID).Find(&answers).Error
+	err := r.conn.Reader(ctx).Where("question_id = ?", questionID).Find(&answers).Error
+	return answers, err
+}
+
+func (r *GormAnswerRepository) ListByQuestionIDs(ctx context.Context, questionIDs []int, perQuestion int) ([]domain.Answer, error) {
+	var answers []domain.Answer
+	if len(questionIDs) == 0 {
+		return answers, nil
+	}
+
+	// скрытые ответы отсекаются вместе с ветками до нумерации, чтобы лимит
+	// считал только видимые ответы
+	err := r.conn.Reader(ctx).Raw(`
+		WITH RECURSIVE visible AS (
+			SELECT a.id, a.question_id, a.parent_answer_id, a.user_id, a.text, a.created_at, a.version
+			FROM answers a
+			WHERE a.question_id IN ? AND a.parent_answer_id IS NULL AND a.deleted_at IS NULL AND NOT a.hidden
+			UNION ALL
+			SELECT c.id, c.question_id, c.parent_answer_id, c.user_id, c.text, c.created_at, c.version
+			FROM answers c
+			JOIN visible v ON c.parent_answer_id = v.id
+			WHERE c.deleted_at IS NULL AND NOT c.hidden
+		)
+		SELECT id, question_id, parent_answer_id, user_id, text, created_at, version
+		FROM (
+			SELECT v.*, ROW_NUMBER() OVER (PARTITION BY question_id ORDER BY id) AS rn
+			FROM visible v
+		) ranked
+		WHERE ? <= 0 OR rn <= ?
+		ORDER BY question_id, id`,
+		questionIDs, perQuestion, perQuestion,
+	).Scan(&answers).Error
 	return answers, err
 }
+
+func (r *GormAnswerRepository) ListThread(ctx context.Context, questionID, maxDepth int) ([]domain.Answer, error) {
+	var answers []domain.Answer
+	// path — цепочка id от корня ветки, сортировка по нему даёт обход дерева в глубину
+	err := r.conn.Reader(ctx).Raw(`
+		WITH RECURSIVE thread AS (
+			SELECT a.id, a.question_id, a.parent_answer_id, a.user_id, a.text, a.created_at, a.version,
+				0 AS depth, ARRAY[a.id] AS path
+			FROM answers a
+			WHERE a.question_id = ? AND a.parent_answer_id IS NULL AND a.deleted_at IS NULL AND NOT a.hidden
+			UNION ALL
+			SELECT c.id, c.question_id, c.parent_answer_id, c.user_id, c.text, c.created_at, c.version,
+				t.depth + 1, t.path || c.id
+			FROM answers c
+			JOIN thread t ON c.parent_answer_id = t.id
+			WHERE t.depth < ? AND c.deleted_at IS NULL AND NOT c.hidden
+		)
+		SELECT id, question_id, parent_answer_id, user_id, text, created_at, version, depth
+		FROM thread
+		ORDER BY path`,
+		questionID, maxDepth,
+	).Scan(&answers).Error
+	return answers, err
+}
+
+func (r *GormAnswerRepository) Depth(ctx context.Context, id int) (int, error) {
+	var depth []int
+	err := r.conn.Reader(ctx).Raw(`
+		WITH RECURSIVE ancestors AS (
+			SELECT id, parent_answer_id, 0 AS depth
+			FROM answers
+			WHERE id = ?
+			UNION ALL
+			SELECT a.id, a.parent_answer_id, anc.depth + 1
+			FROM answers a
+			JOIN ancestors anc ON a.id = anc.parent_answer_id
+		)
+		SELECT MAX(depth) FROM ancestors
+		HAVING COUNT(*) > 0`,
+		id,
+	).Scan(&depth).Error
+	if err != nil {
+		return 0, err
+	}
+	if len(depth) == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return depth[0], nil
+}
+
+func (r *GormAnswerRepository) SetHidden(ctx context.Context, id int, hidden bool) error {
+	return updateColumn(r.conn.Writer(ctx), &domain.Answer{}, id, "hidden", hidden)
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"question-service/internal/domain"
+)
+
+type CommentRepository interface {
+	Create(ctx context.Context, c *domain.Comment) error
+	GetByID(ctx context.Context, id int) (*domain.Comment, error)
+	Delete(ctx context.Context, id int) error
+	// ListByQuestionID возвращает комментарии к самому вопросу по возрастанию id.
+	ListByQuestionID(ctx context.Context, questionID int) ([]domain.Comment, error)
+	// ListByAnswerIDs возвращает комментарии сразу к нескольким ответам одним
+	// запросом, по возрастанию id ответа, затем комментария.
+	ListByAnswerIDs(ctx context.Context, answerIDs []int) ([]domain.Comment, error)
+}
+
+type GormCommentRepository struct {
+	conn Connector
+}
+
+func NewCommentRepository(conn Connector) *GormCommentRepository {
+	return &GormCommentRepository{conn: conn}
+}
+
+func (r *GormCommentRepository) Create(ctx context.Context, c *domain.Comment) error {
+	return r.conn.Writer(ctx).Create(c).Error
+}
+
+func (r *GormCommentRepository) GetByID(ctx context.Context, id int) (*domain.Comment, error) {
+	var c domain.Comment
+	if err := r.conn.Reader(ctx).First(&c, id).Error; err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *GormCommentRepository) Delete(ctx context.Context, id int) error {
+	res := r.conn.Writer(ctx).Delete(&domain.Comment{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *GormCommentRepository) ListByQuestionID(ctx context.Context, questionID int) ([]domain.Comment, error) {
+	var comments []domain.Comment
+	err := r.conn.Reader(ctx).Where("question_id = ?", questionID).Order("id").Find(&comments).Error
+	return comments, err
+}
+
+func (r *GormCommentRepository) ListByAnswerIDs(ctx context.Context, answerIDs []int) ([]domain.Comment, error) {
+	var comments []domain.Comment
+	if len(answerIDs) == 0 {
+		return comments, nil
+	}
+	err := r.conn.Reader(ctx).
+		Where("answer_id IN ?", answerIDs).
+		Order("answer_id, id").
+		Find(&comments).Error
+	return comments, err
+}
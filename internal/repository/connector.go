@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// Connector выбирает подключение к БД в зависимости от того, читает операция или пишет.
+// Реализуется db.Cluster.
+type Connector interface {
+	Reader(ctx context.Context) *gorm.DB
+	Writer(ctx context.Context) *gorm.DB
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm/clause"
+
+	"question-service/internal/domain"
+)
+
+type IdempotencyRepository interface {
+	// Reserve занимает ключ rec.Key для rec.Method и rec.Path со статусом in_progress.
+	// Если ключ уже занят и не истёк, возвращает существующую запись; истёкшая
+	// запись, в том числе in_progress с истёкшей арендой, перезаписывается.
+	Reserve(ctx context.Context, rec *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error)
+	// Complete сохраняет ответ из rec, переводит ключ в статус completed и
+	// продлевает его до rec.ExpiresAt.
+	Complete(ctx context.Context, rec *domain.IdempotencyRecord) error
+	// Release освобождает ключ rec, чтобы запрос можно было повторить.
+	Release(ctx context.Context, rec *domain.IdempotencyRecord) error
+	// DeleteExpired удаляет ключи, истёкшие к моменту now, и возвращает их количество.
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+type GormIdempotencyRepository struct {
+	conn Connector
+}
+
+func NewIdempotencyRepository(conn Connector) *GormIdempotencyRepository {
+	return &GormIdempotencyRepository{conn: conn}
+}
+
+func (r *GormIdempotencyRepository) Reserve(ctx context.Context, rec *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error) {
+	res := r.conn.Writer(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "key"}, {Name: "method"}, {Name: "path"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"fingerprint", "status", "response_code", "content_type", "response_body", "created_at", "expires_at",
+			}),
+			Where: clause.Where{Exprs: []clause.Expression{
+				clause.Lt{Column: clause.Column{Table: "idempotency_keys", Name: "expires_at"}, Value: time.Now()},
+			}},
+		}).
+		Create(rec)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected > 0 {
+		return nil, nil
+	}
+
+	var existing domain.IdempotencyRecord
+	err := r.conn.Writer(ctx).
+		Where("key = ? AND method = ? AND path = ?", rec.Key, rec.Method, rec.Path).
+		First(&existing).Error
+	if err != nil {
+		return nil, err
+	}
+	return &existing, nil
+}
+
+func (r *GormIdempotencyRepository) Complete(ctx context.Context, rec *domain.IdempotencyRecord) error {
+	return r.conn.Writer(ctx).
+		Model(&domain.IdempotencyRecord{}).
+		Where("key = ? AND method = ? AND path = ?", rec.Key, rec.Method, rec.Path).
+		Updates(map[string]any{
+			"status":        domain.IdempotencyCompleted,
+			"response_code": rec.ResponseCode,
+			"content_type":  rec.ContentType,
+			"response_body": rec.ResponseBody,
+			"expires_at":    rec.ExpiresAt,
+		}).Error
+}
+
+func (r *GormIdempotencyRepository) Release(ctx context.Context, rec *domain.IdempotencyRecord) error {
+	return r.conn.Writer(ctx).
+		Where("key = ? AND method = ? AND path = ?", rec.Key, rec.Method, rec.Path).
+		Delete(&domain.IdempotencyRecord{}).Error
+}
+
+func (r *GormIdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	res := r.conn.Writer(ctx).Where("expires_at < ?", now).Delete(&domain.IdempotencyRecord{})
+	return res.RowsAffected, res.Error
+}
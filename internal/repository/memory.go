@@ -0,0 +1,1383 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"question-service/internal/domain"
+)
+
+// MemoryStore — хранилище в памяти для локального запуска и тестов.
+// Повторяет поведение PostgreSQL там, где на него опираются сервисы:
+// gorm.ErrRecordNotFound для отсутствующих записей, ErrStaleVersion при
+// несовпадении версии, gorm.ErrForeignKeyViolated для ответа на несуществующий
+// вопрос или комментарий к несуществующему вопросу или ответу,
+// gorm.ErrDuplicatedKey при повторе external_id вопроса или открытой жалобы,
+// мягкое удаление вопросов и ответов через DeletedAt и каскадное удаление ответов,
+// комментариев и жалоб при окончательном удалении.
+//
+// MemoryStore также реализует WithinTx: транзакция держит блокировку хранилища
+// до своего завершения и при ошибке откатывает все изменения.
+type MemoryStore struct {
+	mu sync.Mutex
+	memoryData
+
+	// relayMu — аналог advisory-блокировки relay; не связан с транзакциями.
+	relayMu sync.Mutex
+	// fanoutMu — то же для раздачи событий в очередь вебхуков.
+	fanoutMu sync.Mutex
+}
+
+// memoryData — содержимое хранилища; копируется целиком для отката транзакций.
+type memoryData struct {
+	questions      map[int]domain.Question
+	answers        map[int]domain.Answer
+	comments       map[int]domain.Comment
+	flags          map[int]domain.Flag
+	actions        []domain.ModerationAction
+	idempotency    map[idempotencyScope]domain.IdempotencyRecord
+	outbox         []domain.Event
+	webhooks       map[int64]domain.WebhookSubscription
+	deliveries     map[int64]domain.WebhookDelivery
+	nextQuestionID int
+	nextAnswerID   int
+	nextCommentID  int
+	nextFlagID     int
+	nextWebhookID  int64
+	nextDeliveryID int64
+}
+
+func (d memoryData) clone() memoryData {
+	d.questions = maps.Clone(d.questions)
+	d.answers = maps.Clone(d.answers)
+	d.comments = maps.Clone(d.comments)
+	d.flags = maps.Clone(d.flags)
+	d.actions = slices.Clone(d.actions)
+	d.idempotency = maps.Clone(d.idempotency)
+	d.outbox = slices.Clone(d.outbox)
+	d.webhooks = maps.Clone(d.webhooks)
+	d.deliveries = maps.Clone(d.deliveries)
+	return d
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		memoryData: memoryData{
+			questions:   make(map[int]domain.Question),
+			answers:     make(map[int]domain.Answer),
+			comments:    make(map[int]domain.Comment),
+			flags:       make(map[int]domain.Flag),
+			idempotency: make(map[idempotencyScope]domain.IdempotencyRecord),
+			webhooks:    make(map[int64]domain.WebhookSubscription),
+			deliveries:  make(map[int64]domain.WebhookDelivery),
+		},
+	}
+}
+
+type memoryTxKey struct{}
+
+// WithinTx выполняет fn атомарно относительно остальных операций хранилища.
+func (s *MemoryStore) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.inTx(ctx) {
+		return fn(ctx)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := s.memoryData.clone()
+	committed := false
+	defer func() {
+		if !committed {
+			s.memoryData = snapshot
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, memoryTxKey{}, s)); err != nil {
+		return err
+	}
+
+	committed = true
+	return nil
+}
+
+func (s *MemoryStore) inTx(ctx context.Context) bool {
+	owner, _ := ctx.Value(memoryTxKey{}).(*MemoryStore)
+	return owner == s
+}
+
+// lock захватывает хранилище вне транзакции; внутри транзакции блокировка уже удерживается.
+func (s *MemoryStore) lock(ctx context.Context) func() {
+	if s.inTx(ctx) {
+		return func() {}
+	}
+
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+// liveQuestion возвращает вопрос, если он есть и не удалён. Вызывается под блокировкой.
+func (s *MemoryStore) liveQuestion(id int) (domain.Question, bool) {
+	q, ok := s.questions[id]
+	return q, ok && !q.DeletedAt.Valid
+}
+
+// liveAnswer возвращает ответ, если он есть и не удалён. Вызывается под блокировкой.
+func (s *MemoryStore) liveAnswer(id int) (domain.Answer, bool) {
+	a, ok := s.answers[id]
+	return a, ok && !a.DeletedAt.Valid
+}
+
+// answersOf возвращает неудалённые ответы на вопрос в порядке создания. Вызывается под блокировкой.
+func (s *MemoryStore) answersOf(questionID int) []domain.Answer {
+	var out []domain.Answer
+	for _, a := range s.answers {
+		if a.QuestionID == questionID && !a.DeletedAt.Valid {
+			out = append(out, a)
+		}
+	}
+	slices.SortFunc(out, func(a, b domain.Answer) int { return a.ID - b.ID })
+	return out
+}
+
+// deleteQuestion окончательно удаляет вопрос с ответами, комментариями и жалобами,
+// а заглушки, ссылавшиеся на него, теряют ссылку. Вызывается под блокировкой.
+func (s *MemoryStore) deleteQuestion(id int) {
+	delete(s.questions, id)
+	for qid, q := range s.questions {
+		if q.MergedIntoID != nil && *q.MergedIntoID == id {
+			q.MergedIntoID = nil
+			s.questions[qid] = q
+		}
+	}
+	for aid, a := range s.answers {
+		if a.QuestionID == id {
+			s.deleteAnswer(aid)
+		}
+	}
+	for cid, c := range s.comments {
+		if c.QuestionID != nil && *c.QuestionID == id {
+			delete(s.comments, cid)
+		}
+	}
+	for fid, f := range s.flags {
+		if f.QuestionID != nil && *f.QuestionID == id {
+			delete(s.flags, fid)
+		}
+	}
+}
+
+// deleteAnswer окончательно удаляет ответ с репликами, комментариями и жалобами. Вызывается под блокировкой.
+func (s *MemoryStore) deleteAnswer(id int) {
+	delete(s.answers, id)
+	for rid, a := range s.answers {
+		if a.ParentAnswerID != nil && *a.ParentAnswerID == id {
+			s.deleteAnswer(rid)
+		}
+	}
+	for cid, c := range s.comments {
+		if c.AnswerID != nil && *c.AnswerID == id {
+			delete(s.comments, cid)
+		}
+	}
+	for fid, f := range s.flags {
+		if f.AnswerID != nil && *f.AnswerID == id {
+			delete(s.flags, fid)
+		}
+	}
+}
+
+type MemoryQuestionRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryQuestionRepository(store *MemoryStore) *MemoryQuestionRepository {
+	return &MemoryQuestionRepository{store: store}
+}
+
+func (r *MemoryQuestionRepository) Create(ctx context.Context, q *domain.Question) error {
+	defer r.store.lock(ctx)()
+
+	return r.store.insertQuestion(q)
+}
+
+func (r *MemoryQuestionRepository) CreateBatch(ctx context.Context, questions []domain.Question, _ int) error {
+	defer r.store.lock(ctx)()
+
+	for i := range questions {
+		if err := r.store.insertQuestion(&questions[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertQuestion сохраняет вопрос без ответов. Вызывается под блокировкой.
+func (s *MemoryStore) insertQuestion(q *domain.Question) error {
+	if q.ExternalID != nil {
+		for _, other := range s.questions {
+			if other.ExternalID != nil && *other.ExternalID == *q.ExternalID {
+				return gorm.ErrDuplicatedKey
+			}
+		}
+	}
+
+	s.nextQuestionID++
+	q.ID = s.nextQuestionID
+	q.Version = 1
+	if q.Status == "" {
+		q.Status = domain.QuestionOpen
+	}
+	if q.CreatedAt.IsZero() {
+		q.CreatedAt = time.Now().UTC()
+	}
+
+	stored := *q
+	stored.Answers = nil
+	s.questions[q.ID] = stored
+	return nil
+}
+
+func (r *MemoryQuestionRepository) ListPage(ctx context.Context, afterID, limit int) ([]domain.Question, error) {
+	defer r.store.lock(ctx)()
+
+	var out []domain.Question
+	for _, q := range r.store.questions {
+		if q.ID > afterID && !q.DeletedAt.Valid && !q.Hidden && q.MergedIntoID == nil {
+			out = append(out, q)
+		}
+	}
+	slices.SortFunc(out, func(a, b domain.Question) int { return a.ID - b.ID })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (r *MemoryQuestionRepository) List(ctx context.Context, status string, afterID, limit int) ([]domain.Question, error) {
+	defer r.store.lock(ctx)()
+
+	var out []domain.Question
+	for _, q := range r.store.questions {
+		if q.ID <= afterID || q.DeletedAt.Valid || q.Hidden || q.MergedIntoID != nil {
+			continue
+		}
+		if status == "" || q.Status == status {
+			out = append(out, q)
+		}
+	}
+	slices.SortFunc(out, func(a, b domain.Question) int { return a.ID - b.ID })
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (r *MemoryQuestionRepository) GetByID(ctx context.Context, id int) (*domain.Question, error) {
+	defer r.store.lock(ctx)()
+
+	q, ok := r.store.liveQuestion(id)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	q.Answers = r.store.answersOf(id)
+	return &q, nil
+}
+
+func (r *MemoryQuestionRepository) GetWithoutAnswers(ctx context.Context, id int) (*domain.Question, error) {
+	defer r.store.lock(ctx)()
+
+	q, ok := r.store.liveQuestion(id)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &q, nil
+}
+
+func (r *MemoryQuestionRepository) FindByExternalIDs(ctx context.Context, externalIDs []string) ([]domain.Question, error) {
+	defer r.store.lock(ctx)()
+
+	var out []domain.Question
+	for _, q := range r.store.questions {
+		if q.ExternalID != nil && slices.Contains(externalIDs, *q.ExternalID) {
+			out = append(out, q)
+		}
+	}
+	slices.SortFunc(out, func(a, b domain.Question) int { return a.ID - b.ID })
+	return out, nil
+}
+
+func (r *MemoryQuestionRepository) Update(ctx context.Context, q *domain.Question) error {
+	defer r.store.lock(ctx)()
+
+	stored, ok := r.store.liveQuestion(q.ID)
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	stored.Text = q.Text
+	stored.CreatedAt = q.CreatedAt
+	stored.Version++
+	r.store.questions[q.ID] = stored
+	q.Version = stored.Version
+	return nil
+}
+
+func (r *MemoryQuestionRepository) BumpVersion(ctx context.Context, id int) error {
+	defer r.store.lock(ctx)()
+
+	q, ok := r.store.liveQuestion(id)
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	q.Version++
+	r.store.questions[id] = q
+	return nil
+}
+
+func (r *MemoryQuestionRepository) Delete(ctx context.Context, id, version int) error {
+	defer r.store.lock(ctx)()
+
+	q, ok := r.store.liveQuestion(id)
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	if version > 0 && q.Version != version {
+		return ErrStaleVersion
+	}
+
+	at := gorm.DeletedAt{Time: time.Now().UTC(), Valid: true}
+	q.DeletedAt = at
+	r.store.questions[id] = q
+	for _, a := range r.store.answersOf(id) {
+		a.DeletedAt = at
+		r.store.answers[a.ID] = a
+	}
+	return nil
+}
+
+func (r *MemoryQuestionRepository) Restore(ctx context.Context, id int) error {
+	defer r.store.lock(ctx)()
+
+	q, ok := r.store.questions[id]
+	if !ok || !q.DeletedAt.Valid {
+		return gorm.ErrRecordNotFound
+	}
+
+	for aid, a := range r.store.answers {
+		if a.QuestionID == id && a.DeletedAt == q.DeletedAt {
+			a.DeletedAt = gorm.DeletedAt{}
+			r.store.answers[aid] = a
+		}
+	}
+	q.DeletedAt = gorm.DeletedAt{}
+	r.store.questions[id] = q
+	return nil
+}
+
+func (r *MemoryQuestionRepository) ListDeleted(ctx context.Context, afterID, limit int) ([]domain.Question, error) {
+	defer r.store.lock(ctx)()
+
+	var out []domain.Question
+	for _, q := range r.store.questions {
+		if q.DeletedAt.Valid && q.ID > afterID {
+			out = append(out, q)
+		}
+	}
+	slices.SortFunc(out, func(a, b domain.Question) int { return a.ID - b.ID })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (r *MemoryQuestionRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	defer r.store.lock(ctx)()
+
+	var n int64
+	for id, q := range r.store.questions {
+		if q.DeletedAt.Valid && q.DeletedAt.Time.Before(before) {
+			r.store.deleteQuestion(id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *MemoryQuestionRepository) SetHidden(ctx context.Context, id int, hidden bool) error {
+	defer r.store.lock(ctx)()
+
+	q, ok := r.store.liveQuestion(id)
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	q.Hidden = hidden
+	r.store.questions[id] = q
+	return nil
+}
+
+func (r *MemoryQuestionRepository) SetStatus(ctx context.Context, st *domain.Question) error {
+	defer r.store.lock(ctx)()
+
+	q, ok := r.store.liveQuestion(st.ID)
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	q.Status = st.Status
+	q.CloseReason = st.CloseReason
+	q.ClosedAt = st.ClosedAt
+	q.ClosedBy = st.ClosedBy
+	q.LockedAt = st.LockedAt
+	q.LockedBy = st.LockedBy
+	r.store.questions[st.ID] = q
+	return nil
+}
+
+func (r *MemoryQuestionRepository) FindSimilar(ctx context.Context, text string, threshold float64, limit int) ([]domain.DuplicateCandidate, error) {
+	defer r.store.lock(ctx)()
+
+	// pg_trgm в памяти нет, поэтому тексты сравниваются по словам
+	var out []domain.DuplicateCandidate
+	for _, q := range r.store.questions {
+		if q.DeletedAt.Valid || q.Hidden || q.MergedIntoID != nil {
+			continue
+		}
+		if score := tokenSimilarity(text, q.Text); score >= threshold {
+			out = append(out, domain.DuplicateCandidate{ID: q.ID, Text: q.Text, Status: q.Status, Score: score})
+		}
+	}
+	slices.SortFunc(out, func(a, b domain.DuplicateCandidate) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		return a.ID - b.ID
+	})
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (r *MemoryQuestionRepository) Merge(ctx context.Context, id, targetID int) (int64, error) {
+	defer r.store.lock(ctx)()
+
+	q, ok := r.store.liveQuestion(id)
+	if !ok {
+		return 0, gorm.ErrRecordNotFound
+	}
+	if _, ok := r.store.questions[targetID]; !ok {
+		return 0, gorm.ErrForeignKeyViolated
+	}
+
+	var n int64
+	for aid, a := range r.store.answers {
+		if a.QuestionID == id {
+			a.QuestionID = targetID
+			r.store.answers[aid] = a
+			n++
+		}
+	}
+	for qid, stub := range r.store.questions {
+		if stub.MergedIntoID != nil && *stub.MergedIntoID == id {
+			stub.MergedIntoID = &targetID
+			r.store.questions[qid] = stub
+		}
+	}
+
+	q.MergedIntoID = &targetID
+	r.store.questions[id] = q
+	return n, nil
+}
+
+type MemoryAnswerRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryAnswerRepository(store *MemoryStore) *MemoryAnswerRepository {
+	return &MemoryAnswerRepository{store: store}
+}
+
+func (r *MemoryAnswerRepository) Create(ctx context.Context, a *domain.Answer) error {
+	defer r.store.lock(ctx)()
+
+	return r.store.insertAnswer(a)
+}
+
+func (r *MemoryAnswerRepository) CreateBatch(ctx context.Context, answers []domain.Answer, _ int) error {
+	defer r.store.lock(ctx)()
+
+	for i := range answers {
+		if err := r.store.insertAnswer(&answers[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *MemoryAnswerRepository) CreateMany(ctx context.Context, answers []domain.Answer) error {
+	return r.CreateBatch(ctx, answers, len(answers))
+}
+
+// insertAnswer сохраняет ответ, проверяя, что вопрос и родительский ответ существуют.
+// Вызывается под блокировкой.
+func (s *MemoryStore) insertAnswer(a *domain.Answer) error {
+	if _, ok := s.questions[a.QuestionID]; !ok {
+		return gorm.ErrForeignKeyViolated
+	}
+	if a.ParentAnswerID != nil {
+		if _, ok := s.answers[*a.ParentAnswerID]; !ok {
+			return gorm.ErrForeignKeyViolated
+		}
+	}
+
+	s.nextAnswerID++
+	a.ID = s.nextAnswerID
+	a.Version = 1
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = time.Now().UTC()
+	}
+	s.answers[a.ID] = *a
+	return nil
+}
+
+func (r *MemoryAnswerRepository) GetByID(ctx context.Context, id int) (*domain.Answer, error) {
+	defer r.store.lock(ctx)()
+
+	a, ok := r.store.liveAnswer(id)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &a, nil
+}
+
+func (r *MemoryAnswerRepository) Delete(ctx context.Context, id, version int) error {
+	defer r.store.lock(ctx)()
+
+	a, ok := r.store.liveAnswer(id)
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	if version > 0 && a.Version != version {
+		return ErrStaleVersion
+	}
+
+	r.store.softDeleteAnswer(id, gorm.DeletedAt{Time: time.Now().UTC(), Valid: true})
+	return nil
+}
+
+// softDeleteAnswer помечает ответ и его неудалённые реплики удалёнными в момент at.
+// Вызывается под блокировкой.
+func (s *MemoryStore) softDeleteAnswer(id int, at gorm.DeletedAt) {
+	a := s.answers[id]
+	a.DeletedAt = at
+	s.answers[id] = a
+	for rid, reply := range s.answers {
+		if reply.ParentAnswerID != nil && *reply.ParentAnswerID == id && !reply.DeletedAt.Valid {
+			s.softDeleteAnswer(rid, at)
+		}
+	}
+}
+
+func (r *MemoryAnswerRepository) Update(ctx context.Context, a *domain.Answer) error {
+	defer r.store.lock(ctx)()
+
+	stored, ok := r.store.liveAnswer(a.ID)
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	stored.UserID = a.UserID
+	stored.Text = a.Text
+	stored.CreatedAt = a.CreatedAt
+	stored.ParentAnswerID = a.ParentAnswerID
+	stored.Version++
+	r.store.answers[a.ID] = stored
+	a.Version = stored.Version
+	return nil
+}
+
+func (r *MemoryAnswerRepository) Restore(ctx context.Context, id int) (*domain.Answer, error) {
+	defer r.store.lock(ctx)()
+
+	a, ok := r.store.answers[id]
+	if !ok || !a.DeletedAt.Valid {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	r.store.restoreAnswer(id, a.DeletedAt)
+	a.DeletedAt = gorm.DeletedAt{}
+	return &a, nil
+}
+
+// restoreAnswer снимает отметку удаления с ответа и его реплик, удалённых в момент at.
+// Вызывается под блокировкой.
+func (s *MemoryStore) restoreAnswer(id int, at gorm.DeletedAt) {
+	a := s.answers[id]
+	a.DeletedAt = gorm.DeletedAt{}
+	s.answers[id] = a
+	for rid, reply := range s.answers {
+		if reply.ParentAnswerID != nil && *reply.ParentAnswerID == id && reply.DeletedAt == at {
+			s.restoreAnswer(rid, at)
+		}
+	}
+}
+
+func (r *MemoryAnswerRepository) ListDeleted(ctx context.Context, afterID, limit int) ([]domain.Answer, error) {
+	defer r.store.lock(ctx)()
+
+	var out []domain.Answer
+	for _, a := range r.store.answers {
+		if !a.DeletedAt.Valid || a.ID <= afterID {
+			continue
+		}
+		if _, ok := r.store.liveQuestion(a.QuestionID); !ok {
+			continue
+		}
+		if a.ParentAnswerID != nil && r.store.answers[*a.ParentAnswerID].DeletedAt == a.DeletedAt {
+			continue
+		}
+		out = append(out, a)
+	}
+	slices.SortFunc(out, func(a, b domain.Answer) int { return a.ID - b.ID })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (r *MemoryAnswerRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	defer r.store.lock(ctx)()
+
+	var n int64
+	for id, a := range r.store.answers {
+		if a.DeletedAt.Valid && a.DeletedAt.Time.Before(before) {
+			r.store.deleteAnswer(id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *MemoryAnswerRepository) ListByQuestionID(ctx context.Context, questionID int) ([]domain.Answer, error) {
+	defer r.store.lock(ctx)()
+
+	return r.store.answersOf(questionID), nil
+}
+
+func (r *MemoryAnswerRepository) ListByQuestionIDs(ctx context.Context, questionIDs []int, perQuestion int) ([]domain.Answer, error) {
+	defer r.store.lock(ctx)()
+
+	ids := slices.Sorted(slices.Values(questionIDs))
+	var out []domain.Answer
+	for _, id := range slices.Compact(ids) {
+		// реплика создаётся позже родителя, поэтому при обходе по id
+		// родитель всегда проверен раньше
+		hidden := make(map[int]bool)
+		n := 0
+		for _, a := range r.store.answersOf(id) {
+			if a.Hidden || (a.ParentAnswerID != nil && hidden[*a.ParentAnswerID]) {
+				hidden[a.ID] = true
+				continue
+			}
+			if perQuestion > 0 && n == perQuestion {
+				break
+			}
+			out = append(out, a)
+			n++
+		}
+	}
+	return out, nil
+}
+
+func (r *MemoryAnswerRepository) ListThread(ctx context.Context, questionID, maxDepth int) ([]domain.Answer, error) {
+	defer r.store.lock(ctx)()
+
+	replies := make(map[int][]domain.Answer)
+	for _, a := range r.store.answersOf(questionID) {
+		parent := 0
+		if a.ParentAnswerID != nil {
+			parent = *a.ParentAnswerID
+		}
+		replies[parent] = append(replies[parent], a)
+	}
+
+	var out []domain.Answer
+	var walk func(parent, depth int)
+	walk = func(parent, depth int) {
+		for _, a := range replies[parent] {
+			if a.Hidden {
+				continue
+			}
+			a.Depth = depth
+			out = append(out, a)
+			if depth < maxDepth {
+				walk(a.ID, depth+1)
+			}
+		}
+	}
+	walk(0, 0)
+	return out, nil
+}
+
+func (r *MemoryAnswerRepository) Depth(ctx context.Context, id int) (int, error) {
+	defer r.store.lock(ctx)()
+
+	a, ok := r.store.answers[id]
+	if !ok {
+		return 0, gorm.ErrRecordNotFound
+	}
+	depth := 0
+	for a.ParentAnswerID != nil {
+		a = r.store.answers[*a.ParentAnswerID]
+		depth++
+	}
+	return depth, nil
+}
+
+func (r *MemoryAnswerRepository) SetHidden(ctx context.Context, id int, hidden bool) error {
+	defer r.store.lock(ctx)()
+
+	a, ok := r.store.liveAnswer(id)
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	a.Hidden = hidden
+	r.store.answers[id] = a
+	return nil
+}
+
+type MemoryCommentRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryCommentRepository(store *MemoryStore) *MemoryCommentRepository {
+	return &MemoryCommentRepository{store: store}
+}
+
+func (r *MemoryCommentRepository) Create(ctx context.Context, c *domain.Comment) error {
+	defer r.store.lock(ctx)()
+
+	switch {
+	case c.QuestionID != nil:
+		if _, ok := r.store.questions[*c.QuestionID]; !ok {
+			return gorm.ErrForeignKeyViolated
+		}
+	case c.AnswerID != nil:
+		if _, ok := r.store.answers[*c.AnswerID]; !ok {
+			return gorm.ErrForeignKeyViolated
+		}
+	}
+
+	r.store.nextCommentID++
+	c.ID = r.store.nextCommentID
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = time.Now().UTC()
+	}
+	r.store.comments[c.ID] = *c
+	return nil
+}
+
+func (r *MemoryCommentRepository) GetByID(ctx context.Context, id int) (*domain.Comment, error) {
+	defer r.store.lock(ctx)()
+
+	c, ok := r.store.comments[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &c, nil
+}
+
+func (r *MemoryCommentRepository) Delete(ctx context.Context, id int) error {
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.comments[id]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	delete(r.store.comments, id)
+	return nil
+}
+
+func (r *MemoryCommentRepository) ListByQuestionID(ctx context.Context, questionID int) ([]domain.Comment, error) {
+	defer r.store.lock(ctx)()
+
+	var out []domain.Comment
+	for _, c := range r.store.comments {
+		if c.QuestionID != nil && *c.QuestionID == questionID {
+			out = append(out, c)
+		}
+	}
+	slices.SortFunc(out, func(a, b domain.Comment) int { return a.ID - b.ID })
+	return out, nil
+}
+
+func (r *MemoryCommentRepository) ListByAnswerIDs(ctx context.Context, answerIDs []int) ([]domain.Comment, error) {
+	defer r.store.lock(ctx)()
+
+	var out []domain.Comment
+	for _, c := range r.store.comments {
+		if c.AnswerID != nil && slices.Contains(answerIDs, *c.AnswerID) {
+			out = append(out, c)
+		}
+	}
+	slices.SortFunc(out, func(a, b domain.Comment) int {
+		if *a.AnswerID != *b.AnswerID {
+			return *a.AnswerID - *b.AnswerID
+		}
+		return a.ID - b.ID
+	})
+	return out, nil
+}
+
+type MemoryModerationRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryModerationRepository(store *MemoryStore) *MemoryModerationRepository {
+	return &MemoryModerationRepository{store: store}
+}
+
+func (r *MemoryModerationRepository) CreateFlag(ctx context.Context, f *domain.Flag) error {
+	defer r.store.lock(ctx)()
+
+	switch {
+	case f.QuestionID != nil:
+		if _, ok := r.store.questions[*f.QuestionID]; !ok {
+			return gorm.ErrForeignKeyViolated
+		}
+	case f.AnswerID != nil:
+		if _, ok := r.store.answers[*f.AnswerID]; !ok {
+			return gorm.ErrForeignKeyViolated
+		}
+	}
+	for _, other := range r.store.openFlags(f.QuestionID, f.AnswerID) {
+		if other.UserID == f.UserID {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+
+	r.store.nextFlagID++
+	f.ID = r.store.nextFlagID
+	if f.CreatedAt.IsZero() {
+		f.CreatedAt = time.Now().UTC()
+	}
+	r.store.flags[f.ID] = *f
+	return nil
+}
+
+// openFlags возвращает открытые жалобы на вопрос questionID или ответ answerID
+// (задан ровно один). Вызывается под блокировкой.
+func (s *MemoryStore) openFlags(questionID, answerID *int) []domain.Flag {
+	var out []domain.Flag
+	for _, f := range s.flags {
+		if f.ResolvedAt != nil {
+			continue
+		}
+		if questionID != nil && f.QuestionID != nil && *f.QuestionID == *questionID ||
+			answerID != nil && f.AnswerID != nil && *f.AnswerID == *answerID {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func (r *MemoryModerationRepository) ResolveFlags(ctx context.Context, questionID, answerID *int, at time.Time) (int64, error) {
+	defer r.store.lock(ctx)()
+
+	open := r.store.openFlags(questionID, answerID)
+	for _, f := range open {
+		f.ResolvedAt = &at
+		r.store.flags[f.ID] = f
+	}
+	return int64(len(open)), nil
+}
+
+func (r *MemoryModerationRepository) Queue(ctx context.Context, limit int) ([]domain.QueueItem, error) {
+	defer r.store.lock(ctx)()
+
+	type key struct {
+		target string
+		id     int
+	}
+	byTarget := make(map[key]*domain.QueueItem)
+	for _, f := range r.store.flags {
+		if f.ResolvedAt != nil {
+			continue
+		}
+
+		var it domain.QueueItem
+		if f.QuestionID != nil {
+			q, ok := r.store.liveQuestion(*f.QuestionID)
+			if !ok || q.Hidden {
+				continue
+			}
+			it = domain.QueueItem{Target: domain.TargetQuestion, ID: q.ID, QuestionID: q.ID, Text: q.Text}
+		} else {
+			a, ok := r.store.liveAnswer(*f.AnswerID)
+			if !ok || a.Hidden {
+				continue
+			}
+			it = domain.QueueItem{Target: domain.TargetAnswer, ID: a.ID, QuestionID: a.QuestionID, Text: a.Text}
+		}
+
+		item, ok := byTarget[key{it.Target, it.ID}]
+		if !ok {
+			it.Reasons = make(map[string]int)
+			it.FirstFlaggedAt = f.CreatedAt
+			it.LastFlaggedAt = f.CreatedAt
+			item = &it
+			byTarget[key{it.Target, it.ID}] = item
+		}
+		item.Flags++
+		item.Reasons[f.Reason]++
+		if f.CreatedAt.Before(item.FirstFlaggedAt) {
+			item.FirstFlaggedAt = f.CreatedAt
+		}
+		if f.CreatedAt.After(item.LastFlaggedAt) {
+			item.LastFlaggedAt = f.CreatedAt
+		}
+	}
+
+	out := make([]domain.QueueItem, 0, len(byTarget))
+	for _, item := range byTarget {
+		out = append(out, *item)
+	}
+	slices.SortFunc(out, func(a, b domain.QueueItem) int {
+		if a.Flags != b.Flags {
+			return b.Flags - a.Flags
+		}
+		if c := a.FirstFlaggedAt.Compare(b.FirstFlaggedAt); c != 0 {
+			return c
+		}
+		return a.ID - b.ID
+	})
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (r *MemoryModerationRepository) CreateAction(ctx context.Context, a *domain.ModerationAction) error {
+	defer r.store.lock(ctx)()
+
+	a.ID = len(r.store.actions) + 1
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = time.Now().UTC()
+	}
+	r.store.actions = append(r.store.actions, *a)
+	return nil
+}
+
+func (r *MemoryModerationRepository) ListActions(ctx context.Context, questionID, limit int) ([]domain.ModerationAction, error) {
+	defer r.store.lock(ctx)()
+
+	var out []domain.ModerationAction
+	for _, a := range slices.Backward(r.store.actions) {
+		if len(out) == limit {
+			break
+		}
+		if questionID > 0 && a.QuestionID != questionID {
+			continue
+		}
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+type MemoryIdempotencyRepository struct {
+	store *MemoryStore
+}
+
+// idempotencyScope — первичный ключ idempotency_keys.
+type idempotencyScope struct {
+	key, method, path string
+}
+
+func scopeOf(rec *domain.IdempotencyRecord) idempotencyScope {
+	return idempotencyScope{rec.Key, rec.Method, rec.Path}
+}
+
+func NewMemoryIdempotencyRepository(store *MemoryStore) *MemoryIdempotencyRepository {
+	return &MemoryIdempotencyRepository{store: store}
+}
+
+func (r *MemoryIdempotencyRepository) Reserve(ctx context.Context, rec *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error) {
+	defer r.store.lock(ctx)()
+
+	if existing, ok := r.store.idempotency[scopeOf(rec)]; ok && !existing.ExpiresAt.Before(time.Now()) {
+		return &existing, nil
+	}
+
+	if rec.CreatedAt.IsZero() {
+		rec.CreatedAt = time.Now().UTC()
+	}
+	r.store.idempotency[scopeOf(rec)] = *rec
+	return nil, nil
+}
+
+func (r *MemoryIdempotencyRepository) Complete(ctx context.Context, done *domain.IdempotencyRecord) error {
+	defer r.store.lock(ctx)()
+
+	rec, ok := r.store.idempotency[scopeOf(done)]
+	if !ok {
+		return nil
+	}
+	rec.Status = domain.IdempotencyCompleted
+	rec.ResponseCode = done.ResponseCode
+	rec.ContentType = done.ContentType
+	rec.ResponseBody = slices.Clone(done.ResponseBody)
+	rec.ExpiresAt = done.ExpiresAt
+	r.store.idempotency[scopeOf(done)] = rec
+	return nil
+}
+
+func (r *MemoryIdempotencyRepository) Release(ctx context.Context, rec *domain.IdempotencyRecord) error {
+	defer r.store.lock(ctx)()
+
+	delete(r.store.idempotency, scopeOf(rec))
+	return nil
+}
+
+func (r *MemoryIdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	defer r.store.lock(ctx)()
+
+	var n int64
+	for key, rec := range r.store.idempotency {
+		if rec.ExpiresAt.Before(now) {
+			delete(r.store.idempotency, key)
+			n++
+		}
+	}
+	return n, nil
+}
+
+type MemoryOutboxRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryOutboxRepository(store *MemoryStore) *MemoryOutboxRepository {
+	return &MemoryOutboxRepository{store: store}
+}
+
+func (r *MemoryOutboxRepository) Append(ctx context.Context, events ...domain.Event) error {
+	defer r.store.lock(ctx)()
+
+	for i := range events {
+		events[i].ID = int64(len(r.store.outbox) + 1)
+		if events[i].CreatedAt.IsZero() {
+			events[i].CreatedAt = time.Now().UTC()
+		}
+		r.store.outbox = append(r.store.outbox, events[i])
+	}
+	return nil
+}
+
+func (r *MemoryOutboxRepository) FetchUnpublished(ctx context.Context, limit int) ([]domain.Event, error) {
+	defer r.store.lock(ctx)()
+
+	type aggregateKey struct {
+		typ string
+		id  int
+	}
+	blocked := make(map[aggregateKey]bool)
+
+	var out []domain.Event
+	for _, e := range r.store.outbox {
+		if len(out) == limit {
+			break
+		}
+		if e.PublishedAt != nil || e.DeadAt != nil {
+			continue
+		}
+		key := aggregateKey{e.AggregateType, e.AggregateID}
+		if blocked[key] {
+			continue
+		}
+		if e.Attempts > 0 {
+			blocked[key] = true
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+func (r *MemoryOutboxRepository) FindByIDs(ctx context.Context, ids []int64) ([]domain.Event, error) {
+	defer r.store.lock(ctx)()
+
+	var out []domain.Event
+	for _, id := range ids {
+		if e := r.event(id); e != nil {
+			out = append(out, *e)
+		}
+	}
+	slices.SortFunc(out, func(a, b domain.Event) int { return int(a.ID - b.ID) })
+	return out, nil
+}
+
+func (r *MemoryOutboxRepository) MarkPublished(ctx context.Context, id int64, at time.Time) error {
+	defer r.store.lock(ctx)()
+
+	if e := r.event(id); e != nil {
+		e.PublishedAt = &at
+	}
+	return nil
+}
+
+func (r *MemoryOutboxRepository) MarkFailed(ctx context.Context, id int64, reason string) error {
+	defer r.store.lock(ctx)()
+
+	if e := r.event(id); e != nil {
+		e.Attempts++
+		e.LastError = reason
+	}
+	return nil
+}
+
+func (r *MemoryOutboxRepository) MarkDead(ctx context.Context, id int64, reason string, at time.Time) error {
+	defer r.store.lock(ctx)()
+
+	if e := r.event(id); e != nil {
+		e.Attempts++
+		e.LastError = reason
+		e.DeadAt = &at
+	}
+	return nil
+}
+
+func (r *MemoryOutboxRepository) WithRelayLock(ctx context.Context, fn func(ctx context.Context) error) (bool, error) {
+	if !r.store.relayMu.TryLock() {
+		return false, nil
+	}
+	defer r.store.relayMu.Unlock()
+
+	return true, fn(ctx)
+}
+
+func (r *MemoryOutboxRepository) FetchNotFannedOut(ctx context.Context, limit int) ([]domain.Event, error) {
+	defer r.store.lock(ctx)()
+
+	var out []domain.Event
+	for _, e := range r.store.outbox {
+		if len(out) == limit {
+			break
+		}
+		if e.FannedOutAt == nil {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (r *MemoryOutboxRepository) MarkFannedOut(ctx context.Context, id int64, at time.Time) error {
+	defer r.store.lock(ctx)()
+
+	if e := r.event(id); e != nil {
+		e.FannedOutAt = &at
+	}
+	return nil
+}
+
+func (r *MemoryOutboxRepository) WithFanoutLock(ctx context.Context, fn func(ctx context.Context) error) (bool, error) {
+	if !r.store.fanoutMu.TryLock() {
+		return false, nil
+	}
+	defer r.store.fanoutMu.Unlock()
+
+	return true, fn(ctx)
+}
+
+// event возвращает событие по id (id совпадает с позицией в outbox). Вызывается под блокировкой.
+func (r *MemoryOutboxRepository) event(id int64) *domain.Event {
+	if id < 1 || id > int64(len(r.store.outbox)) {
+		return nil
+	}
+	return &r.store.outbox[id-1]
+}
+
+type MemoryWebhookRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryWebhookRepository(store *MemoryStore) *MemoryWebhookRepository {
+	return &MemoryWebhookRepository{store: store}
+}
+
+func (r *MemoryWebhookRepository) CreateSubscription(ctx context.Context, s *domain.WebhookSubscription) error {
+	defer r.store.lock(ctx)()
+
+	r.store.nextWebhookID++
+	s.ID = r.store.nextWebhookID
+	now := time.Now().UTC()
+	s.CreatedAt, s.UpdatedAt = now, now
+	s.EventTypes = slices.Clone(s.EventTypes)
+	r.store.webhooks[s.ID] = *s
+	return nil
+}
+
+func (r *MemoryWebhookRepository) GetSubscription(ctx context.Context, id int64) (*domain.WebhookSubscription, error) {
+	defer r.store.lock(ctx)()
+
+	s, ok := r.store.webhooks[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &s, nil
+}
+
+func (r *MemoryWebhookRepository) ListSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	defer r.store.lock(ctx)()
+
+	return r.subscriptions(func(domain.WebhookSubscription) bool { return true }), nil
+}
+
+func (r *MemoryWebhookRepository) ListActiveSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	defer r.store.lock(ctx)()
+
+	return r.subscriptions(func(s domain.WebhookSubscription) bool { return s.Active }), nil
+}
+
+func (r *MemoryWebhookRepository) UpdateSubscription(ctx context.Context, s *domain.WebhookSubscription) error {
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.webhooks[s.ID]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	s.UpdatedAt = time.Now().UTC()
+	r.store.webhooks[s.ID] = *s
+	return nil
+}
+
+func (r *MemoryWebhookRepository) DeleteSubscription(ctx context.Context, id int64) error {
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.webhooks[id]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	delete(r.store.webhooks, id)
+	for did, d := range r.store.deliveries {
+		if d.SubscriptionID == id {
+			delete(r.store.deliveries, did)
+		}
+	}
+	return nil
+}
+
+func (r *MemoryWebhookRepository) RecordSubscriptionSuccess(ctx context.Context, id int64) error {
+	defer r.store.lock(ctx)()
+
+	if s, ok := r.store.webhooks[id]; ok {
+		s.ConsecutiveFailures = 0
+		r.store.webhooks[id] = s
+	}
+	return nil
+}
+
+func (r *MemoryWebhookRepository) RecordSubscriptionFailure(ctx context.Context, id int64, disableAfter int, now time.Time) (bool, error) {
+	defer r.store.lock(ctx)()
+
+	s, ok := r.store.webhooks[id]
+	if !ok {
+		return false, gorm.ErrRecordNotFound
+	}
+	s.ConsecutiveFailures++
+	if s.Active && disableAfter > 0 && s.ConsecutiveFailures >= disableAfter {
+		s.Active = false
+		s.DisabledAt = &now
+	}
+	s.UpdatedAt = now
+	r.store.webhooks[id] = s
+	return !s.Active, nil
+}
+
+func (r *MemoryWebhookRepository) EnqueueDeliveries(ctx context.Context, deliveries []domain.WebhookDelivery) error {
+	defer r.store.lock(ctx)()
+
+	for _, d := range deliveries {
+		duplicate := false
+		for _, existing := range r.store.deliveries {
+			if existing.SubscriptionID == d.SubscriptionID && existing.EventID == d.EventID {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+
+		r.store.nextDeliveryID++
+		d.ID = r.store.nextDeliveryID
+		d.CreatedAt = time.Now().UTC()
+		if d.Status == "" {
+			d.Status = domain.DeliveryPending
+		}
+		r.store.deliveries[d.ID] = d
+	}
+	return nil
+}
+
+func (r *MemoryWebhookRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error) {
+	defer r.store.lock(ctx)()
+
+	var due []domain.WebhookDelivery
+	for _, d := range r.store.deliveries {
+		if d.Status != domain.DeliveryPending || d.NextAttemptAt.After(now) || !r.store.webhooks[d.SubscriptionID].Active {
+			continue
+		}
+		due = append(due, d)
+	}
+	slices.SortFunc(due, func(a, b domain.WebhookDelivery) int {
+		if c := a.NextAttemptAt.Compare(b.NextAttemptAt); c != 0 {
+			return c
+		}
+		return int(a.ID - b.ID)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	for i := range due {
+		due[i].NextAttemptAt = now.Add(lease)
+		r.store.deliveries[due[i].ID] = due[i]
+	}
+	return due, nil
+}
+
+func (r *MemoryWebhookRepository) SaveDeliveryResult(ctx context.Context, d *domain.WebhookDelivery) error {
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.deliveries[d.ID]; ok {
+		r.store.deliveries[d.ID] = *d
+	}
+	return nil
+}
+
+func (r *MemoryWebhookRepository) ListDeliveries(ctx context.Context, subscriptionID int64, limit int) ([]domain.WebhookDelivery, error) {
+	defer r.store.lock(ctx)()
+
+	var out []domain.WebhookDelivery
+	for _, d := range r.store.deliveries {
+		if d.SubscriptionID == subscriptionID {
+			out = append(out, d)
+		}
+	}
+	slices.SortFunc(out, func(a, b domain.WebhookDelivery) int { return int(b.ID - a.ID) })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// subscriptions возвращает подписки, удовлетворяющие keep, по возрастанию id. Вызывается под блокировкой.
+func (r *MemoryWebhookRepository) subscriptions(keep func(domain.WebhookSubscription) bool) []domain.WebhookSubscription {
+	var out []domain.WebhookSubscription
+	for _, s := range r.store.webhooks {
+		if keep(s) {
+			out = append(out, s)
+		}
+	}
+	slices.SortFunc(out, func(a, b domain.WebhookSubscription) int { return int(a.ID - b.ID) })
+	return out
+}
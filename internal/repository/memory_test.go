@@ -0,0 +1,352 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"question-service/internal/domain"
+	"question-service/internal/repository"
+)
+
+func TestMemoryStore_WithinTx_RollsBackOnError(t *testing.T) {
+	store := repository.NewMemoryStore()
+	qRepo := repository.NewMemoryQuestionRepository(store)
+	aRepo := repository.NewMemoryAnswerRepository(store)
+	ctx := context.Background()
+
+	q := &domain.Question{Text: "What is GORM?"}
+	require.NoError(t, qRepo.Create(ctx, q))
+
+	errBoom := errors.New("boom")
+	err := store.WithinTx(ctx, func(ctx context.Context) error {
+		require.NoError(t, aRepo.Create(ctx, &domain.Answer{QuestionID: q.ID, UserID: "u1", Text: "a"}))
+		require.NoError(t, qRepo.Delete(ctx, q.ID, 0))
+		return errBoom
+	})
+	require.ErrorIs(t, err, errBoom)
+
+	got, err := qRepo.GetByID(ctx, q.ID)
+	require.NoError(t, err)
+	require.Empty(t, got.Answers)
+}
+
+func TestMemoryStore_ForeignKeyAndCascade(t *testing.T) {
+	store := repository.NewMemoryStore()
+	qRepo := repository.NewMemoryQuestionRepository(store)
+	aRepo := repository.NewMemoryAnswerRepository(store)
+	ctx := context.Background()
+
+	err := aRepo.Create(ctx, &domain.Answer{QuestionID: 42, UserID: "u1", Text: "a"})
+	require.ErrorIs(t, err, gorm.ErrForeignKeyViolated)
+
+	q := &domain.Question{Text: "q"}
+	require.NoError(t, qRepo.Create(ctx, q))
+	a := &domain.Answer{QuestionID: q.ID, UserID: "u1", Text: "a"}
+	require.NoError(t, aRepo.Create(ctx, a))
+
+	require.NoError(t, qRepo.Delete(ctx, q.ID, 0))
+
+	_, err = aRepo.GetByID(ctx, a.ID)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestMemoryStore_CommentsCascade(t *testing.T) {
+	store := repository.NewMemoryStore()
+	qRepo := repository.NewMemoryQuestionRepository(store)
+	aRepo := repository.NewMemoryAnswerRepository(store)
+	cRepo := repository.NewMemoryCommentRepository(store)
+	ctx := context.Background()
+
+	missing := 42
+	err := cRepo.Create(ctx, &domain.Comment{AnswerID: &missing, UserID: "u1", Text: "c"})
+	require.ErrorIs(t, err, gorm.ErrForeignKeyViolated)
+
+	q := &domain.Question{Text: "q"}
+	require.NoError(t, qRepo.Create(ctx, q))
+	a := &domain.Answer{QuestionID: q.ID, UserID: "u1", Text: "a"}
+	require.NoError(t, aRepo.Create(ctx, a))
+
+	onQuestion := &domain.Comment{QuestionID: &q.ID, UserID: "u2", Text: "why?"}
+	onAnswer := &domain.Comment{AnswerID: &a.ID, UserID: "u2", Text: "source?"}
+	require.NoError(t, cRepo.Create(ctx, onQuestion))
+	require.NoError(t, cRepo.Create(ctx, onAnswer))
+
+	// комментарии переживают перенос в корзину и удаляются вместе с ответом или
+	// вопросом только при очистке корзины
+	require.NoError(t, aRepo.Delete(ctx, a.ID, 0))
+	_, err = cRepo.GetByID(ctx, onAnswer.ID)
+	require.NoError(t, err)
+	_, err = aRepo.Purge(ctx, time.Now().Add(time.Second))
+	require.NoError(t, err)
+	_, err = cRepo.GetByID(ctx, onAnswer.ID)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	require.NoError(t, qRepo.Delete(ctx, q.ID, 0))
+	_, err = qRepo.Purge(ctx, time.Now().Add(time.Second))
+	require.NoError(t, err)
+	_, err = cRepo.GetByID(ctx, onQuestion.ID)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestMemoryAnswerRepository_Thread(t *testing.T) {
+	store := repository.NewMemoryStore()
+	qRepo := repository.NewMemoryQuestionRepository(store)
+	aRepo := repository.NewMemoryAnswerRepository(store)
+	ctx := context.Background()
+
+	q := &domain.Question{Text: "q"}
+	require.NoError(t, qRepo.Create(ctx, q))
+
+	add := func(parent *domain.Answer) *domain.Answer {
+		a := &domain.Answer{QuestionID: q.ID, UserID: "u1", Text: "a"}
+		if parent != nil {
+			a.ParentAnswerID = &parent.ID
+		}
+		require.NoError(t, aRepo.Create(ctx, a))
+		return a
+	}
+	first := add(nil)
+	second := add(nil)
+	reply := add(first)
+	nested := add(reply)
+	late := add(first)
+
+	thread, err := aRepo.ListThread(ctx, q.ID, 5)
+	require.NoError(t, err)
+	var ids, depths []int
+	for _, a := range thread {
+		ids = append(ids, a.ID)
+		depths = append(depths, a.Depth)
+	}
+	require.Equal(t, []int{first.ID, reply.ID, nested.ID, late.ID, second.ID}, ids)
+	require.Equal(t, []int{0, 1, 2, 1, 0}, depths)
+
+	thread, err = aRepo.ListThread(ctx, q.ID, 1)
+	require.NoError(t, err)
+	require.Len(t, thread, 4)
+
+	depth, err := aRepo.Depth(ctx, nested.ID)
+	require.NoError(t, err)
+	require.Equal(t, 2, depth)
+
+	missing := 42
+	err = aRepo.Create(ctx, &domain.Answer{QuestionID: q.ID, ParentAnswerID: &missing, UserID: "u1", Text: "a"})
+	require.ErrorIs(t, err, gorm.ErrForeignKeyViolated)
+
+	// удаление ответа удаляет всю ветку под ним
+	require.NoError(t, aRepo.Delete(ctx, first.ID, 0))
+	rest, err := aRepo.ListByQuestionID(ctx, q.ID)
+	require.NoError(t, err)
+	require.Len(t, rest, 1)
+	require.Equal(t, second.ID, rest[0].ID)
+}
+
+func TestMemoryAnswerRepository_ListByQuestionIDsSkipsHidden(t *testing.T) {
+	store := repository.NewMemoryStore()
+	qRepo := repository.NewMemoryQuestionRepository(store)
+	aRepo := repository.NewMemoryAnswerRepository(store)
+	ctx := context.Background()
+
+	q := &domain.Question{Text: "q"}
+	require.NoError(t, qRepo.Create(ctx, q))
+	hidden := &domain.Answer{QuestionID: q.ID, UserID: "u1", Text: "hidden"}
+	require.NoError(t, aRepo.Create(ctx, hidden))
+	visible := &domain.Answer{QuestionID: q.ID, UserID: "u1", Text: "visible"}
+	require.NoError(t, aRepo.Create(ctx, visible))
+	reply := &domain.Answer{QuestionID: q.ID, ParentAnswerID: &hidden.ID, UserID: "u2", Text: "reply to hidden"}
+	require.NoError(t, aRepo.Create(ctx, reply))
+	last := &domain.Answer{QuestionID: q.ID, UserID: "u3", Text: "last"}
+	require.NoError(t, aRepo.Create(ctx, last))
+	require.NoError(t, aRepo.SetHidden(ctx, hidden.ID, true))
+
+	// лимит считает только видимые ответы, реплика на скрытый не возвращается
+	got, err := aRepo.ListByQuestionIDs(ctx, []int{q.ID}, 2)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	require.Equal(t, visible.ID, got[0].ID)
+	require.Equal(t, last.ID, got[1].ID)
+}
+
+func TestMemoryStore_SoftDeleteAndRestore(t *testing.T) {
+	store := repository.NewMemoryStore()
+	qRepo := repository.NewMemoryQuestionRepository(store)
+	aRepo := repository.NewMemoryAnswerRepository(store)
+	ctx := context.Background()
+
+	q := &domain.Question{Text: "q"}
+	require.NoError(t, qRepo.Create(ctx, q))
+	early := &domain.Answer{QuestionID: q.ID, UserID: "u1", Text: "deleted before the question"}
+	kept := &domain.Answer{QuestionID: q.ID, UserID: "u1", Text: "deleted with the question"}
+	require.NoError(t, aRepo.Create(ctx, early))
+	require.NoError(t, aRepo.Create(ctx, kept))
+	reply := &domain.Answer{QuestionID: q.ID, ParentAnswerID: &early.ID, UserID: "u2", Text: "reply"}
+	require.NoError(t, aRepo.Create(ctx, reply))
+
+	require.NoError(t, aRepo.Delete(ctx, early.ID, 0))
+	_, err := aRepo.GetByID(ctx, reply.ID)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	// реплика удалена вместе с ответом и в корзине отдельно не видна
+	deleted, err := aRepo.ListDeleted(ctx, 0, 100)
+	require.NoError(t, err)
+	require.Len(t, deleted, 1)
+	require.Equal(t, early.ID, deleted[0].ID)
+
+	time.Sleep(time.Millisecond)
+	require.NoError(t, qRepo.Delete(ctx, q.ID, 0))
+	_, err = qRepo.GetByID(ctx, q.ID)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	require.ErrorIs(t, qRepo.Delete(ctx, q.ID, 0), gorm.ErrRecordNotFound)
+
+	// ответы вопроса в корзине не перечисляются отдельно
+	deleted, err = aRepo.ListDeleted(ctx, 0, 100)
+	require.NoError(t, err)
+	require.Empty(t, deleted)
+	trashed, err := qRepo.ListDeleted(ctx, 0, 100)
+	require.NoError(t, err)
+	require.Len(t, trashed, 1)
+
+	// восстанавливаются только ответы, удалённые вместе с вопросом
+	require.NoError(t, qRepo.Restore(ctx, q.ID))
+	got, err := qRepo.GetByID(ctx, q.ID)
+	require.NoError(t, err)
+	require.Len(t, got.Answers, 1)
+	require.Equal(t, kept.ID, got.Answers[0].ID)
+	require.ErrorIs(t, qRepo.Restore(ctx, q.ID), gorm.ErrRecordNotFound)
+
+	restored, err := aRepo.Restore(ctx, early.ID)
+	require.NoError(t, err)
+	require.Equal(t, early.ID, restored.ID)
+	_, err = aRepo.GetByID(ctx, reply.ID)
+	require.NoError(t, err)
+
+	require.NoError(t, aRepo.Delete(ctx, kept.ID, 0))
+	n, err := aRepo.Purge(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Zero(t, n)
+	n, err = aRepo.Purge(ctx, time.Now().Add(time.Second))
+	require.NoError(t, err)
+	require.EqualValues(t, 1, n)
+	_, err = aRepo.Restore(ctx, kept.ID)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestMemoryModerationRepository_FlagsAndQueue(t *testing.T) {
+	store := repository.NewMemoryStore()
+	qRepo := repository.NewMemoryQuestionRepository(store)
+	aRepo := repository.NewMemoryAnswerRepository(store)
+	mRepo := repository.NewMemoryModerationRepository(store)
+	ctx := context.Background()
+
+	q := &domain.Question{Text: "q"}
+	require.NoError(t, qRepo.Create(ctx, q))
+	a := &domain.Answer{QuestionID: q.ID, UserID: "u1", Text: "a"}
+	require.NoError(t, aRepo.Create(ctx, a))
+
+	require.NoError(t, mRepo.CreateFlag(ctx, &domain.Flag{AnswerID: &a.ID, UserID: "u2", Reason: domain.FlagReasonSpam}))
+	err := mRepo.CreateFlag(ctx, &domain.Flag{AnswerID: &a.ID, UserID: "u2", Reason: domain.FlagReasonAbuse})
+	require.ErrorIs(t, err, gorm.ErrDuplicatedKey)
+	missing := 42
+	err = mRepo.CreateFlag(ctx, &domain.Flag{QuestionID: &missing, UserID: "u2", Reason: domain.FlagReasonSpam})
+	require.ErrorIs(t, err, gorm.ErrForeignKeyViolated)
+	require.NoError(t, mRepo.CreateFlag(ctx, &domain.Flag{QuestionID: &q.ID, UserID: "u2", Reason: domain.FlagReasonOther}))
+
+	items, err := mRepo.Queue(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+
+	// скрытые цели в очередь не попадают, но их жалобы остаются открытыми
+	require.NoError(t, qRepo.SetHidden(ctx, q.ID, true))
+	items, err = mRepo.Queue(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Equal(t, domain.TargetAnswer, items[0].Target)
+	require.Equal(t, map[string]int{domain.FlagReasonSpam: 1}, items[0].Reasons)
+
+	n, err := mRepo.ResolveFlags(ctx, nil, &a.ID, time.Now())
+	require.NoError(t, err)
+	require.EqualValues(t, 1, n)
+	require.NoError(t, mRepo.CreateFlag(ctx, &domain.Flag{AnswerID: &a.ID, UserID: "u2", Reason: domain.FlagReasonAbuse}))
+
+	// окончательное удаление вопроса удаляет жалобы, но не журнал
+	require.NoError(t, mRepo.CreateAction(ctx, &domain.ModerationAction{
+		Action: domain.ModerationHide, Target: domain.TargetQuestion, TargetID: q.ID, QuestionID: q.ID, Moderator: "mod",
+	}))
+	require.NoError(t, qRepo.Delete(ctx, q.ID, 0))
+	_, err = qRepo.Purge(ctx, time.Now().Add(time.Second))
+	require.NoError(t, err)
+	items, err = mRepo.Queue(ctx, 10)
+	require.NoError(t, err)
+	require.Empty(t, items)
+	actions, err := mRepo.ListActions(ctx, q.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, actions, 1)
+}
+
+func TestMemoryQuestionRepository_FindSimilarAndMerge(t *testing.T) {
+	store := repository.NewMemoryStore()
+	qRepo := repository.NewMemoryQuestionRepository(store)
+	aRepo := repository.NewMemoryAnswerRepository(store)
+	ctx := context.Background()
+
+	target := &domain.Question{Text: "How to use GORM?"}
+	require.NoError(t, qRepo.Create(ctx, target))
+	dup := &domain.Question{Text: "how to use gorm"}
+	require.NoError(t, qRepo.Create(ctx, dup))
+	require.NoError(t, qRepo.Create(ctx, &domain.Question{Text: "What is a goroutine?"}))
+	require.NoError(t, aRepo.Create(ctx, &domain.Answer{QuestionID: dup.ID, UserID: "u1", Text: "a"}))
+
+	found, err := qRepo.FindSimilar(ctx, "How to use GORM", 0.5, 5)
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	require.Equal(t, target.ID, found[0].ID)
+	require.InDelta(t, 1.0, found[0].Score, 1e-9)
+
+	moved, err := qRepo.Merge(ctx, dup.ID, target.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, moved)
+
+	got, err := qRepo.GetByID(ctx, target.ID)
+	require.NoError(t, err)
+	require.Len(t, got.Answers, 1)
+
+	// заглушки среди похожих не ищутся
+	found, err = qRepo.FindSimilar(ctx, "How to use GORM", 0.5, 5)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+
+	_, err = qRepo.Merge(ctx, target.ID, 42)
+	require.ErrorIs(t, err, gorm.ErrForeignKeyViolated)
+}
+
+func TestMemoryQuestionRepository_List(t *testing.T) {
+	store := repository.NewMemoryStore()
+	qRepo := repository.NewMemoryQuestionRepository(store)
+	ctx := context.Background()
+
+	for _, status := range []string{domain.QuestionOpen, domain.QuestionClosed, domain.QuestionOpen, domain.QuestionClosed, domain.QuestionOpen} {
+		require.NoError(t, qRepo.Create(ctx, &domain.Question{Text: "Q", Status: status}))
+	}
+	require.NoError(t, qRepo.SetHidden(ctx, 3, true))
+	_, err := qRepo.Merge(ctx, 4, 1)
+	require.NoError(t, err)
+
+	ids := func(questions []domain.Question, err error) []int {
+		t.Helper()
+		require.NoError(t, err)
+		out := make([]int, 0, len(questions))
+		for _, q := range questions {
+			out = append(out, q.ID)
+		}
+		return out
+	}
+	require.Equal(t, []int{1, 2, 5}, ids(qRepo.List(ctx, "", 0, 0)))
+	require.Equal(t, []int{1, 5}, ids(qRepo.List(ctx, domain.QuestionOpen, 0, 0)))
+	require.Equal(t, []int{2}, ids(qRepo.List(ctx, domain.QuestionClosed, 0, 0)))
+	require.Equal(t, []int{2}, ids(qRepo.List(ctx, "", 1, 1)))
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"question-service/internal/domain"
+)
+
+type ModerationRepository interface {
+	// CreateFlag сохраняет жалобу. Возвращает gorm.ErrDuplicatedKey, если у пользователя
+	// уже есть открытая жалоба на тот же вопрос или ответ.
+	CreateFlag(ctx context.Context, f *domain.Flag) error
+	// ResolveFlags закрывает открытые жалобы на вопрос questionID или ответ answerID
+	// (задан ровно один) и возвращает их число.
+	ResolveFlags(ctx context.Context, questionID, answerID *int, at time.Time) (int64, error)
+	// Queue возвращает до limit неудалённых и нескрытых вопросов и ответов с открытыми
+	// жалобами: с большим числом жалоб первыми, при равенстве — раньше получившие жалобу.
+	Queue(ctx context.Context, limit int) ([]domain.QueueItem, error)
+	CreateAction(ctx context.Context, a *domain.ModerationAction) error
+	// ListActions возвращает до limit последних действий модераторов, новые первыми;
+	// questionID > 0 оставляет только действия над вопросом и его ответами.
+	ListActions(ctx context.Context, questionID, limit int) ([]domain.ModerationAction, error)
+}
+
+type GormModerationRepository struct {
+	conn Connector
+}
+
+func NewModerationRepository(conn Connector) *GormModerationRepository {
+	return &GormModerationRepository{conn: conn}
+}
+
+func (r *GormModerationRepository) CreateFlag(ctx context.Context, f *domain.Flag) error {
+	return r.conn.Writer(ctx).Create(f).Error
+}
+
+func (r *GormModerationRepository) ResolveFlags(ctx context.Context, questionID, answerID *int, at time.Time) (int64, error) {
+	q := r.conn.Writer(ctx).Model(&domain.Flag{}).Where("resolved_at IS NULL")
+	if questionID != nil {
+		q = q.Where("question_id = ?", *questionID)
+	} else {
+		q = q.Where("answer_id = ?", *answerID)
+	}
+
+	res := q.Update("resolved_at", at)
+	return res.RowsAffected, res.Error
+}
+
+func (r *GormModerationRepository) Queue(ctx context.Context, limit int) ([]domain.QueueItem, error) {
+	db := r.conn.Reader(ctx)
+
+	var items []domain.QueueItem
+	err := db.Raw(`
+		SELECT
+			CASE WHEN f.answer_id IS NULL THEN 'question' ELSE 'answer' END AS target,
+			COALESCE(f.answer_id, f.question_id) AS id,
+			COALESCE(a.question_id, f.question_id) AS question_id,
+			COALESCE(a.text, q.text) AS text,
+			COUNT(*) AS flags,
+			MIN(f.created_at) AS first_flagged_at,
+			MAX(f.created_at) AS last_flagged_at
+		FROM flags f
+		LEFT JOIN questions q ON q.id = f.question_id
+		LEFT JOIN answers a ON a.id = f.answer_id
+		WHERE f.resolved_at IS NULL
+			AND q.deleted_at IS NULL AND a.deleted_at IS NULL
+			AND NOT COALESCE(q.hidden, a.hidden)
+		GROUP BY f.question_id, f.answer_id, q.text, a.text, a.question_id
+		ORDER BY flags DESC, first_flagged_at, id
+		LIMIT ?`,
+		limit,
+	).Scan(&items).Error
+	if err != nil || len(items) == 0 {
+		return items, err
+	}
+
+	var questionIDs, answerIDs []int
+	for _, it := range items {
+		if it.Target == domain.TargetQuestion {
+			questionIDs = append(questionIDs, it.ID)
+		} else {
+			answerIDs = append(answerIDs, it.ID)
+		}
+	}
+
+	// IN с пустым списком недопустим, поэтому подставляется несуществующий id
+	var counts []struct {
+		QuestionID *int
+		AnswerID   *int
+		Reason     string
+		N          int
+	}
+	err = db.Raw(`
+		SELECT question_id, answer_id, reason, COUNT(*) AS n
+		FROM flags
+		WHERE resolved_at IS NULL AND (question_id IN ? OR answer_id IN ?)
+		GROUP BY question_id, answer_id, reason`,
+		append(questionIDs, 0), append(answerIDs, 0),
+	).Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct {
+		target string
+		id     int
+	}
+	reasons := make(map[key]map[string]int, len(items))
+	for _, c := range counts {
+		k := key{domain.TargetAnswer, 0}
+		if c.AnswerID != nil {
+			k.id = *c.AnswerID
+		} else {
+			k = key{domain.TargetQuestion, *c.QuestionID}
+		}
+		if reasons[k] == nil {
+			reasons[k] = make(map[string]int)
+		}
+		reasons[k][c.Reason] = c.N
+	}
+	for i := range items {
+		items[i].Reasons = reasons[key{items[i].Target, items[i].ID}]
+	}
+	return items, nil
+}
+
+func (r *GormModerationRepository) CreateAction(ctx context.Context, a *domain.ModerationAction) error {
+	return r.conn.Writer(ctx).Create(a).Error
+}
+
+func (r *GormModerationRepository) ListActions(ctx context.Context, questionID, limit int) ([]domain.ModerationAction, error) {
+	q := r.conn.Reader(ctx).Order("id DESC").Limit(limit)
+	if questionID > 0 {
+		q = q.Where("question_id = ?", questionID)
+	}
+
+	var actions []domain.ModerationAction
+	err := q.Find(&actions).Error
+	return actions, err
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"question-service/internal/domain"
+)
+
+// outboxRelayLockKey — ключ advisory-блокировки, которой relay гарантирует,
+// что события публикует только один экземпляр сервиса.
+const outboxRelayLockKey = 0x6f7574626f78 // "outbox"
+
+// outboxFanoutLockKey — то же для раздачи событий в очередь вебхуков.
+const outboxFanoutLockKey = 0x66616e6f7574 // "fanout"
+
+type OutboxRepository interface {
+	// Append записывает события в outbox; внутри WithinTx — в той же транзакции.
+	Append(ctx context.Context, events ...domain.Event) error
+	// FetchUnpublished возвращает до limit неопубликованных событий в порядке записи.
+	// Если первое неопубликованное событие агрегата уже не удалось опубликовать,
+	// возвращается только оно: следующие события агрегата ждут его и не занимают
+	// место в пачке. Отброшенные события (MarkDead) не возвращаются.
+	FetchUnpublished(ctx context.Context, limit int) ([]domain.Event, error)
+	MarkPublished(ctx context.Context, id int64, at time.Time) error
+	// FindByIDs возвращает события с заданными ID в порядке записи. Читает из primary.
+	FindByIDs(ctx context.Context, ids []int64) ([]domain.Event, error)
+	// MarkFailed увеличивает счётчик попыток и запоминает ошибку публикации.
+	MarkFailed(ctx context.Context, id int64, reason string) error
+	// MarkDead как MarkFailed, но больше не отдаёт событие в FetchUnpublished.
+	MarkDead(ctx context.Context, id int64, reason string, at time.Time) error
+	// WithRelayLock выполняет fn, только если удалось захватить блокировку relay.
+	// Возвращает false, если блокировку держит другой экземпляр.
+	WithRelayLock(ctx context.Context, fn func(ctx context.Context) error) (bool, error)
+
+	// FetchNotFannedOut возвращает до limit событий, ещё не поставленных в очередь
+	// вебхуков, в порядке записи — независимо от того, опубликованы ли они relay.
+	FetchNotFannedOut(ctx context.Context, limit int) ([]domain.Event, error)
+	MarkFannedOut(ctx context.Context, id int64, at time.Time) error
+	// WithFanoutLock как WithRelayLock, но для раздачи событий в очередь вебхуков.
+	WithFanoutLock(ctx context.Context, fn func(ctx context.Context) error) (bool, error)
+}
+
+type GormOutboxRepository struct {
+	conn Connector
+}
+
+func NewOutboxRepository(conn Connector) *GormOutboxRepository {
+	return &GormOutboxRepository{conn: conn}
+}
+
+func (r *GormOutboxRepository) Append(ctx context.Context, events ...domain.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return r.conn.Writer(ctx).Create(&events).Error
+}
+
+func (r *GormOutboxRepository) FetchUnpublished(ctx context.Context, limit int) ([]domain.Event, error) {
+	var events []domain.Event
+	err := r.conn.Writer(ctx).
+		Where("published_at IS NULL AND dead_at IS NULL").
+		Where(`NOT EXISTS (
+			SELECT 1 FROM outbox p
+			WHERE p.aggregate_type = outbox.aggregate_type AND p.aggregate_id = outbox.aggregate_id
+			  AND p.id < outbox.id AND p.published_at IS NULL AND p.dead_at IS NULL AND p.attempts > 0
+		)`).
+		Order("id").
+		Limit(limit).
+		Find(&events).Error
+	return events, err
+}
+
+func (r *GormOutboxRepository) FindByIDs(ctx context.Context, ids []int64) ([]domain.Event, error) {
+	var events []domain.Event
+	if len(ids) == 0 {
+		return events, nil
+	}
+	err := r.conn.Writer(ctx).Where("id IN ?", ids).Order("id").Find(&events).Error
+	return events, err
+}
+
+func (r *GormOutboxRepository) MarkPublished(ctx context.Context, id int64, at time.Time) error {
+	return r.conn.Writer(ctx).
+		Model(&domain.Event{}).
+		Where("id = ?", id).
+		Update("published_at", at).Error
+}
+
+func (r *GormOutboxRepository) MarkFailed(ctx context.Context, id int64, reason string) error {
+	return r.conn.Writer(ctx).
+		Model(&domain.Event{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"attempts":   gorm.Expr("attempts + 1"),
+			"last_error": reason,
+		}).Error
+}
+
+func (r *GormOutboxRepository) MarkDead(ctx context.Context, id int64, reason string, at time.Time) error {
+	return r.conn.Writer(ctx).
+		Model(&domain.Event{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"attempts":   gorm.Expr("attempts + 1"),
+			"last_error": reason,
+			"dead_at":    at,
+		}).Error
+}
+
+func (r *GormOutboxRepository) FetchNotFannedOut(ctx context.Context, limit int) ([]domain.Event, error) {
+	var events []domain.Event
+	err := r.conn.Writer(ctx).
+		Where("fanned_out_at IS NULL").
+		Order("id").
+		Limit(limit).
+		Find(&events).Error
+	return events, err
+}
+
+func (r *GormOutboxRepository) MarkFannedOut(ctx context.Context, id int64, at time.Time) error {
+	return r.conn.Writer(ctx).
+		Model(&domain.Event{}).
+		Where("id = ?", id).
+		Update("fanned_out_at", at).Error
+}
+
+func (r *GormOutboxRepository) WithRelayLock(ctx context.Context, fn func(ctx context.Context) error) (bool, error) {
+	return r.withLock(ctx, outboxRelayLockKey, fn)
+}
+
+func (r *GormOutboxRepository) WithFanoutLock(ctx context.Context, fn func(ctx context.Context) error) (bool, error) {
+	return r.withLock(ctx, outboxFanoutLockKey, fn)
+}
+
+// withLock держит сессионную advisory-блокировку key на выделенном соединении,
+// пока выполняется fn.
+func (r *GormOutboxRepository) withLock(ctx context.Context, key int64, fn func(ctx context.Context) error) (bool, error) {
+	acquired := false
+
+	err := r.conn.Writer(ctx).Connection(func(conn *gorm.DB) error {
+		if err := conn.Raw("SELECT pg_try_advisory_lock(?)", key).Scan(&acquired).Error; err != nil {
+			return err
+		}
+		if !acquired {
+			return nil
+		}
+		defer conn.WithContext(context.WithoutCancel(ctx)).Exec("SELECT pg_advisory_unlock(?)", key)
+
+		return fn(ctx)
+	})
+
+	return acquired, err
+}
@@ -2,39 +2,117 @@ package repository
 
 import (
 	"context"
+	"strconv"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
 	"question-service/internal/domain"
 )
 
 type QuestionRepository interface {
 	Create(ctx context.Context, q *domain.Question) error
-	GetAll(ctx context.Context) ([]domain.Question, error)
+	// CreateBatch вставляет вопросы пачками по batchSize и заполняет их ID.
+	// Ответы из q.Answers не сохраняются.
+	CreateBatch(ctx context.Context, questions []domain.Question, batchSize int) error
+	// ListPage возвращает до limit вопросов с id > afterID по возрастанию id.
+	// Скрытые модератором вопросы и заглушки объединённых не возвращаются.
+	ListPage(ctx context.Context, afterID, limit int) ([]domain.Question, error)
+	// List возвращает до limit (0 — без ограничения) вопросов с id > afterID по
+	// возрастанию id для публичных списков: скрытые модератором вопросы и заглушки
+	// объединённых не возвращаются, непустой status оставляет только вопросы с этим
+	// статусом.
+	List(ctx context.Context, status string, afterID, limit int) ([]domain.Question, error)
 	GetByID(ctx context.Context, id int) (*domain.Question, error)
-	Delete(ctx context.Context, id int) error
+	// GetWithoutAnswers возвращает вопрос без ответов, в том числе скрытый модератором.
+	GetWithoutAnswers(ctx context.Context, id int) (*domain.Question, error)
+	// FindByExternalIDs возвращает вопросы с указанными внешними идентификаторами, без ответов.
+	// Вопросы из корзины тоже возвращаются (с заполненным DeletedAt): external_id
+	// остаётся занятым, пока вопрос не очищен из корзины.
+	FindByExternalIDs(ctx context.Context, externalIDs []string) ([]domain.Question, error)
+	// Update сохраняет текст и время создания вопроса и увеличивает его версию;
+	// новая версия записывается в q.Version. Возвращает gorm.ErrRecordNotFound, если вопроса нет.
+	Update(ctx context.Context, q *domain.Question) error
+	// BumpVersion увеличивает версию вопроса при изменении его ответов: вопрос с ответами —
+	// один агрегат с общим ETag. Строка вопроса блокируется до конца транзакции, поэтому
+	// его нельзя удалить параллельно. Возвращает gorm.ErrRecordNotFound, если вопроса нет.
+	BumpVersion(ctx context.Context, id int) error
+	// Delete переносит вопрос в корзину вместе с его ответами, если версия вопроса
+	// равна version (0 — без проверки). Возвращает ErrStaleVersion при несовпадении версии.
+	Delete(ctx context.Context, id, version int) error
+	// Restore возвращает вопрос из корзины вместе с ответами, удалёнными вместе с ним.
+	// Возвращает gorm.ErrRecordNotFound, если такого вопроса в корзине нет.
+	Restore(ctx context.Context, id int) error
+	// ListDeleted возвращает до limit вопросов в корзине с id > afterID по возрастанию id.
+	ListDeleted(ctx context.Context, afterID, limit int) ([]domain.Question, error)
+	// Purge окончательно удаляет вопросы, удалённые раньше before, вместе с ответами
+	// и комментариями, и возвращает число удалённых вопросов.
+	Purge(ctx context.Context, before time.Time) (int64, error)
+	// SetHidden скрывает вопрос от пользователей или снова показывает его.
+	// Возвращает gorm.ErrRecordNotFound, если вопроса нет.
+	SetHidden(ctx context.Context, id int, hidden bool) error
+	// SetStatus записывает статус вопроса q.ID вместе со сведениями о закрытии и
+	// блокировке. Возвращает gorm.ErrRecordNotFound, если вопроса нет.
+	SetStatus(ctx context.Context, q *domain.Question) error
+	// FindSimilar возвращает до limit вопросов, текст которых похож на text не меньше
+	// чем на threshold, самые похожие первыми. Удалённые, скрытые модератором и
+	// объединённые вопросы не возвращаются.
+	FindSimilar(ctx context.Context, text string, threshold float64, limit int) ([]domain.DuplicateCandidate, error)
+	// Merge переносит все ответы вопроса id, в том числе из корзины, в вопрос targetID
+	// и делает id заглушкой со ссылкой на targetID. Заглушки, ссылавшиеся на id, тоже
+	// перенаправляются на targetID. Возвращает число перенесённых ответов.
+	Merge(ctx context.Context, id, targetID int) (int64, error)
 }
 
 type GormQuestionRepository struct {
-	db *gorm.DB
+	conn Connector
 }
 
-func NewQuestionRepository(db *gorm.DB) *GormQuestionRepository {
-	return &GormQuestionRepository{db: db}
+func NewQuestionRepository(conn Connector) *GormQuestionRepository {
+	return &GormQuestionRepository{conn: conn}
 }
 
 func (r *GormQuestionRepository) Create(ctx context.Context, q *domain.Question) error {
-	return r.db.WithContext(ctx).Create(q).Error
+	return r.conn.Writer(ctx).Create(q).Error
 }
 
-func (r *GormQuestionRepository) GetAll(ctx context.Context) ([]domain.Question, error) {
+func (r *GormQuestionRepository) CreateBatch(ctx context.Context, questions []domain.Question, batchSize int) error {
+	if len(questions) == 0 {
+		return nil
+	}
+	return r.conn.Writer(ctx).Omit(clause.Associations).CreateInBatches(questions, batchSize).Error
+}
+
+func (r *GormQuestionRepository) ListPage(ctx context.Context, afterID, limit int) ([]domain.Question, error) {
+	var questions []domain.Question
+	err := r.conn.Reader(ctx).
+		Where("id > ? AND NOT hidden AND merged_into_id IS NULL", afterID).
+		Order("id").
+		Limit(limit).
+		Find(&questions).Error
+	return questions, err
+}
+
+func (r *GormQuestionRepository) List(ctx context.Context, status string, afterID, limit int) ([]domain.Question, error) {
+	query := r.conn.Reader(ctx).
+		Where("id > ? AND NOT hidden AND merged_into_id IS NULL", afterID).
+		Order("id")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
 	var questions []domain.Question
-	err := r.db.WithContext(ctx).Find(&questions).Error
+	err := query.Find(&questions).Error
 	return questions, err
 }
 
 func (r *GormQuestionRepository) GetByID(ctx context.Context, id int) (*domain.Question, error) {
 	var q domain.Question
-	err := r.db.WithContext(ctx).
+	err := r.conn.Reader(ctx).
 		Preload("Answers").
 		First(&q, id).Error
 	if err != nil {
@@ -43,6 +121,185 @@ func (r *GormQuestionRepository) GetByID(ctx context.Context, id int) (*domain.Q
 	return &q, nil
 }
 
-func (r *GormQuestionRepository) Delete(ctx context.Context, id int) error {
-	return r.db.WithContext(ctx).Delete(&domain.Question{}, id).Error
+func (r *GormQuestionRepository) GetWithoutAnswers(ctx context.Context, id int) (*domain.Question, error) {
+	var q domain.Question
+	if err := r.conn.Reader(ctx).First(&q, id).Error; err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+func (r *GormQuestionRepository) FindByExternalIDs(ctx context.Context, externalIDs []string) ([]domain.Question, error) {
+	var questions []domain.Question
+	if len(externalIDs) == 0 {
+		return questions, nil
+	}
+	err := r.conn.Reader(ctx).Unscoped().Where("external_id IN ?", externalIDs).Find(&questions).Error
+	return questions, err
+}
+
+func (r *GormQuestionRepository) Update(ctx context.Context, q *domain.Question) error {
+	res := r.conn.Writer(ctx).
+		Model(q).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "version"}}}).
+		Updates(map[string]any{
+			"text":       q.Text,
+			"created_at": q.CreatedAt,
+			"version":    gorm.Expr("version + 1"),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *GormQuestionRepository) BumpVersion(ctx context.Context, id int) error {
+	res := r.conn.Writer(ctx).
+		Model(&domain.Question{}).
+		Where("id = ?", id).
+		Update("version", gorm.Expr("version + 1"))
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *GormQuestionRepository) Delete(ctx context.Context, id, version int) error {
+	db := r.conn.Writer(ctx)
+	now := time.Now().UTC()
+	if err := softDeleteVersioned(db, &domain.Question{}, id, version, now); err != nil {
+		return err
+	}
+
+	// ответы получают ту же отметку времени: по ней Restore отличает их от
+	// удалённых раньше
+	return db.Model(&domain.Answer{}).Where("question_id = ?", id).Update("deleted_at", now).Error
+}
+
+func (r *GormQuestionRepository) Restore(ctx context.Context, id int) error {
+	db := r.conn.Writer(ctx)
+
+	var q domain.Question
+	err := db.Unscoped().
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("deleted_at IS NOT NULL").
+		First(&q, id).Error
+	if err != nil {
+		return err
+	}
+
+	err = db.Unscoped().
+		Model(&domain.Answer{}).
+		Where("question_id = ? AND deleted_at = ?", id, q.DeletedAt).
+		Update("deleted_at", nil).Error
+	if err != nil {
+		return err
+	}
+	return db.Unscoped().Model(&q).Update("deleted_at", nil).Error
+}
+
+func (r *GormQuestionRepository) ListDeleted(ctx context.Context, afterID, limit int) ([]domain.Question, error) {
+	var questions []domain.Question
+	err := r.conn.Reader(ctx).
+		Unscoped().
+		Where("deleted_at IS NOT NULL AND id > ?", afterID).
+		Order("id").
+		Limit(limit).
+		Find(&questions).Error
+	return questions, err
+}
+
+func (r *GormQuestionRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	// ответы и комментарии удаляет каскад по FK
+	res := r.conn.Writer(ctx).
+		Unscoped().
+		Where("deleted_at < ?", before).
+		Delete(&domain.Question{})
+	return res.RowsAffected, res.Error
+}
+
+func (r *GormQuestionRepository) SetHidden(ctx context.Context, id int, hidden bool) error {
+	return updateColumn(r.conn.Writer(ctx), &domain.Question{}, id, "hidden", hidden)
+}
+
+func (r *GormQuestionRepository) SetStatus(ctx context.Context, q *domain.Question) error {
+	res := r.conn.Writer(ctx).
+		Model(&domain.Question{}).
+		Where("id = ?", q.ID).
+		Select("status", "close_reason", "closed_at", "closed_by", "locked_at", "locked_by").
+		Updates(q)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// FindSimilar задаёт порог оператора % (pg_trgm.similarity_threshold) на время
+// своей транзакции, чтобы поиск по GIN-индексу работал при любом пороге.
+func (r *GormQuestionRepository) FindSimilar(ctx context.Context, text string, threshold float64, limit int) ([]domain.DuplicateCandidate, error) {
+	var out []domain.DuplicateCandidate
+	err := r.conn.Reader(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`SELECT set_config('pg_trgm.similarity_threshold', ?, true)`,
+			strconv.FormatFloat(threshold, 'f', -1, 64)).Error
+		if err != nil {
+			return err
+		}
+		return tx.Raw(`
+			SELECT id, text, status, similarity(text, @text) AS score
+			FROM questions
+			WHERE deleted_at IS NULL AND NOT hidden AND merged_into_id IS NULL
+				AND text % @text AND similarity(text, @text) >= @threshold
+			ORDER BY score DESC, id
+			LIMIT @limit`,
+			map[string]any{"text": text, "threshold": threshold, "limit": limit},
+		).Scan(&out).Error
+	})
+	return out, err
+}
+
+func (r *GormQuestionRepository) Merge(ctx context.Context, id, targetID int) (int64, error) {
+	db := r.conn.Writer(ctx)
+
+	res := db.Unscoped().
+		Model(&domain.Answer{}).
+		Where("question_id = ?", id).
+		Update("question_id", targetID)
+	if res.Error != nil {
+		return 0, res.Error
+	}
+
+	err := db.Unscoped().
+		Model(&domain.Question{}).
+		Where("merged_into_id = ?", id).
+		Update("merged_into_id", targetID).Error
+	if err != nil {
+		return 0, err
+	}
+
+	if err := updateColumn(db, &domain.Question{}, id, "merged_into_id", targetID); err != nil {
+		return 0, err
+	}
+	return res.RowsAffected, nil
+}
+
+// updateColumn записывает value в колонку column неудалённой записи model с заданным id.
+// Возвращает gorm.ErrRecordNotFound, если записи нет.
+func updateColumn(db *gorm.DB, model any, id int, column string, value any) error {
+	res := db.Model(model).Where("id = ?", id).Update(column, value)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
 }
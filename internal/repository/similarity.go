@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"strings"
+	"unicode"
+)
+
+// tokenSimilarity оценивает сходство текстов от 0 до 1 как долю общих слов
+// (коэффициент Жаккара) без учёта регистра и знаков препинания. Заменяет
+// similarity из pg_trgm там, где PostgreSQL нет.
+func tokenSimilarity(a, b string) float64 {
+	ta, tb := tokens(a), tokens(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+
+	common := 0
+	for t := range ta {
+		if tb[t] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(ta)+len(tb)-common)
+}
+
+// tokens возвращает множество слов текста в нижнем регистре.
+func tokens(s string) map[string]bool {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return set
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrStaleVersion возвращается, когда запись существует, но её версия
+// не совпадает с ожидаемой (оптимистичная блокировка).
+var ErrStaleVersion = errors.New("stale version")
+
+// softDeleteVersioned помечает запись model с заданным id удалённой в момент at при
+// условии WHERE version = ?. version == 0 означает удаление без проверки версии.
+// Уже удалённая запись считается отсутствующей.
+func softDeleteVersioned(db *gorm.DB, model any, id, version int, at time.Time) error {
+	q := db.Model(model).Where("id = ?", id)
+	if version > 0 {
+		q = q.Where("version = ?", version)
+	}
+
+	res := q.Update("deleted_at", at)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected > 0 {
+		return nil
+	}
+
+	return missingOrStale(db, model, id)
+}
+
+// missingOrStale различает отсутствие записи и несовпадение версии после
+// условного изменения, не затронувшего ни одной строки.
+func missingOrStale(db *gorm.DB, model any, id int) error {
+	var count int64
+	if err := db.Model(model).Where("id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return ErrStaleVersion
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"question-service/internal/domain"
+)
+
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, s *domain.WebhookSubscription) error
+	GetSubscription(ctx context.Context, id int64) (*domain.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error)
+	// ListActiveSubscriptions возвращает включённые подписки.
+	ListActiveSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, s *domain.WebhookSubscription) error
+	DeleteSubscription(ctx context.Context, id int64) error
+	// RecordSubscriptionSuccess обнуляет счётчик неудач подряд.
+	RecordSubscriptionSuccess(ctx context.Context, id int64) error
+	// RecordSubscriptionFailure увеличивает счётчик неудач подряд и выключает подписку,
+	// когда он достигает disableAfter (0 — никогда). Возвращает true, если подписка выключена.
+	RecordSubscriptionFailure(ctx context.Context, id int64, disableAfter int, now time.Time) (bool, error)
+
+	// EnqueueDeliveries ставит доставки в очередь; повторная постановка того же
+	// события той же подписке игнорируется.
+	EnqueueDeliveries(ctx context.Context, deliveries []domain.WebhookDelivery) error
+	// ClaimDueDeliveries забирает до limit доставок, время которых пришло, у включённых
+	// подписок и откладывает их на lease, чтобы их не забрал другой экземпляр.
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error)
+	// SaveDeliveryResult сохраняет статус, попытки и расписание доставки.
+	SaveDeliveryResult(ctx context.Context, d *domain.WebhookDelivery) error
+	// ListDeliveries возвращает последние limit доставок подписки, новые первыми.
+	ListDeliveries(ctx context.Context, subscriptionID int64, limit int) ([]domain.WebhookDelivery, error)
+}
+
+type GormWebhookRepository struct {
+	conn Connector
+}
+
+func NewWebhookRepository(conn Connector) *GormWebhookRepository {
+	return &GormWebhookRepository{conn: conn}
+}
+
+func (r *GormWebhookRepository) CreateSubscription(ctx context.Context, s *domain.WebhookSubscription) error {
+	return r.conn.Writer(ctx).Create(s).Error
+}
+
+func (r *GormWebhookRepository) GetSubscription(ctx context.Context, id int64) (*domain.WebhookSubscription, error) {
+	var s domain.WebhookSubscription
+	if err := r.conn.Reader(ctx).First(&s, id).Error; err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *GormWebhookRepository) ListSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	var subs []domain.WebhookSubscription
+	err := r.conn.Reader(ctx).Order("id").Find(&subs).Error
+	return subs, err
+}
+
+func (r *GormWebhookRepository) ListActiveSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	var subs []domain.WebhookSubscription
+	err := r.conn.Writer(ctx).Where("active").Order("id").Find(&subs).Error
+	return subs, err
+}
+
+func (r *GormWebhookRepository) UpdateSubscription(ctx context.Context, s *domain.WebhookSubscription) error {
+	res := r.conn.Writer(ctx).
+		Model(s).
+		Select("url", "event_types", "secret", "active", "consecutive_failures", "disabled_at", "updated_at").
+		Updates(s)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *GormWebhookRepository) DeleteSubscription(ctx context.Context, id int64) error {
+	res := r.conn.Writer(ctx).Delete(&domain.WebhookSubscription{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *GormWebhookRepository) RecordSubscriptionSuccess(ctx context.Context, id int64) error {
+	return r.conn.Writer(ctx).
+		Model(&domain.WebhookSubscription{}).
+		Where("id = ? AND consecutive_failures > 0", id).
+		Update("consecutive_failures", 0).Error
+}
+
+func (r *GormWebhookRepository) RecordSubscriptionFailure(ctx context.Context, id int64, disableAfter int, now time.Time) (bool, error) {
+	threshold := disableAfter
+	if threshold <= 0 {
+		// счётчик растёт, но подписка не выключается
+		threshold = int(^uint32(0) >> 1)
+	}
+
+	var active []bool
+	err := r.conn.Writer(ctx).Raw(`
+		UPDATE webhook_subscriptions
+		SET consecutive_failures = consecutive_failures + 1,
+		    disabled_at = CASE WHEN active AND consecutive_failures + 1 >= ? THEN ? ELSE disabled_at END,
+		    active      = active AND consecutive_failures + 1 < ?,
+		    updated_at  = ?
+		WHERE id = ?
+		RETURNING active`,
+		threshold, now, threshold, now, id,
+	).Scan(&active).Error
+	if err != nil {
+		return false, err
+	}
+	if len(active) == 0 {
+		return false, gorm.ErrRecordNotFound
+	}
+	return !active[0], nil
+}
+
+func (r *GormWebhookRepository) EnqueueDeliveries(ctx context.Context, deliveries []domain.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return r.conn.Writer(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&deliveries).Error
+}
+
+func (r *GormWebhookRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error) {
+	var deliveries []domain.WebhookDelivery
+	err := r.conn.Writer(ctx).Raw(`
+		UPDATE webhook_deliveries
+		SET next_attempt_at = ?
+		WHERE id IN (
+			SELECT d.id
+			FROM webhook_deliveries d
+			JOIN webhook_subscriptions s ON s.id = d.subscription_id AND s.active
+			WHERE d.status = ? AND d.next_attempt_at <= ?
+			ORDER BY d.next_attempt_at, d.id
+			LIMIT ?
+			FOR UPDATE OF d SKIP LOCKED
+		)
+		RETURNING *`,
+		now.Add(lease), domain.DeliveryPending, now, limit,
+	).Scan(&deliveries).Error
+	return deliveries, err
+}
+
+func (r *GormWebhookRepository) SaveDeliveryResult(ctx context.Context, d *domain.WebhookDelivery) error {
+	return r.conn.Writer(ctx).
+		Model(d).
+		Select("status", "attempts", "next_attempt_at", "last_status_code", "last_error", "delivered_at").
+		Updates(d).Error
+}
+
+func (r *GormWebhookRepository) ListDeliveries(ctx context.Context, subscriptionID int64, limit int) ([]domain.WebhookDelivery, error) {
+	var deliveries []domain.WebhookDelivery
+	err := r.conn.Reader(ctx).
+		Where("subscription_id = ?", subscriptionID).
+		Order("id DESC").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
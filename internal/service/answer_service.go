@@ -12,42 +12,189 @@ import (
 type AnswerService struct {
 	answers   repository.AnswerRepository
 	questions repository.QuestionRepository
+	events    EventRecorder
+	tx        TxManager
+	// notifier получает события об ответах после фиксации; nil — не оповещать.
+	notifier Notifier
+	// maxReplyDepth — допустимая глубина реплик в ветке обсуждения.
+	maxReplyDepth int
 }
 
-func NewAnswerService(aRepo repository.AnswerRepository, qRepo repository.QuestionRepository) *AnswerService {
+// DefaultMaxReplyDepth — допустимая глубина реплик, если она не задана через SetMaxReplyDepth.
+const DefaultMaxReplyDepth = 5
+
+func NewAnswerService(aRepo repository.AnswerRepository, qRepo repository.QuestionRepository, events EventRecorder, tx TxManager, notifier Notifier) *AnswerService {
 	return &AnswerService{
 		answers:   aRepo,
 		questions: qRepo,
+		events:    events,
+		tx:        tx,
+		notifier:  notifier,
+
+		maxReplyDepth: DefaultMaxReplyDepth,
 	}
 }
 
-// CreateAnswer добавляет ответ к вопросу.
+// SetMaxReplyDepth задаёт допустимую глубину реплик: 0 запрещает реплики, ответы
+// возможны только на сам вопрос. Глубже этого уровня ветка и не отдаётся.
+func (s *AnswerService) SetMaxReplyDepth(depth int) {
+	s.maxReplyDepth = max(depth, 0)
+}
+
+// CreateAnswer добавляет ответ к вопросу. Вставка и увеличение версии вопроса
+// выполняются в одной транзакции; строка вопроса блокируется, поэтому параллельный
+// DeleteQuestion не может удалить его между проверкой и вставкой. Ответить можно
+// только на открытый вопрос: на закрытый — ErrQuestionClosed, на заблокированный
+// модератором — ErrQuestionLocked.
 func (s *AnswerService) CreateAnswer(ctx context.Context, questionID int, userID, text string) (*domain.Answer, error) {
-	_, err := s.questions.GetByID(ctx, questionID)
+	ans := &domain.Answer{
+		QuestionID: questionID,
+		UserID:     userID,
+		Text:       text,
+	}
+
+	var ev domain.Event
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.acceptAnswers(ctx, questionID); err != nil {
+			return err
+		}
+
+		if err := s.answers.Create(ctx, ans); err != nil {
+			return err
+		}
+
+		var err error
+		ev, err = record(ctx, s.events, domain.EventAnswerCreated, questionID, ans)
+		return err
+	})
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, gorm.ErrForeignKeyViolated) {
 			return nil, ErrQuestionNotFound
 		}
 
 		return nil, err
 	}
 
+	s.notify(ctx, ev)
+	return ans, nil
+}
+
+// CreateReply добавляет ответ-реплику на ответ parentID того же вопроса. Глубина
+// реплики проверяется в той же транзакции, что и вставка.
+func (s *AnswerService) CreateReply(ctx context.Context, questionID, parentID int, userID, text string) (*domain.Answer, error) {
 	ans := &domain.Answer{
-		QuestionID: questionID,
-		UserID:     userID,
-		Text:       text,
+		QuestionID:     questionID,
+		ParentAnswerID: &parentID,
+		UserID:         userID,
+		Text:           text,
 	}
 
-	if err := s.answers.Create(ctx, ans); err != nil {
+	var ev domain.Event
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.acceptAnswers(ctx, questionID); err != nil {
+			return err
+		}
+
+		parent, err := s.answers.GetByID(ctx, parentID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrParentAnswerNotFound
+			}
+			return err
+		}
+		if parent.Hidden {
+			return ErrParentAnswerNotFound
+		}
+		if parent.QuestionID != questionID {
+			return ErrParentAnswerMismatch
+		}
+
+		depth, err := s.answers.Depth(ctx, parentID)
+		if err != nil {
+			return err
+		}
+		if depth+1 > s.maxReplyDepth {
+			return ErrReplyTooDeep
+		}
+
+		if err := s.answers.Create(ctx, ans); err != nil {
+			if errors.Is(err, gorm.ErrForeignKeyViolated) {
+				// родителя удалили после проверки
+				return ErrParentAnswerNotFound
+			}
+			return err
+		}
+		ans.Depth = depth + 1
+
+		ev, err = record(ctx, s.events, domain.EventAnswerCreated, questionID, ans)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, gorm.ErrForeignKeyViolated) {
+			return nil, ErrQuestionNotFound
+		}
+
 		return nil, err
 	}
 
+	s.notify(ctx, ev)
 	return ans, nil
 }
 
-// GetAnswer возвращает конкретный ответ по id.
+// CreateAnswers добавляет к вопросу несколько ответов одним многострочным INSERT
+// в одной транзакции: либо все, либо ни одного. Версия вопроса увеличивается один
+// раз, а событие answer.created записывается на каждый ответ. У ответов в answers
+// используются только UserID и Text.
+func (s *AnswerService) CreateAnswers(ctx context.Context, questionID int, answers []domain.Answer) ([]domain.Answer, error) {
+	created := make([]domain.Answer, len(answers))
+	for i, a := range answers {
+		created[i] = domain.Answer{QuestionID: questionID, UserID: a.UserID, Text: a.Text}
+	}
+
+	evs := make([]domain.Event, len(created))
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.acceptAnswers(ctx, questionID); err != nil {
+			return err
+		}
+
+		if err := s.answers.CreateMany(ctx, created); err != nil {
+			return err
+		}
+
+		for i := range created {
+			ev, err := domain.NewEvent(domain.EventAnswerCreated, questionID, &created[i])
+			if err != nil {
+				return err
+			}
+			evs[i] = ev
+		}
+		return s.events.Append(ctx, evs...)
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, gorm.ErrForeignKeyViolated) {
+			return nil, ErrQuestionNotFound
+		}
+
+		return nil, err
+	}
+
+	for _, ev := range evs {
+		s.notify(ctx, ev)
+	}
+	return created, nil
+}
+
+// ListThread возвращает ответы на вопрос деревом обсуждения: в порядке обхода
+// в глубину, с заполненной Depth, не глубже допустимой глубины реплик. Скрытые
+// модератором ответы не возвращаются вместе с репликами на них.
+func (s *AnswerService) ListThread(ctx context.Context, questionID int) ([]domain.Answer, error) {
+	return s.answers.ListThread(ctx, questionID, s.maxReplyDepth)
+}
+
+// GetAnswer возвращает конкретный ответ по id. Скрытый модератором ответ или
+// ответ на скрытый вопрос не находится.
 func (s *AnswerService) GetAnswer(ctx context.Context, id int) (*domain.Answer, error) {
-	a, err := s.answers.GetByID(ctx, id)
+	a, err := visibleAnswer(ctx, s.questions, s.answers, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrAnswerNotFound
@@ -57,14 +204,134 @@ func (s *AnswerService) GetAnswer(ctx context.Context, id int) (*domain.Answer,
 	return a, nil
 }
 
-// DeleteAnswer удаляет ответ.
-func (s *AnswerService) DeleteAnswer(ctx context.Context, id int) error {
-	err := s.answers.Delete(ctx, id)
+// ListAnswersByQuestionIDs возвращает ответы на несколько вопросов одним запросом,
+// сгруппированные по id вопроса; perQuestion ограничивает число ответов на вопрос (0 — все).
+// Скрытые модератором ответы не возвращаются вместе с репликами на них.
+func (s *AnswerService) ListAnswersByQuestionIDs(ctx context.Context, questionIDs []int, perQuestion int) (map[int][]domain.Answer, error) {
+	answers, err := s.answers.ListByQuestionIDs(ctx, questionIDs, perQuestion)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[int][]domain.Answer, len(questionIDs))
+	for _, a := range answers {
+		out[a.QuestionID] = append(out[a.QuestionID], a)
+	}
+	return out, nil
+}
+
+// DeleteAnswer удаляет ответ вместе с репликами на него и увеличивает версию его вопроса.
+// Ответ, скрытый модератором сам или вместе с веткой или вопросом, считается несуществующим.
+// Если version > 0, ответ удаляется только при совпадении версии.
+func (s *AnswerService) DeleteAnswer(ctx context.Context, id, version int) error {
+	var ev domain.Event
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		a, err := visibleAnswer(ctx, s.questions, s.answers, id)
+		if err != nil {
+			return err
+		}
+
+		if err := s.answers.Delete(ctx, id, version); err != nil {
+			return err
+		}
+
+		if err := s.questions.BumpVersion(ctx, a.QuestionID); err != nil {
+			return err
+		}
+
+		ev, err = record(ctx, s.events, domain.EventAnswerDeleted, a.QuestionID, deletedPayload{ID: id, QuestionID: a.QuestionID})
+		return err
+	})
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return ErrAnswerNotFound
 		}
+		if errors.Is(err, repository.ErrStaleVersion) {
+			return ErrVersionMismatch
+		}
+		return err
+	}
+
+	s.notify(ctx, ev)
+	return nil
+}
+
+// RestoreAnswer возвращает ответ из корзины вместе с репликами, удалёнными вместе
+// с ним, и увеличивает версию его вопроса. Вопрос и родительский ответ должны
+// быть восстановлены раньше. Скрытый модератором ответ считается несуществующим.
+func (s *AnswerService) RestoreAnswer(ctx context.Context, id int) (*domain.Answer, error) {
+	var (
+		a  *domain.Answer
+		ev domain.Event
+	)
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if a, err = s.answers.Restore(ctx, id); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrAnswerNotFound
+			}
+			return err
+		}
+
+		if err := s.questions.BumpVersion(ctx, a.QuestionID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrParentDeleted
+			}
+			return err
+		}
+		if a.ParentAnswerID != nil {
+			if _, err := s.answers.GetByID(ctx, *a.ParentAnswerID); err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return ErrParentDeleted
+				}
+				return err
+			}
+		}
+		// скрытый ответ не восстанавливается, чтобы не раскрыть его текст
+		if _, err := visibleAnswer(ctx, s.questions, s.answers, id); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrAnswerNotFound
+			}
+			return err
+		}
+
+		ev, err = record(ctx, s.events, domain.EventAnswerRestored, a.QuestionID, a)
 		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.notify(ctx, ev)
+	return a, nil
+}
+
+// acceptAnswers увеличивает версию вопроса, блокируя его строку до конца транзакции,
+// и проверяет, что вопрос принимает ответы: не скрыт модератором и открыт.
+func (s *AnswerService) acceptAnswers(ctx context.Context, questionID int) error {
+	if err := s.questions.BumpVersion(ctx, questionID); err != nil {
+		return err
+	}
+
+	q, err := s.questions.GetWithoutAnswers(ctx, questionID)
+	if err != nil {
+		return err
+	}
+	switch {
+	case q.Hidden:
+		return ErrQuestionNotFound
+	case q.Status == domain.QuestionLocked:
+		return ErrQuestionLocked
+	case q.Status == domain.QuestionClosed:
+		return ErrQuestionClosed
 	}
 	return nil
 }
+
+// notify оповещает подписчиков о зафиксированном событии. Запрос клиента уже
+// выполнен, поэтому отмена его контекста не должна мешать оповещению.
+func (s *AnswerService) notify(ctx context.Context, ev domain.Event) {
+	if s.notifier != nil {
+		s.notifier.Notify(context.WithoutCancel(ctx), ev)
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"question-service/internal/domain"
 	"testing"
+	"time"
 )
 
 type mockAnswerRepo struct {
@@ -22,16 +23,56 @@ func (m *mockAnswerRepo) Create(_ context.Context, a *domain.Answer) error {
 	return nil
 }
 
+func (m *mockAnswerRepo) CreateBatch(_ context.Context, answers []domain.Answer, _ int) error {
+	return nil
+}
+
+func (m *mockAnswerRepo) CreateMany(_ context.Context, answers []domain.Answer) error {
+	for i := range answers {
+		answers[i].ID = i + 1
+		m.created = append(m.created, &answers[i])
+	}
+	return nil
+}
+
 func (m *mockAnswerRepo) GetByID(_ context.Context, id int) (*domain.Answer, error) {
 	return nil, gorm.ErrRecordNotFound
 }
 
-func (m *mockAnswerRepo) Delete(_ context.Context, id int) error { return nil }
+func (m *mockAnswerRepo) Delete(_ context.Context, id, version int) error { return nil }
+
+func (m *mockAnswerRepo) Update(_ context.Context, a *domain.Answer) error { return nil }
 
 func (m *mockAnswerRepo) ListByQuestionID(_ context.Context, qid int) ([]domain.Answer, error) {
 	return nil, nil
 }
 
+func (m *mockAnswerRepo) ListByQuestionIDs(_ context.Context, qids []int, perQuestion int) ([]domain.Answer, error) {
+	return nil, nil
+}
+
+func (m *mockAnswerRepo) ListThread(_ context.Context, questionID, maxDepth int) ([]domain.Answer, error) {
+	return nil, nil
+}
+
+func (m *mockAnswerRepo) Depth(_ context.Context, id int) (int, error) {
+	return 0, nil
+}
+
+func (m *mockAnswerRepo) Restore(_ context.Context, id int) (*domain.Answer, error) {
+	return nil, nil
+}
+
+func (m *mockAnswerRepo) ListDeleted(_ context.Context, _, _ int) ([]domain.Answer, error) {
+	return nil, nil
+}
+
+func (m *mockAnswerRepo) Purge(_ context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockAnswerRepo) SetHidden(_ context.Context, id int, hidden bool) error { return nil }
+
 func TestAnswerService_CreateAnswer_QuestionNotFound(t *testing.T) {
 
 	qRepo := &mockQuestionRepo{
@@ -41,7 +82,7 @@ func TestAnswerService_CreateAnswer_QuestionNotFound(t *testing.T) {
 	}
 
 	aRepo := &mockAnswerRepo{}
-	svc := service.NewAnswerService(aRepo, qRepo)
+	svc := service.NewAnswerService(aRepo, qRepo, &mockOutbox{}, noopTx{}, nil)
 
 	ctx := context.Background()
 	ans, err := svc.CreateAnswer(ctx, 999, "u123", "hi")
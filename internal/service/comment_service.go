@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"question-service/internal/domain"
+	"question-service/internal/repository"
+)
+
+// CommentService ведёт комментарии к вопросам и ответам. Добавление и удаление
+// комментария увеличивает версию вопроса, к которому он относится, поэтому
+// ETag вопроса с комментариями остаётся верным.
+type CommentService struct {
+	comments  repository.CommentRepository
+	questions repository.QuestionRepository
+	answers   repository.AnswerRepository
+	tx        TxManager
+}
+
+func NewCommentService(cRepo repository.CommentRepository, qRepo repository.QuestionRepository, aRepo repository.AnswerRepository, tx TxManager) *CommentService {
+	return &CommentService{
+		comments:  cRepo,
+		questions: qRepo,
+		answers:   aRepo,
+		tx:        tx,
+	}
+}
+
+// CommentOnQuestion добавляет комментарий к вопросу. Скрытый модератором вопрос
+// не находится.
+func (s *CommentService) CommentOnQuestion(ctx context.Context, questionID int, userID, text string) (*domain.Comment, error) {
+	c := &domain.Comment{QuestionID: &questionID, UserID: userID, Text: text}
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.questions.BumpVersion(ctx, questionID); err != nil {
+			return err
+		}
+		if _, err := visibleQuestion(ctx, s.questions, questionID); err != nil {
+			return err
+		}
+		return s.comments.Create(ctx, c)
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, gorm.ErrForeignKeyViolated) {
+			return nil, ErrQuestionNotFound
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+// CommentOnAnswer добавляет комментарий к ответу. Скрытый модератором ответ,
+// реплика на скрытый ответ или ответ на скрытый вопрос не находится.
+func (s *CommentService) CommentOnAnswer(ctx context.Context, answerID int, userID, text string) (*domain.Comment, error) {
+	c := &domain.Comment{AnswerID: &answerID, UserID: userID, Text: text}
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		a, err := visibleAnswer(ctx, s.questions, s.answers, answerID)
+		if err != nil {
+			return err
+		}
+		if err := s.questions.BumpVersion(ctx, a.QuestionID); err != nil {
+			return err
+		}
+		return s.comments.Create(ctx, c)
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, gorm.ErrForeignKeyViolated) {
+			return nil, ErrAnswerNotFound
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+// ListQuestionComments возвращает комментарии к самому вопросу, без комментариев к его ответам.
+// Комментарии к вопросу в корзине или скрытому модератором не отдаются.
+func (s *CommentService) ListQuestionComments(ctx context.Context, questionID int) ([]domain.Comment, error) {
+	if _, err := visibleQuestion(ctx, s.questions, questionID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrQuestionNotFound
+		}
+		return nil, err
+	}
+	return s.comments.ListByQuestionID(ctx, questionID)
+}
+
+// ListAnswerComments возвращает комментарии к ответу. Комментарии к ответу в
+// корзине или скрытому модератором, как и к ответам скрытого вопроса, не отдаются.
+func (s *CommentService) ListAnswerComments(ctx context.Context, answerID int) ([]domain.Comment, error) {
+	if _, err := visibleAnswer(ctx, s.questions, s.answers, answerID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAnswerNotFound
+		}
+		return nil, err
+	}
+	return s.comments.ListByAnswerIDs(ctx, []int{answerID})
+}
+
+// AttachComments заполняет комментарии вопроса и его ответов двумя запросами.
+func (s *CommentService) AttachComments(ctx context.Context, q *domain.Question) error {
+	comments, err := s.comments.ListByQuestionID(ctx, q.ID)
+	if err != nil {
+		return err
+	}
+	q.Comments = comments
+
+	if len(q.Answers) == 0 {
+		return nil
+	}
+	ids := make([]int, len(q.Answers))
+	for i, a := range q.Answers {
+		ids[i] = a.ID
+	}
+	answerComments, err := s.comments.ListByAnswerIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	byAnswer := make(map[int][]domain.Comment, len(q.Answers))
+	for _, c := range answerComments {
+		byAnswer[*c.AnswerID] = append(byAnswer[*c.AnswerID], c)
+	}
+	for i := range q.Answers {
+		q.Answers[i].Comments = byAnswer[q.Answers[i].ID]
+	}
+	return nil
+}
+
+// DeleteComment удаляет комментарий.
+func (s *CommentService) DeleteComment(ctx context.Context, id int) error {
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		c, err := s.comments.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		questionID := 0
+		if c.QuestionID != nil {
+			questionID = *c.QuestionID
+		} else {
+			a, err := s.answers.GetByID(ctx, *c.AnswerID)
+			if err != nil {
+				return err
+			}
+			questionID = a.QuestionID
+		}
+
+		if err := s.comments.Delete(ctx, id); err != nil {
+			return err
+		}
+		return s.questions.BumpVersion(ctx, questionID)
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCommentNotFound
+		}
+		return err
+	}
+	return nil
+}
@@ -5,4 +5,44 @@ import "errors"
 var (
 	ErrQuestionNotFound = errors.New("question not found")
 	ErrAnswerNotFound   = errors.New("answer not found")
+	ErrCommentNotFound  = errors.New("comment not found")
+	// ErrParentAnswerNotFound — нет ответа, на который пишется реплика.
+	ErrParentAnswerNotFound = errors.New("parent answer not found")
+	// ErrParentAnswerMismatch — родительский ответ относится к другому вопросу.
+	ErrParentAnswerMismatch = errors.New("parent answer belongs to another question")
+	// ErrReplyTooDeep — реплика превысила бы допустимую глубину ветки.
+	ErrReplyTooDeep = errors.New("reply is nested too deep")
+	// ErrVersionMismatch — ресурс изменён после того, как клиент получил его версию.
+	ErrVersionMismatch = errors.New("version mismatch")
+	// ErrParentDeleted — ответ нельзя восстановить, пока его вопрос или родительский
+	// ответ в корзине.
+	ErrParentDeleted = errors.New("parent question or answer is deleted, restore it first")
+	// ErrQuestionLocked — модератор запретил новые ответы на вопрос.
+	ErrQuestionLocked = errors.New("question is locked")
+	// ErrQuestionClosed — вопрос закрыт и не принимает ответы.
+	ErrQuestionClosed = errors.New("question is closed")
+	// ErrQuestionNotClosed — открыть снова можно только закрытый вопрос.
+	ErrQuestionNotClosed = errors.New("question is not closed")
+	// ErrQuestionNotLocked — снять блокировку можно только с заблокированного вопроса.
+	ErrQuestionNotLocked = errors.New("question is not locked")
+	// ErrQuestionMerged — вопрос объединён с другим и остался заглушкой.
+	ErrQuestionMerged = errors.New("question is merged into another question")
+	// ErrMergeTargetNotFound — нет вопроса, в который объединяется вопрос.
+	ErrMergeTargetNotFound = errors.New("target question not found")
+	// ErrInvalidMerge оборачивается с описанием, почему объединение невозможно.
+	ErrInvalidMerge = errors.New("invalid merge")
+	// ErrInvalidStatus — неизвестный статус вопроса в фильтре.
+	ErrInvalidStatus = errors.New("invalid question status")
+	// ErrInvalidCloseReason оборачивается с описанием, что не так в причине закрытия.
+	ErrInvalidCloseReason = errors.New("invalid close reason")
+	// ErrAlreadyFlagged — у пользователя уже есть открытая жалоба на этот вопрос или ответ.
+	ErrAlreadyFlagged = errors.New("already flagged by this user")
+	// ErrInvalidFlag оборачивается с описанием, что не так в жалобе.
+	ErrInvalidFlag = errors.New("invalid flag")
+	// ErrInvalidModerationAction оборачивается с описанием, почему действие неприменимо.
+	ErrInvalidModerationAction = errors.New("invalid moderation action")
+
+	ErrWebhookNotFound = errors.New("webhook not found")
+	// ErrInvalidWebhook оборачивается с описанием, что именно не так в подписке.
+	ErrInvalidWebhook = errors.New("invalid webhook subscription")
 )
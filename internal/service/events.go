@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+
+	"question-service/internal/domain"
+)
+
+// EventRecorder записывает доменные события в outbox и проставляет им ID. Вызывается
+// внутри TxManager.WithinTx, чтобы событие сохранялось атомарно с изменением.
+type EventRecorder interface {
+	Append(ctx context.Context, events ...domain.Event) error
+}
+
+// Notifier мгновенно оповещает подписчиков о событии после фиксации транзакции.
+// В отличие от outbox доставка не гарантируется, поэтому ошибки не возвращаются.
+type Notifier interface {
+	Notify(ctx context.Context, ev domain.Event)
+}
+
+// record создаёт событие агрегата вопроса, записывает его и возвращает с присвоенным ID.
+func record(ctx context.Context, events EventRecorder, eventType string, questionID int, data any) (domain.Event, error) {
+	ev, err := domain.NewEvent(eventType, questionID, data)
+	if err != nil {
+		return domain.Event{}, err
+	}
+
+	batch := []domain.Event{ev}
+	if err := events.Append(ctx, batch...); err != nil {
+		return domain.Event{}, err
+	}
+	return batch[0], nil
+}
+
+// deletedPayload — данные событий об удалении.
+type deletedPayload struct {
+	ID         int `json:"id"`
+	QuestionID int `json:"question_id,omitempty"`
+}
@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+
+	"question-service/internal/domain"
+	"question-service/internal/repository"
+)
+
+// ModerationService принимает жалобы пользователей и выполняет действия
+// модераторов. Каждое действие записывается в журнал в той же транзакции,
+// что и само изменение.
+type ModerationService struct {
+	moderation repository.ModerationRepository
+	questions  repository.QuestionRepository
+	answers    repository.AnswerRepository
+	// statuses блокирует и разблокирует вопросы тем же путём, что закрытие и
+	// открытие: с новой версией и событием в outbox.
+	statuses *QuestionService
+	tx       TxManager
+}
+
+func NewModerationService(mRepo repository.ModerationRepository, qRepo repository.QuestionRepository, aRepo repository.AnswerRepository, qSvc *QuestionService, tx TxManager) *ModerationService {
+	return &ModerationService{
+		moderation: mRepo,
+		questions:  qRepo,
+		answers:    aRepo,
+		statuses:   qSvc,
+		tx:         tx,
+	}
+}
+
+// FlagQuestion сохраняет жалобу пользователя на вопрос.
+func (s *ModerationService) FlagQuestion(ctx context.Context, questionID int, userID, reason string) (*domain.Flag, error) {
+	if err := validateFlagReason(reason); err != nil {
+		return nil, err
+	}
+	f := &domain.Flag{QuestionID: &questionID, UserID: userID, Reason: reason}
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		q, err := s.questions.GetWithoutAnswers(ctx, questionID)
+		if err != nil {
+			return err
+		}
+		if q.Hidden {
+			return gorm.ErrRecordNotFound
+		}
+		return s.moderation.CreateFlag(ctx, f)
+	})
+	if err != nil {
+		return nil, flagError(err, ErrQuestionNotFound)
+	}
+	return f, nil
+}
+
+// FlagAnswer сохраняет жалобу пользователя на ответ.
+func (s *ModerationService) FlagAnswer(ctx context.Context, answerID int, userID, reason string) (*domain.Flag, error) {
+	if err := validateFlagReason(reason); err != nil {
+		return nil, err
+	}
+	f := &domain.Flag{AnswerID: &answerID, UserID: userID, Reason: reason}
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := visibleAnswer(ctx, s.questions, s.answers, answerID); err != nil {
+			return err
+		}
+		return s.moderation.CreateFlag(ctx, f)
+	})
+	if err != nil {
+		return nil, flagError(err, ErrAnswerNotFound)
+	}
+	return f, nil
+}
+
+func validateFlagReason(reason string) error {
+	if !slices.Contains(domain.FlagReasons, reason) {
+		return fmt.Errorf("%w: unknown reason %q", ErrInvalidFlag, reason)
+	}
+	return nil
+}
+
+func flagError(err, notFound error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, gorm.ErrForeignKeyViolated):
+		return notFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return ErrAlreadyFlagged
+	}
+	return err
+}
+
+// Queue возвращает до limit вопросов и ответов с открытыми жалобами, с большим
+// числом жалоб первыми.
+func (s *ModerationService) Queue(ctx context.Context, limit int) ([]domain.QueueItem, error) {
+	return s.moderation.Queue(ctx, limit)
+}
+
+// ListActions возвращает до limit последних действий модераторов; questionID > 0
+// оставляет только действия над вопросом и его ответами.
+func (s *ModerationService) ListActions(ctx context.Context, questionID, limit int) ([]domain.ModerationAction, error) {
+	return s.moderation.ListActions(ctx, questionID, limit)
+}
+
+// ModerateQuestion выполняет над вопросом действие hide, unhide, lock, unlock или dismiss
+// и возвращает запись журнала. Блокировка уже заблокированного, снятие блокировки с
+// незаблокированного и блокировка объединённого вопроса — ErrInvalidModerationAction.
+func (s *ModerationService) ModerateQuestion(ctx context.Context, id int, action, moderator, note string) (*domain.ModerationAction, error) {
+	var change, status func(ctx context.Context) error
+	switch action {
+	case domain.ModerationHide, domain.ModerationUnhide:
+		hidden := action == domain.ModerationHide
+		change = func(ctx context.Context) error { return s.questions.SetHidden(ctx, id, hidden) }
+	case domain.ModerationLock:
+		status = func(ctx context.Context) error {
+			_, err := s.statuses.LockQuestion(ctx, id, moderator)
+			return err
+		}
+	case domain.ModerationUnlock:
+		status = func(ctx context.Context) error {
+			_, err := s.statuses.UnlockQuestion(ctx, id)
+			return err
+		}
+	case domain.ModerationDismiss:
+	default:
+		return nil, fmt.Errorf("%w: unknown action %q", ErrInvalidModerationAction, action)
+	}
+
+	rec := &domain.ModerationAction{
+		Action:     action,
+		Target:     domain.TargetQuestion,
+		TargetID:   id,
+		QuestionID: id,
+		Moderator:  moderator,
+		Note:       note,
+	}
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// статус меняет QuestionService: он сам увеличивает версию и пишет событие
+		if status != nil {
+			if err := status(ctx); err != nil {
+				return err
+			}
+		} else if _, err := s.questions.GetWithoutAnswers(ctx, id); err != nil {
+			return err
+		}
+		return s.apply(ctx, rec, change)
+	})
+	switch {
+	case err == nil:
+		return rec, nil
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, ErrQuestionNotFound):
+		return nil, ErrQuestionNotFound
+	case errors.Is(err, ErrQuestionLocked), errors.Is(err, ErrQuestionNotLocked), errors.Is(err, ErrQuestionMerged):
+		return nil, fmt.Errorf("%w: %w", ErrInvalidModerationAction, err)
+	}
+	return nil, err
+}
+
+// ModerateAnswer выполняет над ответом действие hide, unhide или dismiss и
+// возвращает запись журнала.
+func (s *ModerationService) ModerateAnswer(ctx context.Context, id int, action, moderator, note string) (*domain.ModerationAction, error) {
+	var change func(ctx context.Context) error
+	switch action {
+	case domain.ModerationHide, domain.ModerationUnhide:
+		hidden := action == domain.ModerationHide
+		change = func(ctx context.Context) error { return s.answers.SetHidden(ctx, id, hidden) }
+	case domain.ModerationDismiss:
+	case domain.ModerationLock, domain.ModerationUnlock:
+		return nil, fmt.Errorf("%w: %s applies only to questions", ErrInvalidModerationAction, action)
+	default:
+		return nil, fmt.Errorf("%w: unknown action %q", ErrInvalidModerationAction, action)
+	}
+
+	rec := &domain.ModerationAction{
+		Action:    action,
+		Target:    domain.TargetAnswer,
+		TargetID:  id,
+		Moderator: moderator,
+		Note:      note,
+	}
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		a, err := s.answers.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		rec.QuestionID = a.QuestionID
+		return s.apply(ctx, rec, change)
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAnswerNotFound
+		}
+		return nil, err
+	}
+	return rec, nil
+}
+
+// apply применяет изменение change (nil — ничего не меняется), закрывает открытые
+// жалобы, если действие их разбирает, и записывает действие в журнал. Изменение
+// видимости увеличивает версию вопроса, поэтому ETag меняется.
+func (s *ModerationService) apply(ctx context.Context, rec *domain.ModerationAction, change func(ctx context.Context) error) error {
+	if change != nil {
+		if err := change(ctx); err != nil {
+			return err
+		}
+		if err := s.questions.BumpVersion(ctx, rec.QuestionID); err != nil {
+			return err
+		}
+	}
+
+	if rec.Action == domain.ModerationHide || rec.Action == domain.ModerationDismiss {
+		var questionID, answerID *int
+		if rec.Target == domain.TargetQuestion {
+			questionID = &rec.TargetID
+		} else {
+			answerID = &rec.TargetID
+		}
+		n, err := s.moderation.ResolveFlags(ctx, questionID, answerID, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+		rec.ResolvedFlags = int(n)
+	}
+
+	return s.moderation.CreateAction(ctx, rec)
+}
+
+// visibleAnswer возвращает ответ, если ни он, ни ответы выше по ветке, ни его вопрос
+// не скрыты модератором, иначе gorm.ErrRecordNotFound.
+func visibleAnswer(ctx context.Context, questions repository.QuestionRepository, answers repository.AnswerRepository, id int) (*domain.Answer, error) {
+	a, err := answers.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	for p := a; ; {
+		if p.Hidden {
+			return nil, gorm.ErrRecordNotFound
+		}
+		if p.ParentAnswerID == nil {
+			break
+		}
+		if p, err = answers.GetByID(ctx, *p.ParentAnswerID); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := visibleQuestion(ctx, questions, a.QuestionID); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// visibleQuestion возвращает вопрос без ответов или gorm.ErrRecordNotFound, если
+// вопрос скрыт модератором.
+func visibleQuestion(ctx context.Context, questions repository.QuestionRepository, id int) (*domain.Question, error) {
+	q, err := questions.GetWithoutAnswers(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if q.Hidden {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return q, nil
+}
+
+// visibleAnswers убирает из answers скрытые модератором ответы и все реплики на них.
+func visibleAnswers(answers []domain.Answer) []domain.Answer {
+	if !slices.ContainsFunc(answers, func(a domain.Answer) bool { return a.Hidden }) {
+		return answers
+	}
+
+	// порядок ответов не важен: скрытые ветки помечаются, пока есть что помечать
+	hidden := make(map[int]bool)
+	for changed := true; changed; {
+		changed = false
+		for _, a := range answers {
+			if hidden[a.ID] {
+				continue
+			}
+			if a.Hidden || a.ParentAnswerID != nil && hidden[*a.ParentAnswerID] {
+				hidden[a.ID] = true
+				changed = true
+			}
+		}
+	}
+
+	out := make([]domain.Answer, 0, len(answers)-len(hidden))
+	for _, a := range answers {
+		if !hidden[a.ID] {
+			out = append(out, a)
+		}
+	}
+	return out
+}
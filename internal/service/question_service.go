@@ -3,6 +3,9 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
+	"time"
 
 	"question-service/internal/domain"
 	"question-service/internal/repository"
@@ -10,12 +13,34 @@ import (
 	"gorm.io/gorm"
 )
 
+// DefaultDuplicateThreshold — сходство текстов, с которого вопрос считается
+// возможным дубликатом, если SetDuplicateThreshold не вызывался.
+const DefaultDuplicateThreshold = 0.4
+
+// maxDuplicateCandidates — сколько похожих вопросов возвращает FindDuplicates.
+const maxDuplicateCandidates = 5
+
 type QuestionService struct {
 	questions repository.QuestionRepository
+	events    EventRecorder
+	tx        TxManager
+
+	duplicateThreshold float64
 }
 
-func NewQuestionService(qRepo repository.QuestionRepository) *QuestionService {
-	return &QuestionService{questions: qRepo}
+func NewQuestionService(qRepo repository.QuestionRepository, events EventRecorder, tx TxManager) *QuestionService {
+	return &QuestionService{
+		questions:          qRepo,
+		events:             events,
+		tx:                 tx,
+		duplicateThreshold: DefaultDuplicateThreshold,
+	}
+}
+
+// SetDuplicateThreshold задаёт сходство текстов от 0 до 1, с которого вопрос
+// считается возможным дубликатом.
+func (s *QuestionService) SetDuplicateThreshold(threshold float64) {
+	s.duplicateThreshold = min(max(threshold, 0), 1)
 }
 
 // CreateQuestion создает новый вопрос.
@@ -24,19 +49,108 @@ func (s *QuestionService) CreateQuestion(ctx context.Context, text string) (*dom
 		Text: text,
 	}
 
-	if err := s.questions.Create(ctx, q); err != nil {
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.questions.Create(ctx, q); err != nil {
+			return err
+		}
+
+		_, err := record(ctx, s.events, domain.EventQuestionCreated, q.ID, q)
+		return err
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return q, nil
 }
 
-// ListQuestions возвращает список всех вопросов.
-func (s *QuestionService) ListQuestions(ctx context.Context) ([]domain.Question, error) {
-	return s.questions.GetAll(ctx)
+// CreateQuestions создаёт вопросы с текстами texts в одной транзакции: либо все,
+// либо ни одного. На каждый вопрос записывается событие question.created.
+func (s *QuestionService) CreateQuestions(ctx context.Context, texts []string) ([]domain.Question, error) {
+	questions := make([]domain.Question, len(texts))
+	for i, text := range texts {
+		questions[i].Text = text
+	}
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.questions.CreateBatch(ctx, questions, len(questions)); err != nil {
+			return err
+		}
+
+		evs := make([]domain.Event, len(questions))
+		for i := range questions {
+			ev, err := domain.NewEvent(domain.EventQuestionCreated, questions[i].ID, &questions[i])
+			if err != nil {
+				return err
+			}
+			evs[i] = ev
+		}
+		return s.events.Append(ctx, evs...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return questions, nil
+}
+
+// FindDuplicates возвращает существующие вопросы, похожие на вопрос с текстом text,
+// самые похожие первыми. Объединённые вопросы-заглушки не возвращаются.
+func (s *QuestionService) FindDuplicates(ctx context.Context, text string) ([]domain.DuplicateCandidate, error) {
+	return s.questions.FindSimilar(ctx, text, s.duplicateThreshold, maxDuplicateCandidates)
+}
+
+// ListQuestions возвращает список всех вопросов, кроме скрытых модератором и
+// заглушек объединённых. Непустой status оставляет только вопросы с этим статусом;
+// фильтры применяет запрос к хранилищу.
+func (s *QuestionService) ListQuestions(ctx context.Context, status string) ([]domain.Question, error) {
+	if err := validateStatus(status); err != nil {
+		return nil, err
+	}
+
+	return s.questions.List(ctx, status, 0, 0)
+}
+
+// ListQuestionsPage возвращает до first вопросов с id > afterID и признак того,
+// что за ними есть ещё вопросы. Скрытые модератором вопросы и заглушки пропускаются,
+// непустой status оставляет только вопросы с этим статусом.
+func (s *QuestionService) ListQuestionsPage(ctx context.Context, afterID, first int, status string) ([]domain.Question, bool, error) {
+	if err := validateStatus(status); err != nil {
+		return nil, false, err
+	}
+
+	page, err := s.questions.List(ctx, status, afterID, first+1)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(page) > first {
+		return page[:first], true, nil
+	}
+	return page, false, nil
+}
+
+func validateStatus(status string) error {
+	if status != "" && !slices.Contains(domain.QuestionStatuses, status) {
+		return fmt.Errorf("%w: %q", ErrInvalidStatus, status)
+	}
+	return nil
+}
+
+// GetQuestion возвращает вопрос без ответов. Скрытый модератором вопрос не находится.
+func (s *QuestionService) GetQuestion(ctx context.Context, id int) (*domain.Question, error) {
+	q, err := visibleQuestion(ctx, s.questions, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrQuestionNotFound
+		}
+		return nil, err
+	}
+	return q, nil
 }
 
-// GetQuestionWithAnswers возвращает вопрос и все его ответы
+// GetQuestionWithAnswers возвращает вопрос и все его ответы. Скрытый модератором
+// вопрос не находится, скрытые ответы не возвращаются вместе с репликами на них.
+// Для объединённого вопроса возвращается заглушка без ответов с MergedIntoID.
 func (s *QuestionService) GetQuestionWithAnswers(ctx context.Context, id int) (*domain.Question, error) {
 	q, err := s.questions.GetByID(ctx, id)
 	if err != nil {
@@ -45,18 +159,307 @@ func (s *QuestionService) GetQuestionWithAnswers(ctx context.Context, id int) (*
 		}
 		return nil, err
 	}
+	if q.Hidden {
+		return nil, ErrQuestionNotFound
+	}
 
+	q.Answers = visibleAnswers(q.Answers)
 	return q, nil
 }
 
-// DeleteQuestion удаляет вопрос (каскад по FK удалит ответы).
-func (s *QuestionService) DeleteQuestion(ctx context.Context, id int) error {
-	err := s.questions.Delete(ctx, id)
+// DeleteQuestion переносит вопрос с ответами в корзину. Скрытый модератором
+// вопрос считается несуществующим.
+// Если version > 0, вопрос удаляется только при совпадении версии.
+func (s *QuestionService) DeleteQuestion(ctx context.Context, id, version int) error {
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := visibleQuestion(ctx, s.questions, id); err != nil {
+			return err
+		}
+		if err := s.questions.Delete(ctx, id, version); err != nil {
+			return err
+		}
+
+		_, err := record(ctx, s.events, domain.EventQuestionDeleted, id, deletedPayload{ID: id})
+		return err
+	})
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return ErrQuestionNotFound
 		}
+		if errors.Is(err, repository.ErrStaleVersion) {
+			return ErrVersionMismatch
+		}
 		return err
 	}
 	return nil
 }
+
+// CloseQuestion закрывает открытый вопрос: новые ответы на него отклоняются с
+// ErrQuestionClosed, пока вопрос не откроют снова. Закрыть можно только открытый
+// вопрос: закрытый — ErrQuestionClosed, заблокированный модератором — ErrQuestionLocked.
+func (s *QuestionService) CloseQuestion(ctx context.Context, id int, closedBy, reason string) (*domain.Question, error) {
+	if !slices.Contains(domain.CloseReasons, reason) {
+		return nil, fmt.Errorf("%w: unknown reason %q", ErrInvalidCloseReason, reason)
+	}
+
+	now := time.Now().UTC()
+	return s.changeStatus(ctx, id, domain.EventQuestionClosed, func(q *domain.Question) error {
+		switch q.Status {
+		case domain.QuestionClosed:
+			return ErrQuestionClosed
+		case domain.QuestionLocked:
+			return ErrQuestionLocked
+		}
+		q.Status = domain.QuestionClosed
+		q.CloseReason = reason
+		q.ClosedAt = &now
+		q.ClosedBy = closedBy
+		return nil
+	})
+}
+
+// ReopenQuestion снова открывает закрытый вопрос. Открытый вопрос —
+// ErrQuestionNotClosed, заблокированный модератором — ErrQuestionLocked: блокировку
+// снимает только модератор.
+func (s *QuestionService) ReopenQuestion(ctx context.Context, id int) (*domain.Question, error) {
+	return s.changeStatus(ctx, id, domain.EventQuestionReopened, func(q *domain.Question) error {
+		switch q.Status {
+		case domain.QuestionOpen:
+			return ErrQuestionNotClosed
+		case domain.QuestionLocked:
+			return ErrQuestionLocked
+		}
+		reopen(q)
+		return nil
+	})
+}
+
+// reopen возвращает вопрос в статус open и очищает сведения о закрытии.
+func reopen(q *domain.Question) {
+	q.Status = domain.QuestionOpen
+	q.CloseReason = ""
+	q.ClosedAt = nil
+	q.ClosedBy = ""
+}
+
+// LockQuestion блокирует вопрос от имени модератора: новые ответы отклоняются с
+// ErrQuestionLocked. Сведения о закрытии сохраняются, чтобы UnlockQuestion вернул
+// вопрос в прежний статус. Уже заблокированный вопрос — ErrQuestionLocked.
+// Скрытый модератором вопрос тоже можно заблокировать.
+func (s *QuestionService) LockQuestion(ctx context.Context, id int, moderator string) (*domain.Question, error) {
+	now := time.Now().UTC()
+	return s.moderateStatus(ctx, id, domain.EventQuestionLocked, func(q *domain.Question) error {
+		if q.Status == domain.QuestionLocked {
+			return ErrQuestionLocked
+		}
+		q.Status = domain.QuestionLocked
+		q.LockedAt = &now
+		q.LockedBy = moderator
+		return nil
+	})
+}
+
+// UnlockQuestion снимает блокировку: вопрос, закрытый до блокировки, остаётся
+// закрытым, остальные открываются. Незаблокированный вопрос — ErrQuestionNotLocked.
+func (s *QuestionService) UnlockQuestion(ctx context.Context, id int) (*domain.Question, error) {
+	return s.moderateStatus(ctx, id, domain.EventQuestionUnlocked, func(q *domain.Question) error {
+		if q.Status != domain.QuestionLocked {
+			return ErrQuestionNotLocked
+		}
+		unlock(q)
+		return nil
+	})
+}
+
+// unlock снимает с вопроса блокировку и возвращает статус, который был до неё.
+func unlock(q *domain.Question) {
+	q.Status = domain.QuestionOpen
+	if q.ClosedAt != nil {
+		q.Status = domain.QuestionClosed
+	}
+	q.LockedAt = nil
+	q.LockedBy = ""
+}
+
+// changeStatus меняет статус видимого вопроса функцией change, увеличивает версию
+// вопроса и записывает событие eventType. Возвращает вопрос без ответов.
+func (s *QuestionService) changeStatus(ctx context.Context, id int, eventType string, change func(q *domain.Question) error) (*domain.Question, error) {
+	return s.setStatus(ctx, id, eventType, false, change)
+}
+
+// moderateStatus — changeStatus для действий модератора, которым доступны и
+// скрытые вопросы.
+func (s *QuestionService) moderateStatus(ctx context.Context, id int, eventType string, change func(q *domain.Question) error) (*domain.Question, error) {
+	return s.setStatus(ctx, id, eventType, true, change)
+}
+
+func (s *QuestionService) setStatus(ctx context.Context, id int, eventType string, withHidden bool, change func(q *domain.Question) error) (*domain.Question, error) {
+	var q *domain.Question
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// BumpVersion блокирует строку вопроса, поэтому статус не меняется параллельно
+		if err := s.questions.BumpVersion(ctx, id); err != nil {
+			return err
+		}
+
+		var err error
+		if q, err = s.questions.GetWithoutAnswers(ctx, id); err != nil {
+			return err
+		}
+		if q.Hidden && !withHidden {
+			return ErrQuestionNotFound
+		}
+		if q.MergedIntoID != nil {
+			return ErrQuestionMerged
+		}
+		if err := change(q); err != nil {
+			return err
+		}
+		if err := s.questions.SetStatus(ctx, q); err != nil {
+			return err
+		}
+
+		_, err = record(ctx, s.events, eventType, id, q)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrQuestionNotFound
+		}
+		return nil, err
+	}
+	return q, nil
+}
+
+// mergedPayload — данные события question.merged.
+type mergedPayload struct {
+	ID           int `json:"id"`
+	MergedIntoID int `json:"merged_into_id"`
+	Answers      int `json:"answers"`
+}
+
+// MergeQuestion переносит все ответы вопроса id в вопрос targetID и оставляет
+// вместо id закрытую с причиной duplicate заглушку, которая ссылается на targetID.
+// Если targetID сам объединён с другим вопросом, ответы переносятся в тот.
+// Версии обоих вопросов увеличиваются, записывается событие question.merged.
+// Возвращает вопрос targetID с ответами.
+func (s *QuestionService) MergeQuestion(ctx context.Context, id, targetID int, mergedBy string) (*domain.Question, error) {
+	if id == targetID {
+		return nil, fmt.Errorf("%w: question cannot be merged into itself", ErrInvalidMerge)
+	}
+
+	var target *domain.Question
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// заглушки не образуют цепочек, поэтому достаточно одного перехода
+		t, err := s.questions.GetWithoutAnswers(ctx, targetID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrMergeTargetNotFound
+			}
+			return err
+		}
+		if t.MergedIntoID != nil {
+			targetID = *t.MergedIntoID
+		}
+		if id == targetID {
+			return fmt.Errorf("%w: target question is already merged into this one", ErrInvalidMerge)
+		}
+
+		// строки блокируются по возрастанию id, чтобы встречные объединения не ждали друг друга
+		for _, qid := range []int{min(id, targetID), max(id, targetID)} {
+			if err := s.questions.BumpVersion(ctx, qid); err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) && qid == targetID {
+					return ErrMergeTargetNotFound
+				}
+				return err
+			}
+		}
+
+		src, err := s.questions.GetWithoutAnswers(ctx, id)
+		if err != nil {
+			return err
+		}
+		switch {
+		case src.Hidden:
+			return ErrQuestionNotFound
+		case src.MergedIntoID != nil:
+			return ErrQuestionMerged
+		}
+		if t, err = s.questions.GetWithoutAnswers(ctx, targetID); err != nil {
+			return err
+		}
+		if t.Hidden || t.MergedIntoID != nil {
+			return ErrMergeTargetNotFound
+		}
+
+		moved, err := s.questions.Merge(ctx, id, targetID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		src.Status = domain.QuestionClosed
+		src.CloseReason = domain.CloseReasonDuplicate
+		src.ClosedAt = &now
+		src.ClosedBy = mergedBy
+		src.LockedAt = nil
+		src.LockedBy = ""
+		if err := s.questions.SetStatus(ctx, src); err != nil {
+			return err
+		}
+
+		_, err = record(ctx, s.events, domain.EventQuestionMerged, id, mergedPayload{
+			ID:           id,
+			MergedIntoID: targetID,
+			Answers:      int(moved),
+		})
+		if err != nil {
+			return err
+		}
+
+		if target, err = s.questions.GetByID(ctx, targetID); err != nil {
+			return err
+		}
+		target.Answers = visibleAnswers(target.Answers)
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrQuestionNotFound
+		}
+		return nil, err
+	}
+	return target, nil
+}
+
+// RestoreQuestion возвращает вопрос из корзины вместе с ответами, удалёнными
+// вместе с ним, и увеличивает его версию. Скрытый модератором вопрос считается
+// несуществующим: восстановление откатывается, чтобы не раскрыть его текст.
+func (s *QuestionService) RestoreQuestion(ctx context.Context, id int) (*domain.Question, error) {
+	var q *domain.Question
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.questions.Restore(ctx, id); err != nil {
+			return err
+		}
+		if err := s.questions.BumpVersion(ctx, id); err != nil {
+			return err
+		}
+
+		var err error
+		if q, err = s.questions.GetByID(ctx, id); err != nil {
+			return err
+		}
+		if q.Hidden {
+			return gorm.ErrRecordNotFound
+		}
+		q.Answers = visibleAnswers(q.Answers)
+		_, err = record(ctx, s.events, domain.EventQuestionRestored, id, q)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrQuestionNotFound
+		}
+		return nil, err
+	}
+	return q, nil
+}
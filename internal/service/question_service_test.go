@@ -3,6 +3,7 @@ package service_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"question-service/internal/domain"
 	"question-service/internal/service"
@@ -25,12 +26,19 @@ func (m *mockQuestionRepo) Create(_ context.Context, q *domain.Question) error {
 	return nil
 }
 
-func (m *mockQuestionRepo) GetAll(_ context.Context) ([]domain.Question, error) {
-	var out []domain.Question
-	for _, q := range m.created {
-		out = append(out, *q)
+func (m *mockQuestionRepo) CreateBatch(_ context.Context, questions []domain.Question, _ int) error {
+	for i := range questions {
+		questions[i].ID = len(m.created) + i + 1
 	}
-	return out, nil
+	return nil
+}
+
+func (m *mockQuestionRepo) ListPage(_ context.Context, afterID, limit int) ([]domain.Question, error) {
+	return nil, nil
+}
+
+func (m *mockQuestionRepo) List(_ context.Context, status string, afterID, limit int) ([]domain.Question, error) {
+	return nil, nil
 }
 
 func (m *mockQuestionRepo) GetByID(_ context.Context, id int) (*domain.Question, error) {
@@ -40,13 +48,75 @@ func (m *mockQuestionRepo) GetByID(_ context.Context, id int) (*domain.Question,
 	return nil, gorm.ErrRecordNotFound
 }
 
-func (m *mockQuestionRepo) Delete(_ context.Context, id int) error {
+func (m *mockQuestionRepo) FindByExternalIDs(_ context.Context, externalIDs []string) ([]domain.Question, error) {
+	return nil, nil
+}
+
+func (m *mockQuestionRepo) Update(_ context.Context, q *domain.Question) error {
+	return nil
+}
+
+func (m *mockQuestionRepo) BumpVersion(_ context.Context, id int) error {
+	_, err := m.GetByID(context.Background(), id)
+	return err
+}
+
+func (m *mockQuestionRepo) Delete(_ context.Context, id, version int) error {
+	return nil
+}
+
+func (m *mockQuestionRepo) Restore(_ context.Context, id int) error {
+	return nil
+}
+
+func (m *mockQuestionRepo) ListDeleted(_ context.Context, _, _ int) ([]domain.Question, error) {
+	return nil, nil
+}
+
+func (m *mockQuestionRepo) Purge(_ context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockQuestionRepo) GetWithoutAnswers(ctx context.Context, id int) (*domain.Question, error) {
+	return m.GetByID(ctx, id)
+}
+
+func (m *mockQuestionRepo) SetHidden(_ context.Context, id int, hidden bool) error {
+	return nil
+}
+
+func (m *mockQuestionRepo) SetStatus(_ context.Context, q *domain.Question) error {
+	return nil
+}
+
+func (m *mockQuestionRepo) FindSimilar(_ context.Context, text string, threshold float64, limit int) ([]domain.DuplicateCandidate, error) {
+	return nil, nil
+}
+
+func (m *mockQuestionRepo) Merge(_ context.Context, id, targetID int) (int64, error) {
+	return 0, nil
+}
+
+// noopTx выполняет функцию без транзакции.
+type noopTx struct{}
+
+func (noopTx) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// mockOutbox запоминает записанные доменные события.
+type mockOutbox struct {
+	events []domain.Event
+}
+
+func (m *mockOutbox) Append(_ context.Context, events ...domain.Event) error {
+	m.events = append(m.events, events...)
 	return nil
 }
 
 func TestQuestionService_CreateQuestion(t *testing.T) {
 	repo := &mockQuestionRepo{}
-	svc := service.NewQuestionService(repo)
+	svc := service.NewQuestionService(repo, &mockOutbox{}, noopTx{})
 
 	ctx := context.Background()
 	q, err := svc.CreateQuestion(ctx, "What is GORM?")
@@ -61,7 +131,7 @@ func TestQuestionService_GetQuestionWithAnswers_NotFound(t *testing.T) {
 			return nil, gorm.ErrRecordNotFound
 		},
 	}
-	svc := service.NewQuestionService(repo)
+	svc := service.NewQuestionService(repo, &mockOutbox{}, noopTx{})
 
 	ctx := context.Background()
 	q, err := svc.GetQuestionWithAnswers(ctx, 123)
@@ -70,3 +140,70 @@ func TestQuestionService_GetQuestionWithAnswers_NotFound(t *testing.T) {
 	require.Error(t, err)
 	require.ErrorIs(t, err, service.ErrQuestionNotFound)
 }
+
+func TestQuestionService_GetQuestion_Hidden(t *testing.T) {
+	repo := &mockQuestionRepo{
+		getByID: func(id int) (*domain.Question, error) {
+			return &domain.Question{ID: id, Text: "Hidden", Hidden: true}, nil
+		},
+	}
+	svc := service.NewQuestionService(repo, &mockOutbox{}, noopTx{})
+
+	q, err := svc.GetQuestion(context.Background(), 1)
+	require.Nil(t, q)
+	require.ErrorIs(t, err, service.ErrQuestionNotFound)
+}
+
+func TestQuestionService_CreateQuestion_RecordsEvent(t *testing.T) {
+	repo := &mockQuestionRepo{}
+	events := &mockOutbox{}
+	svc := service.NewQuestionService(repo, events, noopTx{})
+
+	q, err := svc.CreateQuestion(context.Background(), "What is GORM?")
+	require.NoError(t, err)
+
+	require.Len(t, events.events, 1)
+	require.Equal(t, domain.EventQuestionCreated, events.events[0].Type)
+	require.Equal(t, domain.AggregateQuestion, events.events[0].AggregateType)
+	require.Equal(t, q.ID, events.events[0].AggregateID)
+}
+
+func TestQuestionService_CreateQuestions_RecordsEventPerQuestion(t *testing.T) {
+	repo := &mockQuestionRepo{}
+	events := &mockOutbox{}
+	svc := service.NewQuestionService(repo, events, noopTx{})
+
+	questions, err := svc.CreateQuestions(context.Background(), []string{"First", "Second"})
+	require.NoError(t, err)
+	require.Len(t, questions, 2)
+
+	require.Len(t, events.events, 2)
+	for i, ev := range events.events {
+		require.NotZero(t, questions[i].ID)
+		require.Equal(t, domain.EventQuestionCreated, ev.Type)
+		require.Equal(t, questions[i].ID, ev.AggregateID)
+	}
+}
+
+func TestQuestionService_CloseQuestion_RecordsEvent(t *testing.T) {
+	repo := &mockQuestionRepo{getByID: func(id int) (*domain.Question, error) {
+		return &domain.Question{ID: id, Text: "What is GORM?", Status: domain.QuestionOpen}, nil
+	}}
+	events := &mockOutbox{}
+	svc := service.NewQuestionService(repo, events, noopTx{})
+
+	_, err := svc.CloseQuestion(context.Background(), 1, "u1", "boring")
+	require.ErrorIs(t, err, service.ErrInvalidCloseReason)
+	require.Empty(t, events.events)
+
+	q, err := svc.CloseQuestion(context.Background(), 1, "u1", domain.CloseReasonDuplicate)
+	require.NoError(t, err)
+	require.Equal(t, domain.QuestionClosed, q.Status)
+	require.Len(t, events.events, 1)
+	require.Equal(t, domain.EventQuestionClosed, events.events[0].Type)
+	require.Equal(t, 1, events.events[0].AggregateID)
+
+	_, err = svc.ReopenQuestion(context.Background(), 1)
+	require.ErrorIs(t, err, service.ErrQuestionNotClosed)
+	require.Len(t, events.events, 1)
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"question-service/internal/domain"
+	"question-service/internal/repository"
+)
+
+// TrashService показывает корзину и окончательно удаляет из неё старые записи.
+// Восстановление — в QuestionService и AnswerService.
+type TrashService struct {
+	questions repository.QuestionRepository
+	answers   repository.AnswerRepository
+}
+
+func NewTrashService(qRepo repository.QuestionRepository, aRepo repository.AnswerRepository) *TrashService {
+	return &TrashService{questions: qRepo, answers: aRepo}
+}
+
+// TrashPage — позиция страницы корзины: вопросы и ответы листаются независимо,
+// каждые по возрастанию id.
+type TrashPage struct {
+	// AfterQuestion и AfterAnswer — id последних вопроса и ответа предыдущей страницы.
+	AfterQuestion int
+	AfterAnswer   int
+	// Limit — сколько вопросов и сколько ответов вернуть.
+	Limit int
+}
+
+// List возвращает страницу корзины и признак того, что за ней есть ещё вопросы
+// или ответы.
+func (s *TrashService) List(ctx context.Context, page TrashPage) (*domain.Trash, bool, error) {
+	questions, err := s.questions.ListDeleted(ctx, page.AfterQuestion, page.Limit+1)
+	if err != nil {
+		return nil, false, err
+	}
+	answers, err := s.answers.ListDeleted(ctx, page.AfterAnswer, page.Limit+1)
+	if err != nil {
+		return nil, false, err
+	}
+	hasNext := len(questions) > page.Limit || len(answers) > page.Limit
+	questions = questions[:min(len(questions), page.Limit)]
+	answers = answers[:min(len(answers), page.Limit)]
+
+	trash := &domain.Trash{
+		Questions: make([]domain.TrashedQuestion, len(questions)),
+		Answers:   make([]domain.TrashedAnswer, len(answers)),
+	}
+	for i, q := range questions {
+		trash.Questions[i] = domain.TrashedQuestion{
+			ID:         q.ID,
+			ExternalID: q.ExternalID,
+			Text:       q.Text,
+			CreatedAt:  q.CreatedAt,
+			DeletedAt:  q.DeletedAt.Time,
+		}
+	}
+	for i, a := range answers {
+		trash.Answers[i] = domain.TrashedAnswer{
+			ID:             a.ID,
+			QuestionID:     a.QuestionID,
+			ParentAnswerID: a.ParentAnswerID,
+			UserID:         a.UserID,
+			Text:           a.Text,
+			CreatedAt:      a.CreatedAt,
+			DeletedAt:      a.DeletedAt.Time,
+		}
+	}
+	return trash, hasNext, nil
+}
+
+// Purge окончательно удаляет вопросы и ответы, пролежавшие в корзине дольше
+// retention, и возвращает число удалённых вопросов и ответов.
+func (s *TrashService) Purge(ctx context.Context, retention time.Duration) (questions, answers int64, err error) {
+	before := time.Now().Add(-retention)
+	if questions, err = s.questions.Purge(ctx, before); err != nil {
+		return 0, 0, err
+	}
+	if answers, err = s.answers.Purge(ctx, before); err != nil {
+		return questions, 0, err
+	}
+	return questions, answers, nil
+}
@@ -0,0 +1,9 @@
+package service
+
+import "context"
+
+// TxManager выполняет функцию атомарно: все вызовы репозиториев с контекстом,
+// переданным в fn, участвуют в одной транзакции.
+type TxManager interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"question-service/internal/domain"
+	"question-service/internal/repository"
+	"question-service/internal/webhook"
+)
+
+// maxDeliveriesListed — сколько последних доставок отдаётся для отладки.
+const maxDeliveriesListed = 100
+
+type WebhookService struct {
+	webhooks repository.WebhookRepository
+
+	// allowPrivateTargets разрешает адреса подписок во внутренней сети.
+	allowPrivateTargets bool
+	lookupIP            func(ctx context.Context, network, host string) ([]net.IP, error)
+}
+
+func NewWebhookService(repo repository.WebhookRepository) *WebhookService {
+	return &WebhookService{webhooks: repo, lookupIP: net.DefaultResolver.LookupIP}
+}
+
+// SetAllowPrivateTargets разрешает подписки на loopback, link-local и частные адреса.
+// По умолчанию они отклоняются: диспетчер шлёт запросы изнутри сети сервиса.
+func (s *WebhookService) SetAllowPrivateTargets(allow bool) {
+	s.allowPrivateTargets = allow
+}
+
+// WebhookInput — поля подписки при создании и изменении. При изменении
+// nil-поля остаются прежними.
+type WebhookInput struct {
+	URL        *string
+	EventTypes []string
+	Secret     *string
+	Active     *bool
+}
+
+// CreateSubscription создаёт подписку. Если секрет не задан, он генерируется;
+// секрет возвращается только в ответе на создание.
+func (s *WebhookService) CreateSubscription(ctx context.Context, in WebhookInput) (*domain.WebhookSubscription, error) {
+	if in.URL == nil {
+		return nil, fmt.Errorf("%w: url is required", ErrInvalidWebhook)
+	}
+	if len(in.EventTypes) == 0 {
+		return nil, fmt.Errorf("%w: event_types is required", ErrInvalidWebhook)
+	}
+
+	sub := &domain.WebhookSubscription{Active: true}
+	if err := s.applyInput(ctx, sub, in); err != nil {
+		return nil, err
+	}
+
+	if sub.Secret == "" {
+		secret, err := generateSecret()
+		if err != nil {
+			return nil, err
+		}
+		sub.Secret = secret
+	}
+
+	if err := s.webhooks.CreateSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// GetSubscription возвращает подписку по id.
+func (s *WebhookService) GetSubscription(ctx context.Context, id int64) (*domain.WebhookSubscription, error) {
+	sub, err := s.webhooks.GetSubscription(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	return sub, nil
+}
+
+// ListSubscriptions возвращает все подписки.
+func (s *WebhookService) ListSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	return s.webhooks.ListSubscriptions(ctx)
+}
+
+// UpdateSubscription изменяет подписку. Повторное включение выключенной подписки
+// сбрасывает счётчик неудач.
+func (s *WebhookService) UpdateSubscription(ctx context.Context, id int64, in WebhookInput) (*domain.WebhookSubscription, error) {
+	sub, err := s.GetSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	wasActive := sub.Active
+	if err := s.applyInput(ctx, sub, in); err != nil {
+		return nil, err
+	}
+	if sub.Active && !wasActive {
+		sub.ConsecutiveFailures = 0
+		sub.DisabledAt = nil
+	}
+
+	if err := s.webhooks.UpdateSubscription(ctx, sub); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	return sub, nil
+}
+
+// DeleteSubscription удаляет подписку вместе с её доставками.
+func (s *WebhookService) DeleteSubscription(ctx context.Context, id int64) error {
+	err := s.webhooks.DeleteSubscription(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrWebhookNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// ListDeliveries возвращает последние доставки подписки, новые первыми.
+func (s *WebhookService) ListDeliveries(ctx context.Context, id int64) ([]domain.WebhookDelivery, error) {
+	if _, err := s.GetSubscription(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.webhooks.ListDeliveries(ctx, id, maxDeliveriesListed)
+}
+
+func (s *WebhookService) applyInput(ctx context.Context, sub *domain.WebhookSubscription, in WebhookInput) error {
+	if in.URL != nil {
+		u, err := url.Parse(*in.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+			return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidWebhook)
+		}
+		if !s.allowPrivateTargets {
+			if err := s.checkPublicHost(ctx, u.Hostname()); err != nil {
+				return err
+			}
+		}
+		sub.URL = *in.URL
+	}
+
+	if in.EventTypes != nil {
+		if len(in.EventTypes) == 0 {
+			return fmt.Errorf("%w: event_types must not be empty", ErrInvalidWebhook)
+		}
+		for _, t := range in.EventTypes {
+			if !slices.Contains(domain.EventTypes, t) {
+				return fmt.Errorf("%w: unknown event type %q", ErrInvalidWebhook, t)
+			}
+		}
+		sub.EventTypes = slices.Compact(slices.Sorted(slices.Values(in.EventTypes)))
+	}
+
+	if in.Secret != nil {
+		sub.Secret = *in.Secret
+	}
+	if in.Active != nil {
+		sub.Active = *in.Active
+	}
+	return nil
+}
+
+// checkPublicHost отклоняет хост подписки, который указывает во внутреннюю сеть:
+// loopback, link-local, частные и специальные адреса. Имя проверяется по всем
+// адресам, в которые оно разрешается.
+func (s *WebhookService) checkPublicHost(ctx context.Context, host string) error {
+	lower := strings.ToLower(strings.TrimSuffix(host, "."))
+	if lower == "localhost" || strings.HasSuffix(lower, ".localhost") {
+		return fmt.Errorf("%w: url must not point to a loopback host", ErrInvalidWebhook)
+	}
+
+	ips := []net.IP{net.ParseIP(host)}
+	if ips[0] == nil {
+		var err error
+		ips, err = s.lookupIP(ctx, "ip", host)
+		if err != nil || len(ips) == 0 {
+			return fmt.Errorf("%w: url host %q does not resolve", ErrInvalidWebhook, host)
+		}
+	}
+
+	for _, ip := range ips {
+		if !webhook.IsPublicIP(ip) {
+			return fmt.Errorf("%w: url must not point to a private, loopback or link-local address", ErrInvalidWebhook)
+		}
+	}
+	return nil
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
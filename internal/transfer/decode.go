@@ -0,0 +1,432 @@
+package transfer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"question-service/internal/domain"
+)
+
+const (
+	// maxLineSize — предел длины строки NDJSON.
+	maxLineSize = 16 << 20
+
+	maxExternalIDLength = 255
+	maxUserIDLength     = 64
+)
+
+// record — вопрос из входных данных. line — строка, с которой начинается запись,
+// а если в записи ошибка — строка с ошибкой.
+type record struct {
+	line     int
+	question domain.Question
+	// answerIDs — id ответов в выгрузке, по порядку question.Answers; 0 — id не указан.
+	answerIDs []int
+	// parents — позиция родительского ответа в question.Answers для каждого ответа;
+	// -1 у ответов верхнего уровня.
+	parents []int
+	// keepStatus — статус в записи не указан: новый вопрос создаётся открытым,
+	// а у сохранённого статус не меняется.
+	keepStatus bool
+	err        error
+}
+
+// recordReader читает записи по одной. next возвращает io.EOF в конце данных;
+// другая ошибка означает, что продолжать разбор нельзя.
+type recordReader interface {
+	next() (*record, error)
+}
+
+func newRecordReader(r io.Reader, format Format) (recordReader, error) {
+	switch format {
+	case FormatNDJSON:
+		sc := bufio.NewScanner(r)
+		sc.Buffer(make([]byte, 64*1024), maxLineSize)
+		return &ndjsonReader{sc: sc}, nil
+	case FormatJSON:
+		lines := &lineCounter{r: r}
+		return &jsonReader{dec: json.NewDecoder(lines), lines: lines}, nil
+	case FormatCSV:
+		return newCSVReader(r)
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+type ndjsonReader struct {
+	sc   *bufio.Scanner
+	line int
+}
+
+func (r *ndjsonReader) next() (*record, error) {
+	for r.sc.Scan() {
+		r.line++
+		data := bytes.TrimSpace(r.sc.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		rec := &record{line: r.line}
+		rec.err = decodeQuestion(data, rec)
+		return rec, nil
+	}
+	if err := r.sc.Err(); err != nil {
+		return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidInput, r.line+1, err)
+	}
+	return nil, io.EOF
+}
+
+type jsonReader struct {
+	dec     *json.Decoder
+	lines   *lineCounter
+	started bool
+}
+
+func (r *jsonReader) next() (*record, error) {
+	if !r.started {
+		tok, err := r.dec.Token()
+		if d, ok := tok.(json.Delim); err != nil || !ok || d != '[' {
+			return nil, fmt.Errorf("%w: expected a JSON array of questions", ErrInvalidInput)
+		}
+		r.started = true
+	}
+
+	if !r.dec.More() {
+		if _, err := r.dec.Token(); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidInput, r.lines.lineAt(r.dec.InputOffset()), err)
+		}
+		if _, err := r.dec.Token(); !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: unexpected data after the array", ErrInvalidInput)
+		}
+		return nil, io.EOF
+	}
+
+	// элемент читается целиком, чтобы ошибка в нём не мешала разбирать следующие
+	var raw json.RawMessage
+	if err := r.dec.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidInput, r.lines.lineAt(r.dec.InputOffset()), err)
+	}
+	rec := &record{line: r.lines.lineAt(r.dec.InputOffset() - int64(len(raw)))}
+	rec.err = decodeQuestion(raw, rec)
+	return rec, nil
+}
+
+// lineCounter считает переводы строк в прочитанных данных, чтобы по смещению
+// находить номер строки. Хранит только ещё не пройденные переводы строк.
+type lineCounter struct {
+	r        io.Reader
+	read     int64
+	newlines []int64
+	line     int
+}
+
+func (c *lineCounter) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	for i, b := range p[:n] {
+		if b == '\n' {
+			c.newlines = append(c.newlines, c.read+int64(i))
+		}
+	}
+	c.read += int64(n)
+	return n, err
+}
+
+// lineAt возвращает номер строки (с 1) байта со смещением off. Смещения в
+// последовательных вызовах не должны убывать.
+func (c *lineCounter) lineAt(off int64) int {
+	for len(c.newlines) > 0 && c.newlines[0] < off {
+		c.newlines = c.newlines[1:]
+		c.line++
+	}
+	return c.line + 1
+}
+
+// decodeQuestion разбирает вопрос в формате выгрузки. Поля version, question_id
+// и comments допускаются, но не используются; id ответов запоминаются в rec.answerIDs.
+func decodeQuestion(data []byte, rec *record) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&rec.question); err != nil {
+		return fmt.Errorf("invalid question: %v", err)
+	}
+	if dec.More() {
+		return errors.New("unexpected data after the question")
+	}
+	return normalize(rec)
+}
+
+// normalize проверяет вопрос и сбрасывает поля, которые назначает база.
+// merged_into_id указывает на id исходной базы и тоже сбрасывается. id ответов
+// переносятся в rec.answerIDs: по ним загрузка находит уже сохранённые ответы.
+// parent_answer_id указывает на id исходной базы, поэтому он заменяется позицией
+// родителя в rec.parents. Родитель должен быть ответом того же вопроса и идти в
+// записи раньше реплики — так ответы идут в выгрузке.
+func normalize(rec *record) error {
+	q := &rec.question
+	if q.ExternalID != nil && (*q.ExternalID == "" || len(*q.ExternalID) > maxExternalIDLength) {
+		return fmt.Errorf("external_id must be 1 to %d characters", maxExternalIDLength)
+	}
+	if strings.TrimSpace(q.Text) == "" {
+		return errors.New("text is required")
+	}
+	if err := normalizeStatus(rec); err != nil {
+		return err
+	}
+
+	q.ID, q.Version, q.Comments, q.MergedIntoID = 0, 1, nil, nil
+	q.CreatedAt = q.CreatedAt.UTC()
+	rec.answerIDs = make([]int, len(q.Answers))
+	rec.parents = make([]int, len(q.Answers))
+	positions := make(map[int]int, len(q.Answers))
+	for i := range q.Answers {
+		a := &q.Answers[i]
+		if a.UserID == "" || len(a.UserID) > maxUserIDLength {
+			return fmt.Errorf("answer %d: user_id must be 1 to %d characters", i+1, maxUserIDLength)
+		}
+		if strings.TrimSpace(a.Text) == "" {
+			return fmt.Errorf("answer %d: text is required", i+1)
+		}
+		if a.ID != 0 {
+			if _, dup := positions[a.ID]; dup {
+				return fmt.Errorf("answer %d: duplicate id %d", i+1, a.ID)
+			}
+			positions[a.ID] = i
+		}
+		rec.parents[i] = -1
+		if a.ParentAnswerID != nil {
+			p, ok := positions[*a.ParentAnswerID]
+			if !ok {
+				return fmt.Errorf("answer %d: parent_answer_id %d is not an earlier answer of this question", i+1, *a.ParentAnswerID)
+			}
+			rec.parents[i] = p
+		}
+		rec.answerIDs[i] = a.ID
+		a.ID, a.QuestionID, a.Version, a.Comments = 0, 0, 1, nil
+		a.ParentAnswerID, a.Depth = nil, 0
+		a.CreatedAt = a.CreatedAt.UTC()
+	}
+	return nil
+}
+
+// normalizeStatus проверяет статус вопроса и сведения о закрытии и блокировке.
+// Сведения, которых при таком статусе быть не может, сбрасываются. Заблокированный
+// вопрос считается закрытым до блокировки, если указан closed_at. Пустые closed_at
+// и locked_at заполняет загрузка.
+func normalizeStatus(rec *record) error {
+	q := &rec.question
+	if q.Status == "" {
+		rec.keepStatus = true
+		q.Status = domain.QuestionOpen
+	}
+	if !slices.Contains(domain.QuestionStatuses, q.Status) {
+		return fmt.Errorf("unknown status %q", q.Status)
+	}
+
+	if q.Status == domain.QuestionClosed || (q.Status == domain.QuestionLocked && q.ClosedAt != nil) {
+		if !slices.Contains(domain.CloseReasons, q.CloseReason) {
+			return fmt.Errorf("unknown close_reason %q", q.CloseReason)
+		}
+		if len(q.ClosedBy) > maxUserIDLength {
+			return fmt.Errorf("closed_by must be at most %d characters", maxUserIDLength)
+		}
+		q.ClosedAt = utcTime(q.ClosedAt)
+	} else {
+		q.CloseReason, q.ClosedAt, q.ClosedBy = "", nil, ""
+	}
+
+	if q.Status == domain.QuestionLocked {
+		if len(q.LockedBy) > maxUserIDLength {
+			return fmt.Errorf("locked_by must be at most %d characters", maxUserIDLength)
+		}
+		q.LockedAt = utcTime(q.LockedAt)
+	} else {
+		q.LockedAt, q.LockedBy = nil, ""
+	}
+	return nil
+}
+
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
+
+// csvReader собирает вопрос из идущих подряд строк с одинаковыми полями вопроса.
+type csvReader struct {
+	r       *csv.Reader
+	cols    map[string]int
+	pending *csvRow
+}
+
+type csvRow struct {
+	line   int
+	fields []string
+	err    error
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: missing CSV header", ErrInvalidInput)
+		}
+		return nil, fmt.Errorf("%w: CSV header: %v", ErrInvalidInput, err)
+	}
+
+	cols := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		if !slices.Contains(csvHeader, name) {
+			return nil, fmt.Errorf("%w: unknown CSV column %q", ErrInvalidInput, name)
+		}
+		if _, dup := cols[name]; dup {
+			return nil, fmt.Errorf("%w: duplicate CSV column %q", ErrInvalidInput, name)
+		}
+		cols[name] = i
+	}
+	if _, ok := cols["question_text"]; !ok {
+		return nil, fmt.Errorf("%w: CSV column question_text is required", ErrInvalidInput)
+	}
+	return &csvReader{r: cr, cols: cols}, nil
+}
+
+func (r *csvReader) row() (*csvRow, error) {
+	if row := r.pending; row != nil {
+		r.pending = nil
+		return row, nil
+	}
+
+	fields, err := r.r.Read()
+	line, _ := r.r.FieldPos(0)
+	var perr *csv.ParseError
+	switch {
+	case err == nil:
+		return &csvRow{line: line, fields: fields}, nil
+	case errors.Is(err, io.EOF):
+		return nil, io.EOF
+	case errors.As(err, &perr):
+		// ошибка относится к одной строке, разбор продолжается со следующей
+		return &csvRow{line: perr.StartLine, err: perr.Err}, nil
+	}
+	return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+}
+
+func (r *csvReader) get(fields []string, col string) string {
+	if i, ok := r.cols[col]; ok {
+		return fields[i]
+	}
+	return ""
+}
+
+// questionKey — поля вопроса, по которым строки объединяются в один вопрос.
+func (r *csvReader) questionKey(fields []string) string {
+	return strings.Join([]string{
+		r.get(fields, "question_id"),
+		r.get(fields, "external_id"),
+		r.get(fields, "question_text"),
+		r.get(fields, "question_created_at"),
+	}, "\x00")
+}
+
+func (r *csvReader) next() (*record, error) {
+	first, err := r.row()
+	if err != nil {
+		return nil, err
+	}
+	rec := &record{line: first.line}
+	if first.err != nil {
+		rec.err = first.err
+		return rec, nil
+	}
+
+	q := &rec.question
+	q.Text = r.get(first.fields, "question_text")
+	if ext := r.get(first.fields, "external_id"); ext != "" {
+		q.ExternalID = &ext
+	}
+	q.CreatedAt, rec.err = parseTime(r.get(first.fields, "question_created_at"), "question_created_at")
+	if rec.err == nil {
+		rec.err = r.addAnswer(q, first.fields)
+	}
+
+	key := r.questionKey(first.fields)
+	for {
+		row, err := r.row()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		// строку, которую не удалось разобрать, нельзя отнести к вопросу: она
+		// сообщается отдельной записью
+		if row.err != nil || r.questionKey(row.fields) != key {
+			r.pending = row
+			break
+		}
+		if rec.err != nil {
+			continue
+		}
+
+		if rec.err = r.addAnswer(q, row.fields); rec.err != nil {
+			rec.line = row.line
+		}
+	}
+
+	if rec.err == nil {
+		rec.err = normalize(rec)
+	}
+	return rec, nil
+}
+
+// addAnswer добавляет ответ из строки; строка без полей ответа — вопрос без ответов.
+func (r *csvReader) addAnswer(q *domain.Question, fields []string) error {
+	userID := r.get(fields, "answer_user_id")
+	text := r.get(fields, "answer_text")
+	created := r.get(fields, "answer_created_at")
+	if userID == "" && text == "" && created == "" {
+		return nil
+	}
+
+	createdAt, err := parseTime(created, "answer_created_at")
+	if err != nil {
+		return err
+	}
+	var id int
+	if v := r.get(fields, "answer_id"); v != "" {
+		if id, err = strconv.Atoi(v); err != nil || id < 0 {
+			return errors.New("answer_id must be a non-negative integer")
+		}
+	}
+	var parent *int
+	if v := r.get(fields, "answer_parent_id"); v != "" {
+		p, err := strconv.Atoi(v)
+		if err != nil || p <= 0 {
+			return errors.New("answer_parent_id must be a positive integer")
+		}
+		parent = &p
+	}
+	q.Answers = append(q.Answers, domain.Answer{ID: id, ParentAnswerID: parent, UserID: userID, Text: text, CreatedAt: createdAt})
+	return nil
+}
+
+func parseTime(s, field string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s must be an RFC 3339 time", field)
+	}
+	return t, nil
+}
@@ -0,0 +1,182 @@
+package transfer
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"question-service/internal/domain"
+)
+
+// csvHeader — колонки CSV. При загрузке обязательна только question_text,
+// порядок колонок любой.
+var csvHeader = []string{
+	"question_id",
+	"external_id",
+	"question_text",
+	"question_created_at",
+	"answer_id",
+	"answer_parent_id",
+	"answer_user_id",
+	"answer_text",
+	"answer_created_at",
+}
+
+// Export пишет в w все вопросы с ответами по возрастанию id и возвращает число
+// выгруженных вопросов. Вопросы читаются страницами, ответы — одним запросом на страницу.
+// Скрытые модератором вопросы и ответы, как и реплики на скрытые ответы, не выгружаются;
+// заглушки объединённых вопросов тоже: их ответы выгружаются с целевым вопросом.
+func (s *Service) Export(ctx context.Context, w io.Writer, format Format) (int, error) {
+	out := newRecordWriter(w, format)
+
+	count, after := 0, 0
+	for {
+		page, err := s.questions.ListPage(ctx, after, s.batchSize)
+		if err != nil {
+			return count, fmt.Errorf("list questions: %w", err)
+		}
+		if len(page) == 0 {
+			break
+		}
+
+		ids := make([]int, len(page))
+		for i, q := range page {
+			ids[i] = q.ID
+		}
+		answers, err := s.answers.ListByQuestionIDs(ctx, ids, 0)
+		if err != nil {
+			return count, fmt.Errorf("list answers: %w", err)
+		}
+		byQuestion := make(map[int][]domain.Answer, len(page))
+		for _, a := range answers {
+			byQuestion[a.QuestionID] = append(byQuestion[a.QuestionID], a)
+		}
+
+		for i := range page {
+			page[i].Answers = byQuestion[page[i].ID]
+			if err := out.write(&page[i]); err != nil {
+				return count, err
+			}
+			count++
+		}
+
+		if len(page) < s.batchSize {
+			break
+		}
+		after = page[len(page)-1].ID
+	}
+	return count, out.close()
+}
+
+// recordWriter пишет вопросы в одном из форматов.
+type recordWriter interface {
+	write(q *domain.Question) error
+	// close дописывает окончание документа.
+	close() error
+}
+
+func newRecordWriter(w io.Writer, format Format) recordWriter {
+	switch format {
+	case FormatCSV:
+		return &csvWriter{w: csv.NewWriter(w)}
+	case FormatJSON:
+		return &jsonWriter{w: w}
+	default:
+		return &ndjsonWriter{enc: json.NewEncoder(w)}
+	}
+}
+
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func (w *ndjsonWriter) write(q *domain.Question) error { return w.enc.Encode(q) }
+func (w *ndjsonWriter) close() error                   { return nil }
+
+type jsonWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (w *jsonWriter) write(q *domain.Question) error {
+	data, err := json.Marshal(q)
+	if err != nil {
+		return err
+	}
+	sep := ",\n"
+	if w.count == 0 {
+		sep = "[\n"
+	}
+	w.count++
+	if _, err := io.WriteString(w.w, sep); err != nil {
+		return err
+	}
+	_, err = w.w.Write(data)
+	return err
+}
+
+func (w *jsonWriter) close() error {
+	end := "\n]\n"
+	if w.count == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(w.w, end)
+	return err
+}
+
+type csvWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (w *csvWriter) write(q *domain.Question) error {
+	if err := w.header(); err != nil {
+		return err
+	}
+
+	externalID := ""
+	if q.ExternalID != nil {
+		externalID = *q.ExternalID
+	}
+	question := []string{strconv.Itoa(q.ID), externalID, q.Text, formatTime(q.CreatedAt)}
+
+	if len(q.Answers) == 0 {
+		return w.w.Write(append(question, "", "", "", "", ""))
+	}
+	for _, a := range q.Answers {
+		parent := ""
+		if a.ParentAnswerID != nil {
+			parent = strconv.Itoa(*a.ParentAnswerID)
+		}
+		row := append(question[:len(question):len(question)],
+			strconv.Itoa(a.ID), parent, a.UserID, a.Text, formatTime(a.CreatedAt))
+		if err := w.w.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *csvWriter) header() error {
+	if w.headerWritten {
+		return nil
+	}
+	w.headerWritten = true
+	return w.w.Write(csvHeader)
+}
+
+func (w *csvWriter) close() error {
+	if err := w.header(); err != nil {
+		return err
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,439 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+
+	"question-service/internal/domain"
+)
+
+// maxReportedErrors — сколько ошибок в записях попадает в отчёт; остальные только считаются.
+const maxReportedErrors = 100
+
+// errDryRun откатывает транзакцию пробной загрузки.
+var errDryRun = errors.New("dry run")
+
+// ImportOptions — параметры загрузки.
+type ImportOptions struct {
+	// DryRun проверяет данные и считает изменения, но не сохраняет их.
+	DryRun bool
+}
+
+// ImportReport — итог загрузки.
+type ImportReport struct {
+	DryRun bool `json:"dry_run"`
+	// Created, Updated и Unchanged — число вопросов: новых, изменённых по
+	// external_id и совпавших с уже сохранёнными.
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	// Answers — сколько ответов добавлено или изменено.
+	Answers int `json:"answers"`
+	// Failed — сколько записей пропущено из-за ошибок.
+	Failed int         `json:"failed"`
+	Errors []LineError `json:"errors"`
+}
+
+// LineError — ошибка в записи. Line — номер строки входных данных; для формата
+// JSON это строка, с которой начинается элемент массива.
+type LineError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+func (r *ImportReport) fail(line int, err error) {
+	r.Failed++
+	if len(r.Errors) < maxReportedErrors {
+		r.Errors = append(r.Errors, LineError{Line: line, Error: err.Error()})
+	}
+}
+
+// Import загружает вопросы с ответами из r. Вопрос с external_id, который уже
+// есть в базе, обновляется: его текст и указанный в записи статус заменяются
+// загруженными, а ответы сравниваются с сохранёнными (см. diffAnswers), так что
+// неизменные ответы остаются как были. Если ничего не отличается, вопрос не меняется. Запись с
+// external_id вопроса из корзины считается ошибкой. Записи с ошибками пропускаются и
+// перечисляются в отчёте. Все изменения выполняются в одной транзакции; если
+// данные нельзя разобрать дальше, возвращается ошибка с ErrInvalidInput и
+// ничего не сохраняется.
+func (s *Service) Import(ctx context.Context, r io.Reader, format Format, opts ImportOptions) (*ImportReport, error) {
+	in, err := newRecordReader(r, format)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{DryRun: opts.DryRun, Errors: []LineError{}}
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var batch []*record
+		inBatch := make(map[string]bool)
+		flush := func() error {
+			if err := s.importBatch(ctx, batch, report); err != nil {
+				return err
+			}
+			batch = batch[:0]
+			clear(inBatch)
+			return nil
+		}
+
+		for {
+			rec, err := in.next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			if rec.err != nil {
+				report.fail(rec.line, rec.err)
+				continue
+			}
+
+			// повтор external_id обновляет вопрос, записанный предыдущей пачкой
+			if ext := rec.question.ExternalID; ext != nil {
+				if inBatch[*ext] {
+					if err := flush(); err != nil {
+						return err
+					}
+				}
+				inBatch[*ext] = true
+			}
+
+			batch = append(batch, rec)
+			if len(batch) == s.batchSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+
+		if opts.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+	return report, nil
+}
+
+// importBatch записывает пачку проверенных вопросов. Ответы добавляются после
+// вопросов, а сохранённые ответы переносятся в корзину в последнюю очередь, чтобы
+// оставшиеся реплики успели перейти к новым родителям и не ушли в корзину вместе со старыми.
+func (s *Service) importBatch(ctx context.Context, batch []*record, report *ImportReport) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	existing, err := s.existingByExternalID(ctx, batch)
+	if err != nil {
+		return err
+	}
+
+	var created []domain.Question
+	var createdRecs []*record
+	var pending []pendingAnswers
+	var update []domain.Answer
+	var remove []int
+	for _, rec := range batch {
+		q := &rec.question
+
+		var old *domain.Question
+		if q.ExternalID != nil {
+			old = existing[*q.ExternalID]
+		}
+		if old == nil {
+			fillStatusTimes(q, nil)
+			created = append(created, *q)
+			createdRecs = append(createdRecs, rec)
+			continue
+		}
+		if old.DeletedAt.Valid {
+			// external_id занят удалённым вопросом, пока его не восстановят или не очистят
+			report.fail(rec.line, fmt.Errorf("question with external_id %q is in trash, restore or purge it first", *q.ExternalID))
+			continue
+		}
+
+		diff := diffAnswers(old.Answers, rec)
+		statusChanged := !rec.keepStatus && !sameStatus(old, q)
+		if old.Text == q.Text && sameTime(old.CreatedAt, q.CreatedAt) && !statusChanged && diff.empty() {
+			report.Unchanged++
+			continue
+		}
+		q.ID = old.ID
+		if q.CreatedAt.IsZero() {
+			q.CreatedAt = old.CreatedAt
+		}
+		if err := s.questions.Update(ctx, q); err != nil {
+			return fmt.Errorf("update question %d: %w", q.ID, err)
+		}
+		if statusChanged {
+			fillStatusTimes(q, old)
+			if err := s.questions.SetStatus(ctx, q); err != nil {
+				return fmt.Errorf("update question %d status: %w", q.ID, err)
+			}
+		}
+		pending = append(pending, pendingAnswers{rec: rec, questionID: q.ID, ids: diff.ids})
+		update = append(update, diff.update...)
+		remove = append(remove, diff.remove...)
+		report.Updated++
+	}
+
+	if err := s.questions.CreateBatch(ctx, created, s.batchSize); err != nil {
+		return fmt.Errorf("insert questions: %w", err)
+	}
+	for i, rec := range createdRecs {
+		ids := make([]int, len(rec.question.Answers))
+		pending = append(pending, pendingAnswers{rec: rec, questionID: created[i].ID, ids: ids})
+	}
+	inserted, err := s.insertAnswers(ctx, pending)
+	if err != nil {
+		return err
+	}
+
+	for i := range update {
+		if err := s.answers.Update(ctx, &update[i]); err != nil {
+			return fmt.Errorf("update answer %d: %w", update[i].ID, err)
+		}
+	}
+	for _, id := range remove {
+		// реплика уже могла уйти в корзину вместе с родительским ответом
+		if err := s.answers.Delete(ctx, id, 0); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("delete answer %d: %w", id, err)
+		}
+	}
+
+	report.Created += len(created)
+	report.Answers += inserted + len(update)
+	return nil
+}
+
+// pendingAnswers — ответы загружаемого вопроса, которые ещё надо добавить.
+type pendingAnswers struct {
+	rec        *record
+	questionID int
+	// ids — id ответов в базе по порядку rec.question.Answers; 0 — ответ ещё не добавлен.
+	ids []int
+}
+
+// insertAnswers добавляет недостающие ответы вопросов волнами: в каждой волне —
+// ответы, родители которых уже сохранены, поэтому реплика получает id больше, чем
+// у родителя. Возвращает число добавленных ответов.
+func (s *Service) insertAnswers(ctx context.Context, pending []pendingAnswers) (int, error) {
+	type slot struct {
+		ids []int
+		i   int
+	}
+
+	total := 0
+	for {
+		var wave []domain.Answer
+		var slots []slot
+		for _, p := range pending {
+			for i, a := range p.rec.question.Answers {
+				if p.ids[i] != 0 {
+					continue
+				}
+				parent := p.rec.parents[i]
+				if parent >= 0 {
+					if p.ids[parent] == 0 {
+						continue
+					}
+					id := p.ids[parent]
+					a.ParentAnswerID = &id
+				}
+				a.QuestionID = p.questionID
+				wave = append(wave, a)
+				slots = append(slots, slot{ids: p.ids, i: i})
+			}
+		}
+		if len(wave) == 0 {
+			return total, nil
+		}
+
+		if err := s.answers.CreateBatch(ctx, wave, s.batchSize); err != nil {
+			return total, fmt.Errorf("insert answers: %w", err)
+		}
+		for k, sl := range slots {
+			sl.ids[sl.i] = wave[k].ID
+		}
+		total += len(wave)
+	}
+}
+
+// existingByExternalID находит сохранённые вопросы пачки вместе с ответами,
+// в том числе вопросы из корзины.
+func (s *Service) existingByExternalID(ctx context.Context, batch []*record) (map[string]*domain.Question, error) {
+	var externalIDs []string
+	for _, rec := range batch {
+		if ext := rec.question.ExternalID; ext != nil {
+			externalIDs = append(externalIDs, *ext)
+		}
+	}
+	if len(externalIDs) == 0 {
+		return nil, nil
+	}
+
+	found, err := s.questions.FindByExternalIDs(ctx, externalIDs)
+	if err != nil {
+		return nil, fmt.Errorf("find questions by external id: %w", err)
+	}
+	if len(found) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]int, len(found))
+	for i, q := range found {
+		ids[i] = q.ID
+	}
+	answers, err := s.answers.ListByQuestionIDs(ctx, ids, 0)
+	if err != nil {
+		return nil, fmt.Errorf("list answers: %w", err)
+	}
+
+	byID := make(map[int]*domain.Question, len(found))
+	out := make(map[string]*domain.Question, len(found))
+	for i := range found {
+		q := &found[i]
+		byID[q.ID] = q
+		out[*q.ExternalID] = q
+	}
+	for _, a := range answers {
+		q := byID[a.QuestionID]
+		q.Answers = append(q.Answers, a)
+	}
+	return out, nil
+}
+
+// answerDiff — изменения ответов сохранённого вопроса при загрузке.
+type answerDiff struct {
+	// ids — id совпавших сохранённых ответов по порядку загружаемых; 0 — ответ добавляется.
+	ids    []int
+	update []domain.Answer
+	remove []int
+}
+
+func (d answerDiff) empty() bool {
+	return len(d.update) == 0 && len(d.remove) == 0 && !slices.Contains(d.ids, 0)
+}
+
+// diffAnswers сопоставляет загружаемые ответы вопроса с сохранёнными. Ответ с id
+// из выгрузки совпадает с сохранённым ответом с тем же id, ответ без id — с
+// сохранённым ответом на той же позиции, если тот ещё не занят. Реплика совпадает,
+// только если совпал её родитель и у него меньший id: так у реплики id всегда
+// больше, чем у родителя. Совпавшие ответы обновляются на месте, поэтому сохраняют
+// id, версию, комментарии, реплики и жалобы; остальные загружаемые ответы
+// добавляются, а несовпавшие сохранённые переносятся в корзину. Пустое время
+// создания в загружаемых данных не сравнивается.
+func diffAnswers(old []domain.Answer, rec *record) answerDiff {
+	byID := make(map[int]int, len(old))
+	for j, a := range old {
+		byID[a.ID] = j
+	}
+
+	imported := rec.question.Answers
+	matched := make([]int, len(imported))
+	claimed := make([]bool, len(old))
+	for i := range imported {
+		matched[i] = -1
+		if j, ok := byID[rec.answerIDs[i]]; ok && !claimed[j] {
+			matched[i], claimed[j] = j, true
+		}
+	}
+	for i := range imported {
+		if rec.answerIDs[i] == 0 && i < len(old) && !claimed[i] {
+			matched[i], claimed[i] = i, true
+		}
+	}
+	// родитель идёт раньше реплики, поэтому его сопоставление уже окончательное
+	for i, p := range rec.parents {
+		j := matched[i]
+		if j >= 0 && p >= 0 && (matched[p] < 0 || old[matched[p]].ID > old[j].ID) {
+			matched[i], claimed[j] = -1, false
+		}
+	}
+
+	d := answerDiff{ids: make([]int, len(imported))}
+	for i, a := range imported {
+		j := matched[i]
+		if j < 0 {
+			continue
+		}
+		o := old[j]
+		d.ids[i] = o.ID
+
+		var parent *int
+		if p := rec.parents[i]; p >= 0 {
+			parent = &old[matched[p]].ID
+		}
+		if o.UserID == a.UserID && o.Text == a.Text && sameTime(o.CreatedAt, a.CreatedAt) && sameParent(o.ParentAnswerID, parent) {
+			continue
+		}
+		o.UserID, o.Text, o.ParentAnswerID = a.UserID, a.Text, parent
+		if !a.CreatedAt.IsZero() {
+			o.CreatedAt = a.CreatedAt
+		}
+		d.update = append(d.update, o)
+	}
+	for j, ok := range claimed {
+		if !ok {
+			d.remove = append(d.remove, old[j].ID)
+		}
+	}
+	return d
+}
+
+func sameParent(old, p *int) bool {
+	if old == nil || p == nil {
+		return old == p
+	}
+	return *old == *p
+}
+
+func sameTime(old, t time.Time) bool {
+	return t.IsZero() || old.Equal(t)
+}
+
+// sameStatus сообщает, совпадают ли статус и сведения о закрытии и блокировке
+// сохранённого вопроса old с загружаемыми. Пустое время закрытия или блокировки
+// в загружаемых данных не сравнивается.
+func sameStatus(old, q *domain.Question) bool {
+	return old.Status == q.Status &&
+		old.CloseReason == q.CloseReason && old.ClosedBy == q.ClosedBy && sameTimePtr(old.ClosedAt, q.ClosedAt) &&
+		old.LockedBy == q.LockedBy && sameTimePtr(old.LockedAt, q.LockedAt)
+}
+
+func sameTimePtr(old, t *time.Time) bool {
+	return t == nil || (old != nil && old.Equal(*t))
+}
+
+// fillStatusTimes заполняет пустые время закрытия закрытого и время блокировки
+// заблокированного вопроса: временем из сохранённого вопроса old, если оно там
+// есть, иначе текущим.
+func fillStatusTimes(q, old *domain.Question) {
+	now := time.Now().UTC()
+	if q.Status == domain.QuestionClosed && q.ClosedAt == nil {
+		q.ClosedAt = &now
+		if old != nil && old.ClosedAt != nil {
+			q.ClosedAt = old.ClosedAt
+		}
+	}
+	if q.Status == domain.QuestionLocked && q.LockedAt == nil {
+		q.LockedAt = &now
+		if old != nil && old.LockedAt != nil {
+			q.LockedAt = old.LockedAt
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// Package transfer выгружает вопросы с ответами и загружает их обратно в форматах
+// NDJSON, CSV и JSON. Данные читаются и пишутся потоково, страницами через
+// репозитории, а не целиком в память.
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"path/filepath"
+	"strings"
+
+	"question-service/internal/repository"
+	"question-service/internal/service"
+)
+
+// Format — формат выгрузки и загрузки.
+type Format string
+
+const (
+	// FormatNDJSON — по вопросу с вложенными ответами на строку.
+	FormatNDJSON Format = "ndjson"
+	// FormatCSV — по строке на ответ; вопрос без ответов занимает одну строку с пустыми полями ответа.
+	FormatCSV Format = "csv"
+	// FormatJSON — один JSON-массив вопросов.
+	FormatJSON Format = "json"
+)
+
+// ErrInvalidInput — входные данные нельзя разобрать, загрузка прервана и не применена.
+var ErrInvalidInput = errors.New("invalid import input")
+
+// ParseFormat разбирает название формата.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatNDJSON, FormatCSV, FormatJSON:
+		return f, nil
+	case "jsonl":
+		return FormatNDJSON, nil
+	}
+	return "", fmt.Errorf("unknown format %q: want ndjson, csv or json", s)
+}
+
+// FormatFromContentType определяет формат по заголовку Content-Type.
+func FormatFromContentType(contentType string) (Format, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", false
+	}
+	switch mediaType {
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return FormatNDJSON, true
+	case "text/csv":
+		return FormatCSV, true
+	case "application/json":
+		return FormatJSON, true
+	}
+	return "", false
+}
+
+// FormatFromPath определяет формат по расширению файла.
+func FormatFromPath(path string) (Format, bool) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON, true
+	case ".csv":
+		return FormatCSV, true
+	case ".json":
+		return FormatJSON, true
+	}
+	return "", false
+}
+
+// ContentType возвращает MIME-тип формата.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatJSON:
+		return "application/json"
+	default:
+		return "application/x-ndjson"
+	}
+}
+
+// Ext возвращает расширение файла для формата.
+func (f Format) Ext() string {
+	return "." + string(f)
+}
+
+// Service выгружает и загружает вопросы с ответами. Загрузка не публикует
+// события: это массовая операция, подписчики о ней не уведомляются.
+type Service struct {
+	questions repository.QuestionRepository
+	answers   repository.AnswerRepository
+	tx        service.TxManager
+
+	// batchSize — сколько вопросов читается и записывается за раз.
+	batchSize int
+}
+
+// defaultBatchSize — размер страницы выгрузки и пачки загрузки.
+const defaultBatchSize = 200
+
+func NewService(qRepo repository.QuestionRepository, aRepo repository.AnswerRepository, tx service.TxManager) *Service {
+	return &Service{
+		questions: qRepo,
+		answers:   aRepo,
+		tx:        tx,
+		batchSize: defaultBatchSize,
+	}
+}
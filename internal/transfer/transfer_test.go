@@ -0,0 +1,470 @@
+package transfer_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"question-service/internal/domain"
+	"question-service/internal/repository"
+	"question-service/internal/transfer"
+)
+
+type testStore struct {
+	store     *repository.MemoryStore
+	questions *repository.MemoryQuestionRepository
+	svc       *transfer.Service
+}
+
+func newTestStore() *testStore {
+	store := repository.NewMemoryStore()
+	qRepo := repository.NewMemoryQuestionRepository(store)
+	aRepo := repository.NewMemoryAnswerRepository(store)
+	return &testStore{store: store, questions: qRepo, svc: transfer.NewService(qRepo, aRepo, store)}
+}
+
+func ptr(s string) *string { return &s }
+
+// fill создаёт n вопросов; у чётных есть external_id, у каждого третьего нет ответов.
+func (s *testStore) fill(t *testing.T, n int) {
+	t.Helper()
+	ctx := context.Background()
+	aRepo := repository.NewMemoryAnswerRepository(s.store)
+	base := time.Date(2025, 3, 1, 12, 0, 0, 123456000, time.UTC)
+
+	for i := 1; i <= n; i++ {
+		q := &domain.Question{Text: fmt.Sprintf("Question %d, with \"quotes\"\nand a newline", i), CreatedAt: base.Add(time.Duration(i) * time.Minute)}
+		if i%2 == 0 {
+			q.ExternalID = ptr(fmt.Sprintf("legacy-%d", i))
+		}
+		require.NoError(t, s.questions.Create(ctx, q))
+		if i%3 == 0 {
+			continue
+		}
+		for j := 1; j <= 2; j++ {
+			a := &domain.Answer{QuestionID: q.ID, UserID: fmt.Sprintf("user-%d", j), Text: fmt.Sprintf("Answer %d.%d", i, j), CreatedAt: q.CreatedAt.Add(time.Duration(j) * time.Second)}
+			require.NoError(t, aRepo.Create(ctx, a))
+		}
+	}
+}
+
+func (s *testStore) export(t *testing.T, format transfer.Format) string {
+	t.Helper()
+	var buf bytes.Buffer
+	_, err := s.svc.Export(context.Background(), &buf, format)
+	require.NoError(t, err)
+	return buf.String()
+}
+
+func TestExportImport_RoundTrip(t *testing.T) {
+	for _, format := range []transfer.Format{transfer.FormatNDJSON, transfer.FormatCSV, transfer.FormatJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			src := newTestStore()
+			src.fill(t, 450) // больше одной страницы выгрузки
+
+			var buf bytes.Buffer
+			n, err := src.svc.Export(context.Background(), &buf, format)
+			require.NoError(t, err)
+			require.Equal(t, 450, n)
+			exported := buf.String()
+
+			dst := newTestStore()
+			report, err := dst.svc.Import(context.Background(), strings.NewReader(exported), format, transfer.ImportOptions{})
+			require.NoError(t, err)
+			require.Equal(t, 450, report.Created)
+			require.Equal(t, 600, report.Answers)
+			require.Zero(t, report.Failed)
+			require.Empty(t, report.Errors)
+
+			require.Equal(t, exported, dst.export(t, format))
+
+			// повторная загрузка: вопросы с external_id совпадают, без него — создаются заново
+			report, err = dst.svc.Import(context.Background(), strings.NewReader(exported), format, transfer.ImportOptions{})
+			require.NoError(t, err)
+			require.Equal(t, 225, report.Unchanged)
+			require.Equal(t, 225, report.Created)
+			require.Zero(t, report.Updated)
+		})
+	}
+}
+
+func TestExport_SkipsHidden(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore()
+	s.fill(t, 2)
+	aRepo := repository.NewMemoryAnswerRepository(s.store)
+
+	require.NoError(t, s.questions.SetHidden(ctx, 1, true))
+	answers, err := aRepo.ListByQuestionID(ctx, 2)
+	require.NoError(t, err)
+	require.NoError(t, aRepo.SetHidden(ctx, answers[0].ID, true))
+
+	exported := s.export(t, transfer.FormatNDJSON)
+	require.NotContains(t, exported, "Question 1")
+	require.Contains(t, exported, "Question 2")
+	require.NotContains(t, exported, "Answer 2.1")
+	require.Contains(t, exported, "Answer 2.2")
+}
+
+func TestExport_SkipsMergedStubs(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore()
+	s.fill(t, 2)
+
+	_, err := s.questions.Merge(ctx, 1, 2)
+	require.NoError(t, err)
+
+	exported := s.export(t, transfer.FormatNDJSON)
+	require.NotContains(t, exported, "Question 1")
+	require.Contains(t, exported, "Question 2")
+	require.Contains(t, exported, "Answer 1.1")
+	require.Equal(t, 1, strings.Count(exported, "\n"))
+}
+
+func TestExport_Empty(t *testing.T) {
+	s := newTestStore()
+	require.Equal(t, "", s.export(t, transfer.FormatNDJSON))
+	require.Equal(t, "[]\n", s.export(t, transfer.FormatJSON))
+	require.Equal(t, "question_id,external_id,question_text,question_created_at,answer_id,answer_parent_id,answer_user_id,answer_text,answer_created_at\n", s.export(t, transfer.FormatCSV))
+}
+
+func TestImport_UpsertByExternalID(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore()
+
+	first := `{"external_id": "q-1", "text": "Old", "answers": [{"user_id": "u1", "text": "A1"}, {"user_id": "u2", "text": "A2"}]}`
+	report, err := s.svc.Import(ctx, strings.NewReader(first), transfer.FormatNDJSON, transfer.ImportOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Created)
+
+	all, err := s.questions.List(ctx, "", 0, 0)
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	id := all[0].ID
+
+	// повтор external_id в одном файле: вторая запись обновляет первую
+	second := `{"external_id": "q-1", "text": "New", "answers": [{"user_id": "u3", "text": "A3"}]}
+{"external_id": "q-1", "text": "Newer", "answers": [{"user_id": "u3", "text": "A3"}]}`
+	report, err = s.svc.Import(ctx, strings.NewReader(second), transfer.FormatNDJSON, transfer.ImportOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 2, report.Updated)
+	require.Zero(t, report.Created)
+
+	q, err := s.questions.GetByID(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, "Newer", q.Text)
+	require.Equal(t, 3, q.Version)
+	require.Len(t, q.Answers, 1)
+	require.Equal(t, "u3", q.Answers[0].UserID)
+}
+
+func TestImport_UpsertKeepsMatchedAnswers(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore()
+	aRepo := repository.NewMemoryAnswerRepository(s.store)
+
+	first := `{"external_id": "q-1", "text": "Q", "answers": [{"user_id": "u1", "text": "A1"}, {"user_id": "u2", "text": "A2"}, {"user_id": "u3", "text": "A3"}]}`
+	_, err := s.svc.Import(ctx, strings.NewReader(first), transfer.FormatNDJSON, transfer.ImportOptions{})
+	require.NoError(t, err)
+
+	// ответ 1 не меняется, 2 меняется, 3 пропал из выгрузки, а без id добавлен новый
+	second := `{"external_id": "q-1", "text": "Q", "answers": [{"id": 1, "user_id": "u1", "text": "A1"}, {"id": 2, "user_id": "u2", "text": "A2 edited"}, {"id": 99, "user_id": "u4", "text": "A4"}]}`
+	report, err := s.svc.Import(ctx, strings.NewReader(second), transfer.FormatNDJSON, transfer.ImportOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Updated)
+	require.Equal(t, 2, report.Answers)
+
+	answers, err := aRepo.ListByQuestionID(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, answers, 3)
+	require.Equal(t, 1, answers[0].ID)
+	require.Equal(t, 1, answers[0].Version)
+	require.Equal(t, 2, answers[1].ID)
+	require.Equal(t, "A2 edited", answers[1].Text)
+	require.Equal(t, 2, answers[1].Version)
+	require.Equal(t, "A4", answers[2].Text)
+
+	deleted, err := aRepo.ListDeleted(ctx, 0, 100)
+	require.NoError(t, err)
+	require.Len(t, deleted, 1)
+	require.Equal(t, 3, deleted[0].ID)
+
+	// повторная загрузка того же ничего не меняет
+	report, err = s.svc.Import(ctx, strings.NewReader(s.export(t, transfer.FormatNDJSON)), transfer.FormatNDJSON, transfer.ImportOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Unchanged)
+}
+
+func TestExportImport_KeepsThreads(t *testing.T) {
+	ctx := context.Background()
+
+	for _, format := range []transfer.Format{transfer.FormatNDJSON, transfer.FormatCSV, transfer.FormatJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			src := newTestStore()
+			aRepo := repository.NewMemoryAnswerRepository(src.store)
+			require.NoError(t, src.questions.Create(ctx, &domain.Question{ExternalID: ptr("q-1"), Text: "Q"}))
+			var ids []int
+			for i, parent := range []int{-1, 0, -1, 1} {
+				a := &domain.Answer{QuestionID: 1, UserID: "u1", Text: fmt.Sprintf("A%d", i)}
+				if parent >= 0 {
+					a.ParentAnswerID = &ids[parent]
+				}
+				require.NoError(t, aRepo.Create(ctx, a))
+				ids = append(ids, a.ID)
+			}
+
+			dst := newTestStore()
+			report, err := dst.svc.Import(ctx, strings.NewReader(src.export(t, format)), format, transfer.ImportOptions{})
+			require.NoError(t, err)
+			require.Zero(t, report.Failed, "%+v", report.Errors)
+
+			thread, err := repository.NewMemoryAnswerRepository(dst.store).ListThread(ctx, 1, 5)
+			require.NoError(t, err)
+			var got []string
+			for _, a := range thread {
+				got = append(got, fmt.Sprintf("%s@%d", a.Text, a.Depth))
+			}
+			require.Equal(t, []string{"A0@0", "A1@1", "A3@2", "A2@0"}, got)
+
+			// повторная загрузка выгрузки сохраняет ветки и ничего не меняет
+			report, err = dst.svc.Import(ctx, strings.NewReader(dst.export(t, format)), format, transfer.ImportOptions{})
+			require.NoError(t, err)
+			require.Equal(t, 1, report.Unchanged)
+		})
+	}
+}
+
+func TestImport_UpsertMovesReplies(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore()
+	aRepo := repository.NewMemoryAnswerRepository(s.store)
+
+	first := `{"external_id": "q-1", "text": "Q", "answers": [{"id": 1, "user_id": "u1", "text": "A1"}, {"id": 2, "user_id": "u2", "text": "A2"}, {"id": 3, "parent_answer_id": 2, "user_id": "u3", "text": "A3"}]}`
+	_, err := s.svc.Import(ctx, strings.NewReader(first), transfer.FormatNDJSON, transfer.ImportOptions{})
+	require.NoError(t, err)
+
+	// родитель реплики пропал из выгрузки, а сама она стала репликой на первый ответ
+	second := `{"external_id": "q-1", "text": "Q", "answers": [{"id": 1, "user_id": "u1", "text": "A1"}, {"id": 3, "parent_answer_id": 1, "user_id": "u3", "text": "A3"}]}`
+	report, err := s.svc.Import(ctx, strings.NewReader(second), transfer.FormatNDJSON, transfer.ImportOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Updated)
+	require.Equal(t, 1, report.Answers)
+
+	thread, err := aRepo.ListThread(ctx, 1, 5)
+	require.NoError(t, err)
+	require.Len(t, thread, 2)
+	require.Equal(t, 1, thread[0].ID)
+	require.Equal(t, 3, thread[1].ID)
+	require.Equal(t, 1, *thread[1].ParentAnswerID)
+
+	deleted, err := aRepo.ListDeleted(ctx, 0, 100)
+	require.NoError(t, err)
+	require.Len(t, deleted, 1)
+	require.Equal(t, 2, deleted[0].ID)
+}
+
+func TestImport_Status(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore()
+
+	input := `{"external_id": "q-1", "text": "Closed", "status": "closed", "close_reason": "resolved", "closed_by": "u1", "merged_into_id": 42, "locked_by": "mod"}
+{"external_id": "q-2", "text": "Unknown status", "status": "archived"}
+{"external_id": "q-3", "text": "Unknown reason", "status": "closed", "close_reason": "spam"}
+{"external_id": "q-4", "text": "Locked", "status": "locked", "locked_by": "mod"}`
+	report, err := s.svc.Import(ctx, strings.NewReader(input), transfer.FormatNDJSON, transfer.ImportOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 2, report.Created)
+	require.Equal(t, []transfer.LineError{
+		{Line: 2, Error: `unknown status "archived"`},
+		{Line: 3, Error: `unknown close_reason "spam"`},
+	}, report.Errors)
+
+	found, err := s.questions.FindByExternalIDs(ctx, []string{"q-1", "q-4"})
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	for _, q := range found {
+		require.Nil(t, q.MergedIntoID)
+		switch *q.ExternalID {
+		case "q-1":
+			require.Equal(t, domain.QuestionClosed, q.Status)
+			require.Equal(t, domain.CloseReasonResolved, q.CloseReason)
+			require.NotNil(t, q.ClosedAt)
+			require.Nil(t, q.LockedAt)
+			require.Empty(t, q.LockedBy)
+		case "q-4":
+			require.Equal(t, domain.QuestionLocked, q.Status)
+			require.NotNil(t, q.LockedAt)
+			require.Nil(t, q.ClosedAt)
+		}
+	}
+
+	// без status сохранённый статус не меняется, с status — заменяется
+	update := `{"external_id": "q-1", "text": "Closed"}
+{"external_id": "q-4", "text": "Locked", "status": "open"}`
+	report, err = s.svc.Import(ctx, strings.NewReader(update), transfer.FormatNDJSON, transfer.ImportOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Unchanged)
+	require.Equal(t, 1, report.Updated)
+
+	found, err = s.questions.FindByExternalIDs(ctx, []string{"q-1", "q-4"})
+	require.NoError(t, err)
+	for _, q := range found {
+		switch *q.ExternalID {
+		case "q-1":
+			require.Equal(t, domain.QuestionClosed, q.Status)
+		case "q-4":
+			require.Equal(t, domain.QuestionOpen, q.Status)
+			require.Nil(t, q.LockedAt)
+		}
+	}
+}
+
+func TestImport_ExternalIDInTrash(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore()
+
+	data := `{"external_id": "q-1", "text": "Q", "answers": [{"user_id": "u1", "text": "A1"}]}`
+	_, err := s.svc.Import(ctx, strings.NewReader(data), transfer.FormatNDJSON, transfer.ImportOptions{})
+	require.NoError(t, err)
+	require.NoError(t, s.questions.Delete(ctx, 1, 0))
+
+	report, err := s.svc.Import(ctx, strings.NewReader(data), transfer.FormatNDJSON, transfer.ImportOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 0, report.Created)
+	require.Equal(t, 1, report.Failed)
+	require.Contains(t, report.Errors[0].Error, "in trash")
+
+	// восстановленный вопрос снова обновляется загрузкой
+	require.NoError(t, s.questions.Restore(ctx, 1))
+	report, err = s.svc.Import(ctx, strings.NewReader(data), transfer.FormatNDJSON, transfer.ImportOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Unchanged)
+}
+
+func TestImport_DryRun(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore()
+	s.fill(t, 4)
+	before := s.export(t, transfer.FormatNDJSON)
+
+	input := `{"external_id": "legacy-2", "text": "Changed"}
+{"external_id": "legacy-4", "text": "Changed too"}
+{"text": "Brand new"}
+{"text": ""}`
+	report, err := s.svc.Import(ctx, strings.NewReader(input), transfer.FormatNDJSON, transfer.ImportOptions{DryRun: true})
+	require.NoError(t, err)
+	require.True(t, report.DryRun)
+	require.Equal(t, 2, report.Updated)
+	require.Equal(t, 1, report.Created)
+	require.Equal(t, 1, report.Failed)
+
+	require.Equal(t, before, s.export(t, transfer.FormatNDJSON))
+}
+
+func TestImport_ReportsErrorsPerLine(t *testing.T) {
+	ctx := context.Background()
+
+	cases := map[transfer.Format]struct {
+		input string
+		lines []int
+	}{
+		transfer.FormatNDJSON: {
+			input: `{"text": "ok"}
+
+{"text": "   "}
+{"text": "ok", "answers": [{"user_id": "", "text": "a"}]}
+{"text": "ok", "title": "unknown field"}
+not json
+{"external_id": "", "text": "ok"}
+{"text": "ok", "answers": [{"id": 1, "parent_answer_id": 2, "user_id": "u1", "text": "a"}, {"id": 2, "user_id": "u2", "text": "b"}]}
+{"text": "ok"}`,
+			lines: []int{3, 4, 5, 6, 7, 8},
+		},
+		transfer.FormatCSV: {
+			input: "question_text,answer_user_id,answer_text,answer_created_at\n" +
+				"ok,u1,a,\n" +
+				"ok,u2,b,yesterday\n" +
+				"\"multi\nline\",,,\n" +
+				"broken,row\n" +
+				"second,u1,,\n",
+			lines: []int{3, 6, 7},
+		},
+		transfer.FormatJSON: {
+			input: `[
+  {"text": "ok"},
+  {
+    "text": ""
+  },
+  {"text": "ok", "answers": [{"user_id": "u1"}]},
+  {"text": 42},
+  {"text": "ok"}
+]`,
+			lines: []int{3, 6, 7},
+		},
+	}
+
+	for format, tc := range cases {
+		t.Run(string(format), func(t *testing.T) {
+			s := newTestStore()
+			report, err := s.svc.Import(ctx, strings.NewReader(tc.input), format, transfer.ImportOptions{})
+			require.NoError(t, err)
+
+			var lines []int
+			for _, e := range report.Errors {
+				require.NotEmpty(t, e.Error)
+				lines = append(lines, e.Line)
+			}
+			require.Equal(t, tc.lines, lines, "%+v", report.Errors)
+			require.Equal(t, len(tc.lines), report.Failed)
+			require.Positive(t, report.Created)
+		})
+	}
+}
+
+func TestImport_InvalidInputIsNotApplied(t *testing.T) {
+	ctx := context.Background()
+
+	cases := []struct {
+		format transfer.Format
+		input  string
+	}{
+		{transfer.FormatJSON, `[{"text": "ok"}, {"text": "broken"`},
+		{transfer.FormatJSON, `{"text": "not an array"}`},
+		{transfer.FormatJSON, `[{"text": "ok"}] trailing`},
+		{transfer.FormatCSV, ""},
+		{transfer.FormatCSV, "question_text,title\nok,t\n"},
+		{transfer.FormatCSV, "answer_text\nx\n"},
+	}
+
+	for _, tc := range cases {
+		s := newTestStore()
+		_, err := s.svc.Import(ctx, strings.NewReader(tc.input), tc.format, transfer.ImportOptions{})
+		require.ErrorIs(t, err, transfer.ErrInvalidInput, "%s: %q", tc.format, tc.input)
+
+		all, err := s.questions.List(ctx, "", 0, 0)
+		require.NoError(t, err)
+		require.Empty(t, all)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for in, want := range map[string]transfer.Format{"ndjson": transfer.FormatNDJSON, "JSONL": transfer.FormatNDJSON, "csv": transfer.FormatCSV, "json": transfer.FormatJSON} {
+		got, err := transfer.ParseFormat(in)
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	}
+	_, err := transfer.ParseFormat("xml")
+	require.Error(t, err)
+
+	f, ok := transfer.FormatFromContentType("text/csv; charset=utf-8")
+	require.True(t, ok)
+	require.Equal(t, transfer.FormatCSV, f)
+	f, ok = transfer.FormatFromPath("backup.jsonl")
+	require.True(t, ok)
+	require.Equal(t, transfer.FormatNDJSON, f)
+}
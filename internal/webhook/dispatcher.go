@@ -0,0 +1,202 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"question-service/internal/domain"
+	"question-service/internal/logger"
+	"question-service/internal/repository"
+)
+
+// DispatcherConfig — настройки отправки вебхуков.
+type DispatcherConfig struct {
+	// Interval — пауза между опросами очереди, когда она пуста.
+	Interval  time.Duration
+	BatchSize int
+	// Timeout — таймаут одного HTTP-запроса к получателю.
+	Timeout time.Duration
+	// MaxAttempts — после стольких неудач доставка переходит в dead.
+	MaxAttempts int
+	// DisableAfter — после стольких неудач подряд подписка выключается (0 — никогда).
+	DisableAfter int
+	// RetryInitialDelay и RetryMaxDelay задают экспоненциальную задержку повторов.
+	RetryInitialDelay time.Duration
+	RetryMaxDelay     time.Duration
+	// AllowPrivateTargets разрешает доставку на адреса во внутренней сети, как
+	// WebhookService.SetAllowPrivateTargets для подписок.
+	AllowPrivateTargets bool
+}
+
+// Dispatcher отправляет доставки из очереди подписчикам.
+//
+// Доставка забирается с арендой: пока запрос выполняется, другой экземпляр её
+// не возьмёт, а если экземпляр упал, доставка вернётся в очередь по истечении аренды.
+type Dispatcher struct {
+	repo   repository.WebhookRepository
+	cfg    DispatcherConfig
+	client *http.Client
+	log    *logger.Logger
+}
+
+func NewDispatcher(repo repository.WebhookRepository, cfg DispatcherConfig, log *logger.Logger) *Dispatcher {
+	return &Dispatcher{
+		repo:   repo,
+		cfg:    cfg,
+		client: newClient(cfg.Timeout, cfg.AllowPrivateTargets),
+		log:    log,
+	}
+}
+
+// Run отправляет доставки, пока не отменён ctx.
+func (d *Dispatcher) Run(ctx context.Context) error {
+	for {
+		n, err := d.DispatchBatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			d.log.Error("webhook dispatch failed", zap.Error(err))
+		}
+
+		if n == 0 || err != nil {
+			t := time.NewTimer(d.cfg.Interval)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return nil
+			case <-t.C:
+			}
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// DispatchBatch отправляет одну пачку доставок, время которых пришло,
+// и возвращает количество обработанных.
+func (d *Dispatcher) DispatchBatch(ctx context.Context) (int, error) {
+	lease := 2*d.cfg.Timeout + time.Minute
+	deliveries, err := d.repo.ClaimDueDeliveries(ctx, time.Now().UTC(), lease, d.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range deliveries {
+		if err := d.dispatch(ctx, &deliveries[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(deliveries), nil
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	sub, err := d.repo.GetSubscription(ctx, delivery.SubscriptionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// подписку удалили вместе с доставками
+			return nil
+		}
+		return err
+	}
+
+	status, sendErr := d.send(ctx, sub, delivery)
+	now := time.Now().UTC()
+	delivery.Attempts++
+	delivery.LastStatusCode = status
+
+	if sendErr == nil {
+		delivery.Status = domain.DeliverySucceeded
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+		if err := d.repo.SaveDeliveryResult(ctx, delivery); err != nil {
+			return err
+		}
+		return d.repo.RecordSubscriptionSuccess(ctx, sub.ID)
+	}
+
+	delivery.LastError = sendErr.Error()
+	if delivery.Attempts >= d.cfg.MaxAttempts {
+		delivery.Status = domain.DeliveryDead
+		d.log.Warn("webhook delivery is dead",
+			zap.Int64("delivery_id", delivery.ID),
+			zap.Int64("subscription_id", sub.ID),
+			zap.Int("attempts", delivery.Attempts),
+			zap.Error(sendErr),
+		)
+	} else {
+		delivery.NextAttemptAt = now.Add(d.retryDelay(delivery.Attempts))
+		d.log.Info("webhook delivery failed, will retry",
+			zap.Int64("delivery_id", delivery.ID),
+			zap.Int64("subscription_id", sub.ID),
+			zap.Int("attempt", delivery.Attempts),
+			zap.Time("next_attempt_at", delivery.NextAttemptAt),
+			zap.Error(sendErr),
+		)
+	}
+	if err := d.repo.SaveDeliveryResult(ctx, delivery); err != nil {
+		return err
+	}
+
+	disabled, err := d.repo.RecordSubscriptionFailure(ctx, sub.ID, d.cfg.DisableAfter, now)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if disabled {
+		d.log.Warn("webhook subscription disabled after repeated failures",
+			zap.Int64("subscription_id", sub.ID),
+			zap.String("url", sub.URL),
+		)
+	}
+	return nil
+}
+
+// send выполняет один HTTP-запрос и возвращает код ответа (0, если ответа нет).
+func (d *Dispatcher) send(ctx context.Context, sub *domain.WebhookSubscription, delivery *domain.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "question-service-webhooks")
+	req.Header.Set("X-Webhook-Event", delivery.EventType)
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set("X-Event-ID", strconv.FormatInt(delivery.EventID, 10))
+	req.Header.Set(SignatureHeader, Sign(sub.Secret, time.Now(), delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("receiver responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// retryDelay — экспоненциальная задержка перед попыткой attempt+1 с джиттером:
+// половина задержки фиксирована, вторая половина случайна.
+func (d *Dispatcher) retryDelay(attempt int) time.Duration {
+	delay := d.cfg.RetryInitialDelay
+	for i := 1; i < attempt && delay < d.cfg.RetryMaxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, d.cfg.RetryMaxDelay)
+	if delay <= 0 {
+		return 0
+	}
+
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
@@ -0,0 +1,134 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"go.uber.org/zap"
+
+	"question-service/internal/domain"
+	"question-service/internal/logger"
+	"question-service/internal/repository"
+)
+
+// Fanout ставит события из outbox в очередь доставки каждой включённой подписке
+// на их тип. Сами запросы отправляет Dispatcher.
+//
+// Fanout читает outbox независимо от outbox.Relay: недоступный внешний
+// публикатор не задерживает вебхуки, а отброшенные relay события всё равно
+// раздаются подписчикам. Раздаёт только один экземпляр сервиса.
+type Fanout struct {
+	events    repository.OutboxRepository
+	repo      repository.WebhookRepository
+	interval  time.Duration
+	batchSize int
+	log       *logger.Logger
+}
+
+func NewFanout(events repository.OutboxRepository, repo repository.WebhookRepository, interval time.Duration, batchSize int, log *logger.Logger) *Fanout {
+	return &Fanout{
+		events:    events,
+		repo:      repo,
+		interval:  interval,
+		batchSize: batchSize,
+		log:       log,
+	}
+}
+
+// Run раздаёт события, пока не отменён ctx.
+func (f *Fanout) Run(ctx context.Context) error {
+	for {
+		acquired, err := f.events.WithFanoutLock(ctx, f.drain)
+		if err != nil && ctx.Err() == nil {
+			f.log.Error("webhook fanout failed", zap.Error(err))
+		}
+		if !acquired {
+			f.log.Debug("webhook fanout lock is held by another instance")
+		}
+
+		if !sleep(ctx, f.interval) {
+			return nil
+		}
+	}
+}
+
+// drain раздаёт пачки событий, пока держит блокировку.
+func (f *Fanout) drain(ctx context.Context) error {
+	for {
+		n, err := f.FanoutBatch(ctx)
+		if err != nil {
+			return err
+		}
+
+		if n == 0 && !sleep(ctx, f.interval) {
+			return nil
+		}
+	}
+}
+
+// FanoutBatch раздаёт одну пачку событий и возвращает их количество.
+// При ошибке пачка раздаётся повторно: повтор не создаёт повторных доставок.
+func (f *Fanout) FanoutBatch(ctx context.Context) (int, error) {
+	events, err := f.events.FetchNotFannedOut(ctx, f.batchSize)
+	if err != nil || len(events) == 0 {
+		return 0, err
+	}
+
+	subs, err := f.repo.ListActiveSubscriptions(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, ev := range events {
+		if err := f.enqueue(ctx, subs, ev); err != nil {
+			return i, err
+		}
+		if err := f.events.MarkFannedOut(ctx, ev.ID, time.Now().UTC()); err != nil {
+			return i, err
+		}
+	}
+	return len(events), nil
+}
+
+// enqueue ставит событие в очередь доставки подпискам subs, которые на него подписаны.
+func (f *Fanout) enqueue(ctx context.Context, subs []domain.WebhookSubscription, ev domain.Event) error {
+	var payload []byte
+	var deliveries []domain.WebhookDelivery
+	now := time.Now().UTC()
+
+	for _, s := range subs {
+		if !s.Matches(ev.Type) {
+			continue
+		}
+		if payload == nil {
+			var err error
+			if payload, err = json.Marshal(ev); err != nil {
+				return err
+			}
+		}
+		deliveries = append(deliveries, domain.WebhookDelivery{
+			SubscriptionID: s.ID,
+			EventID:        ev.ID,
+			EventType:      ev.Type,
+			Payload:        payload,
+			Status:         domain.DeliveryPending,
+			NextAttemptAt:  now,
+		})
+	}
+
+	return f.repo.EnqueueDeliveries(ctx, deliveries)
+}
+
+// sleep ждёт d и возвращает false, если за это время отменён ctx.
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
@@ -0,0 +1,279 @@
+package webhook_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"question-service/internal/domain"
+	"question-service/internal/logger"
+	"question-service/internal/outbox"
+	"question-service/internal/repository"
+	"question-service/internal/service"
+	"question-service/internal/webhook"
+)
+
+const testSecret = "whsec_test"
+
+type env struct {
+	repo       *repository.MemoryWebhookRepository
+	events     *repository.MemoryOutboxRepository
+	fanout     *webhook.Fanout
+	dispatcher *webhook.Dispatcher
+	questions  *service.QuestionService
+	sub        *domain.WebhookSubscription
+}
+
+func newEnv(t *testing.T, receiverURL string, cfg webhook.DispatcherConfig) *env {
+	t.Helper()
+
+	store := repository.NewMemoryStore()
+	log := &logger.Logger{Logger: zap.NewNop()}
+	repo := repository.NewMemoryWebhookRepository(store)
+	events := repository.NewMemoryOutboxRepository(store)
+
+	// получатель — httptest.Server на 127.0.0.1
+	svc := service.NewWebhookService(repo)
+	svc.SetAllowPrivateTargets(true)
+
+	url, secret := receiverURL, testSecret
+	sub, err := svc.CreateSubscription(context.Background(), service.WebhookInput{
+		URL:        &url,
+		EventTypes: []string{domain.EventQuestionCreated},
+		Secret:     &secret,
+	})
+	require.NoError(t, err)
+
+	cfg.BatchSize = 10
+	cfg.AllowPrivateTargets = true
+	return &env{
+		repo:       repo,
+		events:     events,
+		fanout:     webhook.NewFanout(events, repo, time.Millisecond, 10, log),
+		dispatcher: webhook.NewDispatcher(repo, cfg, log),
+		questions:  service.NewQuestionService(repository.NewMemoryQuestionRepository(store), events, store),
+		sub:        sub,
+	}
+}
+
+// createQuestion создаёт вопрос и переносит событие из outbox в очередь доставки.
+func (e *env) createQuestion(t *testing.T) {
+	t.Helper()
+	_, err := e.questions.CreateQuestion(context.Background(), "What is a webhook?")
+	require.NoError(t, err)
+	_, err = e.fanout.FanoutBatch(context.Background())
+	require.NoError(t, err)
+}
+
+type failingPublisher struct{}
+
+func (failingPublisher) Publish(context.Context, domain.Event) error {
+	return errors.New("sink is down")
+}
+
+func TestFanout_DoesNotWaitForPublisher(t *testing.T) {
+	e := newEnv(t, "http://127.0.0.1:1", webhook.DispatcherConfig{Timeout: time.Second, MaxAttempts: 3})
+	ctx := context.Background()
+
+	_, err := e.questions.CreateQuestion(ctx, "What is a webhook?")
+	require.NoError(t, err)
+
+	// внешний публикатор недоступен, и relay отбрасывает событие
+	relay := outbox.NewRelay(e.events, failingPublisher{}, time.Millisecond, 10, &logger.Logger{Logger: zap.NewNop()})
+	relay.SetMaxAttempts(1)
+	_, err = relay.PublishBatch(ctx)
+	require.NoError(t, err)
+	pending, err := e.events.FetchUnpublished(ctx, 10)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+
+	n, err := e.fanout.FanoutBatch(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	n, err = e.fanout.FanoutBatch(ctx)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	deliveries, err := e.repo.ListDeliveries(ctx, e.sub.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	require.Equal(t, domain.DeliveryPending, deliveries[0].Status)
+}
+
+func TestDispatcher_DeliversSignedEvent(t *testing.T) {
+	var verifyErr error
+	var eventType string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		eventType = r.Header.Get("X-Webhook-Event")
+		verifyErr = webhook.Verify(testSecret, r.Header.Get(webhook.SignatureHeader), body, time.Now(), time.Minute)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	e := newEnv(t, srv.URL, webhook.DispatcherConfig{Timeout: time.Second, MaxAttempts: 3})
+	e.createQuestion(t)
+
+	n, err := e.dispatcher.DispatchBatch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.NoError(t, verifyErr)
+	require.Equal(t, domain.EventQuestionCreated, eventType)
+
+	deliveries, err := e.repo.ListDeliveries(context.Background(), e.sub.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	require.Equal(t, domain.DeliverySucceeded, deliveries[0].Status)
+	require.Equal(t, http.StatusNoContent, deliveries[0].LastStatusCode)
+	require.NotNil(t, deliveries[0].DeliveredAt)
+}
+
+func TestDispatcher_RetriesUntilDead(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	e := newEnv(t, srv.URL, webhook.DispatcherConfig{Timeout: time.Second, MaxAttempts: 3})
+	e.createQuestion(t)
+
+	for range 5 {
+		_, err := e.dispatcher.DispatchBatch(context.Background())
+		require.NoError(t, err)
+	}
+	require.EqualValues(t, 3, calls.Load())
+
+	deliveries, err := e.repo.ListDeliveries(context.Background(), e.sub.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	require.Equal(t, domain.DeliveryDead, deliveries[0].Status)
+	require.Equal(t, 3, deliveries[0].Attempts)
+	require.Equal(t, http.StatusServiceUnavailable, deliveries[0].LastStatusCode)
+}
+
+func TestDispatcher_BacksOffBetweenAttempts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	e := newEnv(t, srv.URL, webhook.DispatcherConfig{
+		Timeout:           time.Second,
+		MaxAttempts:       3,
+		RetryInitialDelay: time.Hour,
+		RetryMaxDelay:     time.Hour,
+	})
+	e.createQuestion(t)
+
+	n, err := e.dispatcher.DispatchBatch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	// следующая попытка отложена, поэтому сейчас отправлять нечего
+	n, err = e.dispatcher.DispatchBatch(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	deliveries, err := e.repo.ListDeliveries(context.Background(), e.sub.ID, 10)
+	require.NoError(t, err)
+	require.Equal(t, domain.DeliveryPending, deliveries[0].Status)
+	require.True(t, deliveries[0].NextAttemptAt.After(time.Now().Add(29*time.Minute)))
+}
+
+func TestDispatcher_DisablesFailingSubscription(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	e := newEnv(t, srv.URL, webhook.DispatcherConfig{Timeout: time.Second, MaxAttempts: 10, DisableAfter: 2})
+	e.createQuestion(t)
+
+	for range 3 {
+		_, err := e.dispatcher.DispatchBatch(context.Background())
+		require.NoError(t, err)
+	}
+
+	sub, err := e.repo.GetSubscription(context.Background(), e.sub.ID)
+	require.NoError(t, err)
+	require.False(t, sub.Active)
+	require.NotNil(t, sub.DisabledAt)
+	require.Equal(t, 2, sub.ConsecutiveFailures)
+
+	// выключенной подписке новые события не ставятся
+	e.createQuestion(t)
+	deliveries, err := e.repo.ListDeliveries(context.Background(), e.sub.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	require.Equal(t, 2, deliveries[0].Attempts)
+}
+
+func TestDispatcher_RejectsPrivateTargets(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	// подписка прошла проверку, а при доставке адрес оказался внутренним
+	e := newEnv(t, srv.URL, webhook.DispatcherConfig{})
+	e.createQuestion(t)
+	strict := webhook.NewDispatcher(e.repo, webhook.DispatcherConfig{Timeout: time.Second, MaxAttempts: 3, BatchSize: 10}, &logger.Logger{Logger: zap.NewNop()})
+
+	n, err := strict.DispatchBatch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Zero(t, calls.Load())
+
+	deliveries, err := e.repo.ListDeliveries(context.Background(), e.sub.ID, 10)
+	require.NoError(t, err)
+	require.Equal(t, domain.DeliveryPending, deliveries[0].Status)
+	require.Contains(t, deliveries[0].LastError, "private")
+}
+
+func TestDispatcher_DoesNotFollowRedirects(t *testing.T) {
+	var internal atomic.Int32
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		internal.Add(1)
+	}))
+	defer target.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer srv.Close()
+
+	e := newEnv(t, srv.URL, webhook.DispatcherConfig{Timeout: time.Second, MaxAttempts: 3})
+	e.createQuestion(t)
+
+	_, err := e.dispatcher.DispatchBatch(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, internal.Load())
+
+	deliveries, err := e.repo.ListDeliveries(context.Background(), e.sub.ID, 10)
+	require.NoError(t, err)
+	require.Equal(t, domain.DeliveryPending, deliveries[0].Status)
+	require.Equal(t, http.StatusTemporaryRedirect, deliveries[0].LastStatusCode)
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"type":"question.created"}`)
+	now := time.Now()
+	header := webhook.Sign(testSecret, now, body)
+
+	require.NoError(t, webhook.Verify(testSecret, header, body, now, time.Minute))
+	require.ErrorIs(t, webhook.Verify("other", header, body, now, time.Minute), webhook.ErrInvalidSignature)
+	require.ErrorIs(t, webhook.Verify(testSecret, header, []byte(`{}`), now, time.Minute), webhook.ErrInvalidSignature)
+	require.ErrorIs(t, webhook.Verify(testSecret, header, body, now.Add(time.Hour), time.Minute), webhook.ErrSignatureExpired)
+}
@@ -0,0 +1,26 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"question-service/internal/app"
+	"question-service/internal/logger"
+	"question-service/internal/repository"
+)
+
+// IdempotencyJanitor периодически удаляет истёкшие ключи идемпотентности.
+func IdempotencyJanitor(repo repository.IdempotencyRepository, interval time.Duration, log *logger.Logger) app.Worker {
+	return Periodic("idempotency-janitor", interval, log, func(ctx context.Context) error {
+		n, err := repo.DeleteExpired(ctx, time.Now())
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			log.Info("expired idempotency keys purged", zap.Int64("count", n))
+		}
+		return nil
+	})
+}
@@ -0,0 +1,33 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"question-service/internal/app"
+	"question-service/internal/logger"
+)
+
+// Periodic возвращает фоновую задачу, которая вызывает fn каждые interval, пока не
+// отменён контекст. interval должен быть положительным. Ошибка одного запуска
+// логируется и не останавливает задачу.
+func Periodic(name string, interval time.Duration, log *logger.Logger, fn func(ctx context.Context) error) app.Worker {
+	return func(ctx context.Context) error {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+
+			if err := fn(ctx); err != nil && ctx.Err() == nil {
+				log.Error("periodic job failed", zap.String("job", name), zap.Error(err))
+			}
+		}
+	}
+}
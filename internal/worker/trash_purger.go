@@ -0,0 +1,30 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"question-service/internal/app"
+	"question-service/internal/logger"
+	"question-service/internal/service"
+)
+
+// TrashPurger периодически окончательно удаляет вопросы и ответы, пролежавшие
+// в корзине дольше retention.
+func TrashPurger(trash *service.TrashService, retention, interval time.Duration, log *logger.Logger) app.Worker {
+	return Periodic("trash-purger", interval, log, func(ctx context.Context) error {
+		questions, answers, err := trash.Purge(ctx, retention)
+		if err != nil {
+			return err
+		}
+		if questions > 0 || answers > 0 {
+			log.Info("trash purged",
+				zap.Int64("questions", questions),
+				zap.Int64("answers", answers),
+			)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE answers   ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+-- +goose Down
+ALTER TABLE answers   DROP COLUMN IF EXISTS version;
+ALTER TABLE questions DROP COLUMN IF EXISTS version;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- ключ действует в пределах метода и пути: один и тот же Idempotency-Key для разных
+-- маршрутов — разные запросы
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key           VARCHAR(255)  NOT NULL,
+    method        VARCHAR(16)   NOT NULL,
+    path          VARCHAR(2048) NOT NULL,
+    fingerprint   CHAR(64)      NOT NULL,
+    status        VARCHAR(16)   NOT NULL,
+    response_code INTEGER       NOT NULL DEFAULT 0,
+    content_type  VARCHAR(255)  NOT NULL DEFAULT '',
+    response_body BYTEA,
+    created_at    TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    expires_at    TIMESTAMPTZ   NOT NULL,
+    PRIMARY KEY (key, method, path)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS idempotency_keys;
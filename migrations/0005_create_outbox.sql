@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS outbox (
+    id             BIGSERIAL PRIMARY KEY,
+    event_type     VARCHAR(64) NOT NULL,
+    aggregate_type VARCHAR(32) NOT NULL,
+    aggregate_id   BIGINT      NOT NULL,
+    payload        JSONB       NOT NULL,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    published_at   TIMESTAMPTZ,
+    -- событие, которое не удалось опубликовать за OUTBOX_MAX_ATTEMPTS попыток, больше не повторяется
+    dead_at        TIMESTAMPTZ,
+    attempts       INTEGER     NOT NULL DEFAULT 0,
+    last_error     TEXT        NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_unpublished ON outbox (id) WHERE published_at IS NULL AND dead_at IS NULL;
+-- по нему relay находит агрегаты, у которых первое событие ждёт повтора
+CREATE INDEX IF NOT EXISTS idx_outbox_pending_aggregate ON outbox (aggregate_type, aggregate_id, id)
+    WHERE published_at IS NULL AND dead_at IS NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS outbox;
@@ -0,0 +1,44 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id                   BIGSERIAL PRIMARY KEY,
+    url                  TEXT        NOT NULL,
+    event_types          JSONB       NOT NULL,
+    secret               TEXT        NOT NULL,
+    active               BOOLEAN     NOT NULL DEFAULT TRUE,
+    consecutive_failures INTEGER     NOT NULL DEFAULT 0,
+    disabled_at          TIMESTAMPTZ,
+    created_at           TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at           TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id               BIGSERIAL PRIMARY KEY,
+    subscription_id  BIGINT      NOT NULL,
+    event_id         BIGINT      NOT NULL,
+    event_type       VARCHAR(64) NOT NULL,
+    payload          JSONB       NOT NULL,
+    status           VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts         INTEGER     NOT NULL DEFAULT 0,
+    next_attempt_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_status_code INTEGER     NOT NULL DEFAULT 0,
+    last_error       TEXT        NOT NULL DEFAULT '',
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    delivered_at     TIMESTAMPTZ,
+    CONSTRAINT fk_webhook_deliveries_subscription
+    FOREIGN KEY (subscription_id)
+    REFERENCES webhook_subscriptions (id)
+    ON DELETE CASCADE,
+    CONSTRAINT uq_webhook_deliveries_event UNIQUE (subscription_id, event_id)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+
+-- события раздаются подписчикам независимо от публикации relay
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS fanned_out_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS idx_outbox_not_fanned_out ON outbox (id) WHERE fanned_out_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_outbox_not_fanned_out;
+ALTER TABLE outbox DROP COLUMN IF EXISTS fanned_out_at;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_subscriptions;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS external_id VARCHAR(255);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_questions_external_id ON questions (external_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_questions_external_id;
+ALTER TABLE questions DROP COLUMN IF EXISTS external_id;
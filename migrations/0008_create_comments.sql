@@ -0,0 +1,25 @@
+-- +goose Up
+-- комментарий относится ровно к одному из вопроса или ответа
+CREATE TABLE IF NOT EXISTS comments (
+    id           BIGSERIAL PRIMARY KEY,
+    question_id  BIGINT,
+    answer_id    BIGINT,
+    user_id      VARCHAR(64) NOT NULL,
+    text         TEXT        NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_comments_question
+    FOREIGN KEY (question_id)
+    REFERENCES questions (id)
+    ON DELETE CASCADE,
+    CONSTRAINT fk_comments_answer
+    FOREIGN KEY (answer_id)
+    REFERENCES answers (id)
+    ON DELETE CASCADE,
+    CONSTRAINT chk_comments_target CHECK ((question_id IS NULL) <> (answer_id IS NULL))
+    );
+
+CREATE INDEX IF NOT EXISTS idx_comments_question_id ON comments (question_id) WHERE question_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_comments_answer_id ON comments (answer_id) WHERE answer_id IS NOT NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS comments;
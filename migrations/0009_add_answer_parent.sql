@@ -0,0 +1,14 @@
+-- +goose Up
+-- ответ может быть репликой на другой ответ того же вопроса
+ALTER TABLE answers ADD COLUMN IF NOT EXISTS parent_answer_id BIGINT;
+
+ALTER TABLE answers
+    ADD CONSTRAINT fk_answers_parent
+    FOREIGN KEY (parent_answer_id)
+    REFERENCES answers (id)
+    ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_answers_parent_answer_id ON answers (parent_answer_id) WHERE parent_answer_id IS NOT NULL;
+
+-- +goose Down
+ALTER TABLE answers DROP COLUMN IF EXISTS parent_answer_id;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- удалённые вопросы и ответы остаются в таблицах до очистки корзины
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE answers ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_questions_deleted_at ON questions (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_answers_deleted_at ON answers (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- +goose Down
+DELETE FROM answers WHERE deleted_at IS NOT NULL;
+DELETE FROM questions WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE answers DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE questions DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,55 @@
+-- +goose Up
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS hidden BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS locked BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE answers ADD COLUMN IF NOT EXISTS hidden BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- жалоба относится ровно к одному из вопроса или ответа
+CREATE TABLE IF NOT EXISTS flags (
+    id           BIGSERIAL PRIMARY KEY,
+    question_id  BIGINT,
+    answer_id    BIGINT,
+    user_id      VARCHAR(64) NOT NULL,
+    reason       VARCHAR(32) NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    resolved_at  TIMESTAMPTZ,
+    CONSTRAINT fk_flags_question
+    FOREIGN KEY (question_id)
+    REFERENCES questions (id)
+    ON DELETE CASCADE,
+    CONSTRAINT fk_flags_answer
+    FOREIGN KEY (answer_id)
+    REFERENCES answers (id)
+    ON DELETE CASCADE,
+    CONSTRAINT chk_flags_target CHECK ((question_id IS NULL) <> (answer_id IS NULL))
+    );
+
+-- у пользователя не больше одной открытой жалобы на один вопрос или ответ
+CREATE UNIQUE INDEX IF NOT EXISTS uq_flags_open_question ON flags (question_id, user_id)
+    WHERE resolved_at IS NULL AND question_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_flags_open_answer ON flags (answer_id, user_id)
+    WHERE resolved_at IS NULL AND answer_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_flags_question_id ON flags (question_id) WHERE question_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_flags_answer_id ON flags (answer_id) WHERE answer_id IS NOT NULL;
+
+-- журнал не ссылается на вопросы и ответы и переживает их удаление
+CREATE TABLE IF NOT EXISTS moderation_actions (
+    id              BIGSERIAL PRIMARY KEY,
+    action          VARCHAR(16) NOT NULL,
+    target          VARCHAR(16) NOT NULL,
+    target_id       BIGINT      NOT NULL,
+    question_id     BIGINT      NOT NULL,
+    moderator       VARCHAR(64) NOT NULL,
+    note            TEXT        NOT NULL DEFAULT '',
+    resolved_flags  INTEGER     NOT NULL DEFAULT 0,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_moderation_actions_question_id ON moderation_actions (question_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS moderation_actions;
+DROP TABLE IF EXISTS flags;
+
+ALTER TABLE answers DROP COLUMN IF EXISTS hidden;
+ALTER TABLE questions DROP COLUMN IF EXISTS locked;
+ALTER TABLE questions DROP COLUMN IF EXISTS hidden;
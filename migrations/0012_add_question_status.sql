@@ -0,0 +1,29 @@
+-- +goose Up
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'open';
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS close_reason VARCHAR(32) NOT NULL DEFAULT '';
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS closed_at TIMESTAMPTZ;
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS closed_by VARCHAR(64) NOT NULL DEFAULT '';
+-- блокировка хранится отдельно от закрытия, чтобы после её снятия закрытый вопрос остался закрытым
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS locked_at TIMESTAMPTZ;
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS locked_by VARCHAR(64) NOT NULL DEFAULT '';
+
+-- блокировка модератором становится статусом locked
+UPDATE questions SET status = 'locked' WHERE locked;
+ALTER TABLE questions DROP COLUMN IF EXISTS locked;
+
+ALTER TABLE questions ADD CONSTRAINT chk_questions_status CHECK (status IN ('open', 'closed', 'locked'));
+CREATE INDEX IF NOT EXISTS idx_questions_status ON questions (status);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_questions_status;
+ALTER TABLE questions DROP CONSTRAINT IF EXISTS chk_questions_status;
+
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS locked BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE questions SET locked = TRUE WHERE status = 'locked';
+
+ALTER TABLE questions DROP COLUMN IF EXISTS locked_by;
+ALTER TABLE questions DROP COLUMN IF EXISTS locked_at;
+ALTER TABLE questions DROP COLUMN IF EXISTS closed_by;
+ALTER TABLE questions DROP COLUMN IF EXISTS closed_at;
+ALTER TABLE questions DROP COLUMN IF EXISTS close_reason;
+ALTER TABLE questions DROP COLUMN IF EXISTS status;
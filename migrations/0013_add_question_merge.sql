@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- индекс для оператора % при поиске похожих вопросов
+CREATE INDEX IF NOT EXISTS idx_questions_text_trgm ON questions USING GIN (text gin_trgm_ops);
+
+-- вопрос-заглушка указывает на вопрос, в который перенесены его ответы
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS merged_into_id BIGINT;
+ALTER TABLE questions
+    ADD CONSTRAINT fk_questions_merged_into
+    FOREIGN KEY (merged_into_id)
+    REFERENCES questions (id)
+    ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_questions_merged_into_id ON questions (merged_into_id) WHERE merged_into_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_questions_merged_into_id;
+ALTER TABLE questions DROP CONSTRAINT IF EXISTS fk_questions_merged_into;
+ALTER TABLE questions DROP COLUMN IF EXISTS merged_into_id;
+
+-- расширение pg_trgm не удаляется: им могут пользоваться и другие объекты базы
+DROP INDEX IF EXISTS idx_questions_text_trgm;
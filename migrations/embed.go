@@ -0,0 +1,10 @@
+// Package migrations встраивает SQL-миграции goose в бинарники, чтобы они не
+// зависели от рабочего каталога и не копировались в образ отдельно.
+package migrations
+
+import "embed"
+
+// FS — файлы миграций; они лежат в корне FS.
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Answer — ответ на вопрос.
+type Answer struct {
+	ID         int       `json:"id"`
+	QuestionID int       `json:"question_id"`
+	UserID     string    `json:"user_id"`
+	Text       string    `json:"text"`
+	CreatedAt  time.Time `json:"created_at"`
+	// Version — версия для оптимистичной блокировки, см. DeleteAnswer.
+	Version int `json:"version"`
+	// ParentAnswerID — ответ, на который это реплика; nil у ответов верхнего уровня.
+	ParentAnswerID *int `json:"parent_answer_id,omitempty"`
+	// Depth — глубина реплики в ветке; заполняется только в GetQuestion.
+	Depth int `json:"depth,omitempty"`
+}
+
+// CreateAnswer добавляет ответ пользователя userID к вопросу questionID.
+func (c *Client) CreateAnswer(ctx context.Context, questionID int, userID, text string) (*Answer, error) {
+	var a Answer
+	_, err := c.getJSON(ctx, request{
+		method: http.MethodPost,
+		path:   fmt.Sprintf("/questions/%d/answers", questionID),
+		body:   map[string]string{"user_id": userID, "text": text},
+	}, &a)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// CreateReply добавляет реплику пользователя userID на ответ parentID вопроса questionID.
+func (c *Client) CreateReply(ctx context.Context, questionID, parentID int, userID, text string) (*Answer, error) {
+	var a Answer
+	_, err := c.getJSON(ctx, request{
+		method: http.MethodPost,
+		path:   fmt.Sprintf("/questions/%d/answers", questionID),
+		body:   map[string]any{"user_id": userID, "text": text, "parent_answer_id": parentID},
+	}, &a)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// GetAnswer возвращает ответ.
+func (c *Client) GetAnswer(ctx context.Context, id int) (*Answer, error) {
+	var a Answer
+	if _, err := c.getJSON(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/answers/%d", id)}, &a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// DeleteAnswer удаляет ответ. Если version > 0, ответ удаляется только
+// в этой версии, иначе возвращается ErrVersionMismatch.
+func (c *Client) DeleteAnswer(ctx context.Context, id, version int) error {
+	_, err := c.do(ctx, request{
+		method:  http.MethodDelete,
+		path:    fmt.Sprintf("/answers/%d", id),
+		ifMatch: version,
+	})
+	return err
+}
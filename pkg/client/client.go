@@ -0,0 +1,240 @@
+// Package client — Go-клиент HTTP API сервиса вопросов и ответов.
+//
+//	c, err := client.New("http://localhost:8080", client.Config{})
+//	q, err := c.CreateQuestion(ctx, "What is GORM?")
+//
+// Запросы, завершившиеся ответом 5xx, 429 или сетевой ошибкой, повторяются
+// с экспоненциальной задержкой. POST-запросы отправляются с Idempotency-Key,
+// поэтому их повтор не создаёт дубликатов.
+package client
+
+import (
+	"bytes"
+	"context"
+	cryptorand "crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMaxRetries        = 3
+	defaultRetryInitialDelay = 100 * time.Millisecond
+	defaultRetryMaxDelay     = 5 * time.Second
+)
+
+// Config — настройки клиента. Нулевые значения заменяются значениями по умолчанию.
+type Config struct {
+	// HTTPClient выполняет запросы; nil — http.DefaultClient.
+	HTTPClient *http.Client
+	// Token передаётся в заголовке Authorization: Bearer; пустой — не передаётся.
+	Token string
+	// MaxRetries — сколько раз повторять неудавшийся запрос: 0 — по умолчанию (3),
+	// отрицательное значение отключает повторы.
+	MaxRetries int
+	// RetryInitialDelay и RetryMaxDelay ограничивают задержку между повторами.
+	RetryInitialDelay time.Duration
+	RetryMaxDelay     time.Duration
+}
+
+// Client — клиент API. Безопасен для использования из нескольких горутин.
+type Client struct {
+	baseURL *url.URL
+	http    *http.Client
+	token   string
+
+	maxRetries   int
+	initialDelay time.Duration
+	maxDelay     time.Duration
+}
+
+// New создаёт клиент для сервиса по адресу baseURL, например http://localhost:8080.
+func New(baseURL string, cfg Config) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse base url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("base url must be an absolute http or https url, got %q", baseURL)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+
+	c := &Client{
+		baseURL:      u,
+		http:         cfg.HTTPClient,
+		token:        cfg.Token,
+		maxRetries:   cfg.MaxRetries,
+		initialDelay: cfg.RetryInitialDelay,
+		maxDelay:     cfg.RetryMaxDelay,
+	}
+	if c.http == nil {
+		c.http = http.DefaultClient
+	}
+	switch {
+	case c.maxRetries == 0:
+		c.maxRetries = defaultMaxRetries
+	case c.maxRetries < 0:
+		c.maxRetries = 0
+	}
+	if c.initialDelay <= 0 {
+		c.initialDelay = defaultRetryInitialDelay
+	}
+	if c.maxDelay <= 0 {
+		c.maxDelay = defaultRetryMaxDelay
+	}
+	return c, nil
+}
+
+// request описывает один вызов API.
+type request struct {
+	method  string
+	path    string
+	query   url.Values
+	body    any
+	ifMatch int
+}
+
+// response — успешный ответ API.
+type response struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// do выполняет запрос с повторами и возвращает ответ 2xx.
+// Остальные ответы превращаются в *APIError.
+func (c *Client) do(ctx context.Context, req request) (*response, error) {
+	var body []byte
+	if req.body != nil {
+		b, err := json.Marshal(req.body)
+		if err != nil {
+			return nil, fmt.Errorf("encode request: %w", err)
+		}
+		body = b
+	}
+
+	u := *c.baseURL
+	u.Path += req.path
+	u.RawQuery = req.query.Encode()
+
+	// один ключ на все попытки: сервер вернёт сохранённый ответ вместо повторного создания
+	var idempotencyKey string
+	if req.method == http.MethodPost {
+		idempotencyKey = cryptorand.Text()
+	}
+
+	for attempt := 0; ; attempt++ {
+		httpReq, err := http.NewRequestWithContext(ctx, req.method, u.String(), bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		httpReq.Header.Set("Accept", "application/json")
+		if body != nil {
+			httpReq.Header.Set("Content-Type", "application/json")
+		}
+		if c.token != "" {
+			httpReq.Header.Set("Authorization", "Bearer "+c.token)
+		}
+		if idempotencyKey != "" {
+			httpReq.Header.Set("Idempotency-Key", idempotencyKey)
+		}
+		if req.ifMatch > 0 {
+			httpReq.Header.Set("If-Match", strconv.Quote(strconv.Itoa(req.ifMatch)))
+		}
+
+		resp, err := c.send(httpReq)
+		if err == nil && resp.status < 300 {
+			return resp, nil
+		}
+
+		var retryAfter time.Duration
+		if err == nil {
+			apiErr := newAPIError(resp)
+			if !apiErr.retryable() {
+				return nil, apiErr
+			}
+			retryAfter = parseRetryAfter(resp.header.Get("Retry-After"))
+			err = apiErr
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if attempt >= c.maxRetries {
+			return nil, err
+		}
+
+		delay := backoff(attempt+1, c.initialDelay, c.maxDelay)
+		if retryAfter > delay {
+			delay = min(retryAfter, c.maxDelay)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Client) send(req *http.Request) (*response, error) {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	return &response{status: resp.StatusCode, header: resp.Header, body: body}, nil
+}
+
+// getJSON выполняет запрос и декодирует тело ответа в out.
+func (c *Client) getJSON(ctx context.Context, req request, out any) (*response, error) {
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(resp.body, out); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	return resp, nil
+}
+
+// Health проверяет, что сервис отвечает.
+func (c *Client) Health(ctx context.Context) error {
+	_, err := c.do(ctx, request{method: http.MethodGet, path: "/health"})
+	return err
+}
+
+// backoff — экспоненциальная задержка перед попыткой attempt (с 1) с «equal jitter».
+func backoff(attempt int, initial, max time.Duration) time.Duration {
+	d := initial
+	for i := 1; i < attempt && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+
+	half := d / 2
+	return half + time.Duration(rand.Float64()*float64(d-half))
+}
+
+// parseRetryAfter разбирает Retry-After в секундах; даты не поддерживаются.
+func parseRetryAfter(v string) time.Duration {
+	n, err := strconv.Atoi(strings.TrimSpace(v))
+	if err != nil || n <= 0 {
+		return 0
+	}
+	return time.Duration(n) * time.Second
+}
@@ -0,0 +1,217 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	httptransport "question-service/internal/http"
+	"question-service/internal/logger"
+	"question-service/internal/repository"
+	"question-service/internal/service"
+	"question-service/pkg/client"
+)
+
+// newServer поднимает настоящий роутер на хранилище в памяти. wrap позволяет
+// подменить ответы сервера до роутера.
+func newServer(t *testing.T, wrap func(http.Handler) http.Handler) *client.Client {
+	t.Helper()
+
+	store := repository.NewMemoryStore()
+	qRepo := repository.NewMemoryQuestionRepository(store)
+	events := repository.NewMemoryOutboxRepository(store)
+	qSvc := service.NewQuestionService(qRepo, events, store)
+	aSvc := service.NewAnswerService(repository.NewMemoryAnswerRepository(store), qRepo, events, store, nil)
+
+	var handler http.Handler = httptransport.NewRouter(qSvc, aSvc, &logger.Logger{Logger: zap.NewNop()}, httptransport.Options{
+		Idempotency:    repository.NewMemoryIdempotencyRepository(store),
+		IdempotencyTTL: time.Hour,
+	})
+	if wrap != nil {
+		handler = wrap(handler)
+	}
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	c, err := client.New(srv.URL, client.Config{
+		HTTPClient:        srv.Client(),
+		RetryInitialDelay: time.Millisecond,
+		RetryMaxDelay:     10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	return c
+}
+
+// flaky отвечает status на первые failures запросов, остальные пропускает дальше.
+type flaky struct {
+	mu       sync.Mutex
+	failures int
+	status   int
+	keys     []string
+}
+
+func (f *flaky) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.keys = append(f.keys, r.Header.Get("Idempotency-Key"))
+		fail := len(f.keys) <= f.failures
+		f.mu.Unlock()
+
+		if fail {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "unavailable", f.status)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func TestClient_QuestionsAndAnswers(t *testing.T) {
+	c := newServer(t, nil)
+	ctx := context.Background()
+
+	require.NoError(t, c.Health(ctx))
+
+	q, err := c.CreateQuestion(ctx, "What is GORM?")
+	require.NoError(t, err)
+	require.Equal(t, 1, q.Version)
+
+	a, err := c.CreateAnswer(ctx, q.ID, "user-1", "An ORM")
+	require.NoError(t, err)
+	require.Equal(t, q.ID, a.QuestionID)
+
+	got, err := c.GetQuestion(ctx, q.ID)
+	require.NoError(t, err)
+	require.Len(t, got.Answers, 1)
+	require.Equal(t, "An ORM", got.Answers[0].Text)
+
+	gotAnswer, err := c.GetAnswer(ctx, a.ID)
+	require.NoError(t, err)
+	require.Equal(t, *a, *gotAnswer)
+
+	err = c.DeleteQuestion(ctx, q.ID, q.Version)
+	require.ErrorIs(t, err, client.ErrVersionMismatch)
+
+	require.NoError(t, c.DeleteAnswer(ctx, a.ID, a.Version))
+	_, err = c.GetAnswer(ctx, a.ID)
+	require.ErrorIs(t, err, client.ErrAnswerNotFound)
+
+	require.NoError(t, c.DeleteQuestion(ctx, q.ID, 0))
+	_, err = c.GetQuestion(ctx, q.ID)
+	require.ErrorIs(t, err, client.ErrQuestionNotFound)
+
+	_, err = c.CreateAnswer(ctx, q.ID, "user-1", "late")
+	require.ErrorIs(t, err, client.ErrQuestionNotFound)
+
+	var apiErr *client.APIError
+	require.True(t, errors.As(err, &apiErr))
+	require.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+}
+
+func TestClient_Replies(t *testing.T) {
+	c := newServer(t, nil)
+	ctx := context.Background()
+
+	q, err := c.CreateQuestion(ctx, "What is GORM?")
+	require.NoError(t, err)
+	a, err := c.CreateAnswer(ctx, q.ID, "user-1", "An ORM")
+	require.NoError(t, err)
+
+	reply, err := c.CreateReply(ctx, q.ID, a.ID, "user-2", "Which one?")
+	require.NoError(t, err)
+	require.Equal(t, a.ID, *reply.ParentAnswerID)
+
+	got, err := c.GetQuestion(ctx, q.ID)
+	require.NoError(t, err)
+	require.Len(t, got.Answers, 2)
+	require.Equal(t, 0, got.Answers[0].Depth)
+	require.Equal(t, 1, got.Answers[1].Depth)
+
+	_, err = c.CreateReply(ctx, q.ID, 42, "user-2", "To nothing")
+	var apiErr *client.APIError
+	require.True(t, errors.As(err, &apiErr))
+	require.Equal(t, http.StatusUnprocessableEntity, apiErr.StatusCode)
+}
+
+func TestClient_QuestionsIterator(t *testing.T) {
+	c := newServer(t, nil)
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		_, err := c.CreateQuestion(ctx, fmt.Sprintf("question %d", i))
+		require.NoError(t, err)
+	}
+
+	var ids []int
+	for q, err := range c.Questions(ctx, 2) {
+		require.NoError(t, err)
+		ids = append(ids, q.ID)
+	}
+	require.Equal(t, []int{1, 2, 3, 4, 5}, ids)
+
+	page, err := c.ListQuestionsPage(ctx, 4, 2)
+	require.NoError(t, err)
+	require.Len(t, page.Questions, 1)
+	require.Zero(t, page.NextAfter)
+
+	all, err := c.ListQuestions(ctx)
+	require.NoError(t, err)
+	require.Len(t, all, 5)
+}
+
+func TestClient_RetriesWithSameIdempotencyKey(t *testing.T) {
+	for _, status := range []int{http.StatusServiceUnavailable, http.StatusTooManyRequests} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			f := &flaky{failures: 2, status: status}
+			c := newServer(t, f.wrap)
+
+			q, err := c.CreateQuestion(context.Background(), "retried")
+			require.NoError(t, err)
+			require.Equal(t, 1, q.ID)
+
+			require.Len(t, f.keys, 3)
+			require.NotEmpty(t, f.keys[0])
+			require.Equal(t, f.keys[0], f.keys[1])
+			require.Equal(t, f.keys[0], f.keys[2])
+		})
+	}
+}
+
+func TestClient_GivesUpAfterMaxRetries(t *testing.T) {
+	f := &flaky{failures: 100, status: http.StatusBadGateway}
+	c := newServer(t, f.wrap)
+
+	err := c.Health(context.Background())
+
+	var apiErr *client.APIError
+	require.True(t, errors.As(err, &apiErr))
+	require.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+	require.Len(t, f.keys, 4)
+}
+
+func TestClient_DoesNotRetryClientErrors(t *testing.T) {
+	f := &flaky{}
+	c := newServer(t, f.wrap)
+
+	_, err := c.CreateQuestion(context.Background(), " ")
+
+	var apiErr *client.APIError
+	require.True(t, errors.As(err, &apiErr))
+	require.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	require.Equal(t, "text is required", apiErr.Message)
+	require.Len(t, f.keys, 1)
+}
+
+func TestNew_RejectsRelativeURL(t *testing.T) {
+	_, err := client.New("localhost:8080", client.Config{})
+	require.Error(t, err)
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Ошибки API. Совпадают по смыслу с ошибками сервисного слоя и проверяются
+// через errors.Is:
+//
+//	if errors.Is(err, client.ErrQuestionNotFound) { ... }
+var (
+	ErrQuestionNotFound = errors.New("question not found")
+	ErrAnswerNotFound   = errors.New("answer not found")
+	// ErrVersionMismatch — ресурс изменён после того, как клиент получил его версию.
+	ErrVersionMismatch = errors.New("version mismatch")
+	// ErrPreconditionRequired — сервер требует версию ресурса для изменения.
+	ErrPreconditionRequired = errors.New("precondition required")
+)
+
+// APIError — ответ API с кодом не из диапазона 2xx.
+type APIError struct {
+	StatusCode int
+	// Message — поле error из тела ответа или текст статуса.
+	Message string
+
+	err error
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("question service: %d %s", e.StatusCode, e.Message)
+}
+
+// Unwrap возвращает соответствующую ошибку из списка выше, если она есть.
+func (e *APIError) Unwrap() error {
+	return e.err
+}
+
+func (e *APIError) retryable() bool {
+	return e.StatusCode >= http.StatusInternalServerError || e.StatusCode == http.StatusTooManyRequests
+}
+
+func newAPIError(resp *response) *APIError {
+	e := &APIError{StatusCode: resp.status, Message: http.StatusText(resp.status)}
+
+	var body struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(resp.body, &body) == nil && body.Error != "" {
+		e.Message = body.Error
+	}
+
+	switch {
+	case resp.status == http.StatusNotFound && e.Message == ErrQuestionNotFound.Error():
+		e.err = ErrQuestionNotFound
+	case resp.status == http.StatusNotFound && e.Message == ErrAnswerNotFound.Error():
+		e.err = ErrAnswerNotFound
+	case resp.status == http.StatusPreconditionFailed:
+		e.err = ErrVersionMismatch
+	case resp.status == http.StatusPreconditionRequired:
+		e.err = ErrPreconditionRequired
+	}
+	return e
+}
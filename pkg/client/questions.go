@@ -0,0 +1,136 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Question — вопрос. Answers заполнен только у GetQuestion.
+type Question struct {
+	ID int `json:"id"`
+	// ExternalID — идентификатор во внешней системе, если вопрос загружен через импорт.
+	ExternalID string    `json:"external_id,omitempty"`
+	Text       string    `json:"text"`
+	CreatedAt  time.Time `json:"created_at"`
+	// Version — версия для оптимистичной блокировки, см. DeleteQuestion.
+	Version int      `json:"version"`
+	Answers []Answer `json:"answers,omitempty"`
+}
+
+// QuestionPage — страница списка вопросов.
+type QuestionPage struct {
+	Questions []Question
+	// NextAfter — значение after для следующей страницы; 0 — страница последняя.
+	NextAfter int
+}
+
+// CreateQuestion создаёт вопрос.
+func (c *Client) CreateQuestion(ctx context.Context, text string) (*Question, error) {
+	var q Question
+	_, err := c.getJSON(ctx, request{
+		method: http.MethodPost,
+		path:   "/questions",
+		body:   map[string]string{"text": text},
+	}, &q)
+	if err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+// ListQuestions возвращает все вопросы без ответов. Для больших списков
+// используйте Questions или ListQuestionsPage.
+func (c *Client) ListQuestions(ctx context.Context) ([]Question, error) {
+	var questions []Question
+	if _, err := c.getJSON(ctx, request{method: http.MethodGet, path: "/questions"}, &questions); err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+// ListQuestionsPage возвращает до limit вопросов с id больше after.
+func (c *Client) ListQuestionsPage(ctx context.Context, after, limit int) (*QuestionPage, error) {
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(limit))
+	query.Set("after", strconv.Itoa(after))
+
+	var page QuestionPage
+	resp, err := c.getJSON(ctx, request{method: http.MethodGet, path: "/questions", query: query}, &page.Questions)
+	if err != nil {
+		return nil, err
+	}
+	page.NextAfter = nextAfter(resp.header.Get("Link"))
+	return &page, nil
+}
+
+// Questions перебирает все вопросы по возрастанию id, запрашивая их страницами
+// по pageSize. Ошибка запроса возвращается последним элементом.
+//
+//	for q, err := range c.Questions(ctx, 50) {
+//		if err != nil { ... }
+//	}
+func (c *Client) Questions(ctx context.Context, pageSize int) iter.Seq2[Question, error] {
+	return func(yield func(Question, error) bool) {
+		after := 0
+		for {
+			page, err := c.ListQuestionsPage(ctx, after, pageSize)
+			if err != nil {
+				yield(Question{}, err)
+				return
+			}
+			for _, q := range page.Questions {
+				if !yield(q, nil) {
+					return
+				}
+			}
+			if page.NextAfter == 0 {
+				return
+			}
+			after = page.NextAfter
+		}
+	}
+}
+
+// GetQuestion возвращает вопрос со всеми ответами.
+func (c *Client) GetQuestion(ctx context.Context, id int) (*Question, error) {
+	var q Question
+	if _, err := c.getJSON(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/questions/%d", id)}, &q); err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+// DeleteQuestion удаляет вопрос вместе с ответами. Если version > 0, вопрос
+// удаляется только в этой версии, иначе возвращается ErrVersionMismatch.
+func (c *Client) DeleteQuestion(ctx context.Context, id, version int) error {
+	_, err := c.do(ctx, request{
+		method:  http.MethodDelete,
+		path:    fmt.Sprintf("/questions/%d", id),
+		ifMatch: version,
+	})
+	return err
+}
+
+// nextAfter достаёт параметр after из ссылки rel="next" заголовка Link.
+func nextAfter(link string) int {
+	for _, part := range strings.Split(link, ",") {
+		target, params, ok := strings.Cut(part, ";")
+		if !ok || !strings.Contains(params, `rel="next"`) {
+			continue
+		}
+
+		u, err := url.Parse(strings.Trim(strings.TrimSpace(target), "<>"))
+		if err != nil {
+			return 0
+		}
+		n, _ := strconv.Atoi(u.Query().Get("after"))
+		return n
+	}
+	return 0
+}